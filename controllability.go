@@ -0,0 +1,525 @@
+/*
+   controllability.go
+   Description:
+       Controllability and observability tests "like" MATLAB's ctrb, obsv, ctrbf and obsvf functions,
+       together with the Popov-Belevitch-Hautus (PBH) tests and the Kalman decomposition.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+Ctrb
+Description:
+
+	Computes the controllability matrix [B, AB, A^2 B, ..., A^{n-1} B].
+*/
+func Ctrb(A, B mat.Matrix) (*mat.Dense, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	nB, m := B.Dims()
+	if (n != nA) || (nB != n) {
+		return nil, fmt.Errorf("The dimensions of A (%v x %v) and B (%v x %v) are not compatible.", n, nA, nB, m)
+	}
+
+	// Algorithm
+	controllabilityMatrix := mat.NewDense(n, n*m, nil)
+	block := mat.DenseCopyOf(B)
+	for k := 0; k < n; k++ {
+		controllabilityMatrix.Slice(0, n, k*m, (k+1)*m).(*mat.Dense).Copy(block)
+
+		var nextBlock mat.Dense
+		nextBlock.Mul(A, block)
+		block = &nextBlock
+	}
+
+	return controllabilityMatrix, nil
+}
+
+/*
+Obsv
+Description:
+
+	Computes the observability matrix [C; CA; CA^2; ...; CA^{n-1}].
+*/
+func Obsv(A, C mat.Matrix) (*mat.Dense, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	p, nC := C.Dims()
+	if (n != nA) || (nC != n) {
+		return nil, fmt.Errorf("The dimensions of A (%v x %v) and C (%v x %v) are not compatible.", n, nA, p, nC)
+	}
+
+	// Algorithm
+	observabilityMatrix := mat.NewDense(n*p, n, nil)
+	block := mat.DenseCopyOf(C)
+	for k := 0; k < n; k++ {
+		observabilityMatrix.Slice(k*p, (k+1)*p, 0, n).(*mat.Dense).Copy(block)
+
+		var nextBlock mat.Dense
+		nextBlock.Mul(block, A)
+		block = &nextBlock
+	}
+
+	return observabilityMatrix, nil
+}
+
+/*
+IsControllable
+Description:
+
+	Returns true if the rank of the controllability matrix of sys equals the number of states.
+	The rank is computed with the SVD and the tolerance tol (a nonpositive tol selects the default tolerance).
+*/
+func (sys StateSpace) IsControllable(tol float64) (bool, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return false, err
+	}
+
+	// Algorithm
+	controllabilityMatrix, err := Ctrb(sys.A, sys.B)
+	if err != nil {
+		return false, err
+	}
+
+	rank, err := Rank(controllabilityMatrix, tol)
+	if err != nil {
+		return false, err
+	}
+
+	n, _, _ := sys.Dims()
+	return rank == n, nil
+}
+
+/*
+IsObservable
+Description:
+
+	Returns true if the rank of the observability matrix of sys equals the number of states.
+	The rank is computed with the SVD and the tolerance tol (a nonpositive tol selects the default tolerance).
+*/
+func (sys StateSpace) IsObservable(tol float64) (bool, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return false, err
+	}
+
+	// Algorithm
+	observabilityMatrix, err := Obsv(sys.A, sys.C)
+	if err != nil {
+		return false, err
+	}
+
+	rank, err := Rank(observabilityMatrix, tol)
+	if err != nil {
+		return false, err
+	}
+
+	n, _, _ := sys.Dims()
+	return rank == n, nil
+}
+
+/*
+PBHUncontrollableModes
+Description:
+
+	Applies the Popov-Belevitch-Hautus test: an eigenvalue lambda of A is an uncontrollable mode if
+	rank [lambda I - A, B] < n. Returns the uncontrollable eigenvalues (an empty slice means that (A,B) is
+	controllable). A nonpositive tol selects the default rank tolerance.
+*/
+func PBHUncontrollableModes(A, B mat.Matrix, tol float64) ([]complex128, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	nB, _ := B.Dims()
+	if (n != nA) || (nB != n) {
+		return nil, errors.New("The dimensions of A and B are not compatible.")
+	}
+
+	// Algorithm
+	return pbhTest(A, B, tol)
+}
+
+/*
+PBHUnobservableModes
+Description:
+
+	Applies the Popov-Belevitch-Hautus test: an eigenvalue lambda of A is an unobservable mode if
+	rank [lambda I - A; C] < n. Returns the unobservable eigenvalues (an empty slice means that (A,C) is
+	observable). A nonpositive tol selects the default rank tolerance.
+*/
+func PBHUnobservableModes(A, C mat.Matrix, tol float64) ([]complex128, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	_, nC := C.Dims()
+	if (n != nA) || (nC != n) {
+		return nil, errors.New("The dimensions of A and C are not compatible.")
+	}
+
+	// Algorithm (by duality)
+	return pbhTest(A.T(), C.T(), tol)
+}
+
+/*
+pbhTest
+Description:
+
+	Finds the eigenvalues lambda of A for which rank [lambda I - A, B] < n.
+	The complex rank is computed from the real embedding [Re -Im; Im Re], which has twice the rank.
+*/
+func pbhTest(A, B mat.Matrix, tol float64) ([]complex128, error) {
+	// Constants
+	n, m := B.Dims()
+
+	// Algorithm
+	eigs, err := eigenvalues(A)
+	if err != nil {
+		return nil, err
+	}
+
+	modes := []complex128{}
+	for _, lambda := range eigs {
+		// Build the real embedding of [lambda I - A, B]
+		embedding := mat.NewDense(2*n, 2*(n+m), nil)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				re := -A.At(i, j)
+				im := 0.0
+				if i == j {
+					re += real(lambda)
+					im = imag(lambda)
+				}
+				embedding.Set(i, j, re)
+				embedding.Set(i, n+m+j, -im)
+				embedding.Set(n+i, j, im)
+				embedding.Set(n+i, n+m+j, re)
+			}
+			for j := 0; j < m; j++ {
+				embedding.Set(i, n+j, B.At(i, j))
+				embedding.Set(n+i, 2*n+m+j, B.At(i, j))
+			}
+		}
+
+		rank, err := Rank(embedding, tol)
+		if err != nil {
+			return nil, err
+		}
+		if rank < 2*n {
+			modes = append(modes, lambda)
+		}
+	}
+
+	return modes, nil
+}
+
+type StaircaseForm struct {
+	A          *mat.Dense // Transformed state matrix T^T A T.
+	B          *mat.Dense // Transformed input matrix T^T B.
+	C          *mat.Dense // Transformed output matrix C T.
+	T          *mat.Dense // Orthogonal transformation (the original state is x = T z).
+	BlockSizes []int      // Sizes of the blocks of the staircase. Their sum is the dimension of the controllable (or observable) subspace.
+}
+
+/*
+NumReached
+Description:
+
+	Returns the dimension of the controllable (or observable) subspace that the staircase form found.
+*/
+func (form StaircaseForm) NumReached() int {
+	total := 0
+	for _, size := range form.BlockSizes {
+		total += size
+	}
+	return total
+}
+
+/*
+Ctrbf
+Description:
+
+	Computes the controllability staircase form of (A, B, C) with an orthogonal transformation T.
+	The controllable states come FIRST:
+		T^T A T = [Ac A12; 0 Anc],   T^T B = [Bc; 0],   C T = [Cc Cnc],
+	and (Ac, Bc) is controllable. Each step of the staircase uses an SVD with the tolerance tol to decide the rank
+	(a nonpositive tol selects 10 n eps max(||A||, ||B||)).
+*/
+func Ctrbf(A, B, C mat.Matrix, tol float64) (StaircaseForm, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	nB, m := B.Dims()
+	p, nC := C.Dims()
+	if (n != nA) || (nB != n) || (nC != n) {
+		return StaircaseForm{}, errors.New("The dimensions of A, B and C are not compatible.")
+	}
+
+	if !(tol > 0) {
+		tol = 10 * float64(n) * eps * math.Max(math.Max(mat.Norm(A, 1), mat.Norm(B, 1)), 1)
+	}
+
+	// Algorithm
+	T := eye(n)
+	blockSizes := []int{}
+
+	offset := 0
+	currentA := mat.DenseCopyOf(A)
+	currentB := mat.DenseCopyOf(B)
+	for offset < n {
+		U, values, _, err := fullSVD(currentB)
+		if err != nil {
+			return StaircaseForm{}, err
+		}
+
+		rank := countAbove(values, tol)
+		if rank == 0 {
+			break
+		}
+		blockSizes = append(blockSizes, rank)
+
+		// Rotate the remaining coordinates so that the first rank of them are reached by currentB.
+		remaining := n - offset
+		TTail := T.Slice(0, n, offset, n).(*mat.Dense)
+		var rotated mat.Dense
+		rotated.Mul(TTail, U)
+		TTail.Copy(&rotated)
+
+		if rank == remaining {
+			break
+		}
+
+		var rotatedA, temp mat.Dense
+		temp.Mul(U.T(), currentA)
+		rotatedA.Mul(&temp, U)
+
+		currentB = mat.DenseCopyOf(rotatedA.Slice(rank, remaining, 0, rank))
+		currentA = mat.DenseCopyOf(rotatedA.Slice(rank, remaining, rank, remaining))
+		offset += rank
+	}
+
+	// Transform the model
+	var TA, Abar mat.Dense
+	TA.Mul(T.T(), A)
+	Abar.Mul(&TA, T)
+
+	Bbar := mat.NewDense(n, m, nil)
+	Bbar.Mul(T.T(), B)
+
+	Cbar := mat.NewDense(p, n, nil)
+	Cbar.Mul(C, T)
+
+	// Clean the exact zeros of the staircase structure
+	form := StaircaseForm{A: &Abar, B: Bbar, C: Cbar, T: T, BlockSizes: blockSizes}
+	nc := form.NumReached()
+	for i := nc; i < n; i++ {
+		for j := 0; j < nc; j++ {
+			Abar.Set(i, j, 0)
+		}
+		for j := 0; j < m; j++ {
+			Bbar.Set(i, j, 0)
+		}
+	}
+
+	return form, nil
+}
+
+/*
+Obsvf
+Description:
+
+	Computes the observability staircase form of (A, B, C) with an orthogonal transformation T.
+	The observable states come FIRST:
+		T^T A T = [Ao 0; A21 Ano],   T^T B = [Bo; Bno],   C T = [Co 0],
+	and (Ao, Co) is observable. This is the dual of Ctrbf.
+*/
+func Obsvf(A, B, C mat.Matrix, tol float64) (StaircaseForm, error) {
+	// Algorithm
+	dualForm, err := Ctrbf(A.T(), C.T(), B.T(), tol)
+	if err != nil {
+		return StaircaseForm{}, err
+	}
+
+	return StaircaseForm{
+		A:          mat.DenseCopyOf(dualForm.A.T()),
+		B:          mat.DenseCopyOf(dualForm.C.T()),
+		C:          mat.DenseCopyOf(dualForm.B.T()),
+		T:          dualForm.T,
+		BlockSizes: dualForm.BlockSizes,
+	}, nil
+}
+
+type KalmanDecomposition struct {
+	Sys StateSpace // The transformed model with the state z = T^{-1} x.
+	T   *mat.Dense // The transformation (the original state is x = T z).
+
+	NumControllableObservable     int
+	NumControllableUnobservable   int
+	NumUncontrollableObservable   int
+	NumUncontrollableUnobservable int
+}
+
+/*
+KalmanDecomposition
+Description:
+
+	Computes the Kalman decomposition of sys. The states of the transformed model are ordered as
+	(controllable and observable, controllable and unobservable, uncontrollable and observable,
+	uncontrollable and unobservable), which gives the structure
+		A = [A11 0 A13 0; A21 A22 A23 A24; 0 0 A33 0; 0 0 A43 A44],
+		B = [B1; B2; 0; 0],   C = [C1 0 C3 0].
+	The controllable and unobservable subspaces are found with the staircase forms Ctrbf and Obsvf.
+*/
+func (sys StateSpace) KalmanDecomposition(tol float64) (KalmanDecomposition, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return KalmanDecomposition{}, err
+	}
+
+	// Constants
+	n, _, _ := sys.Dims()
+
+	// Find the controllable subspace R and the unobservable subspace N
+	ctrbForm, err := Ctrbf(sys.A, sys.B, sys.C, tol)
+	if err != nil {
+		return KalmanDecomposition{}, err
+	}
+	obsvForm, err := Obsvf(sys.A, sys.B, sys.C, tol)
+	if err != nil {
+		return KalmanDecomposition{}, err
+	}
+
+	nc, no := ctrbForm.NumReached(), obsvForm.NumReached()
+	var R, N *mat.Dense
+	if nc > 0 {
+		R = mat.DenseCopyOf(ctrbForm.T.Slice(0, n, 0, nc))
+	}
+	if no < n {
+		N = mat.DenseCopyOf(obsvForm.T.Slice(0, n, no, n))
+	}
+
+	// Find the intersection of R and N: the vectors R a with (I - N N^T) R a = 0.
+	var intersection *mat.Dense
+	if (R != nil) && (N != nil) {
+		projected := complementProjection(N, R)
+		nullCoefficients, err := nullSpace(projected, 1e3*eps*float64(n))
+		if err != nil {
+			return KalmanDecomposition{}, err
+		}
+		if nullCoefficients != nil {
+			var RA mat.Dense
+			RA.Mul(R, nullCoefficients)
+			intersection, err = orth(&RA, 0)
+			if err != nil {
+				return KalmanDecomposition{}, err
+			}
+		}
+	}
+
+	// Build the four groups of basis vectors
+	Vco, err := orthComplementWithin(R, intersection)
+	if err != nil {
+		return KalmanDecomposition{}, err
+	}
+	Vnco, err := orthComplementWithin(N, intersection)
+	if err != nil {
+		return KalmanDecomposition{}, err
+	}
+
+	span := hstack(Vco, intersection, Vnco)
+	var Vnc *mat.Dense
+	if span == nil {
+		Vnc = eye(n)
+	} else {
+		Vnc, err = nullSpace(span.T(), 0)
+		if err != nil {
+			return KalmanDecomposition{}, err
+		}
+	}
+
+	T := hstack(Vco, intersection, Vnc, Vnco)
+
+	// Transform the model
+	var TInv mat.Dense
+	if err := TInv.Inverse(T); err != nil {
+		return KalmanDecomposition{}, fmt.Errorf("The Kalman decomposition produced a singular transformation: %v", err)
+	}
+
+	var TInvA, Abar, Bbar, Cbar mat.Dense
+	TInvA.Mul(&TInv, sys.A)
+	Abar.Mul(&TInvA, T)
+	Bbar.Mul(&TInv, sys.B)
+	Cbar.Mul(sys.C, T)
+
+	decomposition := KalmanDecomposition{
+		T:                             T,
+		NumControllableObservable:     numColumns(Vco),
+		NumControllableUnobservable:   numColumns(intersection),
+		NumUncontrollableObservable:   numColumns(Vnc),
+		NumUncontrollableUnobservable: numColumns(Vnco),
+	}
+
+	decomposition.Sys, err = GetDiscreteStateSpace(&Abar, &Bbar, &Cbar, sys.D, sys.Ts)
+	if err != nil {
+		return KalmanDecomposition{}, err
+	}
+	decomposition.Sys.InputDelay = append([]float64(nil), sys.InputDelay...)
+
+	return decomposition, nil
+}
+
+/*
+complementProjection
+Description:
+
+	Computes (I - Q Q^T) M, where Q has orthonormal columns.
+*/
+func complementProjection(Q, M mat.Matrix) *mat.Dense {
+	// Constants
+	n, c := M.Dims()
+
+	// Algorithm
+	var QtM, QQtM mat.Dense
+	QtM.Mul(Q.T(), M)
+	QQtM.Mul(Q, &QtM)
+
+	projected := mat.NewDense(n, c, nil)
+	projected.Sub(M, &QQtM)
+	return projected
+}
+
+/*
+orthComplementWithin
+Description:
+
+	Computes an orthonormal basis for the part of span(V) that is orthogonal to span(W),
+	where W has orthonormal columns. nil matrices represent the subspace {0}.
+*/
+func orthComplementWithin(V, W *mat.Dense) (*mat.Dense, error) {
+	if V == nil {
+		return nil, nil
+	}
+	if W == nil {
+		return orth(V, 1e3*eps)
+	}
+	return orth(complementProjection(W, V), 1e3*eps*math.Max(1, mat.Norm(V, 1)))
+}
+
+/*
+numColumns
+Description:
+
+	Returns the number of columns of M, where a nil matrix has zero columns.
+*/
+func numColumns(M *mat.Dense) int {
+	if M == nil {
+		return 0
+	}
+	_, c := M.Dims()
+	return c
+}
@@ -0,0 +1,793 @@
+/*
+   discretization.go
+   Description:
+       Conversions between continuous-time and discrete-time state space models "like" MATLAB's c2d and d2c.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type DiscretizationMethod int
+
+const (
+	ZeroOrderHold DiscretizationMethod = iota
+	FirstOrderHold
+	Tustin
+	MatchedPoleZero
+)
+
+/*
+String
+Description:
+
+	Returns the name of the discretization method.
+*/
+func (method DiscretizationMethod) String() string {
+	switch method {
+	case ZeroOrderHold:
+		return "ZeroOrderHold"
+	case FirstOrderHold:
+		return "FirstOrderHold"
+	case Tustin:
+		return "Tustin"
+	case MatchedPoleZero:
+		return "MatchedPoleZero"
+	default:
+		return fmt.Sprintf("DiscretizationMethod(%v)", int(method))
+	}
+}
+
+type DiscretizationOptions struct {
+	Method           DiscretizationMethod
+	PrewarpFrequency float64 // Frequency (rad/s) at which the Tustin method matches the frequency response. 0 means no prewarping.
+}
+
+/*
+C2d
+Description:
+
+	Converts the continuous-time model sys into a discrete-time model with sample time Ts
+	using the given discretization method.
+*/
+func C2d(sys StateSpace, Ts float64, method DiscretizationMethod) (StateSpace, error) {
+	return C2dWithOptions(sys, Ts, DiscretizationOptions{Method: method})
+}
+
+/*
+C2dWithOptions
+Description:
+
+	Converts the continuous-time model sys into a discrete-time model with sample time Ts.
+	Input delays are absorbed into the state of the discrete-time model: the delays that are integer multiples
+	of Ts become chains of unit delays and (for the zero-order hold method only) the fractional part of each delay
+	is handled exactly with one extra state per delayed input.
+*/
+func C2dWithOptions(sys StateSpace, Ts float64, options DiscretizationOptions) (StateSpace, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return StateSpace{}, err
+	}
+
+	if sys.IsDiscrete() {
+		return StateSpace{}, errors.New("The input model to C2d must be a continuous-time model.")
+	}
+
+	if !(Ts > 0) || math.IsInf(Ts, 0) {
+		return StateSpace{}, fmt.Errorf("The sample time must be a positive, finite number; received %v.", Ts)
+	}
+
+	if err := options.check(Ts); err != nil {
+		return StateSpace{}, err
+	}
+
+	// Split the input delays into a number of samples and a fraction of a sample
+	integerDelays, fractionalDelays := splitInputDelays(sys, Ts)
+	hasFractionalDelay := false
+	for _, fraction := range fractionalDelays {
+		if fraction > 0 {
+			hasFractionalDelay = true
+		}
+	}
+
+	if hasFractionalDelay && (options.Method != ZeroOrderHold) {
+		return StateSpace{}, fmt.Errorf("The %v method only supports input delays that are integer multiples of the sample time.", options.Method)
+	}
+
+	// Discretize the model
+	delayFree := sys.Copy()
+	delayFree.InputDelay = nil
+
+	var sysD StateSpace
+	var err error
+	switch options.Method {
+	case ZeroOrderHold:
+		sysD, err = c2dZOH(delayFree, Ts, fractionalDelays)
+	case FirstOrderHold:
+		sysD, err = c2dFOH(delayFree, Ts)
+	case Tustin:
+		sysD, err = c2dTustin(delayFree, Ts, options.PrewarpFrequency)
+	case MatchedPoleZero:
+		sysD, err = c2dMatched(delayFree, Ts)
+	default:
+		err = fmt.Errorf("The discretization method %v is not recognized.", options.Method)
+	}
+	if err != nil {
+		return StateSpace{}, err
+	}
+
+	return appendInputDelayStates(sysD, integerDelays)
+}
+
+/*
+D2c
+Description:
+
+	Converts the discrete-time model sys into a continuous-time model using the given method.
+*/
+func D2c(sys StateSpace, method DiscretizationMethod) (StateSpace, error) {
+	return D2cWithOptions(sys, DiscretizationOptions{Method: method})
+}
+
+/*
+D2cWithOptions
+Description:
+
+	Converts the discrete-time model sys into a continuous-time model. This inverts C2dWithOptions for
+	models without input delays.
+*/
+func D2cWithOptions(sys StateSpace, options DiscretizationOptions) (StateSpace, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return StateSpace{}, err
+	}
+
+	if !sys.IsDiscrete() {
+		return StateSpace{}, errors.New("The input model to D2c must be a discrete-time model.")
+	}
+
+	if sys.HasInputDelay() {
+		return StateSpace{}, errors.New("D2c does not support discrete-time models with input delays.")
+	}
+
+	if err := options.check(sys.Ts); err != nil {
+		return StateSpace{}, err
+	}
+
+	// Algorithm
+	switch options.Method {
+	case ZeroOrderHold:
+		return d2cZOH(sys)
+	case FirstOrderHold:
+		return d2cFOH(sys)
+	case Tustin:
+		return d2cTustin(sys, options.PrewarpFrequency)
+	case MatchedPoleZero:
+		return d2cMatched(sys)
+	default:
+		return StateSpace{}, fmt.Errorf("The discretization method %v is not recognized.", options.Method)
+	}
+}
+
+/*
+check
+Description:
+
+	Returns an error if the options are not valid for the sample time Ts.
+*/
+func (options DiscretizationOptions) check(Ts float64) error {
+	if options.PrewarpFrequency < 0 || math.IsNaN(options.PrewarpFrequency) {
+		return fmt.Errorf("The prewarp frequency must be nonnegative; received %v.", options.PrewarpFrequency)
+	}
+
+	if (options.PrewarpFrequency > 0) && (options.Method != Tustin) {
+		return fmt.Errorf("A prewarp frequency can only be used with the Tustin method; received method %v.", options.Method)
+	}
+
+	if options.PrewarpFrequency*Ts >= math.Pi {
+		return fmt.Errorf("The prewarp frequency %v must be below the Nyquist frequency %v.", options.PrewarpFrequency, math.Pi/Ts)
+	}
+
+	return nil
+}
+
+/*
+splitInputDelays
+Description:
+
+	Splits each input delay of sys into an integer number of samples and a remaining fraction of a
+	sample (in seconds).
+*/
+func splitInputDelays(sys StateSpace, Ts float64) ([]int, []float64) {
+	// Constants
+	_, m, _ := sys.Dims()
+	tol := 1e-9 * Ts
+
+	// Algorithm
+	integerDelays := make([]int, m)
+	fractionalDelays := make([]float64, m)
+	for j, delay := range sys.InputDelay {
+		numSamples := math.Floor((delay + tol) / Ts)
+		fraction := delay - numSamples*Ts
+		if fraction < tol {
+			fraction = 0
+		}
+
+		integerDelays[j] = int(numSamples)
+		fractionalDelays[j] = fraction
+	}
+
+	return integerDelays, fractionalDelays
+}
+
+/*
+appendInputDelayStates
+Description:
+
+	Adds a chain of delays[j] unit delays in front of input j of the discrete-time model sysD.
+*/
+func appendInputDelayStates(sysD StateSpace, delays []int) (StateSpace, error) {
+	// Constants
+	n, m, p := sysD.Dims()
+	numDelayStates := 0
+	for _, d := range delays {
+		numDelayStates += d
+	}
+
+	if numDelayStates == 0 {
+		return sysD, nil
+	}
+
+	// Algorithm
+	nAug := n + numDelayStates
+	A := mat.NewDense(nAug, nAug, nil)
+	B := mat.NewDense(nAug, m, nil)
+	C := mat.NewDense(p, nAug, nil)
+	D := mat.DenseCopyOf(sysD.D)
+
+	A.Slice(0, n, 0, n).(*mat.Dense).Copy(sysD.A)
+	C.Slice(0, p, 0, n).(*mat.Dense).Copy(sysD.C)
+
+	offset := n
+	for j := 0; j < m; j++ {
+		if delays[j] == 0 {
+			for i := 0; i < n; i++ {
+				B.Set(i, j, sysD.B.At(i, j))
+			}
+			continue
+		}
+
+		// The chain is z_1+ = u_j, z_{k+1}+ = z_k and the model's input j is z_{d}.
+		B.Set(offset, j, 1)
+		for k := 1; k < delays[j]; k++ {
+			A.Set(offset+k, offset+k-1, 1)
+		}
+
+		last := offset + delays[j] - 1
+		for i := 0; i < n; i++ {
+			A.Set(i, last, sysD.B.At(i, j))
+		}
+		for i := 0; i < p; i++ {
+			C.Set(i, last, sysD.D.At(i, j))
+			D.Set(i, j, 0)
+		}
+
+		offset += delays[j]
+	}
+
+	return GetDiscreteStateSpace(A, B, C, D, sysD.Ts)
+}
+
+/*
+c2dZOH
+Description:
+
+	Discretizes sys with a zero-order hold using the matrix exponential of the augmented matrix [A B; 0 0].
+	Inputs with a fractional delay f_j (0 < f_j < Ts) receive one extra state that stores the previous
+	value of the input.
+*/
+func c2dZOH(sys StateSpace, Ts float64, fractionalDelays []float64) (StateSpace, error) {
+	// Constants
+	n, m, p := sys.Dims()
+
+	// Compute the discrete model without delays
+	Phi, Gamma := zohMatrices(sys.A, sys.B, Ts)
+
+	delayedInputs := []int{}
+	for j, fraction := range fractionalDelays {
+		if fraction > 0 {
+			delayedInputs = append(delayedInputs, j)
+		}
+	}
+
+	if len(delayedInputs) == 0 {
+		return GetDiscreteStateSpace(Phi, Gamma, sys.C, sys.D, Ts)
+	}
+
+	// Add the states that store the previous values of the delayed inputs
+	nAug := n + len(delayedInputs)
+	A := mat.NewDense(nAug, nAug, nil)
+	B := mat.NewDense(nAug, m, nil)
+	C := mat.NewDense(p, nAug, nil)
+	D := mat.DenseCopyOf(sys.D)
+
+	A.Slice(0, n, 0, n).(*mat.Dense).Copy(Phi)
+	B.Slice(0, n, 0, m).(*mat.Dense).Copy(Gamma)
+	C.Slice(0, p, 0, n).(*mat.Dense).Copy(sys.C)
+
+	for k, j := range delayedInputs {
+		// During the first f_j seconds of a sample, the input u_j[k-1] is applied, and then u_j[k] is applied.
+		fraction := fractionalDelays[j]
+		bj := mat.DenseCopyOf(sys.B.Slice(0, n, j, j+1))
+
+		PhiLate, GammaLate := zohMatrices(sys.A, bj, Ts-fraction)
+		_, GammaEarly := zohMatrices(sys.A, bj, fraction)
+
+		var GammaPrevious mat.Dense
+		GammaPrevious.Mul(PhiLate, GammaEarly)
+
+		for i := 0; i < n; i++ {
+			B.Set(i, j, GammaLate.At(i, 0))
+			A.Set(i, n+k, GammaPrevious.At(i, 0))
+		}
+		B.Set(n+k, j, 1)
+
+		for i := 0; i < p; i++ {
+			C.Set(i, n+k, sys.D.At(i, j))
+			D.Set(i, j, 0)
+		}
+	}
+
+	return GetDiscreteStateSpace(A, B, C, D, Ts)
+}
+
+/*
+zohMatrices
+Description:
+
+	Computes Phi = e^{A h} and Gamma = int_0^h e^{A s} ds B from the exponential of [A B; 0 0] h.
+*/
+func zohMatrices(A, B mat.Matrix, h float64) (*mat.Dense, *mat.Dense) {
+	// Constants
+	n, m := B.Dims()
+
+	// Algorithm
+	M := mat.NewDense(n+m, n+m, nil)
+	M.Slice(0, n, 0, n).(*mat.Dense).Copy(A)
+	M.Slice(0, n, n, n+m).(*mat.Dense).Copy(B)
+
+	expM := expmBlock(M, h)
+	Phi := mat.DenseCopyOf(expM.Slice(0, n, 0, n))
+	Gamma := mat.DenseCopyOf(expM.Slice(0, n, n, n+m))
+	return Phi, Gamma
+}
+
+/*
+d2cZOH
+Description:
+
+	Inverts the zero-order hold discretization using the matrix logarithm of [Phi Gamma; 0 I].
+*/
+func d2cZOH(sys StateSpace) (StateSpace, error) {
+	// Constants
+	n, m, _ := sys.Dims()
+	Ts := sys.Ts
+
+	// Algorithm
+	M := mat.NewDense(n+m, n+m, nil)
+	M.Slice(0, n, 0, n).(*mat.Dense).Copy(sys.A)
+	M.Slice(0, n, n, n+m).(*mat.Dense).Copy(sys.B)
+	M.Slice(n, n+m, n, n+m).(*mat.Dense).Copy(eye(m))
+
+	logM, err := logm(M)
+	if err != nil {
+		return StateSpace{}, fmt.Errorf("The zero-order hold conversion failed: %v", err)
+	}
+	logM.Scale(1/Ts, logM)
+
+	return GetStateSpace(logM.Slice(0, n, 0, n), logM.Slice(0, n, n, n+m), sys.C, sys.D)
+}
+
+/*
+fohMatrices
+Description:
+
+	Computes Phi = e^{A T}, W1 = int_0^T e^{A s} ds and W2 = (1/T) int_0^T e^{A (T - s)} s ds
+	from the exponential of [A I 0; 0 0 I/T; 0 0 0] T.
+*/
+func fohMatrices(A mat.Matrix, T float64) (Phi, W1, W2 *mat.Dense) {
+	// Constants
+	n, _ := A.Dims()
+
+	// Algorithm
+	M := mat.NewDense(3*n, 3*n, nil)
+	M.Slice(0, n, 0, n).(*mat.Dense).Copy(A)
+	M.Slice(0, n, n, 2*n).(*mat.Dense).Copy(eye(n))
+	identityOverT := eye(n)
+	identityOverT.Scale(1/T, identityOverT)
+	M.Slice(n, 2*n, 2*n, 3*n).(*mat.Dense).Copy(identityOverT)
+
+	expM := expmBlock(M, T)
+	Phi = mat.DenseCopyOf(expM.Slice(0, n, 0, n))
+	W1 = mat.DenseCopyOf(expM.Slice(0, n, n, 2*n))
+	W2 = mat.DenseCopyOf(expM.Slice(0, n, 2*n, 3*n))
+	return Phi, W1, W2
+}
+
+/*
+c2dFOH
+Description:
+
+	Discretizes sys with a (triangle) first-order hold. With Gamma1 = W1 B and Gamma2 = W2 B, the
+	discrete model uses the state xi = x - Gamma2 u and has the matrices
+		Ad = Phi, Bd = Gamma1 + (Phi - I) Gamma2, Cd = C, Dd = D + C Gamma2.
+*/
+func c2dFOH(sys StateSpace, Ts float64) (StateSpace, error) {
+	// Constants
+	n, m, p := sys.Dims()
+
+	// Algorithm
+	Phi, W1, W2 := fohMatrices(sys.A, Ts)
+
+	Gamma1 := mat.NewDense(n, m, nil)
+	Gamma1.Mul(W1, sys.B)
+	Gamma2 := mat.NewDense(n, m, nil)
+	Gamma2.Mul(W2, sys.B)
+
+	PhiMinusI := mat.NewDense(n, n, nil)
+	PhiMinusI.Sub(Phi, eye(n))
+
+	Bd := mat.NewDense(n, m, nil)
+	Bd.Mul(PhiMinusI, Gamma2)
+	Bd.Add(Bd, Gamma1)
+
+	Dd := mat.NewDense(p, m, nil)
+	Dd.Mul(sys.C, Gamma2)
+	Dd.Add(Dd, sys.D)
+
+	return GetDiscreteStateSpace(Phi, Bd, sys.C, Dd, Ts)
+}
+
+/*
+d2cFOH
+Description:
+
+	Inverts the first-order hold discretization.
+*/
+func d2cFOH(sys StateSpace) (StateSpace, error) {
+	// Constants
+	n, m, p := sys.Dims()
+	Ts := sys.Ts
+
+	// Recover A
+	logPhi, err := logm(sys.A)
+	if err != nil {
+		return StateSpace{}, fmt.Errorf("The first-order hold conversion failed: %v", err)
+	}
+	A := mat.NewDense(n, n, nil)
+	A.Scale(1/Ts, logPhi)
+
+	// Recover B from Bd = (W1 + (Phi - I) W2) B
+	_, W1, W2 := fohMatrices(A, Ts)
+	PhiMinusI := mat.NewDense(n, n, nil)
+	PhiMinusI.Sub(sys.A, eye(n))
+
+	M := mat.NewDense(n, n, nil)
+	M.Mul(PhiMinusI, W2)
+	M.Add(M, W1)
+
+	B := mat.NewDense(n, m, nil)
+	if err := B.Solve(M, sys.B); err != nil {
+		return StateSpace{}, fmt.Errorf("The first-order hold conversion failed: %v", err)
+	}
+
+	// Recover D from Dd = D + C W2 B
+	W2B := mat.NewDense(n, m, nil)
+	W2B.Mul(W2, B)
+	D := mat.NewDense(p, m, nil)
+	D.Mul(sys.C, W2B)
+	D.Sub(sys.D, D)
+
+	return GetStateSpace(A, B, sys.C, D)
+}
+
+/*
+tustinAlpha
+Description:
+
+	Returns the constant alpha of the bilinear map s = alpha (z - 1)/(z + 1).
+	Without prewarping alpha = 2/Ts; with prewarping at w, alpha = w / tan(w Ts / 2).
+*/
+func tustinAlpha(Ts, prewarpFrequency float64) float64 {
+	if prewarpFrequency > 0 {
+		return prewarpFrequency / math.Tan(prewarpFrequency*Ts/2)
+	}
+	return 2 / Ts
+}
+
+/*
+c2dTustin
+Description:
+
+	Discretizes sys with the bilinear (Tustin) transformation s = alpha (z - 1)/(z + 1):
+		Ad = (alpha I + A)(alpha I - A)^{-1}, Bd = sqrt(2 alpha) (alpha I - A)^{-1} B,
+		Cd = sqrt(2 alpha) C (alpha I - A)^{-1}, Dd = D + C (alpha I - A)^{-1} B.
+*/
+func c2dTustin(sys StateSpace, Ts, prewarpFrequency float64) (StateSpace, error) {
+	// Constants
+	n, m, p := sys.Dims()
+	alpha := tustinAlpha(Ts, prewarpFrequency)
+	scale := math.Sqrt(2 * alpha)
+
+	// Algorithm
+	alphaI := eye(n)
+	alphaI.Scale(alpha, alphaI)
+
+	alphaIMinusA := mat.NewDense(n, n, nil)
+	alphaIMinusA.Sub(alphaI, sys.A)
+	var inverse mat.Dense
+	if err := inverse.Inverse(alphaIMinusA); err != nil {
+		return StateSpace{}, fmt.Errorf("The Tustin transformation is singular because A has an eigenvalue at %v: %v", alpha, err)
+	}
+
+	alphaIPlusA := mat.NewDense(n, n, nil)
+	alphaIPlusA.Add(alphaI, sys.A)
+	Ad := mat.NewDense(n, n, nil)
+	Ad.Mul(alphaIPlusA, &inverse)
+
+	InvB := mat.NewDense(n, m, nil)
+	InvB.Mul(&inverse, sys.B)
+	Bd := mat.NewDense(n, m, nil)
+	Bd.Scale(scale, InvB)
+
+	Cd := mat.NewDense(p, n, nil)
+	Cd.Mul(sys.C, &inverse)
+	Cd.Scale(scale, Cd)
+
+	Dd := mat.NewDense(p, m, nil)
+	Dd.Mul(sys.C, InvB)
+	Dd.Add(Dd, sys.D)
+
+	return GetDiscreteStateSpace(Ad, Bd, Cd, Dd, Ts)
+}
+
+/*
+d2cTustin
+Description:
+
+	Inverts the bilinear (Tustin) transformation. With N = (Ad + I)^{-1},
+		A = alpha (Ad - I) N, B = sqrt(2 alpha) N Bd, C = sqrt(2 alpha) Cd N, D = Dd - Cd N Bd.
+*/
+func d2cTustin(sys StateSpace, prewarpFrequency float64) (StateSpace, error) {
+	// Constants
+	n, m, p := sys.Dims()
+	alpha := tustinAlpha(sys.Ts, prewarpFrequency)
+	scale := math.Sqrt(2 * alpha)
+
+	// Algorithm
+	AdPlusI := mat.NewDense(n, n, nil)
+	AdPlusI.Add(sys.A, eye(n))
+	var N mat.Dense
+	if err := N.Inverse(AdPlusI); err != nil {
+		return StateSpace{}, fmt.Errorf("The Tustin transformation is singular because Ad has an eigenvalue at -1: %v", err)
+	}
+
+	AdMinusI := mat.NewDense(n, n, nil)
+	AdMinusI.Sub(sys.A, eye(n))
+	A := mat.NewDense(n, n, nil)
+	A.Mul(AdMinusI, &N)
+	A.Scale(alpha, A)
+
+	NBd := mat.NewDense(n, m, nil)
+	NBd.Mul(&N, sys.B)
+	B := mat.NewDense(n, m, nil)
+	B.Scale(scale, NBd)
+
+	C := mat.NewDense(p, n, nil)
+	C.Mul(sys.C, &N)
+	C.Scale(scale, C)
+
+	D := mat.NewDense(p, m, nil)
+	D.Mul(sys.C, NBd)
+	D.Sub(sys.D, D)
+
+	return GetStateSpace(A, B, C, D)
+}
+
+/*
+c2dMatched
+Description:
+
+	Discretizes sys with the matched pole-zero method. Each entry of the transfer function is mapped
+	separately: poles and finite zeros are mapped with z = e^{s Ts}, all but one of the zeros at infinity
+	are mapped to z = -1 and the gain is matched at DC.
+*/
+func c2dMatched(sys StateSpace, Ts float64) (StateSpace, error) {
+	return mapMatchedPoleZero(sys, func(zeros, poles []complex128, gain float64) ([]complex128, []complex128, float64, error) {
+		// Constants
+		numIntegrators := countRootsAt(poles, 0) - countRootsAt(zeros, 0)
+		relativeDegree := len(poles) - len(zeros)
+
+		// Map the zeros and poles
+		zerosD := []complex128{}
+		for _, z := range zeros {
+			zerosD = append(zerosD, mapRootToDiscrete(z, Ts))
+		}
+		for k := 0; k < relativeDegree-1; k++ {
+			zerosD = append(zerosD, -1)
+		}
+
+		polesD := []complex128{}
+		for _, p := range poles {
+			polesD = append(polesD, mapRootToDiscrete(p, Ts))
+		}
+
+		// Match the DC gain (after removing the roots at s = 0 and z = 1)
+		gainC := gain * reducedProduct(zeros, 0) / reducedProduct(poles, 0)
+		gainD := reducedProduct(zerosD, 1) / reducedProduct(polesD, 1) / math.Pow(Ts, float64(numIntegrators))
+
+		return zerosD, polesD, gainC / gainD, nil
+	}, Ts)
+}
+
+/*
+d2cMatched
+Description:
+
+	Inverts the matched pole-zero method. Zeros at z = -1 are mapped to infinity and the remaining poles and zeros
+	are mapped with s = log(z)/Ts.
+*/
+func d2cMatched(sys StateSpace) (StateSpace, error) {
+	// Constants
+	Ts := sys.Ts
+
+	// Algorithm
+	return mapMatchedPoleZero(sys, func(zerosD, polesD []complex128, gainD float64) ([]complex128, []complex128, float64, error) {
+		// Constants
+		numIntegrators := countRootsAt(polesD, 1) - countRootsAt(zerosD, 1)
+
+		// Map the zeros and poles
+		zeros := []complex128{}
+		for _, z := range zerosD {
+			if cmplx.Abs(z+1) <= 1e-8 {
+				continue
+			}
+			s, err := mapRootToContinuous(z, Ts)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			zeros = append(zeros, s)
+		}
+
+		poles := []complex128{}
+		for _, p := range polesD {
+			s, err := mapRootToContinuous(p, Ts)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			poles = append(poles, s)
+		}
+
+		// Match the DC gain (after removing the roots at s = 0 and z = 1)
+		gainCD := gainD * reducedProduct(zerosD, 1) / reducedProduct(polesD, 1) / math.Pow(Ts, float64(numIntegrators))
+		gainCUnit := reducedProduct(zeros, 0) / reducedProduct(poles, 0)
+
+		return zeros, poles, gainCD / gainCUnit, nil
+	}, 0)
+}
+
+/*
+mapMatchedPoleZero
+Description:
+
+	Applies the map rootMap to the zeros, poles and gain of every entry of the transfer function of sys and
+	returns a realization of the result with the sample time TsOut.
+*/
+func mapMatchedPoleZero(
+	sys StateSpace,
+	rootMap func(zeros, poles []complex128, gain float64) ([]complex128, []complex128, float64, error),
+	TsOut float64,
+) (StateSpace, error) {
+	// Convert to zero-pole-gain form
+	tf, err := Ss2tf(sys)
+	if err != nil {
+		return StateSpace{}, err
+	}
+	tf, err = tf.Minreal(1e-8)
+	if err != nil {
+		return StateSpace{}, err
+	}
+	zpk, err := Tf2zpk(tf)
+	if err != nil {
+		return StateSpace{}, err
+	}
+
+	// Map each entry
+	p, m := zpk.Dims()
+	for i := 0; i < p; i++ {
+		for j := 0; j < m; j++ {
+			if zpk.Gain[i][j] == 0 {
+				zpk.Poles[i][j] = []complex128{}
+				continue
+			}
+
+			zpk.Zeros[i][j], zpk.Poles[i][j], zpk.Gain[i][j], err = rootMap(zpk.Zeros[i][j], zpk.Poles[i][j], zpk.Gain[i][j])
+			if err != nil {
+				return StateSpace{}, err
+			}
+		}
+	}
+	zpk.Ts = TsOut
+
+	return Zpk2ss(zpk)
+}
+
+/*
+mapRootToDiscrete
+Description:
+
+	Maps the continuous-time root s to e^{s Ts}. Roots at s = 0 are mapped exactly to z = 1.
+*/
+func mapRootToDiscrete(s complex128, Ts float64) complex128 {
+	if cmplx.Abs(s) <= 1e-10 {
+		return 1
+	}
+	return cmplx.Exp(s * complex(Ts, 0))
+}
+
+/*
+mapRootToContinuous
+Description:
+
+	Maps the discrete-time root z to log(z)/Ts. Roots at z = 1 are mapped exactly to s = 0.
+*/
+func mapRootToContinuous(z complex128, Ts float64) (complex128, error) {
+	if cmplx.Abs(z-1) <= 1e-10 {
+		return 0, nil
+	}
+	if (real(z) <= 0) && (math.Abs(imag(z)) <= 1e-10) {
+		return 0, fmt.Errorf("The root %v is on the negative real axis, so it does not have a continuous-time equivalent.", z)
+	}
+	return cmplx.Log(z) / complex(Ts, 0), nil
+}
+
+/*
+countRootsAt
+Description:
+
+	Counts the number of roots that are (numerically) equal to the value point.
+*/
+func countRootsAt(roots []complex128, point complex128) int {
+	count := 0
+	for _, r := range roots {
+		if cmplx.Abs(r-point) <= 1e-10 {
+			count++
+		}
+	}
+	return count
+}
+
+/*
+reducedProduct
+Description:
+
+	Computes prod (point - r) over all roots r that are not (numerically) equal to point.
+	The imaginary part is discarded because the roots come in conjugate pairs.
+*/
+func reducedProduct(roots []complex128, point complex128) float64 {
+	product := complex(1, 0)
+	for _, r := range roots {
+		if cmplx.Abs(r-point) <= 1e-10 {
+			continue
+		}
+		product *= point - r
+	}
+	return real(product)
+}
@@ -0,0 +1,456 @@
+/*
+   frequency_response.go
+   Description:
+       Frequency responses of state space models "like" MATLAB's freqresp, bode, nyquist and nichols functions.
+       The state matrix is reduced to Hessenberg form once, so that each frequency only costs O(n^2) operations
+       per input instead of the O(n^3) of a dense solve.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// pointsPerDecade is the density of the automatically selected frequency grids.
+const pointsPerDecade = 50
+
+type BodeResponse struct {
+	Frequency []float64     // Frequencies (rad/s)
+	Magnitude [][][]float64 // Magnitude[i][j][k] is the gain from input j to output i at Frequency[k] (absolute units)
+	Phase     [][][]float64 // Phase[i][j][k] is the unwrapped phase (degrees) from input j to output i at Frequency[k]
+}
+
+type NyquistResponse struct {
+	Frequency []float64     // Frequencies (rad/s)
+	Real      [][][]float64 // Real[i][j][k] is the real part of the response from input j to output i at Frequency[k]
+	Imag      [][][]float64 // Imag[i][j][k] is the imaginary part of the response from input j to output i at Frequency[k]
+}
+
+type NicholsResponse struct {
+	Frequency   []float64     // Frequencies (rad/s)
+	MagnitudeDB [][][]float64 // MagnitudeDB[i][j][k] is the gain (dB) from input j to output i at Frequency[k]
+	Phase       [][][]float64 // Phase[i][j][k] is the unwrapped phase (degrees) from input j to output i at Frequency[k]
+}
+
+/*
+FreqResp
+Description:
+
+	Evaluates the frequency response of sys at each frequency w[k] (rad/s), i.e. H(s) = C (s I - A)^{-1} B + D at
+	s = j w[k] for continuous-time models and at z = exp(j w[k] Ts) for discrete-time models. Input delays are
+	included. Entries at frequencies that are poles of the model are infinite.
+*/
+func FreqResp(sys StateSpace, w []float64) ([]*mat.CDense, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return nil, err
+	}
+
+	// Algorithm
+	evaluator := getFrequencyEvaluator(sys)
+	responses := make([]*mat.CDense, len(w))
+	for k, frequency := range w {
+		responses[k] = evaluator.at(frequency)
+	}
+	return responses, nil
+}
+
+/*
+Bode
+Description:
+
+	Computes the magnitude and phase of the frequency response of sys. If w is nil, then the frequency grid is
+	selected automatically from the dynamics of the model.
+*/
+func Bode(sys StateSpace, w []float64) (BodeResponse, error) {
+	// Algorithm
+	w, responses, err := frequencyResponseOnGrid(sys, w)
+	if err != nil {
+		return BodeResponse{}, err
+	}
+
+	magnitude, phase := magnitudeAndPhase(responses)
+	return BodeResponse{Frequency: w, Magnitude: magnitude, Phase: phase}, nil
+}
+
+/*
+Nyquist
+Description:
+
+	Computes the real and imaginary parts of the frequency response of sys (for positive frequencies). If w is nil,
+	then the frequency grid is selected automatically from the dynamics of the model.
+*/
+func Nyquist(sys StateSpace, w []float64) (NyquistResponse, error) {
+	// Algorithm
+	w, responses, err := frequencyResponseOnGrid(sys, w)
+	if err != nil {
+		return NyquistResponse{}, err
+	}
+
+	p, m := responses[0].Dims()
+	re, im := makeResponseArrays(p, m, len(w)), makeResponseArrays(p, m, len(w))
+	for k, H := range responses {
+		for i := 0; i < p; i++ {
+			for j := 0; j < m; j++ {
+				re[i][j][k] = real(H.At(i, j))
+				im[i][j][k] = imag(H.At(i, j))
+			}
+		}
+	}
+
+	return NyquistResponse{Frequency: w, Real: re, Imag: im}, nil
+}
+
+/*
+Nichols
+Description:
+
+	Computes the gain (in dB) and the unwrapped phase (in degrees) of the frequency response of sys. If w is nil,
+	then the frequency grid is selected automatically from the dynamics of the model.
+*/
+func Nichols(sys StateSpace, w []float64) (NicholsResponse, error) {
+	// Algorithm
+	w, responses, err := frequencyResponseOnGrid(sys, w)
+	if err != nil {
+		return NicholsResponse{}, err
+	}
+
+	magnitude, phase := magnitudeAndPhase(responses)
+	for i := range magnitude {
+		for j := range magnitude[i] {
+			for k := range magnitude[i][j] {
+				magnitude[i][j][k] = 20 * math.Log10(magnitude[i][j][k])
+			}
+		}
+	}
+
+	return NicholsResponse{Frequency: w, MagnitudeDB: magnitude, Phase: phase}, nil
+}
+
+/*
+FrequencyGrid
+Description:
+
+	Selects a logarithmically spaced frequency grid for sys that covers the natural frequencies of its poles
+	(from a decade below the slowest pole to a decade above the fastest one). For discrete-time models, the grid
+	stops at the Nyquist frequency pi / Ts.
+*/
+func FrequencyGrid(sys StateSpace) ([]float64, error) {
+	// Algorithm
+	low, high, err := frequencyRange(sys)
+	if err != nil {
+		return nil, err
+	}
+	return logspace(math.Floor(math.Log10(low)), math.Log10(high), pointsPerDecade), nil
+}
+
+/*
+frequencyRange
+Description:
+
+	Returns the frequency range [low, high] (rad/s) on which the dynamics of sys are visible.
+*/
+func frequencyRange(sys StateSpace) (low, high float64, err error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return 0, 0, err
+	}
+
+	// Algorithm
+	poles, err := sys.Poles()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	low, high = math.Inf(1), 0.0
+	for _, pole := range poles {
+		frequency := cmplx.Abs(pole)
+		if sys.IsDiscrete() {
+			// Natural frequency of the equivalent continuous-time pole
+			if frequency == 0 {
+				continue
+			}
+			frequency = cmplx.Abs(cmplx.Log(pole)) / sys.Ts
+		}
+		if frequency <= 1e-8 {
+			continue
+		}
+		low, high = math.Min(low, frequency), math.Max(high, frequency)
+	}
+	for _, delay := range sys.InputDelay {
+		if delay > 0 {
+			high = math.Max(high, 1/delay)
+			low = math.Min(low, 1/delay)
+		}
+	}
+
+	if high == 0 {
+		low, high = 1, 1
+	}
+	low, high = low/10, high*10
+
+	if sys.IsDiscrete() {
+		nyquistFrequency := math.Pi / sys.Ts
+		high = nyquistFrequency
+		low = math.Min(low, nyquistFrequency/100)
+	} else {
+		high = math.Pow(10, math.Ceil(math.Log10(high)))
+	}
+
+	return low, high, nil
+}
+
+/*
+frequencyResponseOnGrid
+Description:
+
+	Evaluates the frequency response of sys on the grid w, or on an automatically selected grid when w is nil.
+*/
+func frequencyResponseOnGrid(sys StateSpace, w []float64) ([]float64, []*mat.CDense, error) {
+	// Input Processing
+	if w == nil {
+		var err error
+		w, err = FrequencyGrid(sys)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(w) == 0 {
+		return nil, nil, errors.New("The frequency grid is empty.")
+	}
+	for k, frequency := range w {
+		if frequency < 0 {
+			return nil, nil, fmt.Errorf("The frequencies must be nonnegative; w[%v] = %v.", k, frequency)
+		}
+	}
+
+	// Algorithm
+	responses, err := FreqResp(sys, w)
+	if err != nil {
+		return nil, nil, err
+	}
+	return w, responses, nil
+}
+
+/*
+magnitudeAndPhase
+Description:
+
+	Computes the magnitude and the unwrapped phase (in degrees) of each entry of the responses.
+*/
+func magnitudeAndPhase(responses []*mat.CDense) (magnitude, phase [][][]float64) {
+	// Constants
+	p, m := responses[0].Dims()
+	N := len(responses)
+
+	// Algorithm
+	magnitude, phase = makeResponseArrays(p, m, N), makeResponseArrays(p, m, N)
+	for i := 0; i < p; i++ {
+		for j := 0; j < m; j++ {
+			for k, H := range responses {
+				magnitude[i][j][k] = cmplx.Abs(H.At(i, j))
+				phase[i][j][k] = cmplx.Phase(H.At(i, j)) * 180 / math.Pi
+			}
+			unwrapPhase(phase[i][j])
+		}
+	}
+	return magnitude, phase
+}
+
+/*
+unwrapPhase
+Description:
+
+	Removes the jumps of 360 degrees from the phase (in degrees), in place.
+*/
+func unwrapPhase(phase []float64) {
+	offset := 0.0
+	for k := 1; k < len(phase); k++ {
+		raw := phase[k] + offset
+		for raw-phase[k-1] > 180 {
+			raw -= 360
+			offset -= 360
+		}
+		for raw-phase[k-1] < -180 {
+			raw += 360
+			offset += 360
+		}
+		phase[k] = raw
+	}
+}
+
+/*
+makeResponseArrays
+Description:
+
+	Allocates a p x m x N array.
+*/
+func makeResponseArrays(p, m, N int) [][][]float64 {
+	out := make([][][]float64, p)
+	for i := range out {
+		out[i] = make([][]float64, m)
+		for j := range out[i] {
+			out[i][j] = make([]float64, N)
+		}
+	}
+	return out
+}
+
+/*
+logspace
+Description:
+
+	Returns the points 10^a, ..., 10^b with pointsPerDecade points per decade (both ends included).
+*/
+func logspace(a, b float64, perDecade int) []float64 {
+	count := int(math.Ceil((b-a)*float64(perDecade))) + 1
+	if count < 2 {
+		count = 2
+	}
+	out := make([]float64, count)
+	for k := range out {
+		out[k] = math.Pow(10, a+(b-a)*float64(k)/float64(count-1))
+	}
+	return out
+}
+
+type frequencyEvaluator struct {
+	sys StateSpace
+	H   *mat.Dense // Hessenberg form Q^T A Q of the state matrix
+	B   *mat.Dense // Q^T B
+	C   *mat.Dense // C Q
+}
+
+/*
+getFrequencyEvaluator
+Description:
+
+	Reduces the state matrix of sys to Hessenberg form so that the model can be evaluated quickly at many frequencies.
+*/
+func getFrequencyEvaluator(sys StateSpace) frequencyEvaluator {
+	// Algorithm
+	H, Q := hessenberg(sys.A)
+
+	var QtB, CQ mat.Dense
+	QtB.Mul(Q.T(), sys.B)
+	CQ.Mul(sys.C, Q)
+
+	return frequencyEvaluator{sys: sys, H: H, B: &QtB, C: &CQ}
+}
+
+/*
+at
+Description:
+
+	Evaluates the frequency response at the frequency w (rad/s).
+*/
+func (evaluator frequencyEvaluator) at(w float64) *mat.CDense {
+	// Constants
+	sys := evaluator.sys
+	s := complex(0, w)
+	if sys.IsDiscrete() {
+		s = cmplx.Exp(complex(0, w*sys.Ts))
+	}
+
+	// Algorithm
+	return evaluator.atPoint(s, w)
+}
+
+/*
+atPoint
+Description:
+
+	Evaluates C (s I - A)^{-1} B + D at the complex point s, including the input delays at the frequency w.
+*/
+func (evaluator frequencyEvaluator) atPoint(s complex128, w float64) *mat.CDense {
+	// Constants
+	n, m, p := evaluator.sys.Dims()
+
+	// Solve (s I - H) X = Q^T B
+	X, ok := solveShiftedHessenberg(evaluator.H, s, evaluator.B)
+
+	// Algorithm
+	response := mat.NewCDense(p, m, nil)
+	for j := 0; j < m; j++ {
+		delayFactor := complex(1, 0)
+		if (evaluator.sys.InputDelay != nil) && (evaluator.sys.InputDelay[j] > 0) {
+			delayFactor = cmplx.Exp(complex(0, -w*evaluator.sys.InputDelay[j]))
+		}
+
+		for i := 0; i < p; i++ {
+			if !ok {
+				response.Set(i, j, cmplx.Inf())
+				continue
+			}
+			value := complex(evaluator.sys.D.At(i, j), 0)
+			for k := 0; k < n; k++ {
+				value += complex(evaluator.C.At(i, k), 0) * X[k][j]
+			}
+			response.Set(i, j, value*delayFactor)
+		}
+	}
+	return response
+}
+
+/*
+solveShiftedHessenberg
+Description:
+
+	Solves (s I - H) X = B, where H is upper Hessenberg, by Gaussian elimination with partial pivoting.
+	Only adjacent rows can be exchanged, so the cost is O(n^2) per column of B. Returns false if s I - H is singular.
+*/
+func solveShiftedHessenberg(H *mat.Dense, s complex128, B *mat.Dense) ([][]complex128, bool) {
+	// Constants
+	n, m := B.Dims()
+
+	// Build the augmented matrix [s I - H, B]
+	M := make([][]complex128, n)
+	for i := range M {
+		M[i] = make([]complex128, n+m)
+		for j := 0; j < n; j++ {
+			M[i][j] = complex(-H.At(i, j), 0)
+		}
+		M[i][i] += s
+		for j := 0; j < m; j++ {
+			M[i][n+j] = complex(B.At(i, j), 0)
+		}
+	}
+
+	// Eliminate the subdiagonal
+	for k := 0; k < n-1; k++ {
+		if cmplx.Abs(M[k+1][k]) > cmplx.Abs(M[k][k]) {
+			M[k], M[k+1] = M[k+1], M[k]
+		}
+		if M[k][k] == 0 {
+			return nil, false
+		}
+		factor := M[k+1][k] / M[k][k]
+		for j := k; j < n+m; j++ {
+			M[k+1][j] -= factor * M[k][j]
+		}
+	}
+
+	// Back substitution
+	X := make([][]complex128, n)
+	for i := n - 1; i >= 0; i-- {
+		if cmplx.Abs(M[i][i]) <= eps*math.Max(cmplx.Abs(s), 1) {
+			return nil, false
+		}
+		X[i] = make([]complex128, m)
+		for j := 0; j < m; j++ {
+			value := M[i][n+j]
+			for k := i + 1; k < n; k++ {
+				value -= M[i][k] * X[k][j]
+			}
+			X[i][j] = value / M[i][i]
+		}
+	}
+
+	return X, true
+}
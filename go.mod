@@ -2,4 +2,4 @@ module github.com/kwesiRutledge/goControl
 
 go 1.19
 
-require gonum.org/v1/gonum v0.12.0 // indirect
+require gonum.org/v1/gonum v0.12.0
@@ -0,0 +1,128 @@
+/*
+   gramians.go
+   Description:
+       Controllability and observability Gramians of stable state space models "like" MATLAB's gram function.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+ControllabilityGramian
+Description:
+
+	Computes the controllability Gramian Wc of the stable model sys, which solves
+		A Wc + Wc A^T + B B^T = 0       (continuous-time) or
+		A Wc A^T - Wc + B B^T = 0       (discrete-time).
+*/
+func ControllabilityGramian(sys StateSpace) (*mat.SymDense, error) {
+	// Input Processing
+	if err := checkGramianModel(sys); err != nil {
+		return nil, err
+	}
+
+	// Algorithm
+	var BBt mat.Dense
+	BBt.Mul(sys.B, sys.B.T())
+	return solveGramianEquation(sys.A, &BBt, sys.IsDiscrete())
+}
+
+/*
+ObservabilityGramian
+Description:
+
+	Computes the observability Gramian Wo of the stable model sys, which solves
+		A^T Wo + Wo A + C^T C = 0       (continuous-time) or
+		A^T Wo A - Wo + C^T C = 0       (discrete-time).
+*/
+func ObservabilityGramian(sys StateSpace) (*mat.SymDense, error) {
+	// Input Processing
+	if err := checkGramianModel(sys); err != nil {
+		return nil, err
+	}
+
+	// Algorithm
+	var CtC mat.Dense
+	CtC.Mul(sys.C.T(), sys.C)
+	return solveGramianEquation(sys.A.T(), &CtC, sys.IsDiscrete())
+}
+
+/*
+checkGramianModel
+Description:
+
+	Returns an error if sys is not a valid, stable model (the Gramians are only finite for stable models).
+*/
+func checkGramianModel(sys StateSpace) error {
+	// Check the model
+	if err := sys.Check(); err != nil {
+		return err
+	}
+
+	// Check stability
+	stable, err := sys.IsStable()
+	if err != nil {
+		return err
+	}
+	if !stable {
+		return errors.New("The Gramians are only defined for stable models.")
+	}
+
+	return nil
+}
+
+/*
+IsStable
+Description:
+
+	Returns true if every pole of sys has a negative real part (continuous-time) or a magnitude
+	smaller than one (discrete-time).
+*/
+func (sys StateSpace) IsStable() (bool, error) {
+	// Algorithm
+	poles, err := sys.Poles()
+	if err != nil {
+		return false, err
+	}
+
+	for _, pole := range poles {
+		if sys.IsDiscrete() {
+			if real(pole)*real(pole)+imag(pole)*imag(pole) >= 1 {
+				return false, nil
+			}
+		} else if real(pole) >= 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+/*
+solveGramianEquation
+Description:
+
+	Solves A X + X A^T + Q = 0 with Lyap (or A X A^T - X + Q = 0 with Dlyap when discrete is true)
+	and returns the symmetric part of the solution.
+*/
+func solveGramianEquation(A, Q mat.Matrix, discrete bool) (*mat.SymDense, error) {
+	// Algorithm
+	var X *mat.Dense
+	var err error
+	if discrete {
+		X, err = Dlyap(A, Q)
+	} else {
+		X, err = Lyap(A, Q)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("The Gramian equation could not be solved: %v", err)
+	}
+
+	return symmetrize(X), nil
+}
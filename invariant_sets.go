@@ -0,0 +1,241 @@
+/*
+   invariant_sets.go
+   Description:
+       Maximal positively invariant and maximal control invariant sets of linear systems "like" MPT3's
+       invariantSet method, computed by iterating the pre-set operator on Polyhedra.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// defaultInvariantSetIterations is the default limit on the number of pre-set iterations.
+const defaultInvariantSetIterations = 100
+
+type InvariantSetOptions struct {
+	MaxIterations int     // Maximum number of pre-set iterations (0 selects 100)
+	Lambda        float64 // Contraction factor in (0, 1]; values below 1 give a lambda-contractive inner approximation (0 selects 1)
+}
+
+/*
+MaxPositiveInvariant
+Description:
+
+	Computes the maximal positively invariant set of the autonomous system x+ = A x inside the Polyhedron X,
+	i.e. the set of initial states whose trajectories stay in X forever.
+*/
+func MaxPositiveInvariant(A mat.Matrix, X Polyhedron) (Polyhedron, error) {
+	return MaxPositiveInvariantWithOptions(A, X, InvariantSetOptions{})
+}
+
+/*
+MaxPositiveInvariantWithOptions
+Description:
+
+	Computes the maximal (lambda-contractive) positively invariant set of x+ = A x inside X with the iteration
+		O_0 = X,   O_{k+1} = O_k intersected with Pre(O_k),   Pre(S) = {x : A x in lambda S},
+	removing the redundant inequalities at each step. The iteration stops when O_{k+1} = O_k (checked with
+	set containment), and returns an error if this does not happen within MaxIterations iterations (for example,
+	if A is not stable the maximal invariant set may not be finitely determined).
+	For lambda < 1 (and X containing the origin), every state of the result is mapped into lambda times the result.
+*/
+func MaxPositiveInvariantWithOptions(A mat.Matrix, X Polyhedron, options InvariantSetOptions) (Polyhedron, error) {
+	// Input Processing
+	lambda, maxIterations, err := checkInvariantSetInputs(A, nil, X, nil, options)
+	if err != nil {
+		return Polyhedron{}, err
+	}
+
+	// Algorithm
+	pre := func(S Polyhedron) (Polyhedron, error) {
+		return autonomousPreSet(A, S, lambda)
+	}
+	return iteratePreSet(X, pre, maxIterations)
+}
+
+/*
+MaxControlInvariant
+Description:
+
+	Computes the maximal control invariant set of x+ = A x + B u inside the Polyhedron X with inputs in the
+	Polyhedron U, i.e. the set of initial states for which some admissible input keeps the state in X forever.
+*/
+func MaxControlInvariant(A, B mat.Matrix, X, U Polyhedron) (Polyhedron, error) {
+	return MaxControlInvariantWithOptions(A, B, X, U, InvariantSetOptions{})
+}
+
+/*
+MaxControlInvariantWithOptions
+Description:
+
+	Computes the maximal (lambda-contractive) control invariant set of x+ = A x + B u inside X with u in U with the
+	iteration
+		C_0 = X,   C_{k+1} = C_k intersected with Pre(C_k),   Pre(S) = {x : A x + B u in lambda S for some u in U},
+	where the pre-set is the projection onto x of a Polyhedron in (x, u) (see Projection). The iteration stops when
+	C_{k+1} = C_k and returns an error if this does not happen within MaxIterations iterations.
+*/
+func MaxControlInvariantWithOptions(A, B mat.Matrix, X, U Polyhedron, options InvariantSetOptions) (Polyhedron, error) {
+	// Input Processing
+	lambda, maxIterations, err := checkInvariantSetInputs(A, B, X, &U, options)
+	if err != nil {
+		return Polyhedron{}, err
+	}
+
+	// Algorithm
+	pre := func(S Polyhedron) (Polyhedron, error) {
+		return controlPreSet(A, B, S, U, lambda)
+	}
+	return iteratePreSet(X, pre, maxIterations)
+}
+
+/*
+checkInvariantSetInputs
+Description:
+
+	Verifies the dimensions of the system and the sets, and returns the contraction factor and the iteration limit.
+	B and U are nil for autonomous systems.
+*/
+func checkInvariantSetInputs(A, B mat.Matrix, X Polyhedron, U *Polyhedron, options InvariantSetOptions) (float64, int, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	if n != nA {
+		return 0, 0, fmt.Errorf("The matrix A must be square; received %v x %v.", n, nA)
+	}
+	if err := X.Check(); err != nil {
+		return 0, 0, err
+	}
+	if X.Dimension() != n {
+		return 0, 0, fmt.Errorf("The set X has dimension %v; expected %v.", X.Dimension(), n)
+	}
+	if B != nil {
+		nB, m := B.Dims()
+		if nB != n {
+			return 0, 0, fmt.Errorf("The matrix B has %v rows; expected %v.", nB, n)
+		}
+		if err := U.Check(); err != nil {
+			return 0, 0, err
+		}
+		if U.Dimension() != m {
+			return 0, 0, fmt.Errorf("The set U has dimension %v; expected %v.", U.Dimension(), m)
+		}
+	}
+
+	lambda := options.Lambda
+	if lambda == 0 {
+		lambda = 1
+	}
+	if !(lambda > 0) || (lambda > 1) {
+		return 0, 0, fmt.Errorf("The contraction factor must be in (0, 1]; received %v.", lambda)
+	}
+
+	maxIterations := options.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultInvariantSetIterations
+	}
+
+	return lambda, maxIterations, nil
+}
+
+/*
+iteratePreSet
+Description:
+
+	Iterates S_{k+1} = S_k intersected with pre(S_k) from S_0 = X until S_k is contained in S_{k+1}.
+*/
+func iteratePreSet(X Polyhedron, pre func(Polyhedron) (Polyhedron, error), maxIterations int) (Polyhedron, error) {
+	// Algorithm
+	current, err := X.MinHRep()
+	if err != nil {
+		return Polyhedron{}, errors.New("The set X is empty.")
+	}
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		preSet, err := pre(current)
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		next, err := current.Intersect(preSet)
+		if err != nil {
+			return Polyhedron{}, err
+		}
+
+		empty, err := next.IsEmptySet()
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		if empty {
+			return Polyhedron{}, errors.New("The invariant set is empty.")
+		}
+		if next, err = next.MinHRep(); err != nil {
+			return Polyhedron{}, err
+		}
+
+		if next.Contains(current) {
+			return next, nil
+		}
+		current = next
+	}
+
+	return Polyhedron{}, fmt.Errorf("The invariant set did not converge in %v iterations.", maxIterations)
+}
+
+/*
+autonomousPreSet
+Description:
+
+	Computes the pre-set {x : A x in lambda S} = {x : H A x <= lambda h} of S = {x : H x <= h}.
+*/
+func autonomousPreSet(A mat.Matrix, S Polyhedron, lambda float64) (Polyhedron, error) {
+	// Algorithm
+	var HA mat.Dense
+	HA.Mul(S.A, A)
+
+	var h mat.VecDense
+	h.ScaleVec(lambda, S.b)
+
+	return GetPolyhedron(&HA, &h), nil
+}
+
+/*
+controlPreSet
+Description:
+
+	Computes the pre-set {x : A x + B u in lambda S for some u in U} of S = {x : H x <= h} as the projection onto x of
+		{(x, u) : H A x + H B u <= lambda h,   Hu u <= hu}.
+*/
+func controlPreSet(A, B mat.Matrix, S, U Polyhedron, lambda float64) (Polyhedron, error) {
+	// Constants
+	n, m := B.Dims()
+	rowsS, _ := S.A.Dims()
+	rowsU, _ := U.A.Dims()
+
+	// Build the Polyhedron in (x, u)
+	var HA, HB mat.Dense
+	HA.Mul(S.A, A)
+	HB.Mul(S.A, B)
+
+	lifted := mat.NewDense(rowsS+rowsU, n+m, nil)
+	lifted.Slice(0, rowsS, 0, n).(*mat.Dense).Copy(&HA)
+	lifted.Slice(0, rowsS, n, n+m).(*mat.Dense).Copy(&HB)
+	lifted.Slice(rowsS, rowsS+rowsU, n, n+m).(*mat.Dense).Copy(U.A)
+
+	rhs := mat.NewVecDense(rowsS+rowsU, nil)
+	for i := 0; i < rowsS; i++ {
+		rhs.SetVec(i, lambda*S.b.AtVec(i))
+	}
+	for i := 0; i < rowsU; i++ {
+		rhs.SetVec(rowsS+i, U.b.AtVec(i))
+	}
+
+	// Algorithm
+	dims := make([]int, n)
+	for i := range dims {
+		dims[i] = i
+	}
+	return GetPolyhedron(lifted, rhs).Projection(dims)
+}
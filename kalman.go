@@ -0,0 +1,183 @@
+/*
+   kalman.go
+   Description:
+       Steady-state Kalman estimator design "like" MATLAB's kalman function. The noise model is
+           dx/dt = A x + B u + G w   (or x+ = A x + B u + G w when the model is discrete-time)
+               y = C x + D u + v
+       where the process noise w and the measurement noise v are white, with
+           E[w w^T] = Qn,   E[v v^T] = Rn,   E[w v^T] = Nn.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type KalmanEstimator struct {
+	L         *mat.Dense    // Estimator gain. The estimation error dynamics are given by A - L C.
+	M         *mat.Dense    // Innovation gain of the measurement update x[n|n] = x[n|n-1] + M (y[n] - C x[n|n-1] - D u[n]). Only set for discrete-time models.
+	P         *mat.SymDense // Steady-state estimation error covariance (of x[n|n-1] for discrete-time models).
+	Poles     []complex128  // Eigenvalues of A - L C.
+	Estimator StateSpace    // Estimator with inputs [u; y] and outputs [y_hat; x_hat].
+}
+
+/*
+Kalman
+Description:
+
+	Designs the steady-state Kalman estimator of the model sys, whose process noise w enters the state
+	through G (nil means G = I) and whose measurement noise is v. The noise covariances are Qn (of w), Rn (of v) and
+	Nn (the cross covariance E[w v^T], which may be nil).
+	For continuous-time models, the estimator is
+		dx_hat/dt = A x_hat + B u + L (y - C x_hat - D u).
+	For discrete-time models, the estimator is the one-step predictor
+		x_hat[n+1|n] = A x_hat[n|n-1] + B u[n] + L (y[n] - C x_hat[n|n-1] - D u[n]).
+*/
+func Kalman(sys StateSpace, G, Qn, Rn, Nn mat.Matrix) (KalmanEstimator, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return KalmanEstimator{}, err
+	}
+	if sys.HasInputDelay() {
+		return KalmanEstimator{}, errors.New("Kalman does not support models with input delays; discretize the model with C2d first.")
+	}
+
+	// Algorithm
+	var estimator KalmanEstimator
+	var err error
+	if sys.IsDiscrete() {
+		estimator, err = Dkalman(sys.A, G, sys.C, Qn, Rn, Nn)
+	} else {
+		estimator, err = kalmanGain(sys.A, G, sys.C, Qn, Rn, Nn, false)
+	}
+	if err != nil {
+		return KalmanEstimator{}, err
+	}
+
+	estimator.Estimator, err = kalmanEstimatorModel(sys, estimator.L)
+	if err != nil {
+		return KalmanEstimator{}, err
+	}
+
+	return estimator, nil
+}
+
+/*
+Dkalman
+Description:
+
+	Designs the steady-state Kalman filter of the discrete-time model x+ = A x + G w, y = C x + v (G nil means G = I),
+	with noise covariances Qn, Rn and Nn (see Kalman). Returns the predictor gain L, the innovation gain M,
+	the steady-state a priori error covariance P and the eigenvalues of A - L C. The Estimator field is not set.
+*/
+func Dkalman(A, G, C, Qn, Rn, Nn mat.Matrix) (KalmanEstimator, error) {
+	// Algorithm
+	estimator, err := kalmanGain(A, G, C, Qn, Rn, Nn, true)
+	if err != nil {
+		return KalmanEstimator{}, err
+	}
+
+	// M = P C^T (C P C^T + Rn)^{-1}
+	var PCt, CPCt, Mt mat.Dense
+	PCt.Mul(estimator.P, C.T())
+	CPCt.Mul(C, &PCt)
+	CPCt.Add(&CPCt, Rn)
+	if err := Mt.Solve(&CPCt, PCt.T()); err != nil {
+		return KalmanEstimator{}, fmt.Errorf("The innovation covariance is singular: %v", err)
+	}
+	estimator.M = mat.DenseCopyOf(Mt.T())
+
+	return estimator, nil
+}
+
+/*
+kalmanGain
+Description:
+
+	Computes the steady-state estimator gain by duality with the LQR problem: the error covariance P solves the
+	Riccati equation of the pair (A^T, C^T) with the weights G Qn G^T, Rn and G Nn, and L is the transpose of the
+	corresponding gain.
+*/
+func kalmanGain(A, G, C, Qn, Rn, Nn mat.Matrix, discrete bool) (KalmanEstimator, error) {
+	// Input Processing
+	n, _ := A.Dims()
+	if G == nil {
+		G = eye(n)
+	}
+	nG, q := G.Dims()
+	if nG != n {
+		return KalmanEstimator{}, fmt.Errorf("The matrix G has %v rows; expected %v.", nG, n)
+	}
+	if nQ, mQ := Qn.Dims(); (nQ != q) || (mQ != q) {
+		return KalmanEstimator{}, fmt.Errorf("The matrix Qn has dimensions %v x %v; expected %v x %v.", nQ, mQ, q, q)
+	}
+
+	// Build the weights of the dual problem
+	var QnGt, GQnGt mat.Dense
+	QnGt.Mul(Qn, G.T())
+	GQnGt.Mul(G, &QnGt)
+
+	var N mat.Matrix // nil when there is no cross covariance
+	if Nn != nil {
+		if nN, _ := Nn.Dims(); nN != q {
+			return KalmanEstimator{}, fmt.Errorf("The matrix Nn has %v rows; expected %v.", nN, q)
+		}
+		var GNn mat.Dense
+		GNn.Mul(G, Nn)
+		N = &GNn
+	}
+
+	// Solve the Riccati equation
+	var solution RiccatiSolution
+	var err error
+	if discrete {
+		solution, err = Dare(A.T(), C.T(), symmetrize(&GQnGt), Rn, N)
+	} else {
+		solution, err = Care(A.T(), C.T(), symmetrize(&GQnGt), Rn, N)
+	}
+	if err != nil {
+		return KalmanEstimator{}, fmt.Errorf("The Kalman estimator could not be designed: %v", err)
+	}
+
+	return KalmanEstimator{
+		L:     mat.DenseCopyOf(solution.K.T()),
+		P:     solution.X,
+		Poles: solution.ClosedLoopEigenvalues,
+	}, nil
+}
+
+/*
+kalmanEstimatorModel
+Description:
+
+	Builds the estimator model with inputs [u; y] and outputs [y_hat; x_hat]:
+		x_hat' = (A - L C) x_hat + [B - L D, L] [u; y]
+		[y_hat; x_hat] = [C; I] x_hat + [D, 0; 0, 0] [u; y]
+	where x_hat' is the derivative (continuous-time) or the next estimate (discrete-time).
+*/
+func kalmanEstimatorModel(sys StateSpace, L *mat.Dense) (StateSpace, error) {
+	// Constants
+	n, m, p := sys.Dims()
+
+	// Algorithm
+	var LC, LD, ALC, BLD mat.Dense
+	LC.Mul(L, sys.C)
+	LD.Mul(L, sys.D)
+	ALC.Sub(sys.A, &LC)
+	BLD.Sub(sys.B, &LD)
+
+	return GetDiscreteStateSpace(
+		&ALC,
+		hstack(&BLD, L),
+		vstack(sys.C, eye(n)),
+		vstack(
+			hstack(sys.D, mat.NewDense(p, p, nil)),
+			mat.NewDense(n, m+p, nil),
+		),
+		sys.Ts,
+	)
+}
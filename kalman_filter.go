@@ -0,0 +1,545 @@
+/*
+   kalman_filter.go
+   Description:
+       Recursive state estimators that can be run online: the (linear) Kalman filter, the extended Kalman filter and
+       the unscented Kalman filter. Each filter stores its current estimate X and covariance P, and alternates between
+       a Predict step (time update) and an Update step (measurement update).
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// TransitionFunction computes the next state f(x, u) of a discrete-time nonlinear model.
+type TransitionFunction func(x, u mat.Vector) *mat.VecDense
+
+// MeasurementFunction computes the measurement h(x) of a discrete-time nonlinear model.
+type MeasurementFunction func(x mat.Vector) *mat.VecDense
+
+// TransitionJacobian computes the Jacobian of a TransitionFunction with respect to x.
+type TransitionJacobian func(x, u mat.Vector) *mat.Dense
+
+// MeasurementJacobian computes the Jacobian of a MeasurementFunction with respect to x.
+type MeasurementJacobian func(x mat.Vector) *mat.Dense
+
+type KalmanFilter struct {
+	Model StateSpace    // Discrete-time model x+ = A x + B u + w, y = C x + D u + v
+	Q     *mat.SymDense // Covariance of the process noise w
+	R     *mat.SymDense // Covariance of the measurement noise v
+	X     *mat.VecDense // Current state estimate
+	P     *mat.SymDense // Covariance of the current estimation error
+}
+
+/*
+GetKalmanFilter
+Description:
+
+	Creates a Kalman filter for the discrete-time model sys with process noise covariance Q (n x n), measurement
+	noise covariance R (p x p), initial estimate x0 and initial covariance P0.
+*/
+func GetKalmanFilter(sys StateSpace, Q, R mat.Matrix, x0 mat.Vector, P0 mat.Matrix) (KalmanFilter, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return KalmanFilter{}, err
+	}
+	if !sys.IsDiscrete() {
+		return KalmanFilter{}, errors.New("The Kalman filter requires a discrete-time model; discretize the model with C2d first.")
+	}
+	if sys.HasInputDelay() {
+		return KalmanFilter{}, errors.New("The Kalman filter does not support models with input delays.")
+	}
+
+	n, _, p := sys.Dims()
+	if err := checkFilterCovariances(n, p, Q, R, x0, P0); err != nil {
+		return KalmanFilter{}, err
+	}
+
+	// Create Filter
+	return KalmanFilter{
+		Model: sys.Copy(),
+		Q:     symmetrize(Q),
+		R:     symmetrize(R),
+		X:     mat.VecDenseCopyOf(x0),
+		P:     symmetrize(P0),
+	}, nil
+}
+
+/*
+Predict
+Description:
+
+	Propagates the estimate through the model: X <- A X + B u and P <- A P A^T + Q.
+	A nil input u is treated as zero.
+*/
+func (kf *KalmanFilter) Predict(u mat.Vector) error {
+	// Input Processing
+	_, m, _ := kf.Model.Dims()
+	u, err := filterInput(u, m)
+	if err != nil {
+		return err
+	}
+
+	// Algorithm
+	var Ax, Bu mat.VecDense
+	Ax.MulVec(kf.Model.A, kf.X)
+	Bu.MulVec(kf.Model.B, u)
+	kf.X.AddVec(&Ax, &Bu)
+
+	kf.P = propagateCovariance(kf.Model.A, kf.P, kf.Q)
+	return nil
+}
+
+/*
+Update
+Description:
+
+	Corrects the estimate with the measurement y (taken while the input was u; nil means zero) using the Kalman gain
+		K = P C^T (C P C^T + R)^{-1}.
+	The covariance is updated with the Joseph form P <- (I - K C) P (I - K C)^T + K R K^T, which keeps it
+	symmetric and positive semidefinite.
+*/
+func (kf *KalmanFilter) Update(y, u mat.Vector) error {
+	// Input Processing
+	_, m, p := kf.Model.Dims()
+	if y.Len() != p {
+		return fmt.Errorf("The measurement has length %v; expected %v.", y.Len(), p)
+	}
+	u, err := filterInput(u, m)
+	if err != nil {
+		return err
+	}
+
+	// Algorithm
+	var yHat, Du mat.VecDense
+	yHat.MulVec(kf.Model.C, kf.X)
+	Du.MulVec(kf.Model.D, u)
+	yHat.AddVec(&yHat, &Du)
+
+	X, P, err := linearMeasurementUpdate(kf.X, kf.P, kf.Model.C, kf.R, y, &yHat)
+	if err != nil {
+		return err
+	}
+	kf.X, kf.P = X, P
+	return nil
+}
+
+type ExtendedKalmanFilter struct {
+	F  TransitionFunction  // State transition x+ = F(x, u) + w
+	H  MeasurementFunction // Measurement y = H(x) + v
+	FJ TransitionJacobian  // Jacobian of F with respect to x
+	HJ MeasurementJacobian // Jacobian of H with respect to x
+	Q  *mat.SymDense       // Covariance of the process noise w
+	R  *mat.SymDense       // Covariance of the measurement noise v
+	X  *mat.VecDense       // Current state estimate
+	P  *mat.SymDense       // Covariance of the current estimation error
+}
+
+/*
+GetExtendedKalmanFilter
+Description:
+
+	Creates an extended Kalman filter for the discrete-time nonlinear model x+ = F(x, u) + w, y = H(x) + v.
+	The Jacobians FJ and HJ are used to linearize the model around the current estimate.
+*/
+func GetExtendedKalmanFilter(F TransitionFunction, H MeasurementFunction, FJ TransitionJacobian, HJ MeasurementJacobian, Q, R mat.Matrix, x0 mat.Vector, P0 mat.Matrix) (ExtendedKalmanFilter, error) {
+	// Input Processing
+	if (F == nil) || (H == nil) || (FJ == nil) || (HJ == nil) {
+		return ExtendedKalmanFilter{}, errors.New("The extended Kalman filter requires the functions F and H and their Jacobians.")
+	}
+
+	n := x0.Len()
+	p, _ := R.Dims()
+	if err := checkFilterCovariances(n, p, Q, R, x0, P0); err != nil {
+		return ExtendedKalmanFilter{}, err
+	}
+
+	// Create Filter
+	return ExtendedKalmanFilter{
+		F: F, H: H, FJ: FJ, HJ: HJ,
+		Q: symmetrize(Q),
+		R: symmetrize(R),
+		X: mat.VecDenseCopyOf(x0),
+		P: symmetrize(P0),
+	}, nil
+}
+
+/*
+Predict
+Description:
+
+	Propagates the estimate through the nonlinear model, X <- F(X, u), and the covariance through its
+	linearization, P <- FJ P FJ^T + Q, where FJ is evaluated at the previous estimate.
+*/
+func (ekf *ExtendedKalmanFilter) Predict(u mat.Vector) error {
+	// Constants
+	n := ekf.X.Len()
+
+	// Algorithm
+	Fx := ekf.FJ(ekf.X, u)
+	if r, c := Fx.Dims(); (r != n) || (c != n) {
+		return fmt.Errorf("The transition Jacobian has dimensions %v x %v; expected %v x %v.", r, c, n, n)
+	}
+
+	xNext := ekf.F(ekf.X, u)
+	if xNext.Len() != n {
+		return fmt.Errorf("The transition function returned a vector of length %v; expected %v.", xNext.Len(), n)
+	}
+
+	ekf.X = xNext
+	ekf.P = propagateCovariance(Fx, ekf.P, ekf.Q)
+	return nil
+}
+
+/*
+Update
+Description:
+
+	Corrects the estimate with the measurement y, using the linearization HJ of the measurement function at the
+	predicted estimate.
+*/
+func (ekf *ExtendedKalmanFilter) Update(y mat.Vector) error {
+	// Constants
+	n := ekf.X.Len()
+	p, _ := ekf.R.Dims()
+
+	// Input Processing
+	if y.Len() != p {
+		return fmt.Errorf("The measurement has length %v; expected %v.", y.Len(), p)
+	}
+
+	// Algorithm
+	Hx := ekf.HJ(ekf.X)
+	if r, c := Hx.Dims(); (r != p) || (c != n) {
+		return fmt.Errorf("The measurement Jacobian has dimensions %v x %v; expected %v x %v.", r, c, p, n)
+	}
+
+	yHat := ekf.H(ekf.X)
+	if yHat.Len() != p {
+		return fmt.Errorf("The measurement function returned a vector of length %v; expected %v.", yHat.Len(), p)
+	}
+
+	X, P, err := linearMeasurementUpdate(ekf.X, ekf.P, Hx, ekf.R, y, yHat)
+	if err != nil {
+		return err
+	}
+	ekf.X, ekf.P = X, P
+	return nil
+}
+
+type UnscentedKalmanFilter struct {
+	F     TransitionFunction  // State transition x+ = F(x, u) + w
+	H     MeasurementFunction // Measurement y = H(x) + v
+	Q     *mat.SymDense       // Covariance of the process noise w
+	R     *mat.SymDense       // Covariance of the measurement noise v
+	X     *mat.VecDense       // Current state estimate
+	P     *mat.SymDense       // Covariance of the current estimation error
+	Alpha float64             // Spread of the sigma points around the mean (usually small, e.g. 1e-3)
+	Beta  float64             // Prior knowledge of the distribution (2 is optimal for Gaussian distributions)
+	Kappa float64             // Secondary scaling parameter (usually 0)
+}
+
+/*
+GetUnscentedKalmanFilter
+Description:
+
+	Creates an unscented Kalman filter for the discrete-time nonlinear model x+ = F(x, u) + w, y = H(x) + v,
+	with the scaled sigma points of van der Merwe (Alpha = 1e-3, Beta = 2, Kappa = 0). The parameters can be
+	changed after the filter is created.
+*/
+func GetUnscentedKalmanFilter(F TransitionFunction, H MeasurementFunction, Q, R mat.Matrix, x0 mat.Vector, P0 mat.Matrix) (UnscentedKalmanFilter, error) {
+	// Input Processing
+	if (F == nil) || (H == nil) {
+		return UnscentedKalmanFilter{}, errors.New("The unscented Kalman filter requires the functions F and H.")
+	}
+
+	n := x0.Len()
+	p, _ := R.Dims()
+	if err := checkFilterCovariances(n, p, Q, R, x0, P0); err != nil {
+		return UnscentedKalmanFilter{}, err
+	}
+
+	// Create Filter
+	return UnscentedKalmanFilter{
+		F: F, H: H,
+		Q:     symmetrize(Q),
+		R:     symmetrize(R),
+		X:     mat.VecDenseCopyOf(x0),
+		P:     symmetrize(P0),
+		Alpha: 1e-3,
+		Beta:  2,
+		Kappa: 0,
+	}, nil
+}
+
+/*
+Predict
+Description:
+
+	Propagates the sigma points of the current estimate through F and replaces the estimate and covariance by
+	the weighted mean and covariance of the propagated points (plus Q).
+*/
+func (ukf *UnscentedKalmanFilter) Predict(u mat.Vector) error {
+	// Constants
+	n := ukf.X.Len()
+
+	// Algorithm
+	points, meanWeights, covWeights, err := ukf.sigmaPoints()
+	if err != nil {
+		return err
+	}
+
+	propagated := make([]*mat.VecDense, len(points))
+	for i, point := range points {
+		propagated[i] = ukf.F(point, u)
+		if propagated[i].Len() != n {
+			return fmt.Errorf("The transition function returned a vector of length %v; expected %v.", propagated[i].Len(), n)
+		}
+	}
+
+	mean, covariance := weightedMeanAndCovariance(propagated, meanWeights, covWeights)
+	covariance.AddSym(covariance, ukf.Q)
+
+	ukf.X, ukf.P = mean, covariance
+	return nil
+}
+
+/*
+Update
+Description:
+
+	Corrects the estimate with the measurement y: the sigma points of the predicted estimate are passed through H,
+	and the gain K = Pxy Pyy^{-1} is computed from the cross covariance Pxy of the state and measurement and the
+	covariance Pyy of the measurement (plus R).
+*/
+func (ukf *UnscentedKalmanFilter) Update(y mat.Vector) error {
+	// Constants
+	n := ukf.X.Len()
+	p, _ := ukf.R.Dims()
+
+	// Input Processing
+	if y.Len() != p {
+		return fmt.Errorf("The measurement has length %v; expected %v.", y.Len(), p)
+	}
+
+	// Algorithm
+	points, meanWeights, covWeights, err := ukf.sigmaPoints()
+	if err != nil {
+		return err
+	}
+
+	measured := make([]*mat.VecDense, len(points))
+	for i, point := range points {
+		measured[i] = ukf.H(point)
+		if measured[i].Len() != p {
+			return fmt.Errorf("The measurement function returned a vector of length %v; expected %v.", measured[i].Len(), p)
+		}
+	}
+
+	yHat, Pyy := weightedMeanAndCovariance(measured, meanWeights, covWeights)
+	Pyy.AddSym(Pyy, ukf.R)
+
+	Pxy := mat.NewDense(n, p, nil)
+	for i := range points {
+		var dx, dy mat.VecDense
+		dx.SubVec(points[i], ukf.X)
+		dy.SubVec(measured[i], yHat)
+		var outer mat.Dense
+		outer.Outer(covWeights[i], &dx, &dy)
+		Pxy.Add(Pxy, &outer)
+	}
+
+	// K = Pxy Pyy^{-1}
+	var Kt mat.Dense
+	if err := Kt.Solve(Pyy, Pxy.T()); err != nil {
+		return fmt.Errorf("The innovation covariance is singular: %v", err)
+	}
+	K := Kt.T()
+
+	var innovation, correction mat.VecDense
+	innovation.SubVec(y, yHat)
+	correction.MulVec(K, &innovation)
+	ukf.X.AddVec(ukf.X, &correction)
+
+	// P <- P - K Pyy K^T
+	var KPyy, KPyyKt mat.Dense
+	KPyy.Mul(K, Pyy)
+	KPyyKt.Mul(&KPyy, K.T())
+	var P mat.Dense
+	P.Sub(ukf.P, &KPyyKt)
+	ukf.P = symmetrize(&P)
+
+	return nil
+}
+
+/*
+sigmaPoints
+Description:
+
+	Computes the 2n + 1 scaled sigma points X, X +/- sqrt(n + lambda) S_i (S_i the columns of a square root of P)
+	and their weights for the mean and the covariance, where lambda = Alpha^2 (n + Kappa) - n.
+*/
+func (ukf *UnscentedKalmanFilter) sigmaPoints() (points []*mat.VecDense, meanWeights, covWeights []float64, err error) {
+	// Constants
+	n := ukf.X.Len()
+	lambda := ukf.Alpha*ukf.Alpha*(float64(n)+ukf.Kappa) - float64(n)
+	if float64(n)+lambda <= 0 {
+		return nil, nil, nil, errors.New("The parameters Alpha and Kappa must satisfy Alpha^2 (n + Kappa) > 0.")
+	}
+
+	// Square root of (n + lambda) P
+	var chol mat.Cholesky
+	scaled := mat.NewSymDense(n, nil)
+	scaled.ScaleSym(float64(n)+lambda, ukf.P)
+	if ok := chol.Factorize(scaled); !ok {
+		return nil, nil, nil, errors.New("The covariance P is not positive definite.")
+	}
+	var L mat.TriDense
+	chol.LTo(&L)
+	S := mat.DenseCopyOf(&L)
+
+	// Algorithm
+	points = append(points, mat.VecDenseCopyOf(ukf.X))
+	for i := 0; i < n; i++ {
+		plus, minus := mat.VecDenseCopyOf(ukf.X), mat.VecDenseCopyOf(ukf.X)
+		plus.AddVec(plus, S.ColView(i))
+		minus.SubVec(minus, S.ColView(i))
+		points = append(points, plus, minus)
+	}
+
+	meanWeights = make([]float64, 2*n+1)
+	covWeights = make([]float64, 2*n+1)
+	meanWeights[0] = lambda / (float64(n) + lambda)
+	covWeights[0] = meanWeights[0] + 1 - ukf.Alpha*ukf.Alpha + ukf.Beta
+	for i := 1; i < 2*n+1; i++ {
+		meanWeights[i] = 1 / (2 * (float64(n) + lambda))
+		covWeights[i] = meanWeights[i]
+	}
+
+	return points, meanWeights, covWeights, nil
+}
+
+/*
+checkFilterCovariances
+Description:
+
+	Verifies the dimensions of the noise covariances Q and R, the initial estimate x0 and the initial covariance P0.
+*/
+func checkFilterCovariances(n, p int, Q, R mat.Matrix, x0 mat.Vector, P0 mat.Matrix) error {
+	if x0.Len() != n {
+		return fmt.Errorf("The initial estimate has length %v; expected %v.", x0.Len(), n)
+	}
+	if r, c := Q.Dims(); (r != n) || (c != n) {
+		return fmt.Errorf("The process noise covariance has dimensions %v x %v; expected %v x %v.", r, c, n, n)
+	}
+	if r, c := R.Dims(); (r != p) || (c != p) {
+		return fmt.Errorf("The measurement noise covariance has dimensions %v x %v; expected %v x %v.", r, c, p, p)
+	}
+	if r, c := P0.Dims(); (r != n) || (c != n) {
+		return fmt.Errorf("The initial covariance has dimensions %v x %v; expected %v x %v.", r, c, n, n)
+	}
+	return nil
+}
+
+/*
+filterInput
+Description:
+
+	Returns u, or a zero vector of length m when u is nil.
+*/
+func filterInput(u mat.Vector, m int) (mat.Vector, error) {
+	if u == nil {
+		return mat.NewVecDense(m, nil), nil
+	}
+	if u.Len() != m {
+		return nil, fmt.Errorf("The input has length %v; expected %v.", u.Len(), m)
+	}
+	return u, nil
+}
+
+/*
+propagateCovariance
+Description:
+
+	Computes F P F^T + Q.
+*/
+func propagateCovariance(F, P, Q mat.Matrix) *mat.SymDense {
+	var FP, FPFt mat.Dense
+	FP.Mul(F, P)
+	FPFt.Mul(&FP, F.T())
+	FPFt.Add(&FPFt, Q)
+	return symmetrize(&FPFt)
+}
+
+/*
+linearMeasurementUpdate
+Description:
+
+	Computes the Kalman measurement update of the estimate x with covariance P for the measurement y, the
+	predicted measurement yHat, the measurement matrix H and the measurement noise covariance R.
+	The covariance is updated with the Joseph form.
+*/
+func linearMeasurementUpdate(x *mat.VecDense, P *mat.SymDense, H mat.Matrix, R mat.Matrix, y, yHat mat.Vector) (*mat.VecDense, *mat.SymDense, error) {
+	// Constants
+	n := x.Len()
+
+	// K = P H^T (H P H^T + R)^{-1}
+	var PHt, S, Kt mat.Dense
+	PHt.Mul(P, H.T())
+	S.Mul(H, &PHt)
+	S.Add(&S, R)
+	if err := Kt.Solve(&S, PHt.T()); err != nil {
+		return nil, nil, fmt.Errorf("The innovation covariance is singular: %v", err)
+	}
+	K := Kt.T()
+
+	// x <- x + K (y - yHat)
+	var innovation, correction mat.VecDense
+	innovation.SubVec(y, yHat)
+	correction.MulVec(K, &innovation)
+	xOut := mat.NewVecDense(n, nil)
+	xOut.AddVec(x, &correction)
+
+	// P <- (I - K H) P (I - K H)^T + K R K^T
+	var KH, IKH, KR mat.Dense
+	KH.Mul(K, H)
+	IKH.Sub(eye(n), &KH)
+	KR.Mul(K, R)
+	var IKHP, joseph, KRKt mat.Dense
+	IKHP.Mul(&IKH, P)
+	joseph.Mul(&IKHP, IKH.T())
+	KRKt.Mul(&KR, K.T())
+	joseph.Add(&joseph, &KRKt)
+
+	return xOut, symmetrize(&joseph), nil
+}
+
+/*
+weightedMeanAndCovariance
+Description:
+
+	Computes the weighted mean and covariance of the given points.
+*/
+func weightedMeanAndCovariance(points []*mat.VecDense, meanWeights, covWeights []float64) (*mat.VecDense, *mat.SymDense) {
+	// Constants
+	d := points[0].Len()
+
+	// Algorithm
+	mean := mat.NewVecDense(d, nil)
+	for i, point := range points {
+		mean.AddScaledVec(mean, meanWeights[i], point)
+	}
+
+	covariance := mat.NewSymDense(d, nil)
+	for i, point := range points {
+		var deviation mat.VecDense
+		deviation.SubVec(point, mean)
+		covariance.SymRankOne(covariance, covWeights[i], &deviation)
+	}
+
+	return mean, covariance
+}
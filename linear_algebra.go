@@ -0,0 +1,239 @@
+/*
+   linear_algebra.go
+   Description:
+       Rank, range and null space computations based on the singular value decomposition.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+Rank
+Description:
+
+	Computes the rank of M as the number of singular values that are larger than tol.
+	If tol is not positive, then the default tolerance max(rows, cols) * sigma_max * eps is used (as in MATLAB).
+*/
+func Rank(M mat.Matrix, tol float64) (int, error) {
+	// Algorithm
+	values, err := singularValues(M)
+	if err != nil {
+		return -1, err
+	}
+
+	return countAbove(values, defaultRankTolerance(M, values, tol)), nil
+}
+
+/*
+singularValues
+Description:
+
+	Computes the singular values of M in decreasing order.
+*/
+func singularValues(M mat.Matrix) ([]float64, error) {
+	var svd mat.SVD
+	if ok := svd.Factorize(M, mat.SVDNone); !ok {
+		return nil, errors.New("The singular value decomposition did not converge.")
+	}
+	return svd.Values(nil), nil
+}
+
+/*
+defaultRankTolerance
+Description:
+
+	Returns tol if it is positive, and otherwise max(rows, cols) * sigma_max * eps.
+*/
+func defaultRankTolerance(M mat.Matrix, values []float64, tol float64) float64 {
+	if tol > 0 {
+		return tol
+	}
+
+	r, c := M.Dims()
+	sigmaMax := 0.0
+	if len(values) > 0 {
+		sigmaMax = values[0]
+	}
+	return math.Max(float64(r), float64(c)) * sigmaMax * eps
+}
+
+/*
+countAbove
+Description:
+
+	Counts the number of values in the slice that are larger than tol.
+*/
+func countAbove(values []float64, tol float64) int {
+	count := 0
+	for _, value := range values {
+		if value > tol {
+			count++
+		}
+	}
+	return count
+}
+
+/*
+fullSVD
+Description:
+
+	Computes the full singular value decomposition M = U diag(sigma) V^T.
+*/
+func fullSVD(M mat.Matrix) (U *mat.Dense, values []float64, V *mat.Dense, err error) {
+	var svd mat.SVD
+	if ok := svd.Factorize(M, mat.SVDFull); !ok {
+		return nil, nil, nil, errors.New("The singular value decomposition did not converge.")
+	}
+
+	U, V = &mat.Dense{}, &mat.Dense{}
+	svd.UTo(U)
+	svd.VTo(V)
+	return U, svd.Values(nil), V, nil
+}
+
+/*
+orth
+Description:
+
+	Computes an orthonormal basis for the range of M. Returns nil if the range is {0}.
+	If tol is not positive, then the default rank tolerance is used.
+*/
+func orth(M mat.Matrix, tol float64) (*mat.Dense, error) {
+	// Constants
+	r, _ := M.Dims()
+
+	// Algorithm
+	U, values, _, err := fullSVD(M)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := countAbove(values, defaultRankTolerance(M, values, tol))
+	if rank == 0 {
+		return nil, nil
+	}
+	return mat.DenseCopyOf(U.Slice(0, r, 0, rank)), nil
+}
+
+/*
+nullSpace
+Description:
+
+	Computes an orthonormal basis for the null space of M. Returns nil if the null space is {0}.
+	If tol is not positive, then the default rank tolerance is used.
+*/
+func nullSpace(M mat.Matrix, tol float64) (*mat.Dense, error) {
+	// Constants
+	_, c := M.Dims()
+
+	// Algorithm
+	_, values, V, err := fullSVD(M)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := countAbove(values, defaultRankTolerance(M, values, tol))
+	if rank == c {
+		return nil, nil
+	}
+	return mat.DenseCopyOf(V.Slice(0, c, rank, c)), nil
+}
+
+/*
+hstack
+Description:
+
+	Concatenates the matrices horizontally. nil matrices are skipped. Returns nil if every matrix is nil.
+*/
+func hstack(matrices ...*mat.Dense) *mat.Dense {
+	// Constants
+	rows, cols := 0, 0
+	for _, M := range matrices {
+		if M == nil {
+			continue
+		}
+		r, c := M.Dims()
+		rows, cols = r, cols+c
+	}
+
+	if cols == 0 {
+		return nil
+	}
+
+	// Algorithm
+	stacked := mat.NewDense(rows, cols, nil)
+	offset := 0
+	for _, M := range matrices {
+		if M == nil {
+			continue
+		}
+		_, c := M.Dims()
+		stacked.Slice(0, rows, offset, offset+c).(*mat.Dense).Copy(M)
+		offset += c
+	}
+	return stacked
+}
+
+/*
+vstack
+Description:
+
+	Concatenates the matrices vertically. nil matrices are skipped. Returns nil if every matrix is nil.
+*/
+func vstack(matrices ...*mat.Dense) *mat.Dense {
+	// Constants
+	rows, cols := 0, 0
+	for _, M := range matrices {
+		if M == nil {
+			continue
+		}
+		r, c := M.Dims()
+		rows, cols = rows+r, c
+	}
+
+	if rows == 0 {
+		return nil
+	}
+
+	// Algorithm
+	stacked := mat.NewDense(rows, cols, nil)
+	offset := 0
+	for _, M := range matrices {
+		if M == nil {
+			continue
+		}
+		r, _ := M.Dims()
+		stacked.Slice(offset, offset+r, 0, cols).(*mat.Dense).Copy(M)
+		offset += r
+	}
+	return stacked
+}
+
+/*
+symmetrize
+Description:
+
+	Returns the symmetric matrix (M + M^T)/2.
+*/
+func symmetrize(M mat.Matrix) *mat.SymDense {
+	// Constants
+	n, _ := M.Dims()
+
+	// Algorithm
+	S := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			S.SetSym(i, j, 0.5*(M.At(i, j)+M.At(j, i)))
+		}
+	}
+	return S
+}
+
+// eps is the machine precision of float64.
+var eps = math.Nextafter(1, 2) - 1
@@ -0,0 +1,366 @@
+/*
+   linear_program.go
+   Description:
+       Linear programming "like" MATLAB's linprog function, with a two-phase simplex method.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	ErrInfeasible = errors.New("The linear program is infeasible.")
+	ErrUnbounded  = errors.New("The linear program is unbounded.")
+)
+
+/*
+Linprog
+Description:
+
+	Solves the linear program
+		minimize c^T x   subject to   A x <= b,   Aeq x = beq
+	over the free variable x. Either set of constraints may be nil. Returns ErrInfeasible or ErrUnbounded
+	if the problem has no solution.
+	The problem is converted to the standard form of the simplex method by writing x = xp - xn and adding a slack
+	variable to each inequality.
+*/
+func Linprog(c mat.Vector, A mat.Matrix, b mat.Vector, Aeq mat.Matrix, beq mat.Vector) (*mat.VecDense, float64, error) {
+	// Input Processing
+	if c == nil {
+		return nil, 0, errors.New("The cost vector of the linear program is not defined.")
+	}
+	n := c.Len()
+
+	ni, ne := 0, 0
+	if A != nil {
+		rows, cols := A.Dims()
+		if (cols != n) || (b == nil) || (b.Len() != rows) {
+			return nil, 0, fmt.Errorf("The inequality constraints must be a matrix with %v columns and a vector with one entry per row.", n)
+		}
+		ni = rows
+	}
+	if Aeq != nil {
+		rows, cols := Aeq.Dims()
+		if (cols != n) || (beq == nil) || (beq.Len() != rows) {
+			return nil, 0, fmt.Errorf("The equality constraints must be a matrix with %v columns and a vector with one entry per row.", n)
+		}
+		ne = rows
+	}
+
+	var inequalities, equalities *mat.Dense
+	if ni > 0 {
+		inequalities = mat.DenseCopyOf(A)
+	}
+	if ne > 0 {
+		equalities = mat.DenseCopyOf(Aeq)
+	}
+	row := func(i int) (mat.Vector, float64) {
+		if i < ni {
+			return inequalities.RowView(i), b.AtVec(i)
+		}
+		return equalities.RowView(i - ni), beq.AtVec(i - ni)
+	}
+
+	// Drop the rows that are identically zero (after checking that they are satisfied)
+	var rows []int
+	for i := 0; i < ni+ne; i++ {
+		a, bi := row(i)
+		if mat.Norm(a, 1) > 0 {
+			rows = append(rows, i)
+			continue
+		}
+		if ((i < ni) && (bi < 0)) || ((i >= ni) && (bi != 0)) {
+			return nil, 0, ErrInfeasible
+		}
+	}
+
+	// Drop the variables that appear in no constraint (after checking that the cost is bounded)
+	var used []int
+	for j := 0; j < n; j++ {
+		appears := false
+		for _, i := range rows {
+			if a, _ := row(i); a.AtVec(j) != 0 {
+				appears = true
+				break
+			}
+		}
+		if appears {
+			used = append(used, j)
+		} else if c.AtVec(j) != 0 {
+			return nil, 0, ErrUnbounded
+		}
+	}
+
+	x := mat.NewVecDense(n, nil)
+	if len(rows) == 0 {
+		return x, 0, nil
+	}
+
+	// Build the standard form with the variables [xp; xn; s]
+	nu := len(used)
+	var slackRows []int
+	for _, i := range rows {
+		if i < ni {
+			slackRows = append(slackRows, i)
+		}
+	}
+	standardA := mat.NewDense(len(rows), 2*nu+len(slackRows), nil)
+	standardB := make([]float64, len(rows))
+	standardC := make([]float64, 2*nu+len(slackRows))
+	for k, j := range used {
+		standardC[k], standardC[nu+k] = c.AtVec(j), -c.AtVec(j)
+	}
+	slack := 0
+	for r, i := range rows {
+		a, bi := row(i)
+		for k, j := range used {
+			standardA.Set(r, k, a.AtVec(j))
+			standardA.Set(r, nu+k, -a.AtVec(j))
+		}
+		if i < ni {
+			standardA.Set(r, 2*nu+slack, 1)
+			slack++
+		}
+		standardB[r] = bi
+	}
+
+	// Algorithm
+	solution, err := simplex(standardC, standardA, standardB)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for k, j := range used {
+		x.SetVec(j, solution[k]-solution[nu+k])
+	}
+	return x, mat.Dot(c, x), nil
+}
+
+/*
+simplex
+Description:
+
+	Solves the standard form linear program
+		minimize c^T x   subject to   A x = b,   x >= 0
+	with the two-phase tableau simplex method. Phase one minimizes the sum of artificial variables (one per row);
+	phase two minimizes c^T x from the feasible basis of phase one. The entering variable has the most negative
+	reduced cost, except after a long sequence of degenerate pivots, when Bland's rule prevents cycling.
+	The basic variables of the final basis are recomputed from the original data to limit the accumulated roundoff.
+*/
+func simplex(c []float64, A *mat.Dense, b []float64) ([]float64, error) {
+	// Constants
+	m, n := A.Dims()
+	width := n + m + 1
+	rhs := width - 1
+	maxIterations := 50 * (m + n)
+
+	// Build the tableau of phase one: rows with a negative right hand side are negated
+	tableau := mat.NewDense(m+1, width, nil)
+	basis := make([]int, m)
+	for i := 0; i < m; i++ {
+		sign := 1.0
+		if b[i] < 0 {
+			sign = -1
+		}
+		for j := 0; j < n; j++ {
+			tableau.Set(i, j, sign*A.At(i, j))
+		}
+		tableau.Set(i, n+i, 1)
+		tableau.Set(i, rhs, sign*b[i])
+		basis[i] = n + i
+	}
+	for j := 0; j < n; j++ {
+		total := 0.0
+		for i := 0; i < m; i++ {
+			total += tableau.At(i, j)
+		}
+		tableau.Set(m, j, -total)
+	}
+	total := 0.0
+	for i := 0; i < m; i++ {
+		total += tableau.At(i, rhs)
+	}
+	tableau.Set(m, rhs, -total)
+
+	// Phase one
+	if err := simplexIterations(tableau, basis, n+m, maxIterations); err != nil {
+		return nil, err
+	}
+	scale := 1.0
+	for i := 0; i < m; i++ {
+		scale = math.Max(scale, math.Abs(b[i]))
+	}
+	if -tableau.At(m, rhs) > 1e-9*scale {
+		return nil, ErrInfeasible
+	}
+
+	// Drive the artificial variables out of the basis (or drop their rows, which are redundant)
+	active := make([]bool, m)
+	for i := 0; i < m; i++ {
+		active[i] = true
+		if basis[i] < n {
+			continue
+		}
+		pivotColumn := -1
+		for j := 0; j < n; j++ {
+			if math.Abs(tableau.At(i, j)) > 1e-9 {
+				pivotColumn = j
+				break
+			}
+		}
+		if pivotColumn < 0 {
+			active[i] = false
+			continue
+		}
+		simplexPivot(tableau, basis, i, pivotColumn)
+	}
+
+	// Phase two: reduced costs of c in the current basis
+	for j := 0; j < width; j++ {
+		value := 0.0
+		if j < n {
+			value = c[j]
+		}
+		for i := 0; i < m; i++ {
+			if active[i] && (basis[i] < n) {
+				value -= c[basis[i]] * tableau.At(i, j)
+			}
+		}
+		tableau.Set(m, j, value)
+	}
+	for i := 0; i < m; i++ {
+		if !active[i] {
+			for j := 0; j < width; j++ {
+				tableau.Set(i, j, 0)
+			}
+		}
+	}
+	if err := simplexIterations(tableau, basis, n, maxIterations); err != nil {
+		return nil, err
+	}
+
+	// Extract the solution and refine the basic variables with the original data
+	x := make([]float64, n)
+	var rows, columns []int
+	for i := 0; i < m; i++ {
+		if active[i] && (basis[i] < n) {
+			x[basis[i]] = tableau.At(i, rhs)
+			rows, columns = append(rows, i), append(columns, basis[i])
+		}
+	}
+	if len(rows) > 0 {
+		AB := mat.NewDense(len(rows), len(columns), nil)
+		bB := mat.NewVecDense(len(rows), nil)
+		for r, i := range rows {
+			for k, j := range columns {
+				AB.Set(r, k, A.At(i, j))
+			}
+			bB.SetVec(r, b[i])
+		}
+		var refined mat.VecDense
+		if err := refined.SolveVec(AB, bB); err == nil {
+			for k, j := range columns {
+				if refined.AtVec(k) >= -1e-9*scale {
+					x[j] = math.Max(refined.AtVec(k), 0)
+				}
+			}
+		}
+	}
+
+	return x, nil
+}
+
+/*
+simplexIterations
+Description:
+
+	Pivots the tableau (whose last row holds the reduced costs and whose last column holds the right hand side)
+	until no variable among the first numEligible columns has a negative reduced cost.
+	Returns ErrUnbounded if an entering variable can increase without bound.
+*/
+func simplexIterations(tableau *mat.Dense, basis []int, numEligible, maxIterations int) error {
+	// Constants
+	rows, width := tableau.Dims()
+	m, rhs := rows-1, width-1
+	degenerate := 0
+
+	// Algorithm
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		// Entering variable
+		entering, mostNegative := -1, -1e-10
+		for j := 0; j < numEligible; j++ {
+			if reducedCost := tableau.At(m, j); reducedCost < mostNegative {
+				entering, mostNegative = j, reducedCost
+				if degenerate > m {
+					break
+				}
+			}
+		}
+		if entering < 0 {
+			return nil
+		}
+
+		// Leaving variable (ratio test, with ties broken by the smallest basic index)
+		leaving, ratio := -1, math.Inf(1)
+		for i := 0; i < m; i++ {
+			if coefficient := tableau.At(i, entering); coefficient > 1e-9 {
+				candidate := tableau.At(i, rhs) / coefficient
+				if (candidate < ratio-1e-12) || ((candidate <= ratio+1e-12) && (leaving >= 0) && (basis[i] < basis[leaving])) {
+					leaving, ratio = i, candidate
+				}
+			}
+		}
+		if leaving < 0 {
+			return ErrUnbounded
+		}
+
+		if ratio <= 1e-12 {
+			degenerate++
+		} else {
+			degenerate = 0
+		}
+		simplexPivot(tableau, basis, leaving, entering)
+	}
+
+	return errors.New("The simplex method did not converge.")
+}
+
+/*
+simplexPivot
+Description:
+
+	Pivots the tableau on the entry (row, column): the variable of the column enters the basis in place of the
+	basic variable of the row.
+*/
+func simplexPivot(tableau *mat.Dense, basis []int, row, column int) {
+	// Constants
+	rows, width := tableau.Dims()
+
+	// Algorithm
+	pivotRow := tableau.RawRowView(row)
+	pivot := pivotRow[column]
+	for j := 0; j < width; j++ {
+		pivotRow[j] /= pivot
+	}
+	for i := 0; i < rows; i++ {
+		if i == row {
+			continue
+		}
+		current := tableau.RawRowView(i)
+		factor := current[column]
+		if factor == 0 {
+			continue
+		}
+		for j := 0; j < width; j++ {
+			current[j] -= factor * pivotRow[j]
+		}
+		current[column] = 0
+	}
+	basis[row] = column
+}
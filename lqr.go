@@ -0,0 +1,126 @@
+/*
+   lqr.go
+   Description:
+       Linear quadratic regulator design "like" MATLAB's lqr, dlqr and lqi functions.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+Lqr
+Description:
+
+	Computes the optimal state feedback u = -K x for the model sys and the cost
+		J = Integral( x^T Q x + u^T R u + 2 x^T N u )    (continuous-time) or
+		J = Sum( x^T Q x + u^T R u + 2 x^T N u )         (discrete-time).
+	Returns the gain K, the solution S of the associated Riccati equation and the closed-loop poles P (the eigenvalues
+	of A - B K). The cross weight N may be nil, in which case it is taken to be zero.
+*/
+func Lqr(sys StateSpace, Q, R, N mat.Matrix) (K *mat.Dense, S *mat.SymDense, P []complex128, err error) {
+	// Input Processing
+	if err := checkLqrModel(sys); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Algorithm
+	var solution RiccatiSolution
+	if sys.IsDiscrete() {
+		solution, err = Dare(sys.A, sys.B, Q, R, N)
+	} else {
+		solution, err = Care(sys.A, sys.B, Q, R, N)
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("The LQR problem could not be solved: %v", err)
+	}
+
+	return solution.K, solution.X, solution.ClosedLoopEigenvalues, nil
+}
+
+/*
+Dlqr
+Description:
+
+	Computes the optimal state feedback u[k] = -K x[k] for the discrete-time dynamics x[k+1] = A x[k] + B u[k] and
+	the cost J = Sum( x^T Q x + u^T R u + 2 x^T N u ). Returns the gain K, the solution S of the discrete-time
+	Riccati equation and the closed-loop poles P. The cross weight N may be nil, in which case it is taken to be zero.
+*/
+func Dlqr(A, B, Q, R, N mat.Matrix) (K *mat.Dense, S *mat.SymDense, P []complex128, err error) {
+	// Algorithm
+	solution, err := Dare(A, B, Q, R, N)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("The LQR problem could not be solved: %v", err)
+	}
+
+	return solution.K, solution.X, solution.ClosedLoopEigenvalues, nil
+}
+
+/*
+Lqi
+Description:
+
+	Computes the optimal state feedback with integral action u = -K [x; xi] for the model sys, where xi is the
+	integral of the tracking error r - y:
+		xi'     = r - y                   (continuous-time) or
+		xi[k+1] = xi[k] + Ts (r[k] - y[k])  (discrete-time).
+	The weights Q and N act on the augmented state [x; xi], so Q is (n+p) x (n+p) and N (if not nil) is (n+p) x m.
+	Returns the gain K = [Kx, Ki], the Riccati solution S and the closed-loop poles P of the augmented model.
+*/
+func Lqi(sys StateSpace, Q, R, N mat.Matrix) (K *mat.Dense, S *mat.SymDense, P []complex128, err error) {
+	// Input Processing
+	if err := checkLqrModel(sys); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Constants
+	n, m, p := sys.Dims()
+
+	// Build the augmented model
+	integratorGain := 1.0
+	if sys.IsDiscrete() {
+		integratorGain = sys.Ts
+	}
+
+	negC, negD := mat.NewDense(p, n, nil), mat.NewDense(p, m, nil)
+	negC.Scale(-integratorGain, sys.C)
+	negD.Scale(-integratorGain, sys.D)
+
+	integratorDynamics := mat.NewDense(p, p, nil)
+	if sys.IsDiscrete() {
+		integratorDynamics = eye(p)
+	}
+
+	augmented := sys.Copy()
+	augmented.A = vstack(hstack(sys.A, mat.NewDense(n, p, nil)), hstack(negC, integratorDynamics))
+	augmented.B = vstack(sys.B, negD)
+	augmented.C = eye(n + p)
+	augmented.D = mat.NewDense(n+p, m, nil)
+
+	// Algorithm
+	return Lqr(augmented, Q, R, N)
+}
+
+/*
+checkLqrModel
+Description:
+
+	Returns an error if sys is not a valid model for LQR design (models with input delays are not supported).
+*/
+func checkLqrModel(sys StateSpace) error {
+	// Check the model
+	if err := sys.Check(); err != nil {
+		return err
+	}
+
+	if sys.HasInputDelay() {
+		return errors.New("LQR design does not support models with input delays; discretize the model with C2d first.")
+	}
+
+	return nil
+}
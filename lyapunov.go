@@ -0,0 +1,340 @@
+/*
+   lyapunov.go
+   Description:
+       Solvers for the Sylvester and Lyapunov matrix equations "like" MATLAB's sylvester, lyap and dlyap functions.
+       The solvers use the Bartels-Stewart algorithm: each coefficient matrix is reduced to real Schur form,
+       the transformed (quasi-triangular) equation is solved by block substitution, and the solution is transformed back.
+*/
+
+package goControl
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// residualTolerance is the largest relative residual that the equation solvers accept.
+const residualTolerance = 1e-8
+
+/*
+Sylvester
+Description:
+
+	Solves the Sylvester equation A X + X B = C for X, where A is n x n, B is m x m and C is n x m.
+	The solution is unique when A and -B have no common eigenvalues.
+*/
+func Sylvester(A, B, C mat.Matrix) (*mat.Dense, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	m, mB := B.Dims()
+	nC, mC := C.Dims()
+	if (n != nA) || (m != mB) {
+		return nil, fmt.Errorf("The matrices A (%v x %v) and B (%v x %v) must be square.", n, nA, m, mB)
+	}
+	if (nC != n) || (mC != m) {
+		return nil, fmt.Errorf("The matrix C has dimensions %v x %v; expected %v x %v.", nC, mC, n, m)
+	}
+
+	// Reduce A and B to real Schur form
+	TA, U, err := realSchur(A)
+	if err != nil {
+		return nil, err
+	}
+	TB, V, err := realSchur(B)
+	if err != nil {
+		return nil, err
+	}
+
+	// Solve TA Y + Y TB = U^T C V
+	var UtC, F mat.Dense
+	UtC.Mul(U.T(), C)
+	F.Mul(&UtC, V)
+
+	Y, err := solveQuasiTriangularSylvester(TA, TB, &F)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transform back: X = U Y V^T
+	var UY, X mat.Dense
+	UY.Mul(U, Y)
+	X.Mul(&UY, V.T())
+
+	// Check the residual A X + X B - C
+	var AX, XB, residual mat.Dense
+	AX.Mul(A, &X)
+	XB.Mul(&X, B)
+	residual.Add(&AX, &XB)
+	residual.Sub(&residual, C)
+
+	scale := (mat.Norm(A, 1)+mat.Norm(B, 1))*mat.Norm(&X, 1) + mat.Norm(C, 1)
+	if err := checkResidual(&residual, scale); err != nil {
+		return nil, err
+	}
+
+	return &X, nil
+}
+
+/*
+Lyap
+Description:
+
+	Solves the continuous-time Lyapunov equation A X + X A^T + Q = 0 for X.
+	The solution is unique when no two eigenvalues of A sum to zero, and it is symmetric when Q is symmetric.
+*/
+func Lyap(A, Q mat.Matrix) (*mat.Dense, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	nQ, mQ := Q.Dims()
+	if (n != nA) || (nQ != n) || (mQ != n) {
+		return nil, fmt.Errorf("The matrices A (%v x %v) and Q (%v x %v) must be square and of the same size.", n, nA, nQ, mQ)
+	}
+
+	// Algorithm
+	negQ := mat.NewDense(n, n, nil)
+	negQ.Scale(-1, Q)
+
+	X, err := Sylvester(A, A.T(), negQ)
+	if err != nil {
+		return nil, err
+	}
+
+	if mat.Equal(Q, Q.T()) {
+		return mat.DenseCopyOf(symmetrize(X)), nil
+	}
+	return X, nil
+}
+
+/*
+Dlyap
+Description:
+
+	Solves the discrete-time Lyapunov (Stein) equation A X A^T - X + Q = 0 for X.
+	The solution is unique when no product of two eigenvalues of A equals one, and it is symmetric when
+	Q is symmetric.
+*/
+func Dlyap(A, Q mat.Matrix) (*mat.Dense, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	nQ, mQ := Q.Dims()
+	if (n != nA) || (nQ != n) || (mQ != n) {
+		return nil, fmt.Errorf("The matrices A (%v x %v) and Q (%v x %v) must be square and of the same size.", n, nA, nQ, mQ)
+	}
+
+	// Reduce A to real Schur form
+	T, U, err := realSchur(A)
+	if err != nil {
+		return nil, err
+	}
+
+	// Solve T Y T^T - Y + U^T Q U = 0
+	var UtQ, F mat.Dense
+	UtQ.Mul(U.T(), Q)
+	F.Mul(&UtQ, U)
+
+	Y, err := solveQuasiTriangularStein(T, &F)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transform back: X = U Y U^T
+	var UY, X mat.Dense
+	UY.Mul(U, Y)
+	X.Mul(&UY, U.T())
+
+	// Check the residual A X A^T - X + Q
+	var AX, AXAt, residual mat.Dense
+	AX.Mul(A, &X)
+	AXAt.Mul(&AX, A.T())
+	residual.Sub(&AXAt, &X)
+	residual.Add(&residual, Q)
+
+	normA := mat.Norm(A, 1)
+	scale := (normA*normA+1)*mat.Norm(&X, 1) + mat.Norm(Q, 1)
+	if err := checkResidual(&residual, scale); err != nil {
+		return nil, err
+	}
+
+	if mat.Equal(Q, Q.T()) {
+		return mat.DenseCopyOf(symmetrize(&X)), nil
+	}
+	return &X, nil
+}
+
+/*
+checkResidual
+Description:
+
+	Returns an error if the norm of the residual is larger than residualTolerance times scale.
+*/
+func checkResidual(residual mat.Matrix, scale float64) error {
+	// Constants
+	residualNorm := mat.Norm(residual, 1)
+
+	// Algorithm
+	if math.IsNaN(residualNorm) || (residualNorm > residualTolerance*math.Max(scale, eps)) {
+		return fmt.Errorf("The solution has a relative residual of %v; the equation is singular or too ill-conditioned.", residualNorm/math.Max(scale, eps))
+	}
+	return nil
+}
+
+/*
+solveQuasiTriangularSylvester
+Description:
+
+	Solves TA Y + Y TB = F where TA and TB are upper quasi-triangular. The block columns of Y are computed
+	from left to right and, within each block column, the block rows are computed from bottom to top.
+*/
+func solveQuasiTriangularSylvester(TA, TB, F *mat.Dense) (*mat.Dense, error) {
+	// Constants
+	n, m := F.Dims()
+	rowStarts, rowSizes := schurBlocks(TA)
+	colStarts, colSizes := schurBlocks(TB)
+
+	// Algorithm
+	Y := mat.NewDense(n, m, nil)
+	for jb := range colStarts {
+		j0, qj := colStarts[jb], colSizes[jb]
+		Sjj := TB.Slice(j0, j0+qj, j0, j0+qj)
+
+		// G = F_j - Y_{<j} TB_{<j, j}
+		G := mat.DenseCopyOf(F.Slice(0, n, j0, j0+qj))
+		if j0 > 0 {
+			var YTB mat.Dense
+			YTB.Mul(Y.Slice(0, n, 0, j0), TB.Slice(0, j0, j0, j0+qj))
+			G.Sub(G, &YTB)
+		}
+
+		// Solve TA Y_j + Y_j Sjj = G by back substitution over the block rows
+		for ib := len(rowStarts) - 1; ib >= 0; ib-- {
+			i0, pi := rowStarts[ib], rowSizes[ib]
+			Tii := TA.Slice(i0, i0+pi, i0, i0+pi)
+
+			rhs := mat.DenseCopyOf(G.Slice(i0, i0+pi, 0, qj))
+			if i0+pi < n {
+				var TY mat.Dense
+				TY.Mul(TA.Slice(i0, i0+pi, i0+pi, n), Y.Slice(i0+pi, n, j0, j0+qj))
+				rhs.Sub(rhs, &TY)
+			}
+
+			block, err := solveSmallSylvester(Tii, Sjj, rhs, false)
+			if err != nil {
+				return nil, err
+			}
+			Y.Slice(i0, i0+pi, j0, j0+qj).(*mat.Dense).Copy(block)
+		}
+	}
+
+	return Y, nil
+}
+
+/*
+solveQuasiTriangularStein
+Description:
+
+	Solves T Y T^T - Y + F = 0 where T is upper quasi-triangular. Block (i,j) of the equation only involves the
+	blocks Y_kl with k >= i and l >= j, so the blocks are computed from the bottom right corner. For each block row i,
+	M_il = T_ii Y_il + sum_{k > i} T_ik Y_kl is accumulated so that the total cost is O(n^3).
+*/
+func solveQuasiTriangularStein(T, F *mat.Dense) (*mat.Dense, error) {
+	// Constants
+	n, _ := T.Dims()
+	starts, sizes := schurBlocks(T)
+
+	// Algorithm
+	Y := mat.NewDense(n, n, nil)
+	for ib := len(starts) - 1; ib >= 0; ib-- {
+		i0, pi := starts[ib], sizes[ib]
+		Tii := T.Slice(i0, i0+pi, i0, i0+pi)
+
+		// M starts as Z_i = sum_{k > i} T_ik Y_k (for all block columns)
+		M := mat.NewDense(pi, n, nil)
+		if i0+pi < n {
+			M.Mul(T.Slice(i0, i0+pi, i0+pi, n), Y.Slice(i0+pi, n, 0, n))
+		}
+
+		for jb := len(starts) - 1; jb >= 0; jb-- {
+			j0, qj := starts[jb], sizes[jb]
+			Tjj := T.Slice(j0, j0+qj, j0, j0+qj)
+
+			// rhs = -F_ij - Z_ij T_jj^T - sum_{l > j} M_il T_jl^T
+			rhs := mat.NewDense(pi, qj, nil)
+			rhs.Scale(-1, F.Slice(i0, i0+pi, j0, j0+qj))
+
+			var ZT mat.Dense
+			ZT.Mul(M.Slice(0, pi, j0, j0+qj), Tjj.T())
+			rhs.Sub(rhs, &ZT)
+
+			if j0+qj < n {
+				var MT mat.Dense
+				MT.Mul(M.Slice(0, pi, j0+qj, n), T.Slice(j0, j0+qj, j0+qj, n).T())
+				rhs.Sub(rhs, &MT)
+			}
+
+			// Solve T_ii Y_ij T_jj^T - Y_ij = rhs
+			block, err := solveSmallSylvester(Tii, Tjj, rhs, true)
+			if err != nil {
+				return nil, err
+			}
+			Y.Slice(i0, i0+pi, j0, j0+qj).(*mat.Dense).Copy(block)
+
+			// Update M_ij = T_ii Y_ij + Z_ij
+			var TY mat.Dense
+			TY.Mul(Tii, block)
+			Mij := M.Slice(0, pi, j0, j0+qj).(*mat.Dense)
+			Mij.Add(Mij, &TY)
+		}
+	}
+
+	return Y, nil
+}
+
+/*
+solveSmallSylvester
+Description:
+
+	Solves the small (at most 2 x 2 blocks) equation
+		P X + X S = G        when stein is false, or
+		P X S^T - X = G      when stein is true,
+	using the Kronecker product form of the equation with column-major vectorization.
+*/
+func solveSmallSylvester(P, S mat.Matrix, G *mat.Dense, stein bool) (*mat.Dense, error) {
+	// Constants
+	p, _ := P.Dims()
+	q, _ := S.Dims()
+
+	// Build the Kronecker form
+	K := mat.NewDense(p*q, p*q, nil)
+	if stein {
+		K.Kronecker(S, P)
+		K.Sub(K, eye(p*q))
+	} else {
+		var IP, SI mat.Dense
+		IP.Kronecker(eye(q), P)
+		SI.Kronecker(S.T(), eye(p))
+		K.Add(&IP, &SI)
+	}
+
+	vecG := mat.NewVecDense(p*q, nil)
+	for j := 0; j < q; j++ {
+		for i := 0; i < p; i++ {
+			vecG.SetVec(j*p+i, G.At(i, j))
+		}
+	}
+
+	// Solve
+	var vecX mat.VecDense
+	if err := vecX.SolveVec(K, vecG); err != nil {
+		return nil, fmt.Errorf("The matrix equation does not have a unique solution: %v", err)
+	}
+
+	X := mat.NewDense(p, q, nil)
+	for j := 0; j < q; j++ {
+		for i := 0; i < p; i++ {
+			X.Set(i, j, vecX.AtVec(j*p+i))
+		}
+	}
+	return X, nil
+}
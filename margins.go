@@ -0,0 +1,245 @@
+/*
+   margins.go
+   Description:
+       Gain, phase and delay margins of single-input single-output open-loop models "like" MATLAB's margin and
+       allmargin functions. The margins describe how far the negative unity feedback loop around the model is
+       from instability.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// marginPointsPerDecade is the density of the grid on which the crossover frequencies are bracketed.
+const marginPointsPerDecade = 200
+
+type StabilityMargins struct {
+	GainMargin              float64 // Smallest gain margin (absolute units). +Inf if the phase never crosses -180 degrees.
+	PhaseMargin             float64 // Smallest phase margin (degrees). +Inf if the gain never crosses 1.
+	DelayMargin             float64 // Smallest delay margin (seconds). +Inf if the gain never crosses 1.
+	PhaseCrossoverFrequency float64 // Frequency (rad/s) at which the gain margin is measured. NaN if there is none.
+	GainCrossoverFrequency  float64 // Frequency (rad/s) at which the phase margin is measured. NaN if there is none.
+	Stable                  bool    // True if the closed loop (ignoring input delays) is stable.
+}
+
+type AllStabilityMargins struct {
+	GainMargins            []float64 // Gain margins (absolute units) at each phase crossover frequency
+	GainMarginFrequencies  []float64 // Frequencies (rad/s) at which the phase crosses -180 degrees
+	PhaseMargins           []float64 // Phase margins (degrees) at each gain crossover frequency
+	PhaseMarginFrequencies []float64 // Frequencies (rad/s) at which the gain crosses 1
+	DelayMargins           []float64 // Delay margins (seconds) at each gain crossover frequency
+	DelayMarginFrequencies []float64 // Frequencies (rad/s) at which the delay margins are measured
+	Stable                 bool      // True if the closed loop (ignoring input delays) is stable.
+}
+
+/*
+Margin
+Description:
+
+	Computes the smallest gain, phase and delay margins of the single-input single-output model sys, together with
+	the corresponding crossover frequencies. The gain margin with the smallest distance to 1 (in dB) is reported.
+*/
+func Margin(sys StateSpace) (StabilityMargins, error) {
+	// Algorithm
+	all, err := AllMargin(sys)
+	if err != nil {
+		return StabilityMargins{}, err
+	}
+
+	margins := StabilityMargins{
+		GainMargin:              math.Inf(1),
+		PhaseMargin:             math.Inf(1),
+		DelayMargin:             math.Inf(1),
+		PhaseCrossoverFrequency: math.NaN(),
+		GainCrossoverFrequency:  math.NaN(),
+		Stable:                  all.Stable,
+	}
+
+	for k, gm := range all.GainMargins {
+		if math.Abs(math.Log(gm)) < math.Abs(math.Log(margins.GainMargin)) {
+			margins.GainMargin = gm
+			margins.PhaseCrossoverFrequency = all.GainMarginFrequencies[k]
+		}
+	}
+	for k, pm := range all.PhaseMargins {
+		if math.Abs(pm) < math.Abs(margins.PhaseMargin) {
+			margins.PhaseMargin = pm
+			margins.GainCrossoverFrequency = all.PhaseMarginFrequencies[k]
+		}
+	}
+	for _, dm := range all.DelayMargins {
+		margins.DelayMargin = math.Min(margins.DelayMargin, dm)
+	}
+
+	return margins, nil
+}
+
+/*
+AllMargin
+Description:
+
+	Computes every gain, phase and delay margin of the single-input single-output model sys.
+	The crossover frequencies are bracketed on a dense logarithmic grid and refined by bisection.
+	The delay margins are given in seconds for both continuous-time and discrete-time models.
+*/
+func AllMargin(sys StateSpace) (AllStabilityMargins, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return AllStabilityMargins{}, err
+	}
+	if _, m, p := sys.Dims(); (m != 1) || (p != 1) {
+		return AllStabilityMargins{}, errors.New("The stability margins are only defined for single-input single-output models.")
+	}
+
+	// Constants
+	evaluator := getFrequencyEvaluator(sys)
+	response := func(w float64) complex128 { return evaluator.at(w).At(0, 0) }
+
+	low, high, err := frequencyRange(sys)
+	if err != nil {
+		return AllStabilityMargins{}, err
+	}
+	low /= 1000
+	if !sys.IsDiscrete() {
+		high *= 1000
+	}
+	grid := logspace(math.Log10(low), math.Log10(high), marginPointsPerDecade)
+
+	// Find the crossover frequencies
+	margins := AllStabilityMargins{}
+
+	gainCrossovers := findCrossings(grid, func(w float64) float64 { return math.Log(cmplx.Abs(response(w))) })
+	for _, w := range gainCrossovers {
+		pm := cmplx.Phase(response(w))*180/math.Pi + 180
+		if pm > 180 {
+			pm -= 360
+		}
+		margins.PhaseMargins = append(margins.PhaseMargins, pm)
+		margins.PhaseMarginFrequencies = append(margins.PhaseMarginFrequencies, w)
+
+		dm := pm
+		if dm < 0 {
+			dm += 360
+		}
+		margins.DelayMargins = append(margins.DelayMargins, dm*math.Pi/180/w)
+		margins.DelayMarginFrequencies = append(margins.DelayMarginFrequencies, w)
+	}
+
+	phaseCrossovers := findCrossings(grid, func(w float64) float64 { return imag(response(w)) })
+	if H0 := response(0); isNegativeReal(H0) {
+		phaseCrossovers = append([]float64{0}, phaseCrossovers...)
+	}
+	if sys.IsDiscrete() {
+		if Hn := response(math.Pi / sys.Ts); isNegativeReal(Hn) {
+			phaseCrossovers = append(phaseCrossovers, math.Pi/sys.Ts)
+		}
+	}
+	for k, w := range phaseCrossovers {
+		if (k > 0) && (w-phaseCrossovers[k-1] <= 1e-8*w) {
+			continue
+		}
+		H := response(w)
+		if real(H) >= 0 || cmplx.IsInf(H) {
+			continue
+		}
+		margins.GainMargins = append(margins.GainMargins, 1/cmplx.Abs(H))
+		margins.GainMarginFrequencies = append(margins.GainMarginFrequencies, w)
+	}
+
+	// Check the stability of the closed loop
+	margins.Stable, err = unityFeedbackIsStable(sys)
+	if err != nil {
+		return AllStabilityMargins{}, err
+	}
+
+	return margins, nil
+}
+
+/*
+findCrossings
+Description:
+
+	Returns the points of the (increasing) grid where the function f changes sign, refined by bisection
+	in logarithmic scale. Sign changes across infinite or undefined values (poles) are ignored.
+*/
+func findCrossings(grid []float64, f func(w float64) float64) []float64 {
+	// Algorithm
+	var crossings []float64
+	previous := f(grid[0])
+	for k := 1; k < len(grid); k++ {
+		current := f(grid[k])
+		if isFinite(previous) && isFinite(current) && ((previous < 0) != (current < 0)) {
+			a, b, fa := grid[k-1], grid[k], previous
+			for iteration := 0; iteration < 60; iteration++ {
+				mid := math.Sqrt(a * b)
+				fMid := f(mid)
+				if !isFinite(fMid) {
+					break
+				}
+				if (fa < 0) != (fMid < 0) {
+					b = mid
+				} else {
+					a, fa = mid, fMid
+				}
+			}
+			crossings = append(crossings, math.Sqrt(a*b))
+		}
+		previous = current
+	}
+
+	sort.Float64s(crossings)
+	return crossings
+}
+
+/*
+unityFeedbackIsStable
+Description:
+
+	Returns true if the negative unity feedback loop around the SISO model sys is stable. The closed-loop state
+	matrix is A - B (1 + D)^{-1} C. Input delays are ignored.
+*/
+func unityFeedbackIsStable(sys StateSpace) (bool, error) {
+	// Constants
+	denominator := 1 + sys.D.At(0, 0)
+	if denominator == 0 {
+		return false, nil
+	}
+
+	// Algorithm
+	closedLoop := sys.Copy()
+	closedLoop.InputDelay = nil
+
+	n, _, _ := sys.Dims()
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			closedLoop.A.Set(i, j, sys.A.At(i, j)-sys.B.At(i, 0)*sys.C.At(0, j)/denominator)
+		}
+	}
+
+	return closedLoop.IsStable()
+}
+
+/*
+isNegativeReal
+Description:
+
+	Returns true if z is finite, has a negative real part and a negligible imaginary part.
+*/
+func isNegativeReal(z complex128) bool {
+	return !cmplx.IsInf(z) && !cmplx.IsNaN(z) && (real(z) < 0) && (math.Abs(imag(z)) <= 1e-10*cmplx.Abs(z))
+}
+
+/*
+isFinite
+Description:
+
+	Returns true if x is neither infinite nor NaN.
+*/
+func isFinite(x float64) bool {
+	return !math.IsInf(x, 0) && !math.IsNaN(x)
+}
@@ -0,0 +1,166 @@
+/*
+   matrix_functions.go
+   Description:
+       Functions of square matrices (logarithms, square roots, etc.) that are not provided by gonum.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+sqrtm
+Description:
+
+	Computes the principal square root of the square matrix M using the Denman-Beavers iteration.
+	M must not have eigenvalues on the closed negative real axis.
+*/
+func sqrtm(M mat.Matrix) (*mat.Dense, error) {
+	// Constants
+	n, _ := M.Dims()
+	maxIterations := 100
+
+	// Algorithm
+	Y := mat.DenseCopyOf(M)
+	Z := eye(n)
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		var YInv, ZInv mat.Dense
+		if err := YInv.Inverse(Y); err != nil {
+			return nil, errors.New("The matrix square root iteration encountered a singular matrix.")
+		}
+		if err := ZInv.Inverse(Z); err != nil {
+			return nil, errors.New("The matrix square root iteration encountered a singular matrix.")
+		}
+
+		YNext := mat.NewDense(n, n, nil)
+		YNext.Add(Y, &ZInv)
+		YNext.Scale(0.5, YNext)
+
+		ZNext := mat.NewDense(n, n, nil)
+		ZNext.Add(Z, &YInv)
+		ZNext.Scale(0.5, ZNext)
+
+		var change mat.Dense
+		change.Sub(YNext, Y)
+		Y, Z = YNext, ZNext
+
+		if mat.Norm(&change, 1) <= 1e-14*mat.Norm(Y, 1) {
+			return Y, nil
+		}
+	}
+
+	return nil, errors.New("The matrix square root iteration did not converge.")
+}
+
+/*
+logm
+Description:
+
+	Computes the principal logarithm of the square matrix M using the inverse scaling and squaring method.
+	Square roots are taken until M is close to the identity, and then the logarithm is approximated with a
+	Gauss-Legendre quadrature of log(I + E) = int_0^1 E (I + t E)^{-1} dt.
+	M must not have eigenvalues on the closed negative real axis.
+*/
+func logm(M mat.Matrix) (*mat.Dense, error) {
+	// Constants
+	n, _ := M.Dims()
+	maxSquareRoots := 60
+
+	// Input Processing
+	eigs, err := eigenvalues(M)
+	if err != nil {
+		return nil, err
+	}
+	for _, lambda := range eigs {
+		if (real(lambda) <= 0) && (math.Abs(imag(lambda)) <= 1e-12*math.Max(1, math.Abs(real(lambda)))) {
+			return nil, errors.New("The matrix has an eigenvalue on the closed negative real axis, so it does not have a real principal logarithm.")
+		}
+	}
+
+	// Take square roots until M is close to the identity
+	X := mat.DenseCopyOf(M)
+	numSquareRoots := 0
+	E := mat.NewDense(n, n, nil)
+	E.Sub(X, eye(n))
+	for mat.Norm(E, 1) > 0.25 {
+		if numSquareRoots == maxSquareRoots {
+			return nil, errors.New("The matrix logarithm could not reduce its input to a neighborhood of the identity.")
+		}
+
+		X, err = sqrtm(X)
+		if err != nil {
+			return nil, err
+		}
+		numSquareRoots++
+		E.Sub(X, eye(n))
+	}
+
+	// Apply quadrature to log(I + E)
+	nodes, weights := gaussLegendre01()
+	L := mat.NewDense(n, n, nil)
+	for k := range nodes {
+		IPlusTE := mat.NewDense(n, n, nil)
+		IPlusTE.Scale(nodes[k], E)
+		IPlusTE.Add(IPlusTE, eye(n))
+
+		term := mat.NewDense(n, n, nil)
+		if err := term.Solve(IPlusTE, E); err != nil {
+			return nil, err
+		}
+		term.Scale(weights[k], term)
+		L.Add(L, term)
+	}
+
+	L.Scale(math.Pow(2, float64(numSquareRoots)), L)
+	return L, nil
+}
+
+/*
+gaussLegendre01
+Description:
+
+	Returns the nodes and weights of the 8 point Gauss-Legendre quadrature rule on the interval [0,1].
+*/
+func gaussLegendre01() ([]float64, []float64) {
+	// Constants (nodes and weights on [-1,1])
+	nodes := []float64{
+		-0.9602898564975363, -0.7966664774136267, -0.5255324099163290, -0.1834346424956498,
+		0.1834346424956498, 0.5255324099163290, 0.7966664774136267, 0.9602898564975363,
+	}
+	weights := []float64{
+		0.1012285362903763, 0.2223810344533745, 0.3137066458778873, 0.3626837833783620,
+		0.3626837833783620, 0.3137066458778873, 0.2223810344533745, 0.1012285362903763,
+	}
+
+	// Shift the rule to [0,1]
+	for k := range nodes {
+		nodes[k] = 0.5 * (nodes[k] + 1)
+		weights[k] = 0.5 * weights[k]
+	}
+
+	return nodes, weights
+}
+
+/*
+expmBlock
+Description:
+
+	Computes the matrix exponential of M * t and returns it.
+*/
+func expmBlock(M mat.Matrix, t float64) *mat.Dense {
+	// Constants
+	n, _ := M.Dims()
+
+	// Algorithm
+	Mt := mat.NewDense(n, n, nil)
+	Mt.Scale(t, M)
+
+	expMt := mat.NewDense(n, n, nil)
+	expMt.Exp(Mt)
+	return expMt
+}
@@ -0,0 +1,364 @@
+/*
+   model_reduction.go
+   Description:
+       Minimal realizations, balanced realizations and balanced truncation of state space models "like" MATLAB's
+       minreal, hsvd, balreal and balred functions.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type BalancedRealization struct {
+	Sys  StateSpace // The balanced model, with equal and diagonal controllability and observability Gramians.
+	HSV  []float64  // Hankel singular values (the diagonal of the balanced Gramians), in decreasing order.
+	T    *mat.Dense // Transformation from the balanced state to the original state (x = T z).
+	Tinv *mat.Dense // Transformation from the original state to the balanced state (z = Tinv x).
+}
+
+type BalancedTruncationOptions struct {
+	MatchDCGain bool // Residualize the discarded states (singular perturbation) instead of truncating them, so that the DC gain is preserved.
+}
+
+/*
+Minreal
+Description:
+
+	Removes the uncontrollable and unobservable states of sys. The controllable part is extracted with Ctrbf and the
+	observable part of the result with Obsvf, so the returned model is minimal and has the same transfer function.
+	A nonpositive tol selects the default tolerance of Ctrbf.
+*/
+func (sys StateSpace) Minreal(tol float64) (StateSpace, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return StateSpace{}, err
+	}
+
+	// Keep the controllable part
+	controllable, err := Ctrbf(sys.A, sys.B, sys.C, tol)
+	if err != nil {
+		return StateSpace{}, err
+	}
+	nc := controllable.NumReached()
+	if nc == 0 {
+		return StateSpace{}, errors.New("The minimal realization has no states; static gains are not supported.")
+	}
+
+	A := controllable.A.Slice(0, nc, 0, nc)
+	B := controllable.B.Slice(0, nc, 0, controllable.B.RawMatrix().Cols)
+	C := controllable.C.Slice(0, controllable.C.RawMatrix().Rows, 0, nc)
+
+	// Keep the observable part of the controllable part
+	observable, err := Obsvf(A, B, C, tol)
+	if err != nil {
+		return StateSpace{}, err
+	}
+	nco := observable.NumReached()
+	if nco == 0 {
+		return StateSpace{}, errors.New("The minimal realization has no states; static gains are not supported.")
+	}
+
+	_, m, p := sys.Dims()
+	minimal, err := GetDiscreteStateSpace(
+		observable.A.Slice(0, nco, 0, nco),
+		observable.B.Slice(0, nco, 0, m),
+		observable.C.Slice(0, p, 0, nco),
+		sys.D,
+		sys.Ts,
+	)
+	if err != nil {
+		return StateSpace{}, err
+	}
+	minimal.InputDelay = append([]float64(nil), sys.InputDelay...)
+
+	return minimal, nil
+}
+
+/*
+HSVD
+Description:
+
+	Computes the Hankel singular values of the stable model sys, i.e. the square roots of the eigenvalues of Wc Wo,
+	in decreasing order. They are computed as the singular values of Lo^T Lc, where Wc = Lc Lc^T and Wo = Lo Lo^T.
+*/
+func HSVD(sys StateSpace) ([]float64, error) {
+	// Algorithm
+	_, values, _, _, _, err := gramianSquareRoots(sys)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+/*
+Balreal
+Description:
+
+	Computes a balanced realization of the stable and minimal model sys with the square-root method: with
+	Wc = Lc Lc^T, Wo = Lo Lo^T and the SVD Lo^T Lc = U S V^T, the transformation is
+		T = Lc V S^{-1/2},   Tinv = S^{-1/2} U^T Lo^T.
+	Non-minimal models (with a zero Hankel singular value) are rejected; use Minreal first.
+*/
+func Balreal(sys StateSpace) (BalancedRealization, error) {
+	// Algorithm
+	Lc, values, U, V, Lo, err := gramianSquareRoots(sys)
+	if err != nil {
+		return BalancedRealization{}, err
+	}
+
+	n := len(values)
+	if values[n-1] <= hsvTolerance(values) {
+		return BalancedRealization{}, errors.New("The model is not minimal (it has a zero Hankel singular value); use Minreal before Balreal.")
+	}
+
+	balanced, T, Tinv, err := balancedProjection(sys, Lc, values, U, V, Lo, n)
+	if err != nil {
+		return BalancedRealization{}, err
+	}
+
+	return BalancedRealization{Sys: balanced, HSV: values, T: T, Tinv: Tinv}, nil
+}
+
+/*
+BalancedTruncation
+Description:
+
+	Reduces the stable model sys to the given order with the square-root balanced truncation method and returns the
+	reduced model and the error bound 2 (sigma_{order+1} + ... + sigma_n), where sigma are the Hankel singular
+	values: the H-infinity norm of the difference between sys and the reduced model is at most this bound.
+*/
+func BalancedTruncation(sys StateSpace, order int) (StateSpace, float64, error) {
+	return BalancedTruncationWithOptions(sys, order, BalancedTruncationOptions{})
+}
+
+/*
+BalancedTruncationWithOptions
+Description:
+
+	Reduces the stable model sys to the given order with balanced truncation (see BalancedTruncation). With the
+	MatchDCGain option, the discarded states of the balanced realization are residualized (their derivative, or their
+	change for discrete-time models, is set to zero) instead of truncated, so that the reduced model has the same DC gain.
+	The error bound is the same for both methods.
+*/
+func BalancedTruncationWithOptions(sys StateSpace, order int, options BalancedTruncationOptions) (StateSpace, float64, error) {
+	// Input Processing
+	Lc, values, U, V, Lo, err := gramianSquareRoots(sys)
+	if err != nil {
+		return StateSpace{}, 0, err
+	}
+	n := len(values)
+	if (order < 1) || (order > n) {
+		return StateSpace{}, 0, fmt.Errorf("The order of the reduced model must be between 1 and %v; received %v.", n, order)
+	}
+
+	// The states with a zero Hankel singular value do not contribute to the transfer function
+	minimalOrder := countAbove(values, hsvTolerance(values))
+	if order > minimalOrder {
+		order = minimalOrder
+	}
+
+	errorBound := 0.0
+	for _, value := range values[order:] {
+		errorBound += 2 * value
+	}
+
+	// Algorithm
+	if !options.MatchDCGain {
+		reduced, _, _, err := balancedProjection(sys, Lc, values, U, V, Lo, order)
+		return reduced, errorBound, err
+	}
+
+	balanced, _, _, err := balancedProjection(sys, Lc, values, U, V, Lo, minimalOrder)
+	if err != nil {
+		return StateSpace{}, 0, err
+	}
+	reduced, err := residualize(balanced, order)
+	return reduced, errorBound, err
+}
+
+/*
+gramianSquareRoots
+Description:
+
+	Computes square-root factors Lc and Lo of the Gramians (Wc = Lc Lc^T and Wo = Lo Lo^T) of the stable model sys,
+	and the SVD Lo^T Lc = U diag(values) V^T. The factors are computed from the eigendecompositions of the Gramians,
+	so that they exist even when the Gramians are only positive semidefinite.
+*/
+func gramianSquareRoots(sys StateSpace) (Lc *mat.Dense, values []float64, U, V *mat.Dense, Lo *mat.Dense, err error) {
+	// Compute the Gramians
+	Wc, err := ControllabilityGramian(sys)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	Wo, err := ObservabilityGramian(sys)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	// Compute the factors
+	Lc, err = symmetricSquareRoot(Wc)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	Lo, err = symmetricSquareRoot(Wo)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	var LotLc mat.Dense
+	LotLc.Mul(Lo.T(), Lc)
+	U, values, V, err = fullSVD(&LotLc)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	return Lc, values, U, V, Lo, nil
+}
+
+/*
+symmetricSquareRoot
+Description:
+
+	Computes L = V diag(sqrt(lambda)) such that W = L L^T for the symmetric positive semidefinite matrix W, where
+	W = V diag(lambda) V^T. Small negative eigenvalues (due to roundoff) are set to zero.
+*/
+func symmetricSquareRoot(W *mat.SymDense) (*mat.Dense, error) {
+	// Constants
+	n := W.SymmetricDim()
+
+	// Algorithm
+	var eig mat.EigenSym
+	if ok := eig.Factorize(W, true); !ok {
+		return nil, errors.New("The eigenvalue decomposition of the Gramian did not converge.")
+	}
+	lambda := eig.Values(nil)
+	var V mat.Dense
+	eig.VectorsTo(&V)
+
+	L := mat.NewDense(n, n, nil)
+	for j := 0; j < n; j++ {
+		scale := math.Sqrt(math.Max(lambda[j], 0))
+		for i := 0; i < n; i++ {
+			L.Set(i, j, V.At(i, j)*scale)
+		}
+	}
+	return L, nil
+}
+
+/*
+hsvTolerance
+Description:
+
+	Returns the tolerance below which a Hankel singular value is considered to be zero. The square-root factors of
+	singular Gramians are only accurate to about sqrt(eps), so the tolerance is relative to that accuracy.
+*/
+func hsvTolerance(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return 10 * float64(len(values)) * math.Sqrt(eps) * values[0]
+}
+
+/*
+balancedProjection
+Description:
+
+	Projects sys onto its first r balanced states with the square-root transformation
+		T = Lc V_r S_r^{-1/2},   Tinv = S_r^{-1/2} U_r^T Lo^T,
+	and returns the projected model Tinv A T, Tinv B, C T, D.
+*/
+func balancedProjection(sys StateSpace, Lc *mat.Dense, values []float64, U, V, Lo *mat.Dense, r int) (StateSpace, *mat.Dense, *mat.Dense, error) {
+	// Constants
+	n, _, _ := sys.Dims()
+
+	// Algorithm
+	scale := mat.NewDiagDense(r, nil)
+	for i := 0; i < r; i++ {
+		if values[i] <= 0 {
+			return StateSpace{}, nil, nil, errors.New("Cannot balance a state with a zero Hankel singular value.")
+		}
+		scale.SetDiag(i, 1/math.Sqrt(values[i]))
+	}
+
+	var LcV, T, UtLot, Tinv mat.Dense
+	LcV.Mul(Lc, V.Slice(0, n, 0, r))
+	T.Mul(&LcV, scale)
+	UtLot.Mul(U.Slice(0, n, 0, r).T(), Lo.T())
+	Tinv.Mul(scale, &UtLot)
+
+	var TinvA, A, B, C mat.Dense
+	TinvA.Mul(&Tinv, sys.A)
+	A.Mul(&TinvA, &T)
+	B.Mul(&Tinv, sys.B)
+	C.Mul(sys.C, &T)
+
+	projected, err := GetDiscreteStateSpace(&A, &B, &C, sys.D, sys.Ts)
+	if err != nil {
+		return StateSpace{}, nil, nil, err
+	}
+	projected.InputDelay = append([]float64(nil), sys.InputDelay...)
+
+	return projected, &T, &Tinv, nil
+}
+
+/*
+residualize
+Description:
+
+	Eliminates the states r, ..., n-1 of sys by setting their derivative (or, for discrete-time models, their change
+	x2[k+1] - x2[k]) to zero, which preserves the DC gain. With the partition A = [A11 A12; A21 A22],
+		Ar = A11 + A12 W A21,   Br = B1 + A12 W B2,   Cr = C1 + C2 W A21,   Dr = D + C2 W B2,
+	where W = -A22^{-1} (continuous-time) or W = (I - A22)^{-1} (discrete-time).
+*/
+func residualize(sys StateSpace, r int) (StateSpace, error) {
+	// Constants
+	n, m, p := sys.Dims()
+	if r == n {
+		return sys, nil
+	}
+
+	A11, A12 := sys.A.Slice(0, r, 0, r), sys.A.Slice(0, r, r, n)
+	A21, A22 := sys.A.Slice(r, n, 0, r), sys.A.Slice(r, n, r, n)
+	B1, B2 := sys.B.Slice(0, r, 0, m), sys.B.Slice(r, n, 0, m)
+	C1, C2 := sys.C.Slice(0, p, 0, r), sys.C.Slice(0, p, r, n)
+
+	// Compute W A21 and W B2
+	M := mat.NewDense(n-r, n-r, nil)
+	if sys.IsDiscrete() {
+		M.Sub(eye(n-r), A22)
+	} else {
+		M.Scale(-1, A22)
+	}
+	var WA21, WB2 mat.Dense
+	if err := WA21.Solve(M, A21); err != nil {
+		return StateSpace{}, fmt.Errorf("The discarded states cannot be residualized: %v", err)
+	}
+	if err := WB2.Solve(M, B2); err != nil {
+		return StateSpace{}, fmt.Errorf("The discarded states cannot be residualized: %v", err)
+	}
+
+	// Algorithm
+	var A, B, C, D mat.Dense
+	A.Mul(A12, &WA21)
+	A.Add(A11, &A)
+	B.Mul(A12, &WB2)
+	B.Add(B1, &B)
+	C.Mul(C2, &WA21)
+	C.Add(C1, &C)
+	D.Mul(C2, &WB2)
+	D.Add(sys.D, &D)
+
+	reduced, err := GetDiscreteStateSpace(&A, &B, &C, &D, sys.Ts)
+	if err != nil {
+		return StateSpace{}, err
+	}
+	reduced.InputDelay = append([]float64(nil), sys.InputDelay...)
+
+	return reduced, nil
+}
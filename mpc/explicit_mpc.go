@@ -0,0 +1,728 @@
+/*
+   explicit_mpc.go
+   Description:
+       Explicit model predictive control "like" MPT3's EMPCController: the QP of the controller is solved offline for
+       every state with a multiparametric QP solver, which partitions the state space into critical regions with affine
+       control laws. Online, the control law is evaluated by locating the region that contains the current state.
+*/
+
+package mpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+// explorationStep is the distance (relative to the size of the domain) by which the exploration steps over a facet.
+const explorationStep = 1e-6
+
+// sideTolerance is the tolerance used to decide on which side of a hyperplane a region lies.
+const sideTolerance = 1e-9
+
+type MultiparametricQP struct {
+	H *mat.SymDense // Positive definite Hessian of the objective 1/2 z^T H z + (F x)^T z
+	F *mat.Dense    // Parametric linear term
+	G *mat.Dense    // Constraints G z <= W + E x
+	W *mat.VecDense
+	E *mat.Dense
+}
+
+type CriticalRegion struct {
+	Region    goControl.Polyhedron // The parameters x for which ActiveSet is the optimal active set
+	ActiveSet []int                // Indices of the active constraints
+	Gain      *mat.Dense           // The optimizer is z(x) = Gain x + Offset in the region
+	Offset    *mat.VecDense
+}
+
+type ExplicitController struct {
+	Controller Controller           // The controller whose QP is solved explicitly (in the condensed formulation)
+	Domain     goControl.Polyhedron // The set of states that was explored
+	Regions    []CriticalRegion     // The critical regions; the first input is the first m rows of the optimizer
+	tree       *searchTreeNode
+}
+
+/*
+searchTreeNode
+Description:
+
+	A node of the binary search tree of the partition. Internal nodes test the sign of Normal^T x - Offset
+	(Below holds the regions that intersect Normal^T x <= Offset); leaves hold the candidate regions.
+*/
+type searchTreeNode struct {
+	Normal  *mat.VecDense
+	Offset  float64
+	Below   *searchTreeNode
+	Above   *searchTreeNode
+	Regions []int
+}
+
+/*
+Solve
+Description:
+
+	Solves the multiparametric QP over the parameter set domain with a geometric exploration: starting from the
+	critical region of a feasible parameter, the solver steps over the center of each facet of each region, solves the
+	QP there and adds the critical region of the resulting active set, until no new region is found. The critical
+	region of the active set A is
+		{x : lambda_A(x) >= 0,  G_i z(x) <= W_i + E_i x (i not in A),  x in domain},
+	where z(x) and lambda_A(x) are the affine solutions of the KKT conditions with the constraints in A active.
+*/
+func (mpqp MultiparametricQP) Solve(domain goControl.Polyhedron) ([]CriticalRegion, error) {
+	// Input Processing
+	if err := mpqp.check(); err != nil {
+		return nil, err
+	}
+	if err := domain.Check(); err != nil {
+		return nil, err
+	}
+	n := domain.Dimension()
+	if _, cols := mpqp.F.Dims(); cols != n {
+		return nil, fmt.Errorf("The domain has dimension %v; expected %v.", n, cols)
+	}
+
+	center, radius, err := domain.ChebyshevBall()
+	if err != nil {
+		return nil, err
+	}
+	if math.IsInf(radius, 1) {
+		return nil, errors.New("The domain of the multiparametric QP must be bounded.")
+	}
+	if !(radius > 0) {
+		return nil, errors.New("The domain of the multiparametric QP must be full-dimensional.")
+	}
+	step := explorationStep * math.Max(radius, 1)
+
+	var Hinv mat.Dense
+	if err := Hinv.Inverse(mpqp.H); err != nil {
+		return nil, fmt.Errorf("The Hessian of the multiparametric QP is singular: %v", err)
+	}
+
+	// Find the first region
+	start, err := mpqp.feasibleParameter(domain, center)
+	if err != nil {
+		return nil, err
+	}
+	regions := []CriticalRegion{}
+	discovered := map[string]bool{}
+
+	addRegion := func(x *mat.VecDense) error {
+		solution, err := mpqp.at(x).Solve(QPWarmStart{})
+		if err != nil {
+			return nil
+		}
+		activeSet := append([]int(nil), solution.ActiveSet...)
+		sort.Ints(activeSet)
+		key := fmt.Sprint(activeSet)
+		if discovered[key] {
+			return nil
+		}
+		discovered[key] = true
+
+		region, ok, err := mpqp.criticalRegion(activeSet, &Hinv, domain)
+		if err != nil || !ok {
+			return err
+		}
+		regions = append(regions, region)
+		return nil
+	}
+	if err := addRegion(start); err != nil {
+		return nil, err
+	}
+	if len(regions) == 0 {
+		return nil, errors.New("The critical region of the first feasible parameter is not full-dimensional.")
+	}
+
+	// Explore the neighbors of each region
+	for k := 0; k < len(regions); k++ {
+		facets := regions[k].Region
+		rows, _ := facets.A.Dims()
+		for i := 0; i < rows; i++ {
+			facetCenter, err := facetCenter(facets, i)
+			if err != nil {
+				continue
+			}
+
+			var x mat.VecDense
+			x.AddScaledVec(facetCenter, step, mat.NewVecDense(n, mat.Row(nil, i, facets.A)))
+			if !domain.Contains(&x) || locateSequential(regions, &x) >= 0 {
+				continue
+			}
+			if err := addRegion(&x); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return regions, nil
+}
+
+/*
+check
+Description:
+
+	Verifies the dimensions of the multiparametric QP.
+*/
+func (mpqp MultiparametricQP) check() error {
+	// Input Processing
+	if (mpqp.H == nil) || (mpqp.F == nil) {
+		return errors.New("The Hessian H and the linear term F of the multiparametric QP must be defined.")
+	}
+	nz := mpqp.H.SymmetricDim()
+	if rows, _ := mpqp.F.Dims(); rows != nz {
+		return fmt.Errorf("The linear term F has %v rows; expected %v.", rows, nz)
+	}
+	if mpqp.G == nil {
+		return nil
+	}
+	ni, cols := mpqp.G.Dims()
+	if (cols != nz) || (mpqp.W == nil) || (mpqp.W.Len() != ni) || (mpqp.E == nil) {
+		return errors.New("The constraints G z <= W + E x of the multiparametric QP have incompatible dimensions.")
+	}
+	if rows, cols := mpqp.E.Dims(); (rows != ni) || (cols != mpqp.F.RawMatrix().Cols) {
+		return errors.New("The constraints G z <= W + E x of the multiparametric QP have incompatible dimensions.")
+	}
+	return nil
+}
+
+/*
+at
+Description:
+
+	Evaluates the multiparametric QP at the parameter x.
+*/
+func (mpqp MultiparametricQP) at(x mat.Vector) QuadraticProgram {
+	problem := parametricQP{H: mpqp.H, F: mpqp.F, G: mpqp.G, W: mpqp.W, E: mpqp.E}
+	return problem.at(x)
+}
+
+/*
+feasibleParameter
+Description:
+
+	Returns the point x0 if the QP is feasible there, and otherwise a parameter in the domain for which it is
+	feasible (found with a linear program over the parameters and the decision variables).
+*/
+func (mpqp MultiparametricQP) feasibleParameter(domain goControl.Polyhedron, x0 *mat.VecDense) (*mat.VecDense, error) {
+	// Try the given point
+	if _, err := mpqp.at(x0).Solve(QPWarmStart{}); err == nil {
+		return x0, nil
+	}
+
+	// Constants
+	nz := mpqp.H.SymmetricDim()
+	n := domain.Dimension()
+	ni, _ := mpqp.G.Dims()
+	nd, _ := domain.A.Dims()
+
+	// Find a feasible [x; z]: G z - E x <= W and x in the domain
+	A := mat.NewDense(ni+nd, n+nz, nil)
+	b := mat.NewVecDense(ni+nd, nil)
+	A.Slice(0, ni, 0, n).(*mat.Dense).Scale(-1, mpqp.E)
+	A.Slice(0, ni, n, n+nz).(*mat.Dense).Copy(mpqp.G)
+	A.Slice(ni, ni+nd, 0, n).(*mat.Dense).Copy(domain.A)
+	for i := 0; i < ni; i++ {
+		b.SetVec(i, mpqp.W.AtVec(i))
+	}
+	for i := 0; i < nd; i++ {
+		b.SetVec(ni+i, domain.Get_b().AtVec(i))
+	}
+
+	solution, _, err := goControl.Linprog(mat.NewVecDense(n+nz, nil), A, b, nil, nil)
+	if errors.Is(err, goControl.ErrInfeasible) {
+		return nil, errors.New("The multiparametric QP is infeasible for every parameter in the domain.")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mat.VecDenseCopyOf(solution.SliceVec(0, n)), nil
+}
+
+/*
+criticalRegion
+Description:
+
+	Computes the critical region of the active set A. With M = G_A H^{-1} G_A^T and S = E + G H^{-1} F, the multipliers
+	and the optimizer are
+		lambda_A(x) = -M^{-1} (S_A x + W_A),   z(x) = -H^{-1} (F x + G_A^T lambda_A(x)).
+	Returns false if the region is not full-dimensional.
+*/
+func (mpqp MultiparametricQP) criticalRegion(activeSet []int, Hinv *mat.Dense, domain goControl.Polyhedron) (CriticalRegion, bool, error) {
+	// Constants
+	nz := mpqp.H.SymmetricDim()
+	n := domain.Dimension()
+	ni := 0
+	if mpqp.G != nil {
+		ni, _ = mpqp.G.Dims()
+	}
+	na := len(activeSet)
+
+	// Compute the affine multipliers and optimizer
+	gain := mat.NewDense(nz, n, nil)
+	gain.Mul(Hinv, mpqp.F)
+	gain.Scale(-1, gain)
+	offset := mat.NewVecDense(nz, nil)
+
+	var lambdaGain *mat.Dense
+	var lambdaOffset *mat.VecDense
+	if na > 0 {
+		GA := mat.NewDense(na, nz, nil)
+		EA := mat.NewDense(na, n, nil)
+		WA := mat.NewVecDense(na, nil)
+		for k, i := range activeSet {
+			GA.SetRow(k, mpqp.G.RawRowView(i))
+			EA.SetRow(k, mpqp.E.RawRowView(i))
+			WA.SetVec(k, mpqp.W.AtVec(i))
+		}
+
+		var HinvGAt, M, SA, HinvF mat.Dense
+		HinvGAt.Mul(Hinv, GA.T())
+		M.Mul(GA, &HinvGAt)
+		HinvF.Mul(Hinv, mpqp.F)
+		SA.Mul(GA, &HinvF)
+		SA.Add(&SA, EA)
+
+		lambdaGain, lambdaOffset = mat.NewDense(na, n, nil), mat.NewVecDense(na, nil)
+		if err := lambdaGain.Solve(&M, &SA); err != nil {
+			return CriticalRegion{}, false, nil
+		}
+		if err := lambdaOffset.SolveVec(&M, WA); err != nil {
+			return CriticalRegion{}, false, nil
+		}
+		lambdaGain.Scale(-1, lambdaGain)
+		lambdaOffset.ScaleVec(-1, lambdaOffset)
+
+		var correction mat.Dense
+		correction.Mul(&HinvGAt, lambdaGain)
+		gain.Sub(gain, &correction)
+		offset.MulVec(&HinvGAt, lambdaOffset)
+		offset.ScaleVec(-1, offset)
+	}
+
+	// Build the region: primal feasibility, dual feasibility and the domain
+	var rowsA [][]float64
+	var rowsB []float64
+	for i := 0; i < ni; i++ {
+		if containsIndex(activeSet, i) {
+			continue
+		}
+		Gi := mat.NewVecDense(nz, mpqp.G.RawRowView(i))
+		var GiGain mat.VecDense
+		GiGain.MulVec(gain.T(), Gi)
+		row := make([]float64, n)
+		for j := 0; j < n; j++ {
+			row[j] = GiGain.AtVec(j) - mpqp.E.At(i, j)
+		}
+		rowsA = append(rowsA, row)
+		rowsB = append(rowsB, mpqp.W.AtVec(i)-mat.Dot(Gi, offset))
+	}
+	for k := 0; k < na; k++ {
+		row := make([]float64, n)
+		for j := 0; j < n; j++ {
+			row[j] = -lambdaGain.At(k, j)
+		}
+		rowsA = append(rowsA, row)
+		rowsB = append(rowsB, lambdaOffset.AtVec(k))
+	}
+	nd, _ := domain.A.Dims()
+	for i := 0; i < nd; i++ {
+		rowsA = append(rowsA, mat.Row(nil, i, domain.A))
+		rowsB = append(rowsB, domain.Get_b().AtVec(i))
+	}
+
+	A := mat.NewDense(len(rowsA), n, nil)
+	for i, row := range rowsA {
+		A.SetRow(i, row)
+	}
+	region := goControl.GetPolyhedron(A, mat.NewVecDense(len(rowsB), rowsB))
+
+	// Keep only full-dimensional regions, in their minimal representation
+	full, err := region.IsFullDimensional()
+	if err != nil || !full {
+		return CriticalRegion{}, false, err
+	}
+	region, err = region.MinHRep()
+	if err != nil {
+		return CriticalRegion{}, false, err
+	}
+
+	return CriticalRegion{Region: region, ActiveSet: activeSet, Gain: gain, Offset: offset}, true, nil
+}
+
+/*
+facetCenter
+Description:
+
+	Computes the center of the largest ball inside facet i of the Polyhedron (in minimal representation),
+	i.e. maximizes r subject to a_i^T x = b_i and a_j^T x + r ||a_j|| <= b_j for the other inequalities.
+*/
+func facetCenter(region goControl.Polyhedron, i int) (*mat.VecDense, error) {
+	// Constants
+	rows, n := region.A.Dims()
+
+	// Build the linear program over [x; r]
+	A := mat.NewDense(rows, n+1, nil)
+	b := mat.NewVecDense(rows, nil)
+	for j := 0; j < rows; j++ {
+		row := mat.NewVecDense(n, mat.Row(nil, j, region.A))
+		if j == i {
+			A.Set(j, n, -1)
+			continue
+		}
+		A.Slice(j, j+1, 0, n).(*mat.Dense).Copy(row.T())
+		A.Set(j, n, mat.Norm(row, 2))
+		b.SetVec(j, region.Get_b().AtVec(j))
+	}
+	Aeq := mat.NewDense(1, n+1, nil)
+	Aeq.Slice(0, 1, 0, n).(*mat.Dense).Copy(mat.NewVecDense(n, mat.Row(nil, i, region.A)).T())
+	beq := mat.NewVecDense(1, []float64{region.Get_b().AtVec(i)})
+
+	c := mat.NewVecDense(n+1, nil)
+	c.SetVec(n, -1)
+
+	// Algorithm
+	solution, _, err := goControl.Linprog(c, A, b, Aeq, beq)
+	if err != nil {
+		return nil, err
+	}
+	return mat.VecDenseCopyOf(solution.SliceVec(0, n)), nil
+}
+
+/*
+ToExplicit
+Description:
+
+	Computes the explicit form of the controller over the set of states domain (nil selects the state constraints).
+	The QP of the controller is solved in the condensed formulation for every state in the domain, and the search
+	tree of the partition is built for fast point location.
+*/
+func (c Controller) ToExplicit(domain *goControl.Polyhedron) (ExplicitController, error) {
+	// Input Processing
+	if domain == nil {
+		domain = c.StateConstraints
+	}
+	if domain == nil {
+		return ExplicitController{}, errors.New("The explicit controller requires a bounded domain; give one or add state constraints.")
+	}
+	if err := checkConstraintSet(domain, c.Model.A.RawMatrix().Rows, "domain"); err != nil {
+		return ExplicitController{}, err
+	}
+
+	// Build the condensed QP
+	condensed := c
+	condensed.Options.Formulation = Condensed
+	condensed.problem = condensed.buildProblem()
+	condensed.warmStart = QPWarmStart{}
+
+	mpqp := MultiparametricQP{
+		H: condensed.problem.H,
+		F: condensed.problem.F,
+		G: condensed.problem.G,
+		W: condensed.problem.W,
+		E: condensed.problem.E,
+	}
+
+	// Algorithm
+	regions, err := mpqp.Solve(*domain)
+	if err != nil {
+		return ExplicitController{}, err
+	}
+
+	explicit := ExplicitController{Controller: condensed, Domain: *domain, Regions: regions}
+	if err := explicit.BuildSearchTree(); err != nil {
+		return ExplicitController{}, err
+	}
+	return explicit, nil
+}
+
+/*
+NumRegions
+Description:
+
+	Returns the number of critical regions of the partition.
+*/
+func (ec ExplicitController) NumRegions() int {
+	return len(ec.Regions)
+}
+
+/*
+Evaluate
+Description:
+
+	Returns the first input u_0 of the optimal input sequence at the state x and the index of the region that
+	contains x. The region is located with the search tree if it has been built, and sequentially otherwise.
+	Returns an error if x is not in the partition (the QP is infeasible there, or x is outside the domain).
+*/
+func (ec ExplicitController) Evaluate(x mat.Vector) (*mat.VecDense, int, error) {
+	// Input Processing
+	n, m, _ := ec.Controller.Model.Dims()
+	if (x == nil) || (x.Len() != n) {
+		return nil, -1, fmt.Errorf("The state must have length %v.", n)
+	}
+
+	// Algorithm
+	var index int
+	if ec.tree != nil {
+		index = ec.LocateTree(x)
+	} else {
+		index = ec.LocateSequential(x)
+	}
+	if index < 0 {
+		return nil, -1, errors.New("The state is not in any critical region of the explicit controller.")
+	}
+
+	z := ec.Regions[index].optimizer(x)
+	return mat.VecDenseCopyOf(z.SliceVec(0, m)), index, nil
+}
+
+/*
+LocateSequential
+Description:
+
+	Returns the index of the first critical region that contains x, or -1 if there is none, by checking the
+	regions one at a time.
+*/
+func (ec ExplicitController) LocateSequential(x mat.Vector) int {
+	return locateSequential(ec.Regions, x)
+}
+
+/*
+LocateTree
+Description:
+
+	Returns the index of a critical region that contains x, or -1 if there is none (or if the search tree has not
+	been built), by descending the binary search tree and checking the few candidate regions of the leaf.
+*/
+func (ec ExplicitController) LocateTree(x mat.Vector) int {
+	// Input Processing
+	if ec.tree == nil {
+		return -1
+	}
+
+	// Algorithm
+	node := ec.tree
+	for node.Normal != nil {
+		if mat.Dot(node.Normal, x) <= node.Offset {
+			node = node.Below
+		} else {
+			node = node.Above
+		}
+	}
+	for _, index := range node.Regions {
+		if ec.Regions[index].Region.Contains(x) {
+			return index
+		}
+	}
+	return -1
+}
+
+/*
+BuildSearchTree
+Description:
+
+	Builds the binary search tree of the partition (Tondel, Johansen and Bemporad, 2003). The hyperplanes of the tree
+	are the facets of the regions; each node uses the hyperplane that splits its regions most evenly, where a region
+	that intersects both sides is kept on both sides.
+*/
+func (ec *ExplicitController) BuildSearchTree() error {
+	// Collect the distinct hyperplanes
+	var normals []*mat.VecDense
+	var offsets []float64
+	for _, region := range ec.Regions {
+		rows, n := region.Region.A.Dims()
+		for i := 0; i < rows; i++ {
+			normal := mat.NewVecDense(n, mat.Row(nil, i, region.Region.A))
+			offset := region.Region.Get_b().AtVec(i)
+			scale := mat.Norm(normal, 2)
+			if normal.AtVec(firstNonzero(normal)) < 0 {
+				scale = -scale
+			}
+			normal.ScaleVec(1/scale, normal)
+			offset /= scale
+
+			duplicate := false
+			for k := range normals {
+				if mat.EqualApprox(normals[k], normal, sideTolerance) && (math.Abs(offsets[k]-offset) <= sideTolerance) {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				normals = append(normals, normal)
+				offsets = append(offsets, offset)
+			}
+		}
+	}
+
+	// Find on which side of each hyperplane each region lies (-1 below, +1 above, 0 both)
+	sides := make([][]int, len(normals))
+	for h := range normals {
+		sides[h] = make([]int, len(ec.Regions))
+		var negative mat.VecDense
+		negative.ScaleVec(-1, normals[h])
+		for r, region := range ec.Regions {
+			highest, err := region.Region.Support(normals[h])
+			if err != nil {
+				return err
+			}
+			lowest, err := region.Region.Support(&negative)
+			if err != nil {
+				return err
+			}
+			switch {
+			case highest <= offsets[h]+sideTolerance:
+				sides[h][r] = -1
+			case -lowest >= offsets[h]-sideTolerance:
+				sides[h][r] = 1
+			}
+		}
+	}
+
+	// Build the tree
+	all := make([]int, len(ec.Regions))
+	for r := range all {
+		all[r] = r
+	}
+	ec.tree = buildSearchTreeNode(all, normals, offsets, sides)
+	return nil
+}
+
+/*
+buildSearchTreeNode
+Description:
+
+	Builds the subtree for the given regions by choosing the hyperplane that minimizes the larger of the two
+	resulting sets of regions. Returns a leaf if no hyperplane reduces the number of regions.
+*/
+func buildSearchTreeNode(regions []int, normals []*mat.VecDense, offsets []float64, sides [][]int) *searchTreeNode {
+	// Find the best hyperplane
+	best, bestSize := -1, len(regions)
+	var bestBelow, bestAbove []int
+	if len(regions) > 1 {
+		for h := range normals {
+			var below, above []int
+			for _, r := range regions {
+				if sides[h][r] <= 0 {
+					below = append(below, r)
+				}
+				if sides[h][r] >= 0 {
+					above = append(above, r)
+				}
+			}
+			if (len(below) == 0) || (len(above) == 0) {
+				continue
+			}
+			size := len(below)
+			if len(above) > size {
+				size = len(above)
+			}
+			if size < bestSize {
+				best, bestSize, bestBelow, bestAbove = h, size, below, above
+			}
+		}
+	}
+
+	if best < 0 {
+		return &searchTreeNode{Regions: regions}
+	}
+
+	return &searchTreeNode{
+		Normal: normals[best],
+		Offset: offsets[best],
+		Below:  buildSearchTreeNode(bestBelow, normals, offsets, sides),
+		Above:  buildSearchTreeNode(bestAbove, normals, offsets, sides),
+	}
+}
+
+/*
+Export
+Description:
+
+	Writes the partition as JSON: for each region, the inequalities A x <= b and the control law u_0 = F x + g.
+*/
+func (ec ExplicitController) Export(w io.Writer) error {
+	// Constants
+	n, m, _ := ec.Controller.Model.Dims()
+
+	type exportedRegion struct {
+		A         [][]float64 `json:"A"`
+		B         []float64   `json:"b"`
+		F         [][]float64 `json:"F"`
+		G         []float64   `json:"g"`
+		ActiveSet []int       `json:"activeSet"`
+	}
+	type exportedPartition struct {
+		StateDimension int              `json:"stateDimension"`
+		InputDimension int              `json:"inputDimension"`
+		Regions        []exportedRegion `json:"regions"`
+	}
+
+	// Algorithm
+	partition := exportedPartition{StateDimension: n, InputDimension: m, Regions: []exportedRegion{}}
+	for _, region := range ec.Regions {
+		rows, _ := region.Region.A.Dims()
+		exported := exportedRegion{ActiveSet: region.ActiveSet}
+		for i := 0; i < rows; i++ {
+			exported.A = append(exported.A, mat.Row(nil, i, region.Region.A))
+			exported.B = append(exported.B, region.Region.Get_b().AtVec(i))
+		}
+		for i := 0; i < m; i++ {
+			exported.F = append(exported.F, mat.Row(nil, i, region.Gain))
+			exported.G = append(exported.G, region.Offset.AtVec(i))
+		}
+		partition.Regions = append(partition.Regions, exported)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(partition)
+}
+
+/*
+optimizer
+Description:
+
+	Evaluates the affine optimizer Gain x + Offset of the region.
+*/
+func (region CriticalRegion) optimizer(x mat.Vector) *mat.VecDense {
+	var z mat.VecDense
+	z.MulVec(region.Gain, x)
+	z.AddVec(&z, region.Offset)
+	return &z
+}
+
+/*
+locateSequential
+Description:
+
+	Returns the index of the first region that contains x, or -1 if there is none.
+*/
+func locateSequential(regions []CriticalRegion, x mat.Vector) int {
+	for index, region := range regions {
+		if region.Region.Contains(x) {
+			return index
+		}
+	}
+	return -1
+}
+
+/*
+firstNonzero
+Description:
+
+	Returns the index of the first nonzero entry of v (or 0 if v is zero).
+*/
+func firstNonzero(v mat.Vector) int {
+	for i := 0; i < v.Len(); i++ {
+		if v.AtVec(i) != 0 {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,622 @@
+/*
+   mpc.go
+   Description:
+       Linear model predictive control "like" MPT3's MPCController: at each step, a quadratic program over a finite
+       horizon is built from a discrete-time state space model and Polyhedron constraints and solved with warm starting.
+*/
+
+package mpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+type Formulation int
+
+const (
+	Condensed Formulation = iota // The states are eliminated with the dynamics; the decision variables are the inputs.
+	Sparse                       // The states and inputs are decision variables coupled by equality constraints.
+)
+
+type Options struct {
+	Formulation     Formulation           // Condensed (default) or Sparse
+	TerminalCost    mat.Matrix            // P in the terminal cost x_N^T P x_N; nil selects Q
+	LQRTerminalCost bool                  // Use the solution of the discrete-time algebraic Riccati equation as P
+	TerminalSet     *goControl.Polyhedron // Terminal constraint x_N in TerminalSet; nil means no terminal constraint
+	ColdStart       bool                  // Solve each QP from a phase one problem instead of the previous solution
+	QPOptions       QPOptions             // Options of the active-set solver
+}
+
+type Controller struct {
+	Model            goControl.StateSpace  // Discrete-time model x+ = A x + B u
+	Q                *mat.SymDense         // State weight
+	R                *mat.SymDense         // Input weight
+	P                *mat.SymDense         // Terminal state weight
+	Horizon          int                   // Prediction horizon N
+	StateConstraints *goControl.Polyhedron // Constraint on x_1, ..., x_N (nil means unconstrained)
+	InputConstraints *goControl.Polyhedron // Constraint on u_0, ..., u_{N-1} (nil means unconstrained)
+	Options          Options
+
+	problem   parametricQP
+	warmStart QPWarmStart
+}
+
+type SolverDiagnostics struct {
+	NumVariables   int     // Number of decision variables of the QP
+	NumConstraints int     // Number of inequality constraints of the QP
+	Iterations     int     // Number of active-set iterations
+	ActiveSet      []int   // Indices of the active inequality constraints at the solution
+	WarmStarted    bool    // True if the QP was started from the previous solution
+	PrimalResidual float64 // Largest constraint violation of the solution
+	DualResidual   float64 // Largest entry of the gradient of the Lagrangian at the solution
+}
+
+type Solution struct {
+	U           *mat.Dense // N x m matrix; row k is the predicted input u_k
+	X           *mat.Dense // (N + 1) x n matrix; row k is the predicted state x_k (row 0 is the current state)
+	Cost        float64    // sum_{k<N} (x_k^T Q x_k + u_k^T R u_k) + x_N^T P x_N
+	Diagnostics SolverDiagnostics
+}
+
+/*
+parametricQP
+Description:
+
+	The QP of the controller as a function of the current state x0:
+		minimize 1/2 z^T H z + (F x0)^T z + x0^T Y x0
+		subject to G z <= W + E x0,   Aeq z = Beq x0.
+	The predicted trajectories are x_k = StateMaps[k] z + StateOffsets[k] x0 and u_k = InputMaps[k] z.
+*/
+type parametricQP struct {
+	H            *mat.SymDense
+	F            *mat.Dense
+	Y            *mat.SymDense
+	G            *mat.Dense
+	W            *mat.VecDense
+	E            *mat.Dense
+	Aeq          *mat.Dense
+	Beq          *mat.Dense
+	StateMaps    []*mat.Dense
+	StateOffsets []*mat.Dense
+	InputMaps    []*mat.Dense
+}
+
+/*
+GetController
+Description:
+
+	Creates a model predictive controller for the discrete-time model sys with state weight Q, input weight R,
+	horizon N and the (optional) state and input constraints. The controller minimizes
+		sum_{k=0}^{N-1} (x_k^T Q x_k + u_k^T R u_k) + x_N^T Q x_N
+	subject to x_{k+1} = A x_k + B u_k, x_k in stateConstraints (k = 1, ..., N) and u_k in inputConstraints.
+*/
+func GetController(sys goControl.StateSpace, Q, R mat.Matrix, N int, stateConstraints, inputConstraints *goControl.Polyhedron) (Controller, error) {
+	return GetControllerWithOptions(sys, Q, R, N, stateConstraints, inputConstraints, Options{})
+}
+
+/*
+GetControllerWithOptions
+Description:
+
+	Creates a model predictive controller (see GetController) with a terminal cost, a terminal set, the sparse
+	formulation or different solver options.
+*/
+func GetControllerWithOptions(sys goControl.StateSpace, Q, R mat.Matrix, N int, stateConstraints, inputConstraints *goControl.Polyhedron, options Options) (Controller, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return Controller{}, err
+	}
+	if !sys.IsDiscrete() {
+		return Controller{}, errors.New("Model predictive control requires a discrete-time model; discretize the model with C2d first.")
+	}
+	if sys.HasInputDelay() {
+		return Controller{}, errors.New("Model predictive control does not support models with input delays.")
+	}
+	n, m, _ := sys.Dims()
+
+	if N < 1 {
+		return Controller{}, fmt.Errorf("The horizon must be positive; received %v.", N)
+	}
+	if (options.Formulation != Condensed) && (options.Formulation != Sparse) {
+		return Controller{}, fmt.Errorf("The formulation %v is not recognized.", options.Formulation)
+	}
+
+	QSym, err := checkWeight(Q, n, "state weight Q", false)
+	if err != nil {
+		return Controller{}, err
+	}
+	RSym, err := checkWeight(R, m, "input weight R", true)
+	if err != nil {
+		return Controller{}, err
+	}
+
+	P := QSym
+	if options.LQRTerminalCost {
+		riccati, err := goControl.Dare(sys.A, sys.B, QSym, RSym, nil)
+		if err != nil {
+			return Controller{}, fmt.Errorf("The LQR terminal cost could not be computed: %v", err)
+		}
+		P = riccati.X
+	} else if options.TerminalCost != nil {
+		if P, err = checkWeight(options.TerminalCost, n, "terminal cost P", false); err != nil {
+			return Controller{}, err
+		}
+	}
+
+	for _, constraint := range []struct {
+		set       *goControl.Polyhedron
+		dimension int
+		name      string
+	}{
+		{stateConstraints, n, "state constraints"},
+		{inputConstraints, m, "input constraints"},
+		{options.TerminalSet, n, "terminal set"},
+	} {
+		if err := checkConstraintSet(constraint.set, constraint.dimension, constraint.name); err != nil {
+			return Controller{}, err
+		}
+	}
+
+	// Create Controller
+	controller := Controller{
+		Model:            sys.Copy(),
+		Q:                QSym,
+		R:                RSym,
+		P:                P,
+		Horizon:          N,
+		StateConstraints: stateConstraints,
+		InputConstraints: inputConstraints,
+		Options:          options,
+	}
+	controller.problem = controller.buildProblem()
+
+	return controller, nil
+}
+
+/*
+Solve
+Description:
+
+	Solves the finite horizon optimal control problem from the current state x0 and returns the predicted
+	trajectories. Unless the ColdStart option is set, the QP is warm started with the active set of the previous
+	solution and its shifted input sequence.
+*/
+func (c *Controller) Solve(x0 mat.Vector) (Solution, error) {
+	// Input Processing
+	n, m, _ := c.Model.Dims()
+	if (x0 == nil) || (x0.Len() != n) {
+		return Solution{}, fmt.Errorf("The current state must have length %v.", n)
+	}
+
+	// Algorithm
+	qp := c.problem.at(x0)
+	warmStart := c.warmStart
+	if c.Options.ColdStart {
+		warmStart = QPWarmStart{}
+	}
+
+	qpSolution, err := qp.SolveWithOptions(warmStart, c.Options.QPOptions)
+	if err != nil {
+		return Solution{}, err
+	}
+
+	// Extract the trajectories
+	N := c.Horizon
+	U := mat.NewDense(N, m, nil)
+	X := mat.NewDense(N+1, n, nil)
+	for k := 0; k <= N; k++ {
+		var x, offset mat.VecDense
+		x.MulVec(c.problem.StateMaps[k], qpSolution.Z)
+		offset.MulVec(c.problem.StateOffsets[k], x0)
+		x.AddVec(&x, &offset)
+		X.SetRow(k, x.RawVector().Data)
+		if k < N {
+			var u mat.VecDense
+			u.MulVec(c.problem.InputMaps[k], qpSolution.Z)
+			U.SetRow(k, u.RawVector().Data)
+		}
+	}
+
+	var Yx0 mat.VecDense
+	Yx0.MulVec(c.problem.Y, x0)
+	cost := qpSolution.Objective + mat.Dot(x0, &Yx0)
+
+	c.warmStart = c.shiftedWarmStart(qpSolution, U, X)
+
+	nz, ni, _ := qp.Dims()
+	return Solution{
+		U:    U,
+		X:    X,
+		Cost: cost,
+		Diagnostics: SolverDiagnostics{
+			NumVariables:   nz,
+			NumConstraints: ni,
+			Iterations:     qpSolution.Iterations,
+			ActiveSet:      qpSolution.ActiveSet,
+			WarmStarted:    qpSolution.WarmStarted,
+			PrimalResidual: qpSolution.PrimalResidual,
+			DualResidual:   qpSolution.DualResidual,
+		},
+	}, nil
+}
+
+/*
+Control
+Description:
+
+	Returns the first input u_0 of the optimal input sequence from the current state x0 (the receding horizon law).
+*/
+func (c *Controller) Control(x0 mat.Vector) (*mat.VecDense, error) {
+	// Algorithm
+	solution, err := c.Solve(x0)
+	if err != nil {
+		return nil, err
+	}
+	return mat.VecDenseCopyOf(solution.U.RowView(0)), nil
+}
+
+/*
+Reset
+Description:
+
+	Discards the warm start, so that the next QP is solved from a phase one problem.
+*/
+func (c *Controller) Reset() {
+	c.warmStart = QPWarmStart{}
+}
+
+/*
+shiftedWarmStart
+Description:
+
+	Builds the warm start of the next QP: the same active set, and the input sequence shifted by one step
+	(u_1, ..., u_{N-1}, u_{N-1}). In the sparse formulation, the states are shifted as well, with the last state
+	predicted from the model.
+*/
+func (c *Controller) shiftedWarmStart(solution QPSolution, U, X *mat.Dense) QPWarmStart {
+	// Constants
+	N := c.Horizon
+	n, m, _ := c.Model.Dims()
+
+	// Algorithm
+	shiftedU := mat.NewDense(N, m, nil)
+	for k := 0; k < N; k++ {
+		shiftedU.SetRow(k, U.RawRowView(minInt(k+1, N-1)))
+	}
+
+	z := mat.NewVecDense(N*m, shiftedU.RawMatrix().Data)
+	if c.Options.Formulation == Sparse {
+		z = mat.NewVecDense(N*(m+n), nil)
+		for k := 0; k < N*m; k++ {
+			z.SetVec(k, shiftedU.RawMatrix().Data[k])
+		}
+		for k := 1; k <= N; k++ {
+			var next mat.VecDense
+			if k < N {
+				next.CloneFromVec(X.RowView(k + 1))
+			} else {
+				var Bu mat.VecDense
+				next.MulVec(c.Model.A, X.RowView(N))
+				Bu.MulVec(c.Model.B, shiftedU.RowView(N-1))
+				next.AddVec(&next, &Bu)
+			}
+			for i := 0; i < n; i++ {
+				z.SetVec(N*m+(k-1)*n+i, next.AtVec(i))
+			}
+		}
+	}
+
+	return QPWarmStart{Z: z, ActiveSet: append([]int(nil), solution.ActiveSet...)}
+}
+
+/*
+buildProblem
+Description:
+
+	Builds the parametric QP of the controller. In the condensed formulation the decision variables are
+	z = [u_0; ...; u_{N-1}] and x_k = A^k x0 + sum_{j<k} A^{k-1-j} B u_j. In the sparse formulation the decision
+	variables are z = [u_0; ...; u_{N-1}; x_1; ...; x_N] and the dynamics are equality constraints.
+*/
+func (c *Controller) buildProblem() parametricQP {
+	// Constants
+	N := c.Horizon
+	n, m, _ := c.Model.Dims()
+	A, B := c.Model.A, c.Model.B
+
+	nz := N * m
+	if c.Options.Formulation == Sparse {
+		nz = N * (m + n)
+	}
+
+	// Describe the predicted trajectories
+	problem := parametricQP{}
+	for k := 0; k < N; k++ {
+		inputMap := mat.NewDense(m, nz, nil)
+		inputMap.Slice(0, m, k*m, (k+1)*m).(*mat.Dense).Copy(eye(m))
+		problem.InputMaps = append(problem.InputMaps, inputMap)
+	}
+
+	problem.StateMaps = []*mat.Dense{mat.NewDense(n, nz, nil)}
+	problem.StateOffsets = []*mat.Dense{eye(n)}
+	for k := 1; k <= N; k++ {
+		stateMap, offset := mat.NewDense(n, nz, nil), mat.NewDense(n, n, nil)
+		if c.Options.Formulation == Sparse {
+			stateMap.Slice(0, n, N*m+(k-1)*n, N*m+k*n).(*mat.Dense).Copy(eye(n))
+		} else {
+			var Ax, Bu mat.Dense
+			Ax.Mul(A, problem.StateMaps[k-1])
+			Bu.Mul(B, problem.InputMaps[k-1])
+			stateMap.Add(&Ax, &Bu)
+			offset.Mul(A, problem.StateOffsets[k-1])
+		}
+		problem.StateMaps = append(problem.StateMaps, stateMap)
+		problem.StateOffsets = append(problem.StateOffsets, offset)
+	}
+
+	// Cost
+	H := mat.NewDense(nz, nz, nil)
+	F := mat.NewDense(nz, n, nil)
+	Y := mat.NewDense(n, n, nil)
+	for k := 0; k <= N; k++ {
+		weight := c.Q
+		if k == N {
+			weight = c.P
+		}
+		addQuadraticTerm(H, F, Y, problem.StateMaps[k], problem.StateOffsets[k], weight)
+		if k < N {
+			addQuadraticTerm(H, F, Y, problem.InputMaps[k], nil, c.R)
+		}
+	}
+	problem.H = symmetricCopy(H)
+	problem.F = F
+	problem.Y = symmetricCopy(Y)
+
+	// Inequality constraints
+	var GRows, ERows []*mat.Dense
+	var WValues []float64
+	addConstraint := func(set *goControl.Polyhedron, zMap, offset *mat.Dense) {
+		var G, E mat.Dense
+		G.Mul(set.A, zMap)
+		GRows = append(GRows, &G)
+		rows, _ := set.A.Dims()
+		if offset != nil {
+			E.Mul(set.A, offset)
+			E.Scale(-1, &E)
+		} else {
+			E.ReuseAs(rows, n)
+			E.Zero()
+		}
+		ERows = append(ERows, &E)
+		for i := 0; i < rows; i++ {
+			WValues = append(WValues, set.Get_b().AtVec(i))
+		}
+	}
+	for k := 0; k < N; k++ {
+		if c.InputConstraints != nil {
+			addConstraint(c.InputConstraints, problem.InputMaps[k], nil)
+		}
+	}
+	for k := 1; k <= N; k++ {
+		if c.StateConstraints != nil {
+			addConstraint(c.StateConstraints, problem.StateMaps[k], problem.StateOffsets[k])
+		}
+	}
+	if c.Options.TerminalSet != nil {
+		addConstraint(c.Options.TerminalSet, problem.StateMaps[N], problem.StateOffsets[N])
+	}
+	if len(WValues) > 0 {
+		problem.G = stackRows(GRows)
+		problem.E = stackRows(ERows)
+		problem.W = mat.NewVecDense(len(WValues), WValues)
+	}
+
+	// Equality constraints (sparse formulation): x_{k+1} - A x_k - B u_k = 0, with x_0 given
+	if c.Options.Formulation == Sparse {
+		problem.Aeq = mat.NewDense(N*n, nz, nil)
+		problem.Beq = mat.NewDense(N*n, n, nil)
+		for k := 0; k < N; k++ {
+			block := problem.Aeq.Slice(k*n, (k+1)*n, 0, nz).(*mat.Dense)
+			block.Copy(problem.StateMaps[k+1])
+			var Bu mat.Dense
+			Bu.Mul(B, problem.InputMaps[k])
+			block.Sub(block, &Bu)
+			if k == 0 {
+				problem.Beq.Slice(0, n, 0, n).(*mat.Dense).Copy(A)
+			} else {
+				var Ax mat.Dense
+				Ax.Mul(A, problem.StateMaps[k])
+				block.Sub(block, &Ax)
+			}
+		}
+	}
+
+	return problem
+}
+
+/*
+at
+Description:
+
+	Evaluates the parametric QP at the state x0.
+*/
+func (problem parametricQP) at(x0 mat.Vector) QuadraticProgram {
+	// Constants
+	nz := problem.H.SymmetricDim()
+
+	// Algorithm
+	qp := QuadraticProgram{H: problem.H, F: mat.NewVecDense(nz, nil)}
+	qp.F.MulVec(problem.F, x0)
+
+	if problem.G != nil {
+		qp.G = problem.G
+		qp.W = mat.NewVecDense(problem.W.Len(), nil)
+		qp.W.MulVec(problem.E, x0)
+		qp.W.AddVec(qp.W, problem.W)
+	}
+	if problem.Aeq != nil {
+		qp.Aeq = problem.Aeq
+		qp.Beq = mat.NewVecDense(problem.Aeq.RawMatrix().Rows, nil)
+		qp.Beq.MulVec(problem.Beq, x0)
+	}
+	return qp
+}
+
+/*
+addQuadraticTerm
+Description:
+
+	Adds the cost v^T weight v of v = zMap z + offset x0 to the parametric cost 1/2 z^T H z + x0^T F^T z + x0^T Y x0,
+	i.e. H += 2 zMap^T weight zMap, F += 2 zMap^T weight offset and Y += offset^T weight offset. A nil offset is zero.
+*/
+func addQuadraticTerm(H, F, Y *mat.Dense, zMap, offset *mat.Dense, weight mat.Matrix) {
+	// Algorithm
+	var weightedMap, term mat.Dense
+	weightedMap.Mul(weight, zMap)
+
+	term.Mul(zMap.T(), &weightedMap)
+	term.Scale(2, &term)
+	H.Add(H, &term)
+
+	if offset == nil {
+		return
+	}
+
+	var crossTerm, weightedOffset, offsetTerm mat.Dense
+	crossTerm.Mul(weightedMap.T(), offset)
+	crossTerm.Scale(2, &crossTerm)
+	F.Add(F, &crossTerm)
+
+	weightedOffset.Mul(weight, offset)
+	offsetTerm.Mul(offset.T(), &weightedOffset)
+	Y.Add(Y, &offsetTerm)
+}
+
+/*
+checkWeight
+Description:
+
+	Verifies that the weight W is a symmetric n x n matrix that is positive semidefinite (or positive definite if
+	definite is true), and returns it as a SymDense.
+*/
+func checkWeight(W mat.Matrix, n int, name string, definite bool) (*mat.SymDense, error) {
+	// Input Processing
+	if W == nil {
+		return nil, fmt.Errorf("The %v is not defined.", name)
+	}
+	if rows, cols := W.Dims(); (rows != n) || (cols != n) {
+		return nil, fmt.Errorf("The %v has dimensions %v x %v; expected %v x %v.", name, rows, cols, n, n)
+	}
+	if !mat.Equal(W, W.T()) && !mat.EqualApprox(W, W.T(), 1e-10*mat.Norm(W, 1)) {
+		return nil, fmt.Errorf("The %v is not symmetric.", name)
+	}
+
+	// Algorithm
+	symmetric := symmetricCopy(W)
+	var eig mat.EigenSym
+	if ok := eig.Factorize(symmetric, false); !ok {
+		return nil, fmt.Errorf("The eigenvalues of the %v could not be computed.", name)
+	}
+	smallest := eig.Values(nil)[0]
+	if definite && !(smallest > 0) {
+		return nil, fmt.Errorf("The %v must be positive definite; its smallest eigenvalue is %v.", name, smallest)
+	}
+	if smallest < -1e-10*mat.Norm(W, 1) {
+		return nil, fmt.Errorf("The %v must be positive semidefinite; its smallest eigenvalue is %v.", name, smallest)
+	}
+
+	return symmetric, nil
+}
+
+/*
+checkConstraintSet
+Description:
+
+	Verifies that the (optional) constraint set is a valid Polyhedron of the given dimension.
+*/
+func checkConstraintSet(set *goControl.Polyhedron, dimension int, name string) error {
+	// Input Processing
+	if set == nil {
+		return nil
+	}
+	if err := set.Check(); err != nil {
+		return fmt.Errorf("The %v are not a valid Polyhedron: %v", name, err)
+	}
+	if set.Dimension() != dimension {
+		return fmt.Errorf("The %v have dimension %v; expected %v.", name, set.Dimension(), dimension)
+	}
+	return nil
+}
+
+/*
+symmetricCopy
+Description:
+
+	Returns the symmetric part (M + M^T) / 2 of the square matrix M.
+*/
+func symmetricCopy(M mat.Matrix) *mat.SymDense {
+	n, _ := M.Dims()
+	symmetric := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			symmetric.SetSym(i, j, 0.5*(M.At(i, j)+M.At(j, i)))
+		}
+	}
+	return symmetric
+}
+
+/*
+stackRows
+Description:
+
+	Stacks the matrices (which have the same number of columns) vertically.
+*/
+func stackRows(blocks []*mat.Dense) *mat.Dense {
+	// Constants
+	_, cols := blocks[0].Dims()
+	rows := 0
+	for _, block := range blocks {
+		r, _ := block.Dims()
+		rows += r
+	}
+
+	// Algorithm
+	stacked := mat.NewDense(rows, cols, nil)
+	offset := 0
+	for _, block := range blocks {
+		r, _ := block.Dims()
+		stacked.Slice(offset, offset+r, 0, cols).(*mat.Dense).Copy(block)
+		offset += r
+	}
+	return stacked
+}
+
+/*
+eye
+Description:
+
+	Returns the n x n identity matrix.
+*/
+func eye(n int) *mat.Dense {
+	identity := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		identity.Set(i, i, 1)
+	}
+	return identity
+}
+
+/*
+minInt
+Description:
+
+	Returns the smaller of two integers.
+*/
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,504 @@
+/*
+   quadratic_program.go
+   Description:
+       A dense primal active-set solver for the convex quadratic programs that appear in model predictive control.
+*/
+
+package mpc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// defaultQPTolerance is the default feasibility and optimality tolerance of the active-set solver.
+const defaultQPTolerance = 1e-9
+
+type QuadraticProgram struct {
+	H   *mat.SymDense // Hessian of the objective 1/2 z^T H z + F^T z (positive definite on the null space of Aeq)
+	F   *mat.VecDense // Linear term of the objective
+	G   *mat.Dense    // Inequality constraints G z <= W (nil if there are none)
+	W   *mat.VecDense // Right hand side of the inequality constraints
+	Aeq *mat.Dense    // Equality constraints Aeq z = Beq (nil if there are none)
+	Beq *mat.VecDense // Right hand side of the equality constraints
+}
+
+type QPOptions struct {
+	MaxIterations int     // Maximum number of active-set iterations (0 selects 10 (nz + ni) + 100)
+	Tolerance     float64 // Feasibility and optimality tolerance (0 selects 1e-9)
+}
+
+type QPWarmStart struct {
+	Z         mat.Vector // Guess of the minimizer; used as the starting point if it is feasible.
+	ActiveSet []int      // Guess of the active inequality constraints at the minimizer.
+}
+
+type QPSolution struct {
+	Z              *mat.VecDense // Minimizer
+	Lambda         *mat.VecDense // Multipliers of the inequality constraints (zero for the inactive constraints)
+	Nu             *mat.VecDense // Multipliers of the equality constraints
+	Objective      float64       // Optimal value of 1/2 z^T H z + F^T z
+	ActiveSet      []int         // Indices of the inequality constraints in the final working set
+	Iterations     int           // Number of active-set iterations
+	WarmStarted    bool          // True if the solver started from the warm start instead of a phase one problem
+	PrimalResidual float64       // Largest violation of the constraints at Z
+	DualResidual   float64       // Largest entry of H Z + F + G^T Lambda + Aeq^T Nu
+}
+
+/*
+Dims
+Description:
+
+	Returns the number of variables nz, of inequality constraints ni and of equality constraints ne of the QP.
+*/
+func (qp QuadraticProgram) Dims() (nz, ni, ne int) {
+	nz = qp.H.SymmetricDim()
+	if qp.G != nil {
+		ni, _ = qp.G.Dims()
+	}
+	if qp.Aeq != nil {
+		ne, _ = qp.Aeq.Dims()
+	}
+	return nz, ni, ne
+}
+
+/*
+Check
+Description:
+
+	Verifies that the dimensions of the matrices and vectors of the QP are compatible.
+*/
+func (qp QuadraticProgram) Check() error {
+	// Input Processing
+	if qp.H == nil {
+		return errors.New("The Hessian H of the quadratic program is not defined.")
+	}
+	nz, ni, ne := qp.Dims()
+	if (qp.F == nil) || (qp.F.Len() != nz) {
+		return fmt.Errorf("The linear term F of the quadratic program must have length %v.", nz)
+	}
+	if qp.G != nil {
+		if _, cols := qp.G.Dims(); (cols != nz) || (qp.W == nil) || (qp.W.Len() != ni) {
+			return fmt.Errorf("The inequality constraints must be a %v x %v matrix G and a vector W of length %v.", ni, nz, ni)
+		}
+	}
+	if qp.Aeq != nil {
+		if _, cols := qp.Aeq.Dims(); (cols != nz) || (qp.Beq == nil) || (qp.Beq.Len() != ne) {
+			return fmt.Errorf("The equality constraints must be a %v x %v matrix Aeq and a vector Beq of length %v.", ne, nz, ne)
+		}
+	}
+	return nil
+}
+
+/*
+Solve
+Description:
+
+	Solves the quadratic program with the default options (see SolveWithOptions).
+*/
+func (qp QuadraticProgram) Solve(warmStart QPWarmStart) (QPSolution, error) {
+	return qp.SolveWithOptions(warmStart, QPOptions{})
+}
+
+/*
+SolveWithOptions
+Description:
+
+	Solves the quadratic program
+		minimize 1/2 z^T H z + F^T z   subject to   G z <= W,   Aeq z = Beq
+	with a primal active-set method. Each iteration solves the KKT system of the equality constrained problem
+	defined by the working set, and either adds the first blocking constraint or removes the constraint with the
+	most negative multiplier. The starting point is (in order of preference) the solution of the equality constrained
+	problem defined by warmStart.ActiveSet, the point warmStart.Z, or a feasible point found by a phase one linear
+	program. Warm starting with the solution of a nearby problem usually converges in a few iterations.
+*/
+func (qp QuadraticProgram) SolveWithOptions(warmStart QPWarmStart, options QPOptions) (QPSolution, error) {
+	// Input Processing
+	if err := qp.Check(); err != nil {
+		return QPSolution{}, err
+	}
+	nz, ni, _ := qp.Dims()
+
+	tol := options.Tolerance
+	if !(tol > 0) {
+		tol = defaultQPTolerance
+	}
+	maxIterations := options.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = 10*(nz+ni) + 100
+	}
+
+	// Find a feasible starting point
+	z, working, warmStarted, err := qp.startingPoint(warmStart, tol)
+	if err != nil {
+		return QPSolution{}, err
+	}
+
+	// Algorithm
+	for iteration := 1; iteration <= maxIterations; iteration++ {
+		p, lambda, nu, err := qp.equalityStep(z, working)
+		if err != nil {
+			return QPSolution{}, err
+		}
+
+		if mat.Norm(p, math.Inf(1)) <= tol*(1+mat.Norm(z, math.Inf(1))) {
+			// Remove the constraint with the most negative multiplier, or stop
+			gradient := qp.gradient(z)
+			threshold := -tol * (1 + mat.Norm(gradient, math.Inf(1)))
+			removed := -1
+			for k := range working {
+				if lambda[k] < threshold {
+					threshold, removed = lambda[k], k
+				}
+			}
+			if removed < 0 {
+				return qp.solution(z, working, lambda, nu, iteration, warmStarted), nil
+			}
+			working = append(working[:removed], working[removed+1:]...)
+			continue
+		}
+
+		// Take the longest step along p that keeps z feasible
+		alpha, blocking := 1.0, -1
+		pNorm := mat.Norm(p, 2)
+		for i := 0; i < ni; i++ {
+			if containsIndex(working, i) {
+				continue
+			}
+			row := qp.G.RawRowView(i)
+			Gp := mat.Dot(mat.NewVecDense(nz, row), p)
+			if Gp <= 1e-12*pNorm*floats.Norm(row, 2) {
+				continue
+			}
+			slack := math.Max(qp.W.AtVec(i)-mat.Dot(mat.NewVecDense(nz, row), z), 0)
+			if step := slack / Gp; step < alpha {
+				alpha, blocking = step, i
+			}
+		}
+
+		z.AddScaledVec(z, alpha, p)
+		if blocking >= 0 {
+			working = append(working, blocking)
+		}
+	}
+
+	return QPSolution{}, fmt.Errorf("The active-set method did not converge in %v iterations.", maxIterations)
+}
+
+/*
+startingPoint
+Description:
+
+	Finds a feasible starting point and working set for the active-set method from the warm start, or with a
+	phase one linear program if the warm start is not feasible.
+*/
+func (qp QuadraticProgram) startingPoint(warmStart QPWarmStart, tol float64) (*mat.VecDense, []int, bool, error) {
+	// Constants
+	nz, ni, _ := qp.Dims()
+
+	// Try the equality constrained problem defined by the guessed active set
+	if len(warmStart.ActiveSet) > 0 {
+		working := []int{}
+		for _, i := range warmStart.ActiveSet {
+			if (i >= 0) && (i < ni) && !containsIndex(working, i) {
+				working = append(working, i)
+			}
+		}
+		z, err := qp.equalityConstrainedMinimizer(working)
+		if (err == nil) && (qp.primalResidual(z) <= tol*(1+mat.Norm(z, math.Inf(1)))) {
+			return z, working, true, nil
+		}
+	}
+
+	// Try the guessed point
+	if (warmStart.Z != nil) && (warmStart.Z.Len() == nz) {
+		z := mat.VecDenseCopyOf(warmStart.Z)
+		if qp.primalResidual(z) <= tol*(1+mat.Norm(z, math.Inf(1))) {
+			return z, []int{}, true, nil
+		}
+	}
+
+	// Without inequality constraints, the equality constrained minimizer is the solution
+	if ni == 0 {
+		z, err := qp.equalityConstrainedMinimizer([]int{})
+		return z, []int{}, false, err
+	}
+
+	z, err := qp.phaseOne()
+	return z, []int{}, false, err
+}
+
+/*
+phaseOne
+Description:
+
+	Finds a feasible point of the constraints G z <= W, Aeq z = Beq with a linear program with zero cost.
+*/
+func (qp QuadraticProgram) phaseOne() (*mat.VecDense, error) {
+	// Constants
+	nz, _, _ := qp.Dims()
+
+	// Algorithm
+	var G, Aeq mat.Matrix
+	var W, Beq mat.Vector
+	if qp.G != nil {
+		G, W = qp.G, qp.W
+	}
+	if qp.Aeq != nil {
+		Aeq, Beq = qp.Aeq, qp.Beq
+	}
+
+	z, _, err := goControl.Linprog(mat.NewVecDense(nz, nil), G, W, Aeq, Beq)
+	if errors.Is(err, goControl.ErrInfeasible) {
+		return nil, errors.New("The quadratic program is infeasible.")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("The phase one problem of the quadratic program failed: %v", err)
+	}
+	return z, nil
+}
+
+/*
+equalityStep
+Description:
+
+	Solves the KKT system of the problem minimize 1/2 p^T H p + g^T p subject to a_i^T p = 0 for the equality
+	constraints and the inequality constraints in the working set, where g = H z + F. Returns the step p and the
+	multipliers of the working set and of the equality constraints.
+*/
+func (qp QuadraticProgram) equalityStep(z *mat.VecDense, working []int) (*mat.VecDense, []float64, []float64, error) {
+	// Constants
+	nz, _, ne := qp.Dims()
+	gradient := qp.gradient(z)
+
+	// Algorithm
+	rhs := mat.NewVecDense(nz+ne+len(working), nil)
+	for j := 0; j < nz; j++ {
+		rhs.SetVec(j, -gradient.AtVec(j))
+	}
+	solution, err := qp.solveKKT(working, rhs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	p := mat.VecDenseCopyOf(solution.SliceVec(0, nz))
+	nu := make([]float64, ne)
+	for i := range nu {
+		nu[i] = solution.AtVec(nz + i)
+	}
+	lambda := make([]float64, len(working))
+	for k := range lambda {
+		lambda[k] = solution.AtVec(nz + ne + k)
+	}
+	return p, lambda, nu, nil
+}
+
+/*
+equalityConstrainedMinimizer
+Description:
+
+	Solves minimize 1/2 z^T H z + F^T z subject to the equality constraints and the inequality constraints in
+	the working set (as equalities).
+*/
+func (qp QuadraticProgram) equalityConstrainedMinimizer(working []int) (*mat.VecDense, error) {
+	// Constants
+	nz, _, ne := qp.Dims()
+
+	// Algorithm
+	rhs := mat.NewVecDense(nz+ne+len(working), nil)
+	for j := 0; j < nz; j++ {
+		rhs.SetVec(j, -qp.F.AtVec(j))
+	}
+	for i := 0; i < ne; i++ {
+		rhs.SetVec(nz+i, qp.Beq.AtVec(i))
+	}
+	for k, i := range working {
+		rhs.SetVec(nz+ne+k, qp.W.AtVec(i))
+	}
+
+	solution, err := qp.solveKKT(working, rhs)
+	if err != nil {
+		return nil, err
+	}
+	return mat.VecDenseCopyOf(solution.SliceVec(0, nz)), nil
+}
+
+/*
+solveKKT
+Description:
+
+	Solves the KKT system [H A^T; A 0] x = rhs, where the rows of A are the equality constraints followed by the
+	inequality constraints in the working set.
+*/
+func (qp QuadraticProgram) solveKKT(working []int, rhs *mat.VecDense) (*mat.VecDense, error) {
+	// Constants
+	nz, _, ne := qp.Dims()
+	size := nz + ne + len(working)
+
+	// Build the KKT matrix
+	K := mat.NewDense(size, size, nil)
+	for i := 0; i < nz; i++ {
+		for j := 0; j < nz; j++ {
+			K.Set(i, j, qp.H.At(i, j))
+		}
+	}
+	for k := 0; k < ne+len(working); k++ {
+		var row []float64
+		if k < ne {
+			row = qp.constraintRow(qp.numInequalities() + k)
+		} else {
+			row = qp.constraintRow(working[k-ne])
+		}
+		for j := 0; j < nz; j++ {
+			K.Set(nz+k, j, row[j])
+			K.Set(j, nz+k, row[j])
+		}
+	}
+
+	// Algorithm
+	var solution mat.VecDense
+	if err := solution.SolveVec(K, rhs); err != nil {
+		var condition mat.Condition
+		if !errors.As(err, &condition) || math.IsInf(float64(condition), 1) || (float64(condition) > 1e14) {
+			return nil, errors.New("The KKT system of the working set is singular; the constraints may be linearly dependent.")
+		}
+	}
+	return &solution, nil
+}
+
+/*
+solution
+Description:
+
+	Assembles the QPSolution at the minimizer z with the given working set and multipliers.
+*/
+func (qp QuadraticProgram) solution(z *mat.VecDense, working []int, workingLambda, nu []float64, iterations int, warmStarted bool) QPSolution {
+	// Constants
+	nz, ni, ne := qp.Dims()
+
+	// Algorithm
+	lambda := &mat.VecDense{}
+	if ni > 0 {
+		lambda = mat.NewVecDense(ni, nil)
+	}
+	for k, i := range working {
+		lambda.SetVec(i, math.Max(workingLambda[k], 0))
+	}
+
+	var Hz mat.VecDense
+	Hz.MulVec(qp.H, z)
+	objective := 0.5*mat.Dot(z, &Hz) + mat.Dot(qp.F, z)
+
+	// Residuals
+	stationarity := qp.gradient(z)
+	for i := 0; i < ni; i++ {
+		stationarity.AddScaledVec(stationarity, lambda.AtVec(i), mat.NewVecDense(nz, qp.G.RawRowView(i)))
+	}
+	for i := 0; i < ne; i++ {
+		stationarity.AddScaledVec(stationarity, nu[i], mat.NewVecDense(nz, qp.Aeq.RawRowView(i)))
+	}
+
+	activeSet := append([]int(nil), working...)
+	nuVec := &mat.VecDense{}
+	if ne > 0 {
+		nuVec = mat.NewVecDense(ne, nu)
+	}
+
+	return QPSolution{
+		Z:              z,
+		Lambda:         lambda,
+		Nu:             nuVec,
+		Objective:      objective,
+		ActiveSet:      activeSet,
+		Iterations:     iterations,
+		WarmStarted:    warmStarted,
+		PrimalResidual: qp.primalResidual(z),
+		DualResidual:   mat.Norm(stationarity, math.Inf(1)),
+	}
+}
+
+/*
+gradient
+Description:
+
+	Returns the gradient H z + F of the objective.
+*/
+func (qp QuadraticProgram) gradient(z mat.Vector) *mat.VecDense {
+	gradient := mat.NewVecDense(qp.F.Len(), nil)
+	gradient.MulVec(qp.H, z)
+	gradient.AddVec(gradient, qp.F)
+	return gradient
+}
+
+/*
+primalResidual
+Description:
+
+	Returns the largest violation of the inequality and equality constraints at z.
+*/
+func (qp QuadraticProgram) primalResidual(z mat.Vector) float64 {
+	// Constants
+	_, ni, ne := qp.Dims()
+
+	// Algorithm
+	residual := 0.0
+	if ni > 0 {
+		var Gz mat.VecDense
+		Gz.MulVec(qp.G, z)
+		for i := 0; i < ni; i++ {
+			residual = math.Max(residual, Gz.AtVec(i)-qp.W.AtVec(i))
+		}
+	}
+	if ne > 0 {
+		var Az mat.VecDense
+		Az.MulVec(qp.Aeq, z)
+		for i := 0; i < ne; i++ {
+			residual = math.Max(residual, math.Abs(Az.AtVec(i)-qp.Beq.AtVec(i)))
+		}
+	}
+	return residual
+}
+
+/*
+numInequalities
+Description:
+
+	Returns the number of inequality constraints.
+*/
+func (qp QuadraticProgram) numInequalities() int {
+	_, ni, _ := qp.Dims()
+	return ni
+}
+
+/*
+constraintRow
+Description:
+
+	Returns row i of the stacked constraint matrix [G; Aeq].
+*/
+func (qp QuadraticProgram) constraintRow(i int) []float64 {
+	if ni := qp.numInequalities(); i >= ni {
+		return qp.Aeq.RawRowView(i - ni)
+	}
+	return qp.G.RawRowView(i)
+}
+
+/*
+containsIndex
+Description:
+
+	Returns true if the slice contains the index i.
+*/
+func containsIndex(indices []int, i int) bool {
+	for _, index := range indices {
+		if index == i {
+			return true
+		}
+	}
+	return false
+}
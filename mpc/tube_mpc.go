@@ -0,0 +1,265 @@
+/*
+   tube_mpc.go
+   Description:
+       Tube-based robust model predictive control of linear systems with bounded additive disturbances
+           x+ = A x + B u + w,   w in W.
+*/
+
+package mpc
+
+import (
+	"fmt"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+// defaultTubeEpsilon is the default accuracy of the minimal RPI approximation used as the tube.
+const defaultTubeEpsilon = 1e-2
+
+type TubeOptions struct {
+	Gain       mat.Matrix            // Ancillary gain K of u = v - K (x - z); nil selects the LQR gain of Q and R
+	Tube       *goControl.Polyhedron // Robust positively invariant set of e+ = (A - B K) e + w; nil selects MinimalRPI
+	Epsilon    float64               // Accuracy of the MinimalRPI approximation (0 selects 1e-2)
+	Controller Options               // Options of the nominal controller (its TerminalSet constrains the nominal state)
+}
+
+type TubeController struct {
+	Nominal          Controller            // Controller of the nominal system z+ = A z + B v with the tightened constraints
+	Gain             *mat.Dense            // Ancillary gain K
+	Disturbance      goControl.Polyhedron  // Disturbance set W
+	Tube             goControl.Polyhedron  // Robust positively invariant set Z of the error e = x - z
+	StateConstraints *goControl.Polyhedron // Original constraint on the state (nil means unconstrained)
+	InputConstraints *goControl.Polyhedron // Original constraint on the input (nil means unconstrained)
+
+	nominalState *mat.VecDense
+}
+
+type TubeSolution struct {
+	U            *mat.VecDense // Applied input u = v_0 - K (x - z_0)
+	NominalState *mat.VecDense // Nominal state z_0 at the current step
+	Nominal      Solution      // Solution of the nominal problem from z_0
+}
+
+/*
+GetTubeController
+Description:
+
+	Creates a tube MPC controller for the discrete-time model sys with the disturbance x+ = A x + B u + w, w in W
+	(a Polyhedron containing the origin in its interior), state weight Q, input weight R, horizon N and the
+	(optional) state and input constraints.
+*/
+func GetTubeController(sys goControl.StateSpace, Q, R mat.Matrix, N int, stateConstraints, inputConstraints *goControl.Polyhedron, W goControl.Polyhedron) (TubeController, error) {
+	return GetTubeControllerWithOptions(sys, Q, R, N, stateConstraints, inputConstraints, W, TubeOptions{})
+}
+
+/*
+GetTubeControllerWithOptions
+Description:
+
+	Creates a tube MPC controller. The error e = x - z between the true state and the state of the nominal model
+	z+ = A z + B v evolves as e+ = (A - B K) e + w under the ancillary law u = v - K e, and stays in the robust
+	positively invariant set Z (by default an outer approximation of the minimal RPI set). The nominal controller
+	uses the tightened constraints
+		z in X - Z,   v in U - K Z   (Pontryagin differences),
+	so that x = z + e is in X and u = v - K e is in U for every disturbance sequence in W.
+	Returns an error if A - B K is not strictly stable or if the tightened constraints are empty.
+*/
+func GetTubeControllerWithOptions(sys goControl.StateSpace, Q, R mat.Matrix, N int, stateConstraints, inputConstraints *goControl.Polyhedron, W goControl.Polyhedron, options TubeOptions) (TubeController, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return TubeController{}, err
+	}
+	n, m, _ := sys.Dims()
+	if err := W.Check(); err != nil {
+		return TubeController{}, fmt.Errorf("The disturbance set is not valid: %v", err)
+	}
+	if W.Dimension() != n {
+		return TubeController{}, fmt.Errorf("The disturbance set has dimension %v; expected %v.", W.Dimension(), n)
+	}
+	for _, constraint := range []struct {
+		set       *goControl.Polyhedron
+		dimension int
+		name      string
+	}{
+		{stateConstraints, n, "state constraints"},
+		{inputConstraints, m, "input constraints"},
+	} {
+		if err := checkConstraintSet(constraint.set, constraint.dimension, constraint.name); err != nil {
+			return TubeController{}, err
+		}
+	}
+
+	// Ancillary gain
+	var K *mat.Dense
+	if options.Gain != nil {
+		if rows, cols := options.Gain.Dims(); (rows != m) || (cols != n) {
+			return TubeController{}, fmt.Errorf("The ancillary gain must be %v x %v; received %v x %v.", m, n, rows, cols)
+		}
+		K = mat.DenseCopyOf(options.Gain)
+	} else {
+		gain, _, _, err := goControl.Dlqr(sys.A, sys.B, Q, R, nil)
+		if err != nil {
+			return TubeController{}, fmt.Errorf("The ancillary LQR gain could not be computed: %v", err)
+		}
+		K = gain
+	}
+
+	var BK, Acl mat.Dense
+	BK.Mul(sys.B, K)
+	Acl.Sub(sys.A, &BK)
+
+	// Tube
+	var Z goControl.Polyhedron
+	if options.Tube != nil {
+		if err := options.Tube.Check(); err != nil {
+			return TubeController{}, fmt.Errorf("The tube is not a valid Polyhedron: %v", err)
+		}
+		if options.Tube.Dimension() != n {
+			return TubeController{}, fmt.Errorf("The tube has dimension %v; expected %v.", options.Tube.Dimension(), n)
+		}
+		Z = *options.Tube
+	} else {
+		epsilon := options.Epsilon
+		if epsilon == 0 {
+			epsilon = defaultTubeEpsilon
+		}
+		approximation, err := goControl.MinimalRPI(&Acl, W, epsilon)
+		if err != nil {
+			return TubeController{}, fmt.Errorf("The tube could not be computed: %v", err)
+		}
+		Z = approximation.Set
+	}
+
+	// Tightened constraints
+	tightenedState, err := tightenConstraint(stateConstraints, Z, "state constraints")
+	if err != nil {
+		return TubeController{}, err
+	}
+	var tightenedInput *goControl.Polyhedron
+	if inputConstraints != nil {
+		var negativeK mat.Dense
+		negativeK.Scale(-1, K)
+		KZ, err := Z.AffineMap(&negativeK)
+		if err != nil {
+			return TubeController{}, err
+		}
+		if tightenedInput, err = tightenConstraint(inputConstraints, KZ, "input constraints"); err != nil {
+			return TubeController{}, err
+		}
+	}
+
+	// Create Controller
+	nominal, err := GetControllerWithOptions(sys, Q, R, N, tightenedState, tightenedInput, options.Controller)
+	if err != nil {
+		return TubeController{}, err
+	}
+
+	return TubeController{
+		Nominal:          nominal,
+		Gain:             K,
+		Disturbance:      W,
+		Tube:             Z,
+		StateConstraints: stateConstraints,
+		InputConstraints: inputConstraints,
+	}, nil
+}
+
+/*
+Solve
+Description:
+
+	Computes the input applied at the current (measured) state x. The nominal state is initialized with x at the
+	first call (and after Reset) and then follows the nominal model, z+ = A z + B v_0; the nominal problem is solved
+	from z and the applied input is u = v_0 - K (x - z).
+*/
+func (c *TubeController) Solve(x mat.Vector) (TubeSolution, error) {
+	// Input Processing
+	n, _, _ := c.Nominal.Model.Dims()
+	if (x == nil) || (x.Len() != n) {
+		return TubeSolution{}, fmt.Errorf("The current state must have length %v.", n)
+	}
+
+	z := c.nominalState
+	if z == nil {
+		z = mat.VecDenseCopyOf(x)
+	}
+
+	// Algorithm
+	nominal, err := c.Nominal.Solve(z)
+	if err != nil {
+		return TubeSolution{}, fmt.Errorf("The nominal problem could not be solved: %v", err)
+	}
+
+	var e, Ke mat.VecDense
+	e.SubVec(x, z)
+	Ke.MulVec(c.Gain, &e)
+	u := mat.VecDenseCopyOf(nominal.U.RowView(0))
+	u.SubVec(u, &Ke)
+
+	c.nominalState = mat.VecDenseCopyOf(nominal.X.RowView(1))
+
+	return TubeSolution{
+		U:            u,
+		NominalState: z,
+		Nominal:      nominal,
+	}, nil
+}
+
+/*
+Control
+Description:
+
+	Returns the input u = v_0 - K (x - z) applied at the current state x (see Solve).
+*/
+func (c *TubeController) Control(x mat.Vector) (*mat.VecDense, error) {
+	// Algorithm
+	solution, err := c.Solve(x)
+	if err != nil {
+		return nil, err
+	}
+	return solution.U, nil
+}
+
+/*
+Reset
+Description:
+
+	Discards the nominal state and the warm start, so that the next call starts a new tube at the measured state.
+*/
+func (c *TubeController) Reset() {
+	c.nominalState = nil
+	c.Nominal.Reset()
+}
+
+/*
+tightenConstraint
+Description:
+
+	Computes the Pontryagin difference set - Z in minimal representation (nil for a nil set), and returns an error
+	if it is empty.
+*/
+func tightenConstraint(set *goControl.Polyhedron, Z goControl.Polyhedron, name string) (*goControl.Polyhedron, error) {
+	// Input Processing
+	if set == nil {
+		return nil, nil
+	}
+
+	// Algorithm
+	tightened, err := set.Minus(Z)
+	if err != nil {
+		return nil, err
+	}
+	empty, err := tightened.IsEmptySet()
+	if err != nil {
+		return nil, err
+	}
+	if empty {
+		return nil, fmt.Errorf("The tightened %v are empty; the disturbance is too large for the constraints.", name)
+	}
+	if tightened, err = tightened.MinHRep(); err != nil {
+		return nil, err
+	}
+
+	return &tightened, nil
+}
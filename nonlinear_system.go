@@ -0,0 +1,479 @@
+/*
+   nonlinear_system.go
+   Description:
+       Nonlinear models whose dynamics are polynomials built with the symbolic package,
+           dx/dt = f(x, u)   (or x+ = f(x, u) in discrete time),   y = h(x, u),
+       with simulation and linearization around an operating point through symbolic Jacobians.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kwesiRutledge/goControl/ode"
+	"github.com/kwesiRutledge/goControl/symbolic"
+	"gonum.org/v1/gonum/mat"
+)
+
+// nonlinearSubsteps is the number of RK4 steps taken between two time samples of a continuous-time simulation.
+const nonlinearSubsteps = 10
+
+type NonlinearSystem struct {
+	States   []symbolic.Variable   // State variables x
+	Inputs   []symbolic.Variable   // Input variables u
+	Dynamics []symbolic.Polynomial // f(x, u), one polynomial per state
+	Outputs  []symbolic.Polynomial // h(x, u), one polynomial per output (nil means y = x)
+	Ts       float64               // Sample time (0 for continuous-time models)
+}
+
+/*
+GetNonlinearSystem
+Description:
+
+	Creates the continuous-time (Ts = 0) or discrete-time (Ts > 0) model with state variables x, input variables u
+	and dynamics f (each a Variable, Monomial or Polynomial, or a constant). The output is the state.
+*/
+func GetNonlinearSystem(x, u []symbolic.Variable, f []interface{}, Ts float64) (NonlinearSystem, error) {
+	return GetNonlinearSystemWithOutputs(x, u, f, nil, Ts)
+}
+
+/*
+GetNonlinearSystemWithOutputs
+Description:
+
+	Creates the model with state variables x, input variables u, dynamics f and outputs h (each a Variable, Monomial
+	or Polynomial, or a constant). A nil h means that the output is the state.
+*/
+func GetNonlinearSystemWithOutputs(x, u []symbolic.Variable, f, h []interface{}, Ts float64) (NonlinearSystem, error) {
+	// Input Processing
+	dynamics, err := toPolynomials(f, "dynamics")
+	if err != nil {
+		return NonlinearSystem{}, err
+	}
+	var outputs []symbolic.Polynomial
+	if h != nil {
+		if outputs, err = toPolynomials(h, "outputs"); err != nil {
+			return NonlinearSystem{}, err
+		}
+	}
+
+	// Create System
+	sys := NonlinearSystem{
+		States:   append([]symbolic.Variable{}, x...),
+		Inputs:   append([]symbolic.Variable{}, u...),
+		Dynamics: dynamics,
+		Outputs:  outputs,
+		Ts:       Ts,
+	}
+
+	// Check it
+	if err := sys.Check(); err != nil {
+		return NonlinearSystem{}, err
+	}
+
+	return sys, nil
+}
+
+/*
+Check
+Description:
+
+	Returns an error if the model has no states, if a variable is repeated, if the number of dynamics does not match
+	the number of states, if the sample time is not valid, or if f or h depends on a variable that is neither a
+	state nor an input.
+*/
+func (sys NonlinearSystem) Check() error {
+	// Input Processing
+	if len(sys.States) == 0 {
+		return errors.New("A nonlinear system must have at least one state.")
+	}
+	if len(sys.Dynamics) != len(sys.States) {
+		return fmt.Errorf("The number of dynamics (%v) does not match the number of states (%v).", len(sys.Dynamics), len(sys.States))
+	}
+	if (sys.Outputs != nil) && (len(sys.Outputs) == 0) {
+		return errors.New("The outputs of a nonlinear system must be nil (y = x) or nonempty.")
+	}
+	if !(sys.Ts >= 0) || math.IsInf(sys.Ts, 0) {
+		return fmt.Errorf("The sample time must be nonnegative and finite; received %v.", sys.Ts)
+	}
+
+	variables := append(append([]symbolic.Variable{}, sys.States...), sys.Inputs...)
+	for i, v := range variables {
+		if v.FoundIn(variables[:i]) != -1 {
+			return fmt.Errorf("The variable %v appears more than once among the states and inputs.", v)
+		}
+	}
+
+	for _, group := range []struct {
+		polynomials []symbolic.Polynomial
+		name        string
+	}{
+		{sys.Dynamics, "dynamics"},
+		{sys.Outputs, "output"},
+	} {
+		for i, p := range group.polynomials {
+			for _, v := range p.Variables() {
+				if v.FoundIn(variables) == -1 {
+					return fmt.Errorf("The %v %v depends on the variable %v, which is neither a state nor an input.", group.name, i, v)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+Dims
+Description:
+
+	Returns the number of states, inputs and outputs of the model.
+*/
+func (sys NonlinearSystem) Dims() (n, m, p int) {
+	n, m, p = len(sys.States), len(sys.Inputs), len(sys.Outputs)
+	if sys.Outputs == nil {
+		p = n
+	}
+	return n, m, p
+}
+
+/*
+IsDiscrete
+Description:
+
+	Returns true if the model is a discrete-time model.
+*/
+func (sys NonlinearSystem) IsDiscrete() bool {
+	return sys.Ts > 0
+}
+
+/*
+Evaluate
+Description:
+
+	Evaluates the dynamics f(x, u) (the derivative of the state, or the next state in discrete time).
+*/
+func (sys NonlinearSystem) Evaluate(x, u mat.Vector) (*mat.VecDense, error) {
+	// Input Processing
+	values, err := sys.point(x, u)
+	if err != nil {
+		return nil, err
+	}
+
+	// Algorithm
+	return evaluatePolynomials(sys.Dynamics, values)
+}
+
+/*
+Output
+Description:
+
+	Evaluates the output h(x, u) (the state when the model has no outputs).
+*/
+func (sys NonlinearSystem) Output(x, u mat.Vector) (*mat.VecDense, error) {
+	// Input Processing
+	values, err := sys.point(x, u)
+	if err != nil {
+		return nil, err
+	}
+
+	// Algorithm
+	if sys.Outputs == nil {
+		return mat.VecDenseCopyOf(x), nil
+	}
+	return evaluatePolynomials(sys.Outputs, values)
+}
+
+/*
+StateJacobian
+Description:
+
+	Returns the symbolic Jacobian df/dx of the dynamics; entry (i, j) is the partial derivative of f_i with respect
+	to x_j.
+*/
+func (sys NonlinearSystem) StateJacobian() [][]symbolic.Polynomial {
+	return jacobian(sys.Dynamics, sys.States)
+}
+
+/*
+InputJacobian
+Description:
+
+	Returns the symbolic Jacobian df/du of the dynamics.
+*/
+func (sys NonlinearSystem) InputJacobian() [][]symbolic.Polynomial {
+	return jacobian(sys.Dynamics, sys.Inputs)
+}
+
+/*
+Linearize
+Description:
+
+	Linearizes the model around the operating point (x0, u0) with the symbolic Jacobians,
+		A = df/dx,  B = df/du,  C = dh/dx,  D = dh/du   (evaluated at (x0, u0)),
+	and returns the state-space model (with the sample time of the model) of the deviations
+	dx = x - x0, du = u - u0 and dy = y - h(x0, u0). The operating point does not need to be an equilibrium;
+	the constant term f(x0, u0) (or f(x0, u0) - x0 in discrete time) is not part of the returned model.
+*/
+func (sys NonlinearSystem) Linearize(x0, u0 mat.Vector) (StateSpace, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return StateSpace{}, err
+	}
+	if len(sys.Inputs) == 0 {
+		return StateSpace{}, errors.New("A nonlinear system without inputs cannot be linearized into a state-space model.")
+	}
+	values, err := sys.point(x0, u0)
+	if err != nil {
+		return StateSpace{}, err
+	}
+
+	// Algorithm
+	A, err := evaluateJacobian(sys.StateJacobian(), values, len(sys.States))
+	if err != nil {
+		return StateSpace{}, err
+	}
+	B, err := evaluateJacobian(sys.InputJacobian(), values, len(sys.Inputs))
+	if err != nil {
+		return StateSpace{}, err
+	}
+
+	n, m, p := sys.Dims()
+	C, D := eye(n), mat.NewDense(p, m, nil)
+	if sys.Outputs != nil {
+		if C, err = evaluateJacobian(jacobian(sys.Outputs, sys.States), values, n); err != nil {
+			return StateSpace{}, err
+		}
+		if D, err = evaluateJacobian(jacobian(sys.Outputs, sys.Inputs), values, m); err != nil {
+			return StateSpace{}, err
+		}
+	}
+
+	if sys.IsDiscrete() {
+		return GetDiscreteStateSpace(A, B, C, D, sys.Ts)
+	}
+	return GetStateSpace(A, B, C, D)
+}
+
+/*
+Simulate
+Description:
+
+	Simulates the response of the model to the input u (an N x m matrix whose row k is applied at time t[k])
+	starting from the initial state x0. The input is held constant between samples. Continuous-time models are
+	integrated between samples with the fixed-step RK4 method of the ode package (with nonlinearSubsteps steps per
+	interval); the time samples of discrete-time models must be spaced by the sample time.
+*/
+func (sys NonlinearSystem) Simulate(u mat.Matrix, t []float64, x0 mat.Vector) (TimeResponse, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return TimeResponse{}, err
+	}
+
+	n, m, p := sys.Dims()
+	N := len(t)
+	if N == 0 {
+		return TimeResponse{}, errors.New("The time vector given to Simulate is empty.")
+	}
+	if m == 0 {
+		if u != nil {
+			if uRows, uCols := u.Dims(); (uRows != N) || (uCols != 0) {
+				return TimeResponse{}, fmt.Errorf("The input has dimensions %v x %v; expected %v x %v.", uRows, uCols, N, m)
+			}
+		}
+	} else if u == nil {
+		return TimeResponse{}, fmt.Errorf("The input must be a %v x %v matrix.", N, m)
+	} else if uRows, uCols := u.Dims(); (uRows != N) || (uCols != m) {
+		return TimeResponse{}, fmt.Errorf("The input has dimensions %v x %v; expected %v x %v.", uRows, uCols, N, m)
+	}
+	for k := 1; k < N; k++ {
+		if !(t[k] > t[k-1]) {
+			return TimeResponse{}, fmt.Errorf("The time vector must be increasing; t[%v] = %v and t[%v] = %v.", k-1, t[k-1], k, t[k])
+		}
+		if sys.IsDiscrete() && (math.Abs((t[k]-t[k-1])-sys.Ts) > 1e-9*sys.Ts) {
+			return TimeResponse{}, fmt.Errorf("The time samples of a discrete-time model must be spaced by the sample time %v.", sys.Ts)
+		}
+	}
+	if (x0 == nil) || (x0.Len() != n) {
+		return TimeResponse{}, fmt.Errorf("The initial state must have length %v.", n)
+	}
+
+	// Algorithm
+	response := TimeResponse{
+		Time:   mat.NewVecDense(N, append([]float64{}, t...)),
+		Output: mat.NewDense(N, p, nil),
+		State:  mat.NewDense(N, n, nil),
+	}
+
+	x := mat.VecDenseCopyOf(x0)
+	var uk mat.Vector
+	for k := 0; k < N; k++ {
+		if m > 0 {
+			uk = mat.NewVecDense(m, mat.Row(nil, k, u))
+		}
+		y, err := sys.Output(x, uk)
+		if err != nil {
+			return TimeResponse{}, err
+		}
+		response.State.SetRow(k, x.RawVector().Data)
+		response.Output.SetRow(k, y.RawVector().Data)
+
+		if k == N-1 {
+			break
+		}
+		if sys.IsDiscrete() {
+			x, err = sys.Evaluate(x, uk)
+		} else {
+			x, err = sys.integrate(x, uk, t[k], t[k+1])
+		}
+		if err != nil {
+			return TimeResponse{}, err
+		}
+		for i := 0; i < n; i++ {
+			if !isFinite(x.AtVec(i)) {
+				return TimeResponse{}, fmt.Errorf("The state is not finite at time %v.", t[k+1])
+			}
+		}
+	}
+
+	return response, nil
+}
+
+/*
+integrate
+Description:
+
+	Integrates dx/dt = f(x, u) from x at time t0 until time t1, with the input held constant, using nonlinearSubsteps
+	steps of the RK4 method of the ode package.
+*/
+func (sys NonlinearSystem) integrate(x, u mat.Vector, t0, t1 float64) (*mat.VecDense, error) {
+	// Constants
+	f := func(t float64, x mat.Vector) (*mat.VecDense, error) {
+		return sys.Evaluate(x, u)
+	}
+	h := (t1 - t0) / nonlinearSubsteps
+
+	// Algorithm
+	current := mat.VecDenseCopyOf(x)
+	derivative, err := f(t0, current)
+	if err != nil {
+		return nil, err
+	}
+	for step := 0; step < nonlinearSubsteps; step++ {
+		if current, derivative, err = ode.RK4Step(f, t0+float64(step)*h, h, current, derivative); err != nil {
+			return nil, err
+		}
+	}
+
+	return current, nil
+}
+
+/*
+point
+Description:
+
+	Returns the values of the state and input variables at (x, u). A nil u is accepted for models without inputs.
+*/
+func (sys NonlinearSystem) point(x, u mat.Vector) (map[symbolic.Variable]float64, error) {
+	// Input Processing
+	if (x == nil) || (x.Len() != len(sys.States)) {
+		return nil, fmt.Errorf("The state must have length %v.", len(sys.States))
+	}
+	uLength := 0
+	if u != nil {
+		uLength = u.Len()
+	}
+	if uLength != len(sys.Inputs) {
+		return nil, fmt.Errorf("The input must have length %v; received %v.", len(sys.Inputs), uLength)
+	}
+
+	// Algorithm
+	values := make(map[symbolic.Variable]float64, len(sys.States)+len(sys.Inputs))
+	for i, v := range sys.States {
+		values[v] = x.AtVec(i)
+	}
+	for i, v := range sys.Inputs {
+		values[v] = u.AtVec(i)
+	}
+	return values, nil
+}
+
+/*
+toPolynomials
+Description:
+
+	Converts each expression of the slice into a symbolic Polynomial.
+*/
+func toPolynomials(expressions []interface{}, name string) ([]symbolic.Polynomial, error) {
+	// Algorithm
+	polynomials := make([]symbolic.Polynomial, len(expressions))
+	for i, e := range expressions {
+		p, err := symbolic.ToPolynomial(e)
+		if err != nil {
+			return nil, fmt.Errorf("Entry %v of the %v is not valid: %v", i, name, err)
+		}
+		polynomials[i] = p
+	}
+	return polynomials, nil
+}
+
+/*
+evaluatePolynomials
+Description:
+
+	Evaluates each polynomial of the slice at the given values.
+*/
+func evaluatePolynomials(polynomials []symbolic.Polynomial, values map[symbolic.Variable]float64) (*mat.VecDense, error) {
+	// Algorithm
+	result := make([]float64, len(polynomials))
+	for i, p := range polynomials {
+		value, err := p.Evaluate(values)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return mat.NewVecDense(len(result), result), nil
+}
+
+/*
+jacobian
+Description:
+
+	Returns the symbolic Jacobian of the polynomials with respect to the variables.
+*/
+func jacobian(polynomials []symbolic.Polynomial, variables []symbolic.Variable) [][]symbolic.Polynomial {
+	// Algorithm
+	J := make([][]symbolic.Polynomial, len(polynomials))
+	for i, p := range polynomials {
+		J[i] = make([]symbolic.Polynomial, len(variables))
+		for j, v := range variables {
+			J[i][j] = p.Derivative(v)
+		}
+	}
+	return J
+}
+
+/*
+evaluateJacobian
+Description:
+
+	Evaluates a symbolic Jacobian with the given number of columns at the given values.
+*/
+func evaluateJacobian(J [][]symbolic.Polynomial, values map[symbolic.Variable]float64, cols int) (*mat.Dense, error) {
+	// Algorithm
+	M := mat.NewDense(len(J), cols, nil)
+	for i, row := range J {
+		for j, p := range row {
+			value, err := p.Evaluate(values)
+			if err != nil {
+				return nil, err
+			}
+			M.Set(i, j, value)
+		}
+	}
+	return M, nil
+}
@@ -0,0 +1,306 @@
+/*
+   norms.go
+   Description:
+       The H2 and H-infinity norms of state space models "like" MATLAB's norm function.
+*/
+
+package goControl
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// hinfTolerance is the relative accuracy of the H-infinity norm computation.
+const hinfTolerance = 1e-10
+
+/*
+Norm
+Description:
+
+	Computes the norm of the model sys. The normType is either "2" (the H2 norm) or "inf" (the H-infinity norm, i.e.
+	the peak gain over all frequencies). For the H-infinity norm, the frequency (rad/s) at which the peak gain is
+	reached is returned as well; it is NaN for the H2 norm. Unstable models have an infinite norm.
+	Input delays do not change either norm and are ignored.
+*/
+func Norm(sys StateSpace, normType string) (norm, peakFrequency float64, err error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return 0, 0, err
+	}
+
+	delayFree := sys.Copy()
+	delayFree.InputDelay = nil
+
+	// Algorithm
+	switch normType {
+	case "2":
+		norm, err = h2Norm(delayFree)
+		return norm, math.NaN(), err
+	case "inf", "Inf":
+		return hinfNorm(delayFree)
+	default:
+		return 0, 0, fmt.Errorf("The norm type %v is not recognized; use \"2\" or \"inf\".", normType)
+	}
+}
+
+/*
+h2Norm
+Description:
+
+	Computes the H2 norm sqrt(trace(C Wc C^T)) (continuous-time) or sqrt(trace(C Wc C^T + D D^T)) (discrete-time),
+	where Wc is the controllability Gramian. Continuous-time models with a nonzero D matrix have an infinite H2 norm.
+*/
+func h2Norm(sys StateSpace) (float64, error) {
+	// Check stability
+	stable, err := sys.IsStable()
+	if err != nil {
+		return 0, err
+	}
+	if !stable {
+		return math.Inf(1), nil
+	}
+	if !sys.IsDiscrete() && (mat.Norm(sys.D, 1) > 0) {
+		return math.Inf(1), nil
+	}
+
+	// Algorithm
+	Wc, err := ControllabilityGramian(sys)
+	if err != nil {
+		return 0, err
+	}
+
+	var WcCt, CWcCt mat.Dense
+	WcCt.Mul(Wc, sys.C.T())
+	CWcCt.Mul(sys.C, &WcCt)
+	total := mat.Trace(&CWcCt)
+
+	if sys.IsDiscrete() {
+		total += mat.Norm(sys.D, 2) * mat.Norm(sys.D, 2)
+	}
+
+	return math.Sqrt(math.Max(total, 0)), nil
+}
+
+/*
+hinfNorm
+Description:
+
+	Computes the H-infinity norm and the peak frequency. Discrete-time models are mapped to continuous-time models
+	with the bilinear transformation, which maps the unit circle onto the imaginary axis and preserves the norm.
+*/
+func hinfNorm(sys StateSpace) (float64, float64, error) {
+	// Check stability
+	stable, err := sys.IsStable()
+	if err != nil {
+		return 0, 0, err
+	}
+	if !stable {
+		return math.Inf(1), math.NaN(), nil
+	}
+
+	if !sys.IsDiscrete() {
+		return bruinsmaSteinbuch(sys)
+	}
+
+	// Algorithm (discrete-time)
+	sysC, err := D2c(sys, Tustin)
+	if err != nil {
+		return 0, 0, err
+	}
+	norm, frequency, err := bruinsmaSteinbuch(sysC)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	alpha := tustinAlpha(sys.Ts, 0)
+	return norm, 2 * math.Atan(frequency/alpha) / sys.Ts, nil
+}
+
+/*
+bruinsmaSteinbuch
+Description:
+
+	Computes the H-infinity norm of the stable continuous-time model sys with the algorithm of Bruinsma and
+	Steinbuch. For gamma larger than the largest singular value of D, gamma is a singular value of G(j w) if and only
+	if j w is an eigenvalue of the Hamiltonian matrix
+		H(gamma) = [ A - B R^{-1} D^T C,          -gamma B R^{-1} B^T         ]
+		           [ gamma C^T S^{-1} C,           -A^T + C^T D R^{-1} B^T   ]
+	with R = D^T D - gamma^2 I and S = D D^T - gamma^2 I. Starting from a lower bound, each iteration evaluates the gain
+	at the midpoints of the imaginary eigenvalues of H((1 + 2 tol) gamma), which increases the lower bound quadratically,
+	until H has no imaginary eigenvalues.
+*/
+func bruinsmaSteinbuch(sys StateSpace) (float64, float64, error) {
+	// Constants
+	n, m, p := sys.Dims()
+	evaluator := getFrequencyEvaluator(sys)
+	gain := func(w float64) float64 { return largestComplexSingularValue(evaluator.at(w)) }
+
+	// Initial lower bound: the gains at infinity, at DC and at the natural frequencies of the poles
+	dValues, err := singularValues(sys.D)
+	if err != nil {
+		return 0, 0, err
+	}
+	gammaLow, peakFrequency := 0.0, math.Inf(1)
+	if len(dValues) > 0 {
+		gammaLow = dValues[0]
+	}
+
+	poles, err := sys.Poles()
+	if err != nil {
+		return 0, 0, err
+	}
+	candidates := []float64{0}
+	for _, pole := range poles {
+		candidates = append(candidates, cmplx.Abs(pole))
+		if imag(pole) > 0 {
+			candidates = append(candidates, imag(pole))
+		}
+	}
+	for _, w := range candidates {
+		if value := gain(w); value > gammaLow {
+			gammaLow, peakFrequency = value, w
+		}
+	}
+	if gammaLow == 0 {
+		return 0, 0, nil
+	}
+
+	// Iterate
+	for iteration := 0; iteration < 100; iteration++ {
+		gamma := (1 + 2*hinfTolerance) * gammaLow
+
+		frequencies, err := hamiltonianImaginaryFrequencies(sys, gamma, n, m, p)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(frequencies) == 0 {
+			break
+		}
+
+		improved := false
+		for k := 0; k+1 < len(frequencies); k++ {
+			w := 0.5 * (frequencies[k] + frequencies[k+1])
+			if value := gain(w); value > gammaLow {
+				gammaLow, peakFrequency = value, w
+				improved = true
+			}
+		}
+		if len(frequencies) == 1 {
+			if value := gain(frequencies[0]); value > gammaLow {
+				gammaLow, peakFrequency = value, frequencies[0]
+				improved = true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	return gammaLow, peakFrequency, nil
+}
+
+/*
+hamiltonianImaginaryFrequencies
+Description:
+
+	Returns the sorted nonnegative frequencies w such that j w is an eigenvalue of the Hamiltonian matrix H(gamma)
+	(see bruinsmaSteinbuch).
+*/
+func hamiltonianImaginaryFrequencies(sys StateSpace, gamma float64, n, m, p int) ([]float64, error) {
+	// Constants
+	A, B, C, D := sys.A, sys.B, sys.C, sys.D
+
+	// R = D^T D - gamma^2 I and S = D D^T - gamma^2 I
+	R := mat.NewDense(m, m, nil)
+	R.Mul(D.T(), D)
+	R.Sub(R, scaledEye(m, gamma*gamma))
+	S := mat.NewDense(p, p, nil)
+	S.Mul(D, D.T())
+	S.Sub(S, scaledEye(p, gamma*gamma))
+
+	var RinvDtC, RinvBt, SinvC mat.Dense
+	var DtC mat.Dense
+	DtC.Mul(D.T(), C)
+	if err := RinvDtC.Solve(R, &DtC); err != nil {
+		return nil, err
+	}
+	if err := RinvBt.Solve(R, B.T()); err != nil {
+		return nil, err
+	}
+	if err := SinvC.Solve(S, C); err != nil {
+		return nil, err
+	}
+
+	// Build H(gamma)
+	H11, H12, H21, H22 := mat.NewDense(n, n, nil), mat.NewDense(n, n, nil), mat.NewDense(n, n, nil), mat.NewDense(n, n, nil)
+	H11.Mul(B, &RinvDtC)
+	H11.Sub(A, H11)
+
+	H12.Mul(B, &RinvBt)
+	H12.Scale(-gamma, H12)
+
+	H21.Mul(C.T(), &SinvC)
+	H21.Scale(gamma, H21)
+
+	var DRinvBt mat.Dense
+	DRinvBt.Mul(D, &RinvBt)
+	H22.Mul(C.T(), &DRinvBt)
+	H22.Sub(H22, A.T())
+
+	H := vstack(hstack(H11, H12), hstack(H21, H22))
+
+	// Find the imaginary eigenvalues
+	eigs, err := eigenvalues(H)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := math.Max(mat.Norm(H, 1), 1)
+	var frequencies []float64
+	for _, lambda := range eigs {
+		if (math.Abs(real(lambda)) <= 1e-8*scale) && (imag(lambda) >= 0) {
+			frequencies = append(frequencies, imag(lambda))
+		}
+	}
+
+	sort.Float64s(frequencies)
+	return frequencies, nil
+}
+
+/*
+largestComplexSingularValue
+Description:
+
+	Computes the largest singular value of the complex matrix G from the real embedding [Re -Im; Im Re],
+	whose singular values are those of G, each repeated twice.
+*/
+func largestComplexSingularValue(G *mat.CDense) float64 {
+	// Constants
+	p, m := G.Dims()
+
+	// Algorithm
+	embedding := mat.NewDense(2*p, 2*m, nil)
+	for i := 0; i < p; i++ {
+		for j := 0; j < m; j++ {
+			value := G.At(i, j)
+			if cmplx.IsInf(value) || cmplx.IsNaN(value) {
+				return math.Inf(1)
+			}
+			embedding.Set(i, j, real(value))
+			embedding.Set(i, m+j, -imag(value))
+			embedding.Set(p+i, j, imag(value))
+			embedding.Set(p+i, m+j, real(value))
+		}
+	}
+
+	values, err := singularValues(embedding)
+	if (err != nil) || (len(values) == 0) {
+		return math.NaN()
+	}
+	return values[0]
+}
@@ -0,0 +1,553 @@
+/*
+   ode.go
+   Description:
+       Numerical integration of ordinary differential equations dx/dt = f(t, x) "like" MATLAB's ode45 and ode23s,
+       with fixed-step and adaptive explicit Runge-Kutta methods, a Rosenbrock method for stiff systems, event
+       detection (zero-crossings) and dense output.
+*/
+
+package ode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	defaultRelTol   = 1e-3   // Default relative tolerance of the adaptive methods
+	defaultAbsTol   = 1e-6   // Default absolute tolerance of the adaptive methods
+	defaultMaxSteps = 100000 // Default maximum number of steps
+	defaultRK4Steps = 100    // Default number of steps of RK4 over the time span
+
+	machineEpsilon = 2.220446049250313e-16
+)
+
+// Function is the right-hand side f(t, x) of dx/dt = f(t, x).
+type Function func(t float64, x mat.Vector) (*mat.VecDense, error)
+
+// Jacobian returns the Jacobian df/dx of the right-hand side at (t, x).
+type Jacobian func(t float64, x mat.Vector) (*mat.Dense, error)
+
+type Method int
+
+const (
+	DormandPrince Method = iota // Adaptive explicit Runge-Kutta method of order 5(4) (like ode45)
+	RK4                         // Classical fourth-order Runge-Kutta method with a fixed step
+	Rosenbrock                  // Adaptive linearly implicit Rosenbrock method of order 2(3) for stiff systems (like ode23s)
+)
+
+type Event struct {
+	Function  func(t float64, x mat.Vector) float64 // Event function g(t, x); an event occurs when g crosses zero
+	Direction int                                   // 1 for rising crossings only, -1 for falling crossings only, 0 for both
+	Terminal  bool                                  // Stop the integration at the event
+}
+
+type Options struct {
+	Method   Method   // DormandPrince (default), RK4 or Rosenbrock
+	Step     float64  // Step of RK4 (0 selects 1/100 of the time span) or initial step of the adaptive methods (0 selects it automatically)
+	RelTol   float64  // Relative tolerance of the adaptive methods (0 selects 1e-3)
+	AbsTol   float64  // Absolute tolerance of the adaptive methods (0 selects 1e-6)
+	MaxStep  float64  // Largest step of the adaptive methods (0 selects 1/10 of the time span)
+	MaxSteps int      // Largest number of steps, including rejected steps (0 selects 100000)
+	Events   []Event  // Event functions whose zero-crossings are located
+	Jacobian Jacobian // Jacobian of f for Rosenbrock (nil selects finite differences)
+}
+
+type EventRecord struct {
+	Time  float64       // Time of the zero-crossing
+	State *mat.VecDense // State at the zero-crossing
+	Index int           // Index of the event in Options.Events
+}
+
+type Solution struct {
+	Time        *mat.VecDense // Output times: the steps of the method, or the requested times
+	State       *mat.Dense    // Row k is the state at Time[k]
+	Events      []EventRecord // Zero-crossings of the event functions, in chronological order
+	Terminated  bool          // True if a terminal event stopped the integration
+	Steps       int           // Number of accepted steps
+	Rejected    int           // Number of rejected steps
+	Evaluations int           // Number of evaluations of f
+
+	segments []segment
+}
+
+/*
+segment
+Description:
+
+	One step of the integration, from (t0, x0) to (t1, x1), with the derivatives f0 and f1 at its ends used by the
+	cubic Hermite dense output.
+*/
+type segment struct {
+	t0, t1         float64
+	x0, x1, f0, f1 *mat.VecDense
+}
+
+/*
+Solve
+Description:
+
+	Integrates dx/dt = f(t, x) from x(tspan[0]) = x0 with the Dormand-Prince method and the default options.
+	See SolveWithOptions.
+*/
+func Solve(f Function, tspan []float64, x0 mat.Vector) (Solution, error) {
+	return SolveWithOptions(f, tspan, x0, Options{})
+}
+
+/*
+SolveWithOptions
+Description:
+
+	Integrates dx/dt = f(t, x) from x(tspan[0]) = x0 until tspan[len(tspan)-1] (or a terminal event). With two times
+	in tspan, the solution contains every step of the method; with more times, it contains the state at each time of
+	tspan (reached before a terminal event), computed with the dense output. tspan must be increasing.
+*/
+func SolveWithOptions(f Function, tspan []float64, x0 mat.Vector, options Options) (Solution, error) {
+	// Input Processing
+	if len(tspan) < 2 {
+		return Solution{}, errors.New("The time span must contain at least an initial and a final time.")
+	}
+	for k := 1; k < len(tspan); k++ {
+		if !(tspan[k] > tspan[k-1]) {
+			return Solution{}, fmt.Errorf("The time span must be increasing; tspan[%v] = %v and tspan[%v] = %v.", k-1, tspan[k-1], k, tspan[k])
+		}
+	}
+
+	// Algorithm
+	sol, err := integrate(f, tspan[0], tspan[len(tspan)-1], x0, options)
+	if err != nil {
+		return Solution{}, err
+	}
+	if len(tspan) == 2 {
+		return sol, nil
+	}
+
+	// Dense output at the requested times
+	tEnd := sol.Time.AtVec(sol.Time.Len() - 1)
+	times := []float64{}
+	for _, t := range tspan {
+		if t <= tEnd {
+			times = append(times, t)
+		}
+	}
+	n := x0.Len()
+	state := mat.NewDense(len(times), n, nil)
+	for k, t := range times {
+		x, err := sol.At(t)
+		if err != nil {
+			return Solution{}, err
+		}
+		state.SetRow(k, x.RawVector().Data)
+	}
+	sol.Time = mat.NewVecDense(len(times), times)
+	sol.State = state
+
+	return sol, nil
+}
+
+/*
+At
+Description:
+
+	Evaluates the dense output of the solution at time t, which must lie between the initial time and the final
+	time of the integration. Each step is interpolated with the cubic Hermite polynomial that matches the states and
+	the derivatives at both of its ends.
+*/
+func (sol Solution) At(t float64) (*mat.VecDense, error) {
+	// Input Processing
+	if len(sol.segments) == 0 {
+		return nil, errors.New("The solution has no steps.")
+	}
+	tStart, tEnd := sol.segments[0].t0, sol.segments[len(sol.segments)-1].t1
+	if (t < tStart) || (t > tEnd) || math.IsNaN(t) {
+		return nil, fmt.Errorf("The time %v is outside of the solution interval [%v, %v].", t, tStart, tEnd)
+	}
+
+	// Algorithm
+	index := sort.Search(len(sol.segments), func(i int) bool { return sol.segments[i].t1 >= t })
+	return sol.segments[index].interpolate(t), nil
+}
+
+/*
+integrate
+Description:
+
+	Integrates dx/dt = f(t, x) from x(t0) = x0 to tf and returns every step of the method.
+*/
+func integrate(f Function, t0, tf float64, x0 mat.Vector, options Options) (Solution, error) {
+	// Input Processing
+	if x0 == nil || x0.Len() == 0 {
+		return Solution{}, errors.New("The initial state must be a nonempty vector.")
+	}
+	if !(tf > t0) || math.IsInf(tf-t0, 0) {
+		return Solution{}, fmt.Errorf("The final time %v must be finite and larger than the initial time %v.", tf, t0)
+	}
+	if err := options.check(); err != nil {
+		return Solution{}, err
+	}
+	options = options.withDefaults(tf - t0)
+
+	sol := Solution{}
+	counted := func(t float64, x mat.Vector) (*mat.VecDense, error) {
+		sol.Evaluations++
+		dx, err := f(t, x)
+		if err != nil {
+			return nil, err
+		}
+		if (dx == nil) || (dx.Len() != x.Len()) {
+			return nil, fmt.Errorf("The derivative at time %v must have length %v.", t, x.Len())
+		}
+		return mat.VecDenseCopyOf(dx), nil
+	}
+
+	t := t0
+	x := mat.VecDenseCopyOf(x0)
+	fx, err := counted(t, x)
+	if err != nil {
+		return Solution{}, err
+	}
+	times, states := []float64{t}, []*mat.VecDense{x}
+
+	eventValues := make([]float64, len(options.Events))
+	for i, event := range options.Events {
+		eventValues[i] = event.Function(t, x)
+	}
+
+	h := options.Step
+	if (h == 0) && (options.Method != RK4) {
+		h = initialStep(x, fx, options)
+	}
+	adaptive := options.Method != RK4
+
+	// Algorithm
+	for t < tf {
+		if sol.Steps+sol.Rejected >= options.MaxSteps {
+			return Solution{}, fmt.Errorf("The integration did not reach the final time %v in %v steps (it stopped at %v).", tf, options.MaxSteps, t)
+		}
+
+		// Step size
+		if adaptive {
+			h = math.Min(h, options.MaxStep)
+		}
+		tNew := t + h
+		if (tNew >= tf) || (tf-tNew <= 1e-12*math.Max(1, math.Abs(tf))) {
+			tNew = tf
+		}
+		h = tNew - t
+
+		var xNew, fNew, errorVector *mat.VecDense
+		switch options.Method {
+		case DormandPrince:
+			xNew, fNew, errorVector, err = dormandPrinceStep(counted, t, h, x, fx)
+		case RK4:
+			xNew, fNew, err = RK4Step(counted, t, h, x, fx)
+		case Rosenbrock:
+			xNew, fNew, errorVector, err = rosenbrockStep(counted, options.Jacobian, t, h, x, fx)
+		}
+		if err != nil {
+			return Solution{}, err
+		}
+
+		// Error control
+		if adaptive {
+			errorNorm := scaledErrorNorm(errorVector, x, xNew, options)
+			exponent := 1.0 / float64(methodOrder(options.Method)+1)
+			if !(errorNorm <= 1) {
+				sol.Rejected++
+				factor := 0.2
+				if !math.IsInf(errorNorm, 1) && !math.IsNaN(errorNorm) {
+					factor = math.Max(0.2, 0.9*math.Pow(errorNorm, -exponent))
+				}
+				h *= factor
+				if h < 16*machineEpsilon*math.Max(1, math.Abs(t)) {
+					return Solution{}, fmt.Errorf("The step size became too small at time %v; the problem may be stiff or singular.", t)
+				}
+				continue
+			}
+			growth := 5.0
+			if errorNorm > 0 {
+				growth = math.Min(5, 0.9*math.Pow(errorNorm, -exponent))
+			}
+			h *= growth
+		}
+
+		for i := 0; i < xNew.Len(); i++ {
+			if !isFinite(xNew.AtVec(i)) {
+				return Solution{}, fmt.Errorf("The state is not finite at time %v.", tNew)
+			}
+		}
+		sol.Steps++
+		step := segment{t0: t, t1: tNew, x0: x, x1: xNew, f0: fx, f1: fNew}
+
+		// Events
+		if len(options.Events) > 0 {
+			records, terminal := locateEvents(options.Events, eventValues, step)
+			sol.Events = append(sol.Events, records...)
+			if terminal != nil {
+				fEvent, err := counted(terminal.Time, terminal.State)
+				if err != nil {
+					return Solution{}, err
+				}
+				sol.segments = append(sol.segments, segment{t0: t, t1: terminal.Time, x0: x, x1: terminal.State, f0: fx, f1: fEvent})
+				times, states = append(times, terminal.Time), append(states, terminal.State)
+				sol.Terminated = true
+				break
+			}
+		}
+
+		sol.segments = append(sol.segments, step)
+		times, states = append(times, tNew), append(states, xNew)
+		t, x, fx = tNew, xNew, fNew
+	}
+
+	// Output
+	n := x0.Len()
+	sol.Time = mat.NewVecDense(len(times), times)
+	sol.State = mat.NewDense(len(times), n, nil)
+	for k, state := range states {
+		sol.State.SetRow(k, state.RawVector().Data)
+	}
+
+	return sol, nil
+}
+
+/*
+check
+Description:
+
+	Returns an error if the method is not recognized, if a step, tolerance or limit is negative, or if an event has
+	no function.
+*/
+func (options Options) check() error {
+	// Input Processing
+	if (options.Method < DormandPrince) || (options.Method > Rosenbrock) {
+		return fmt.Errorf("The integration method %v is not recognized.", options.Method)
+	}
+	for _, value := range []struct {
+		value float64
+		name  string
+	}{
+		{options.Step, "step"},
+		{options.RelTol, "relative tolerance"},
+		{options.AbsTol, "absolute tolerance"},
+		{options.MaxStep, "maximum step"},
+	} {
+		if !(value.value >= 0) || math.IsInf(value.value, 0) {
+			return fmt.Errorf("The %v must be nonnegative and finite; received %v.", value.name, value.value)
+		}
+	}
+	if options.MaxSteps < 0 {
+		return fmt.Errorf("The maximum number of steps must be nonnegative; received %v.", options.MaxSteps)
+	}
+	for i, event := range options.Events {
+		if event.Function == nil {
+			return fmt.Errorf("Event %v has no event function.", i)
+		}
+	}
+
+	return nil
+}
+
+/*
+withDefaults
+Description:
+
+	Replaces the zero values of the options by the defaults for a time span of the given length.
+*/
+func (options Options) withDefaults(span float64) Options {
+	if options.RelTol == 0 {
+		options.RelTol = defaultRelTol
+	}
+	if options.AbsTol == 0 {
+		options.AbsTol = defaultAbsTol
+	}
+	if options.MaxStep == 0 {
+		options.MaxStep = span / 10
+	}
+	if options.MaxSteps == 0 {
+		options.MaxSteps = defaultMaxSteps
+	}
+	if (options.Method == RK4) && (options.Step == 0) {
+		options.Step = span / defaultRK4Steps
+	}
+	return options
+}
+
+/*
+methodOrder
+Description:
+
+	Returns the order of the error estimate of an adaptive method, which sets the exponent of the step size control.
+*/
+func methodOrder(method Method) int {
+	switch method {
+	case DormandPrince:
+		return 4
+	case Rosenbrock:
+		return 2
+	}
+	return 4
+}
+
+/*
+initialStep
+Description:
+
+	Chooses the initial step of an adaptive method from the scaled sizes of the state and its derivative, as
+	described by Hairer, Norsett and Wanner.
+*/
+func initialStep(x, fx *mat.VecDense, options Options) float64 {
+	// Algorithm
+	d0, d1 := 0.0, 0.0
+	for i := 0; i < x.Len(); i++ {
+		scale := options.AbsTol + options.RelTol*math.Abs(x.AtVec(i))
+		d0 += math.Pow(x.AtVec(i)/scale, 2)
+		d1 += math.Pow(fx.AtVec(i)/scale, 2)
+	}
+	d0, d1 = math.Sqrt(d0/float64(x.Len())), math.Sqrt(d1/float64(x.Len()))
+
+	h := 1e-6
+	if (d0 >= 1e-5) && (d1 >= 1e-5) {
+		h = 0.01 * d0 / d1
+	}
+	return math.Min(h, options.MaxStep)
+}
+
+/*
+scaledErrorNorm
+Description:
+
+	Returns the largest entry of the local error estimate scaled by AbsTol + RelTol max(|x_i|, |xNew_i|). The step is
+	accepted when the norm is at most one.
+*/
+func scaledErrorNorm(errorVector, x, xNew *mat.VecDense, options Options) float64 {
+	// Algorithm
+	norm := 0.0
+	for i := 0; i < x.Len(); i++ {
+		scale := options.AbsTol + options.RelTol*math.Max(math.Abs(x.AtVec(i)), math.Abs(xNew.AtVec(i)))
+		value := math.Abs(errorVector.AtVec(i)) / scale
+		if math.IsNaN(value) {
+			return math.NaN()
+		}
+		norm = math.Max(norm, value)
+	}
+	return norm
+}
+
+/*
+interpolate
+Description:
+
+	Evaluates the cubic Hermite interpolant of the step at time t.
+*/
+func (s segment) interpolate(t float64) *mat.VecDense {
+	// Constants
+	h := s.t1 - s.t0
+	theta := (t - s.t0) / h
+
+	// Algorithm
+	h00 := (1 + 2*theta) * (1 - theta) * (1 - theta)
+	h10 := theta * (1 - theta) * (1 - theta)
+	h01 := theta * theta * (3 - 2*theta)
+	h11 := theta * theta * (theta - 1)
+
+	x := mat.NewVecDense(s.x0.Len(), nil)
+	x.AddScaledVec(x, h00, s.x0)
+	x.AddScaledVec(x, h10*h, s.f0)
+	x.AddScaledVec(x, h01, s.x1)
+	x.AddScaledVec(x, h11*h, s.f1)
+	return x
+}
+
+/*
+locateEvents
+Description:
+
+	Finds the zero-crossings of the event functions during the step with the Illinois variant of the regula falsi
+	method on the dense output. The values of the event functions at the start of the step are replaced by their
+	values at the end of the step. Returns the crossings in chronological order up to the first terminal one, and
+	that terminal crossing (nil if there is none).
+*/
+func locateEvents(events []Event, values []float64, step segment) ([]EventRecord, *EventRecord) {
+	// Algorithm
+	records := []EventRecord{}
+	for i, event := range events {
+		gStart := values[i]
+		gEnd := event.Function(step.t1, step.x1)
+		values[i] = gEnd
+
+		rising := (gStart < 0) && (gEnd >= 0)
+		falling := (gStart > 0) && (gEnd <= 0)
+		if !((rising && (event.Direction >= 0)) || (falling && (event.Direction <= 0))) {
+			continue
+		}
+
+		tEvent := findCrossing(func(t float64) float64 { return event.Function(t, step.interpolate(t)) }, step.t0, step.t1, gStart, gEnd)
+		state := step.x1
+		if tEvent < step.t1 {
+			state = step.interpolate(tEvent)
+		}
+		records = append(records, EventRecord{Time: tEvent, State: state, Index: i})
+	}
+
+	sort.SliceStable(records, func(a, b int) bool { return records[a].Time < records[b].Time })
+	for k, record := range records {
+		if events[record.Index].Terminal {
+			terminal := record
+			return records[:k+1], &terminal
+		}
+	}
+	return records, nil
+}
+
+/*
+findCrossing
+Description:
+
+	Locates a zero of g in [a, b], where g(a) = ga and g(b) = gb have different signs (or gb is zero), with the
+	Illinois method. Returns the end of the final bracket on the side of b, so that the sign of g has changed at the
+	returned time.
+*/
+func findCrossing(g func(t float64) float64, a, b, ga, gb float64) float64 {
+	// Constants
+	tolerance := 4 * machineEpsilon * math.Max(math.Abs(a), math.Abs(b))
+	tolerance = math.Max(tolerance, 1e-14*(b-a))
+
+	// Algorithm
+	side := 0
+	for iteration := 0; (iteration < 100) && (b-a > tolerance) && (gb != 0); iteration++ {
+		c := b - gb*(b-a)/(gb-ga)
+		if !(c > a) || !(c < b) {
+			c = (a + b) / 2
+		}
+		gc := g(c)
+		if (gc == 0) || ((gc > 0) == (gb > 0)) {
+			b, gb = c, gc
+			if side == -1 {
+				ga /= 2
+			}
+			side = -1
+		} else {
+			a, ga = c, gc
+			if side == 1 {
+				gb /= 2
+			}
+			side = 1
+		}
+	}
+	return b
+}
+
+/*
+isFinite
+Description:
+
+	Returns true if x is neither infinite nor NaN.
+*/
+func isFinite(x float64) bool {
+	return !math.IsInf(x, 0) && !math.IsNaN(x)
+}
@@ -0,0 +1,156 @@
+/*
+   rosenbrock.go
+   Description:
+       The linearly implicit Rosenbrock method of order 2(3) of Shampine and Reichelt (used by MATLAB's ode23s) for
+       stiff systems, with finite difference Jacobians.
+*/
+
+package ode
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Coefficients of the modified Rosenbrock triple of Shampine and Reichelt
+var (
+	rosenbrockD   = 1 / (2 + math.Sqrt2)
+	rosenbrockE32 = 6 + math.Sqrt2
+)
+
+/*
+rosenbrockStep
+Description:
+
+	Takes one step of length h of the Rosenbrock method from (t, x), where fx = f(t, x):
+		W = I - h d J,   T = df/dt,
+		W k1 = f(t, x) + h d T,
+		W (k2 - k1) = f(t + h/2, x + h/2 k1) - k1,
+		x_new = x + h k2,
+		W k3 = f(t + h, x_new) - e32 (k2 - f(t + h/2, x + h/2 k1)) - 2 (k1 - f(t, x)) + h d T,
+	with the local error estimate h/6 (k1 - 2 k2 + k3). The method is L-stable, so it takes large steps on stiff
+	problems. The Jacobian J = df/dx is computed with jacobian, or with forward differences when it is nil.
+*/
+func rosenbrockStep(f Function, jacobian Jacobian, t, h float64, x, fx *mat.VecDense) (xNew, fNew, errorVector *mat.VecDense, err error) {
+	// Constants
+	n := x.Len()
+	d := rosenbrockD
+
+	// Jacobians
+	var J *mat.Dense
+	if jacobian != nil {
+		if J, err = jacobian(t, x); err != nil {
+			return nil, nil, nil, err
+		}
+		if rows, cols := J.Dims(); (rows != n) || (cols != n) {
+			return nil, nil, nil, fmt.Errorf("The Jacobian must be %v x %v; received %v x %v.", n, n, rows, cols)
+		}
+	} else if J, err = finiteDifferenceJacobian(f, t, x, fx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	deltaT := math.Sqrt(machineEpsilon) * math.Max(math.Abs(t), 1)
+	fT, err := f(t+deltaT, x)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	T := mat.NewVecDense(n, nil)
+	T.SubVec(fT, fx)
+	T.ScaleVec(h*d/deltaT, T) // h d df/dt
+
+	// Algorithm
+	W := mat.NewDense(n, n, nil)
+	W.Scale(-h*d, J)
+	for i := 0; i < n; i++ {
+		W.Set(i, i, W.At(i, i)+1)
+	}
+	var lu mat.LU
+	lu.Factorize(W)
+	if cond := lu.Cond(); math.IsInf(cond, 1) || (cond > 1/machineEpsilon) {
+		return nil, nil, nil, fmt.Errorf("The Rosenbrock iteration matrix is singular at time %v.", t)
+	}
+	solve := func(rhs *mat.VecDense) (*mat.VecDense, error) {
+		var k mat.VecDense
+		if err := lu.SolveVecTo(&k, false, rhs); err != nil {
+			return nil, err
+		}
+		return &k, nil
+	}
+
+	rhs := mat.NewVecDense(n, nil)
+	rhs.AddVec(fx, T)
+	k1, err := solve(rhs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var stage mat.VecDense
+	stage.AddScaledVec(x, h/2, k1)
+	f1, err := f(t+h/2, &stage)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rhs.SubVec(f1, k1)
+	k2, err := solve(rhs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	k2.AddVec(k2, k1)
+
+	xNew = mat.NewVecDense(n, nil)
+	xNew.AddScaledVec(x, h, k2)
+	if fNew, err = f(t+h, xNew); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// rhs = fNew - e32 (k2 - f1) - 2 (k1 - fx) + T
+	rhs.CopyVec(fNew)
+	rhs.AddScaledVec(rhs, -rosenbrockE32, k2)
+	rhs.AddScaledVec(rhs, rosenbrockE32, f1)
+	rhs.AddScaledVec(rhs, -2, k1)
+	rhs.AddScaledVec(rhs, 2, fx)
+	rhs.AddVec(rhs, T)
+	k3, err := solve(rhs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	errorVector = mat.NewVecDense(n, nil)
+	errorVector.AddScaledVec(k1, -2, k2)
+	errorVector.AddVec(errorVector, k3)
+	errorVector.ScaleVec(h/6, errorVector)
+
+	return xNew, fNew, errorVector, nil
+}
+
+/*
+finiteDifferenceJacobian
+Description:
+
+	Approximates the Jacobian df/dx at (t, x), where fx = f(t, x), with forward differences whose increments are
+	sqrt(eps) max(|x_j|, 1).
+*/
+func finiteDifferenceJacobian(f Function, t float64, x, fx *mat.VecDense) (*mat.Dense, error) {
+	// Constants
+	n := x.Len()
+
+	// Algorithm
+	J := mat.NewDense(n, n, nil)
+	perturbed := mat.VecDenseCopyOf(x)
+	for j := 0; j < n; j++ {
+		delta := math.Sqrt(machineEpsilon) * math.Max(math.Abs(x.AtVec(j)), 1)
+		perturbed.SetVec(j, x.AtVec(j)+delta)
+		fj, err := f(t, perturbed)
+		if err != nil {
+			return nil, err
+		}
+		perturbed.SetVec(j, x.AtVec(j))
+
+		for i := 0; i < n; i++ {
+			J.Set(i, j, (fj.AtVec(i)-fx.AtVec(i))/delta)
+		}
+	}
+	return J, nil
+}
@@ -0,0 +1,107 @@
+/*
+   runge_kutta.go
+   Description:
+       Explicit Runge-Kutta steps: the classical fourth-order method and the Dormand-Prince pair of order 5(4).
+*/
+
+package ode
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// Butcher tableau of the Dormand-Prince method (the last row of a is also the weights of the fifth-order solution).
+var (
+	dormandPrinceC = []float64{0, 1.0 / 5, 3.0 / 10, 4.0 / 5, 8.0 / 9, 1, 1}
+	dormandPrinceA = [][]float64{
+		{},
+		{1.0 / 5},
+		{3.0 / 40, 9.0 / 40},
+		{44.0 / 45, -56.0 / 15, 32.0 / 9},
+		{19372.0 / 6561, -25360.0 / 2187, 64448.0 / 6561, -212.0 / 729},
+		{9017.0 / 3168, -355.0 / 33, 46732.0 / 5247, 49.0 / 176, -5103.0 / 18656},
+		{35.0 / 384, 0, 500.0 / 1113, 125.0 / 192, -2187.0 / 6784, 11.0 / 84},
+	}
+	// Difference between the weights of the fifth- and fourth-order solutions
+	dormandPrinceE = []float64{71.0 / 57600, 0, -71.0 / 16695, 71.0 / 1920, -17253.0 / 339200, 22.0 / 525, -1.0 / 40}
+)
+
+/*
+RK4Step
+Description:
+
+	Takes one step of length h of the classical fourth-order Runge-Kutta method from (t, x), where fx = f(t, x).
+	Returns the new state and its derivative, which is the fx of the next step, so that a sequence of steps (for
+	example between the samples of a simulation) needs no Solution and four evaluations of f per step.
+*/
+func RK4Step(f Function, t, h float64, x, fx *mat.VecDense) (xNew, fNew *mat.VecDense, err error) {
+	// Algorithm
+	var stage mat.VecDense
+	stage.AddScaledVec(x, h/2, fx)
+	k2, err := f(t+h/2, &stage)
+	if err != nil {
+		return nil, nil, err
+	}
+	stage.AddScaledVec(x, h/2, k2)
+	k3, err := f(t+h/2, &stage)
+	if err != nil {
+		return nil, nil, err
+	}
+	stage.AddScaledVec(x, h, k3)
+	k4, err := f(t+h, &stage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	xNew = mat.VecDenseCopyOf(x)
+	xNew.AddScaledVec(xNew, h/6, fx)
+	xNew.AddScaledVec(xNew, h/3, k2)
+	xNew.AddScaledVec(xNew, h/3, k3)
+	xNew.AddScaledVec(xNew, h/6, k4)
+
+	fNew, err = f(t+h, xNew)
+	if err != nil {
+		return nil, nil, err
+	}
+	return xNew, fNew, nil
+}
+
+/*
+dormandPrinceStep
+Description:
+
+	Takes one step of length h of the Dormand-Prince method from (t, x), where fx = f(t, x). Returns the fifth-order
+	solution, its derivative (the last stage, which is reused as the first stage of the next step) and the estimate
+	of the local error (the difference with the fourth-order solution).
+*/
+func dormandPrinceStep(f Function, t, h float64, x, fx *mat.VecDense) (xNew, fNew, errorVector *mat.VecDense, err error) {
+	// Constants
+	stages := len(dormandPrinceC)
+
+	// Algorithm
+	k := make([]*mat.VecDense, stages)
+	k[0] = fx
+	for i := 1; i < stages; i++ {
+		stage := mat.VecDenseCopyOf(x)
+		for j, a := range dormandPrinceA[i] {
+			if a != 0 {
+				stage.AddScaledVec(stage, h*a, k[j])
+			}
+		}
+		if k[i], err = f(t+dormandPrinceC[i]*h, stage); err != nil {
+			return nil, nil, nil, err
+		}
+		if i == stages-1 {
+			xNew = stage
+		}
+	}
+
+	errorVector = mat.NewVecDense(x.Len(), nil)
+	for i, e := range dormandPrinceE {
+		if e != 0 {
+			errorVector.AddScaledVec(errorVector, h*e, k[i])
+		}
+	}
+
+	return xNew, k[stages-1], errorVector, nil
+}
@@ -0,0 +1,126 @@
+/*
+   sampled.go
+   Description:
+       Simulation of sampled-data closed loops: a continuous-time plant dx/dt = f(t, x, u) controlled by a
+       discrete-time control law (for example a PID or MPC controller) through a zero-order hold.
+*/
+
+package ode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Plant is the right-hand side f(t, x, u) of a continuous-time plant with input u.
+type Plant func(t float64, x, u mat.Vector) (*mat.VecDense, error)
+
+// ControlLaw returns the input applied from the sample time t, given the state x at that time.
+type ControlLaw func(t float64, x mat.Vector) (*mat.VecDense, error)
+
+type SampledSolution struct {
+	Solution                  // Continuous trajectory of the plant (every step of the method over all sample intervals)
+	SampleTimes *mat.VecDense // Sample times t_k = t0 + k Ts at which the control law was called
+	Inputs      *mat.Dense    // Row k is the input held on [t_k, t_{k+1})
+}
+
+/*
+SimulateSampled
+Description:
+
+	Simulates the plant dx/dt = f(t, x, u) from x(t0) = x0 until tFinal with the control law called every Ts
+	seconds; the input u_k = law(t_k, x(t_k)) is held constant on [t_k, t_{k+1}) and the plant is integrated over
+	each sample interval with the given options. A terminal event stops the whole simulation. The control law may keep
+	state (a PID controller's Update or an MPC controller's Control), since it is called once per sample, in order.
+*/
+func SimulateSampled(plant Plant, law ControlLaw, Ts, t0, tFinal float64, x0 mat.Vector, options Options) (SampledSolution, error) {
+	// Input Processing
+	if (plant == nil) || (law == nil) {
+		return SampledSolution{}, errors.New("The plant and the control law must not be nil.")
+	}
+	if !(Ts > 0) || math.IsInf(Ts, 0) {
+		return SampledSolution{}, fmt.Errorf("The sample time must be positive and finite; received %v.", Ts)
+	}
+	if !(tFinal > t0) || math.IsInf(tFinal-t0, 0) {
+		return SampledSolution{}, fmt.Errorf("The final time %v must be finite and larger than the initial time %v.", tFinal, t0)
+	}
+	if (x0 == nil) || (x0.Len() == 0) {
+		return SampledSolution{}, errors.New("The initial state must be a nonempty vector.")
+	}
+
+	// Constants
+	n := x0.Len()
+	samples := int(math.Ceil((tFinal-t0)/Ts - 1e-9))
+	if samples < 1 {
+		samples = 1
+	}
+
+	// Algorithm
+	result := SampledSolution{}
+	times, states := []float64{t0}, [][]float64{mat.Col(nil, 0, x0)}
+	sampleTimes, inputs := []float64{}, [][]float64{}
+
+	x := mat.VecDenseCopyOf(x0)
+	for k := 0; k < samples; k++ {
+		tk := t0 + float64(k)*Ts
+		tNext := t0 + float64(k+1)*Ts
+		if k == samples-1 {
+			tNext = tFinal
+		}
+
+		u, err := law(tk, x)
+		if err != nil {
+			return SampledSolution{}, fmt.Errorf("The control law failed at time %v: %v", tk, err)
+		}
+		if (u == nil) || (u.Len() == 0) {
+			return SampledSolution{}, fmt.Errorf("The control law returned no input at time %v.", tk)
+		}
+		if (len(inputs) > 0) && (u.Len() != len(inputs[0])) {
+			return SampledSolution{}, fmt.Errorf("The control law returned an input of length %v at time %v; expected %v.", u.Len(), tk, len(inputs[0]))
+		}
+		held := mat.VecDenseCopyOf(u)
+		sampleTimes, inputs = append(sampleTimes, tk), append(inputs, held.RawVector().Data)
+
+		interval, err := integrate(func(t float64, x mat.Vector) (*mat.VecDense, error) {
+			return plant(t, x, held)
+		}, tk, tNext, x, options)
+		if err != nil {
+			return SampledSolution{}, err
+		}
+
+		// Append the interval (its first point is the last point of the previous interval)
+		rows, _ := interval.State.Dims()
+		for i := 1; i < rows; i++ {
+			times = append(times, interval.Time.AtVec(i))
+			states = append(states, mat.Row(nil, i, interval.State))
+		}
+		result.segments = append(result.segments, interval.segments...)
+		result.Events = append(result.Events, interval.Events...)
+		result.Steps += interval.Steps
+		result.Rejected += interval.Rejected
+		result.Evaluations += interval.Evaluations
+
+		x = mat.NewVecDense(n, mat.Row(nil, rows-1, interval.State))
+		if interval.Terminated {
+			result.Terminated = true
+			break
+		}
+	}
+
+	// Output
+	result.Time = mat.NewVecDense(len(times), times)
+	result.State = mat.NewDense(len(times), n, nil)
+	for k, state := range states {
+		result.State.SetRow(k, state)
+	}
+	result.SampleTimes = mat.NewVecDense(len(sampleTimes), sampleTimes)
+	result.Inputs = mat.NewDense(len(inputs), len(inputs[0]), nil)
+	for k, input := range inputs {
+		result.Inputs.SetRow(k, input)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,362 @@
+/*
+   pid.go
+   Description:
+       Discrete-time PID controllers "like" MATLAB's pid and pidstd objects, with a filtered derivative, setpoint
+       weighting, anti-windup and bumpless transfer between manual and automatic modes. The controller state is
+       kept in the struct, so each call to Update runs without allocations.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type PIDForm int
+
+const (
+	ParallelForm PIDForm = iota // u = Kp e + Ki int(e) + Kd de/dt
+	IdealForm                   // u = Kp (e + 1/Ti int(e) + Td de/dt)
+)
+
+type AntiWindup int
+
+const (
+	NoAntiWindup              AntiWindup = iota // The integrator is never stopped
+	ClampingAntiWindup                          // The integrator is frozen while the output saturates in the direction of the error
+	BackCalculationAntiWindup                   // The integrator is driven by (u - v) / Tt, the difference between the saturated and unsaturated outputs
+)
+
+type PID struct {
+	Kp         float64    // Proportional gain (parallel form)
+	Ki         float64    // Integral gain (parallel form)
+	Kd         float64    // Derivative gain (parallel form)
+	Tf         float64    // Time constant of the first order derivative filter (0 means no filter)
+	Ts         float64    // Sample time
+	Form       PIDForm    // Form in which the gains were given; the gains are always stored in parallel form
+	B          float64    // Setpoint weight of the proportional term (1 by default)
+	C          float64    // Setpoint weight of the derivative term (1 by default)
+	UMin       float64    // Lower output limit (-Inf by default)
+	UMax       float64    // Upper output limit (+Inf by default)
+	AntiWindup AntiWindup // Anti-windup method (NoAntiWindup by default)
+	Tt         float64    // Tracking time constant of back-calculation (0 selects sqrt(Ti Td), or Ti without derivative action)
+
+	integral      float64 // Integral term
+	derivative    float64 // Filtered derivative term
+	previousError float64 // Previous derivative error C r - y
+	output        float64 // Last output
+	initialized   bool
+	manual        bool
+	manualOutput  float64
+}
+
+/*
+GetPID
+Description:
+
+	Creates a discrete-time PID controller in parallel form with sample time Ts,
+		C(s) = Kp + Ki / s + Kd s / (Tf s + 1),
+	whose integral is discretized with the forward Euler method and whose derivative is discretized with the
+	backward Euler method. A zero filter time constant gives the unfiltered backward difference.
+*/
+func GetPID(Kp, Ki, Kd, Tf, Ts float64) (PID, error) {
+	// Create Controller
+	pid := PID{
+		Kp:   Kp,
+		Ki:   Ki,
+		Kd:   Kd,
+		Tf:   Tf,
+		Ts:   Ts,
+		Form: ParallelForm,
+		B:    1,
+		C:    1,
+		UMin: math.Inf(-1),
+		UMax: math.Inf(1),
+	}
+
+	// Check it
+	if err := pid.Check(); err != nil {
+		return PID{}, err
+	}
+
+	return pid, nil
+}
+
+/*
+GetIdealPID
+Description:
+
+	Creates a discrete-time PID controller in ideal (standard) form with sample time Ts,
+		C(s) = Kp (1 + 1 / (Ti s) + Td s / (Td s / N + 1)).
+	Ti = +Inf removes the integral action and N = +Inf (or 0) removes the derivative filter.
+*/
+func GetIdealPID(Kp, Ti, Td, N, Ts float64) (PID, error) {
+	// Input Processing
+	if !(Ti > 0) {
+		return PID{}, fmt.Errorf("The integral time must be positive (or +Inf); received %v.", Ti)
+	}
+	if !(Td >= 0) || math.IsInf(Td, 0) {
+		return PID{}, fmt.Errorf("The derivative time must be nonnegative and finite; received %v.", Td)
+	}
+	if N < 0 || math.IsNaN(N) {
+		return PID{}, fmt.Errorf("The derivative filter divisor must be positive (or +Inf); received %v.", N)
+	}
+
+	// Algorithm
+	Ki := 0.0
+	if !math.IsInf(Ti, 1) {
+		Ki = Kp / Ti
+	}
+	Tf := 0.0
+	if (N > 0) && !math.IsInf(N, 1) {
+		Tf = Td / N
+	}
+
+	pid, err := GetPID(Kp, Ki, Kp*Td, Tf, Ts)
+	if err != nil {
+		return PID{}, err
+	}
+	pid.Form = IdealForm
+
+	return pid, nil
+}
+
+/*
+Check
+Description:
+
+	Returns an error if a gain is not finite, if the sample time or the filter time constant is not valid, or if the
+	output limits are inconsistent.
+*/
+func (pid PID) Check() error {
+	// Input Processing
+	for _, gain := range []struct {
+		value float64
+		name  string
+	}{
+		{pid.Kp, "proportional gain"},
+		{pid.Ki, "integral gain"},
+		{pid.Kd, "derivative gain"},
+		{pid.B, "proportional setpoint weight"},
+		{pid.C, "derivative setpoint weight"},
+	} {
+		if !isFinite(gain.value) {
+			return fmt.Errorf("The %v must be finite; received %v.", gain.name, gain.value)
+		}
+	}
+	if !(pid.Ts > 0) || math.IsInf(pid.Ts, 0) {
+		return fmt.Errorf("The sample time of a PID controller must be positive; received %v.", pid.Ts)
+	}
+	if !(pid.Tf >= 0) || math.IsInf(pid.Tf, 0) {
+		return fmt.Errorf("The filter time constant must be nonnegative and finite; received %v.", pid.Tf)
+	}
+	if math.IsNaN(pid.UMin) || math.IsNaN(pid.UMax) || (pid.UMin > pid.UMax) {
+		return fmt.Errorf("The output limits [%v, %v] are not valid.", pid.UMin, pid.UMax)
+	}
+	if !(pid.Tt >= 0) {
+		return fmt.Errorf("The tracking time constant must be nonnegative; received %v.", pid.Tt)
+	}
+	if (pid.AntiWindup < NoAntiWindup) || (pid.AntiWindup > BackCalculationAntiWindup) {
+		return fmt.Errorf("The anti-windup method %v is not recognized.", pid.AntiWindup)
+	}
+
+	return nil
+}
+
+/*
+IdealGains
+Description:
+
+	Returns the gains of the ideal form Kp (1 + 1 / (Ti s) + Td s / (Td s / N + 1)) of the controller.
+	Ti is +Inf without integral action and N is +Inf without derivative filter. Returns an error if the
+	proportional gain is zero while the integral or derivative gain is not.
+*/
+func (pid PID) IdealGains() (Kp, Ti, Td, N float64, err error) {
+	// Input Processing
+	if (pid.Kp == 0) && ((pid.Ki != 0) || (pid.Kd != 0)) {
+		return 0, 0, 0, 0, errors.New("A PID controller without proportional gain has no ideal form.")
+	}
+
+	// Algorithm
+	Kp, Ti, Td, N = pid.Kp, math.Inf(1), 0, math.Inf(1)
+	if pid.Ki != 0 {
+		Ti = pid.Kp / pid.Ki
+	}
+	if pid.Kp != 0 {
+		Td = pid.Kd / pid.Kp
+	}
+	if (pid.Tf > 0) && (Td != 0) {
+		N = Td / pid.Tf
+	}
+
+	return Kp, Ti, Td, N, nil
+}
+
+/*
+TransferFunction
+Description:
+
+	Returns the continuous-time transfer function Kp + Ki / s + Kd s / (Tf s + 1) of the controller. Without a
+	derivative filter the transfer function is improper when Kd is not zero.
+*/
+func (pid PID) TransferFunction() (TransferFunction, error) {
+	// Algorithm
+	num, den := pidPolynomials(pid.Kp, pid.Ki, pid.Kd, pid.Tf)
+	return GetTransferFunction(num, den)
+}
+
+/*
+Update
+Description:
+
+	Computes the output of the controller for the setpoint r and the measurement y, and advances its state by one
+	sample. The output is
+		v = Kp (B r - y) + I + D,   u = min(max(v, UMin), UMax),
+	where the integral I is updated with the error r - y (and the anti-windup correction) after computing the
+	output, and the derivative D is the filtered backward difference of C r - y. At the first call the derivative
+	is zero, which avoids a derivative kick. In manual mode the manual output is returned and the integral tracks
+	it, so that the switch back to automatic mode is bumpless.
+*/
+func (pid *PID) Update(r, y float64) float64 {
+	// Constants
+	e := r - y
+	derivativeError := pid.C*r - y
+
+	// Algorithm
+	if !pid.initialized {
+		pid.previousError = derivativeError
+		pid.initialized = true
+	}
+	pid.derivative = (pid.Tf*pid.derivative + pid.Kd*(derivativeError-pid.previousError)) / (pid.Tf + pid.Ts)
+	pid.previousError = derivativeError
+	proportional := pid.Kp * (pid.B*r - y)
+
+	if pid.manual {
+		pid.output = math.Min(math.Max(pid.manualOutput, pid.UMin), pid.UMax)
+		pid.integral = pid.output - proportional - pid.derivative
+		return pid.output
+	}
+
+	v := proportional + pid.integral + pid.derivative
+	u := math.Min(math.Max(v, pid.UMin), pid.UMax)
+
+	increment := pid.Ki * pid.Ts * e
+	switch pid.AntiWindup {
+	case ClampingAntiWindup:
+		if ((v > pid.UMax) && (increment > 0)) || ((v < pid.UMin) && (increment < 0)) {
+			increment = 0
+		}
+	case BackCalculationAntiWindup:
+		if Tt := pid.trackingTimeConstant(); Tt > 0 {
+			increment += pid.Ts / Tt * (u - v)
+		}
+	}
+	pid.integral += increment
+
+	pid.output = u
+	return u
+}
+
+/*
+SetManual
+Description:
+
+	Switches the controller to manual mode with the output u. The integral term tracks u, so that the switch back
+	to automatic mode does not cause a jump in the output.
+*/
+func (pid *PID) SetManual(u float64) {
+	pid.manual = true
+	pid.manualOutput = u
+}
+
+/*
+SetAutomatic
+Description:
+
+	Switches the controller back to automatic mode. The output continues from the last manual output.
+*/
+func (pid *PID) SetAutomatic() {
+	pid.manual = false
+}
+
+/*
+IsManual
+Description:
+
+	Returns true if the controller is in manual mode.
+*/
+func (pid PID) IsManual() bool {
+	return pid.manual
+}
+
+/*
+Output
+Description:
+
+	Returns the last output of the controller.
+*/
+func (pid PID) Output() float64 {
+	return pid.output
+}
+
+/*
+Reset
+Description:
+
+	Clears the integral and derivative terms and the last output. The mode is not changed.
+*/
+func (pid *PID) Reset() {
+	pid.integral = 0
+	pid.derivative = 0
+	pid.previousError = 0
+	pid.output = 0
+	pid.initialized = false
+}
+
+/*
+trackingTimeConstant
+Description:
+
+	Returns the tracking time constant of back-calculation: Tt if it is set, otherwise sqrt(Ti Td) (or Ti without
+	derivative action). Returns zero if the controller has no integral action.
+*/
+func (pid PID) trackingTimeConstant() float64 {
+	if pid.Tt > 0 {
+		return pid.Tt
+	}
+	if (pid.Ki == 0) || (pid.Kp == 0) {
+		return 0
+	}
+	Ti := math.Abs(pid.Kp / pid.Ki)
+	if pid.Kd == 0 {
+		return Ti
+	}
+	return math.Sqrt(Ti * math.Abs(pid.Kd/pid.Kp))
+}
+
+/*
+pidPolynomials
+Description:
+
+	Returns the numerator and denominator of Kp + Ki / s + Kd s / (Tf s + 1). The integrator is omitted when Ki is
+	zero and the filter is omitted when Tf is zero.
+*/
+func pidPolynomials(Kp, Ki, Kd, Tf float64) (num, den []float64) {
+	// Algorithm
+	filter := []float64{1}
+	if Tf > 0 {
+		filter = []float64{Tf, 1}
+	}
+
+	// Kp + Kd s / filter
+	num = polyAdd(polyScale(Kp, filter), []float64{Kd, 0})
+	den = filter
+	if Ki != 0 {
+		num = polyAdd(polyMul(num, []float64{1, 0}), polyScale(Ki, filter))
+		den = polyMul(den, []float64{1, 0})
+	}
+
+	return polyTrim(num), den
+}
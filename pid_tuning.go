@@ -0,0 +1,431 @@
+/*
+   pid_tuning.go
+   Description:
+       Tuning rules for PID controllers: the Ziegler-Nichols ultimate sensitivity rules, Skogestad's SIMC rules and
+       a loop-shaping search "like" MATLAB's pidtune function.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// pidFilterDivisor is the ratio N = Td / Tf of the derivative filters chosen by the tuning rules.
+const pidFilterDivisor = 10
+
+// defaultPIDTunePhaseMargin is the default target phase margin (degrees) of PIDTune.
+const defaultPIDTunePhaseMargin = 60
+
+// defaultPIDTuneMaxSensitivity is the default bound on the peak of the sensitivity function in PIDTune.
+const defaultPIDTuneMaxSensitivity = 2
+
+type PIDType int
+
+const (
+	PIDTypeP    PIDType = iota // Proportional
+	PIDTypePI                  // Proportional-integral
+	PIDTypePD                  // Proportional-derivative
+	PIDTypePID                 // Proportional-integral-derivative without derivative filter
+	PIDTypePIDF                // Proportional-integral-derivative with derivative filter
+)
+
+type SIMCModel struct {
+	Gain               float64 // Static gain k
+	TimeConstant       float64 // Dominant time constant tau1
+	SecondTimeConstant float64 // Second time constant tau2 (0 for a first order model)
+	Delay              float64 // Time delay theta
+}
+
+type PIDTuneOptions struct {
+	CrossoverFrequency float64 // Target gain crossover frequency (rad/s); 0 selects the best admissible one
+	PhaseMargin        float64 // Target phase margin in degrees (0 selects 60)
+	MaxSensitivity     float64 // Bound on the peak of |1 / (1 + L)| used in the search (0 selects 2)
+}
+
+type PIDTuneInfo struct {
+	CrossoverFrequency float64 // Gain crossover frequency of the loop (rad/s)
+	PhaseMargin        float64 // Smallest phase margin of the loop (degrees)
+	PeakSensitivity    float64 // Peak of |1 / (1 + L)| on the frequency grid
+	Stable             bool    // True if the closed loop is stable
+}
+
+/*
+UltimateGain
+Description:
+
+	Computes the ultimate gain Ku (the gain margin) and the ultimate period Pu = 2 pi / w180 of the SISO model sys,
+	where w180 is the phase crossover frequency. These are the parameters of the Ziegler-Nichols rules.
+*/
+func UltimateGain(sys StateSpace) (Ku, Pu float64, err error) {
+	// Algorithm
+	margins, err := Margin(sys)
+	if err != nil {
+		return 0, 0, err
+	}
+	if math.IsInf(margins.GainMargin, 1) || !(margins.PhaseCrossoverFrequency > 0) {
+		return 0, 0, errors.New("The model has no phase crossover, so its ultimate gain is not defined.")
+	}
+
+	return margins.GainMargin, 2 * math.Pi / margins.PhaseCrossoverFrequency, nil
+}
+
+/*
+ZieglerNichols
+Description:
+
+	Tunes a PID controller with sample time Ts from the ultimate gain Ku and the ultimate period Pu with the
+	closed-loop Ziegler-Nichols rules:
+		P:    Kp = 0.5 Ku
+		PI:   Kp = 0.45 Ku,  Ti = Pu / 1.2
+		PD:   Kp = 0.8 Ku,   Td = Pu / 8
+		PID:  Kp = 0.6 Ku,   Ti = Pu / 2,  Td = Pu / 8   (PIDF adds the filter N = 10)
+*/
+func ZieglerNichols(Ku, Pu float64, pidType PIDType, Ts float64) (PID, error) {
+	// Input Processing
+	if !(Ku > 0) || math.IsInf(Ku, 0) {
+		return PID{}, fmt.Errorf("The ultimate gain must be positive and finite; received %v.", Ku)
+	}
+	if !(Pu > 0) || math.IsInf(Pu, 0) {
+		return PID{}, fmt.Errorf("The ultimate period must be positive and finite; received %v.", Pu)
+	}
+
+	// Algorithm
+	switch pidType {
+	case PIDTypeP:
+		return GetIdealPID(0.5*Ku, math.Inf(1), 0, math.Inf(1), Ts)
+	case PIDTypePI:
+		return GetIdealPID(0.45*Ku, Pu/1.2, 0, math.Inf(1), Ts)
+	case PIDTypePD:
+		return GetIdealPID(0.8*Ku, math.Inf(1), Pu/8, math.Inf(1), Ts)
+	case PIDTypePID:
+		return GetIdealPID(0.6*Ku, Pu/2, Pu/8, math.Inf(1), Ts)
+	case PIDTypePIDF:
+		return GetIdealPID(0.6*Ku, Pu/2, Pu/8, pidFilterDivisor, Ts)
+	}
+
+	return PID{}, fmt.Errorf("The controller type %v is not recognized.", pidType)
+}
+
+/*
+SIMC
+Description:
+
+	Tunes a PID controller with sample time Ts for the model k exp(-theta s) / ((tau1 s + 1)(tau2 s + 1)) with
+	Skogestad's SIMC rules and the closed-loop time constant tauC (0 selects tauC = theta):
+		Kc = tau1 / (k (tauC + theta)),   tauI = min(tau1, 4 (tauC + theta)),   tauD = tau2.
+	The series form Kc (1 + 1 / (tauI s)) (1 + tauD s) is converted to parallel form. For P and PI controllers the
+	second time constant is split with the half rule (half is added to tau1 and half to theta).
+*/
+func SIMC(model SIMCModel, tauC float64, pidType PIDType, Ts float64) (PID, error) {
+	// Input Processing
+	k, tau1, tau2, theta := model.Gain, model.TimeConstant, model.SecondTimeConstant, model.Delay
+	if (k == 0) || !isFinite(k) {
+		return PID{}, fmt.Errorf("The model gain must be nonzero and finite; received %v.", k)
+	}
+	if !(tau1 > 0) || !(tau2 >= 0) || !(theta >= 0) || !isFinite(tau1+tau2+theta) {
+		return PID{}, errors.New("The time constants of the model must be positive (tau2 and the delay may be zero).")
+	}
+	if tau2 > tau1 {
+		tau1, tau2 = tau2, tau1
+	}
+	if tauC == 0 {
+		tauC = theta
+	}
+	if !(tauC > 0) || math.IsInf(tauC, 0) {
+		return PID{}, fmt.Errorf("The closed-loop time constant must be positive (give one for models without delay); received %v.", tauC)
+	}
+
+	// Algorithm
+	if (pidType == PIDTypeP) || (pidType == PIDTypePI) {
+		tau1, theta, tau2 = tau1+tau2/2, theta+tau2/2, 0
+	}
+	Kc := tau1 / (k * (tauC + theta))
+	tauI := math.Min(tau1, 4*(tauC+theta))
+
+	switch pidType {
+	case PIDTypeP:
+		return GetPID(Kc, 0, 0, 0, Ts)
+	case PIDTypePI:
+		return GetPID(Kc, Kc/tauI, 0, 0, Ts)
+	case PIDTypePD:
+		return GetPID(Kc, 0, Kc*tau2, 0, Ts)
+	case PIDTypePID, PIDTypePIDF:
+		Tf := 0.0
+		if pidType == PIDTypePIDF {
+			Tf = tau2 / pidFilterDivisor
+		}
+		return GetPID(Kc*(1+tau2/tauI), Kc/tauI, Kc*tau2, Tf, Ts)
+	}
+
+	return PID{}, fmt.Errorf("The controller type %v is not recognized.", pidType)
+}
+
+/*
+PIDTune
+Description:
+
+	Tunes a PID controller of type pidType with sample time Ts for the continuous-time SISO plant G by loop shaping
+	(see PIDTuneWithOptions), with a target phase margin of 60 degrees.
+*/
+func PIDTune(G TransferFunction, pidType PIDType, Ts float64) (PID, PIDTuneInfo, error) {
+	return PIDTuneWithOptions(G, pidType, Ts, PIDTuneOptions{})
+}
+
+/*
+PIDTuneWithOptions
+Description:
+
+	Tunes a PID controller for the continuous-time SISO plant G. At a candidate crossover frequency wc, the gains are
+	chosen so that the loop L = C G satisfies |L(j wc)| = 1 and has the target phase margin at wc:
+		PI:        Kp = Re(C*),  Ki = -wc Im(C*),   where C* = exp(j (PM - 180 deg)) / G(j wc)
+		PD:        Kp = Re(C*),  Kd = Im(C*) / wc
+		PID/PIDF:  Ti = 4 Td, with Td found by bisection so that the phase of C(j wc) matches C*
+		P:         Kp = 1 / |G(j wc)| (the phase margin is not assigned)
+	Without a given crossover frequency, the candidates are searched on a logarithmic grid covering the dynamics of
+	G. Among the candidates for which all gains have the same sign, the closed loop is stable, every phase margin
+	reaches the target and the sensitivity peak is below MaxSensitivity, the one with the largest integral gain
+	(the largest proportional gain for P and PD controllers) is kept, which gives the best rejection of load
+	disturbances for the required robustness.
+*/
+func PIDTuneWithOptions(G TransferFunction, pidType PIDType, Ts float64, options PIDTuneOptions) (PID, PIDTuneInfo, error) {
+	// Input Processing
+	if err := G.Check(); err != nil {
+		return PID{}, PIDTuneInfo{}, err
+	}
+	if !G.IsSISO() || G.IsDiscrete() || !G.IsProper() {
+		return PID{}, PIDTuneInfo{}, errors.New("PIDTune requires a proper continuous-time single-input single-output plant.")
+	}
+	if (pidType < PIDTypeP) || (pidType > PIDTypePIDF) {
+		return PID{}, PIDTuneInfo{}, fmt.Errorf("The controller type %v is not recognized.", pidType)
+	}
+
+	phaseMargin := options.PhaseMargin
+	if phaseMargin == 0 {
+		phaseMargin = defaultPIDTunePhaseMargin
+	}
+	if !(phaseMargin > 0) || !(phaseMargin < 90) {
+		return PID{}, PIDTuneInfo{}, fmt.Errorf("The target phase margin must be in (0, 90) degrees; received %v.", phaseMargin)
+	}
+	maxSensitivity := options.MaxSensitivity
+	if maxSensitivity == 0 {
+		maxSensitivity = defaultPIDTuneMaxSensitivity
+	}
+	if !(maxSensitivity > 1) {
+		return PID{}, PIDTuneInfo{}, fmt.Errorf("The bound on the sensitivity peak must be larger than 1; received %v.", maxSensitivity)
+	}
+
+	// Constants
+	num, den := G.Numerator[0][0], G.Denominator[0][0]
+	plant := func(w float64) complex128 {
+		s := complex(0, w)
+		return polyEval(num, s) / polyEval(den, s)
+	}
+
+	sys, err := Tf2ss(G)
+	if err != nil {
+		return PID{}, PIDTuneInfo{}, err
+	}
+	low, high, err := frequencyRange(sys)
+	if err != nil {
+		return PID{}, PIDTuneInfo{}, err
+	}
+	grid := logspace(math.Log10(low)-3, math.Log10(high)+3, pointsPerDecade)
+
+	// Given crossover frequency
+	if wc := options.CrossoverFrequency; wc != 0 {
+		if !(wc > 0) || math.IsInf(wc, 0) {
+			return PID{}, PIDTuneInfo{}, fmt.Errorf("The crossover frequency must be positive; received %v.", wc)
+		}
+		gains, ok := pidGainsAtCrossover(pidType, wc, plant(wc), phaseMargin)
+		if !ok {
+			return PID{}, PIDTuneInfo{}, fmt.Errorf("No %v controller has the target phase margin at the crossover frequency %v.", pidTypeName(pidType), wc)
+		}
+		info, err := pidLoopInfo(gains, num, den, plant, grid)
+		if err != nil {
+			return PID{}, PIDTuneInfo{}, err
+		}
+		pid, err := GetPID(gains[0], gains[1], gains[2], gains[3], Ts)
+		return pid, info, err
+	}
+
+	// Search the admissible crossover frequency with the best load disturbance rejection
+	var best [4]float64
+	var bestInfo PIDTuneInfo
+	bestObjective, found := 0.0, false
+	for _, wc := range logspace(math.Log10(low), math.Log10(high), 20) {
+		gains, ok := pidGainsAtCrossover(pidType, wc, plant(wc), phaseMargin)
+		if !ok {
+			continue
+		}
+		info, err := pidLoopInfo(gains, num, den, plant, grid)
+		if err != nil {
+			return PID{}, PIDTuneInfo{}, err
+		}
+		if !info.Stable || (info.PhaseMargin < phaseMargin-0.5) || (info.PeakSensitivity > maxSensitivity) {
+			continue
+		}
+		objective := math.Abs(gains[1])
+		if (pidType == PIDTypeP) || (pidType == PIDTypePD) {
+			objective = math.Abs(gains[0])
+		}
+		if !found || (objective > bestObjective) {
+			best, bestInfo, bestObjective, found = gains, info, objective, true
+		}
+	}
+	if !found {
+		return PID{}, PIDTuneInfo{}, fmt.Errorf("No %v controller satisfies the phase margin and sensitivity requirements.", pidTypeName(pidType))
+	}
+
+	pid, err := GetPID(best[0], best[1], best[2], best[3], Ts)
+	return pid, bestInfo, err
+}
+
+/*
+pidGainsAtCrossover
+Description:
+
+	Computes the gains [Kp, Ki, Kd, Tf] of a controller of type pidType such that C(j wc) G(j wc) has unit
+	magnitude and the phase PM - 180 degrees (see PIDTuneWithOptions). Returns false if no such gains with
+	the same sign exist.
+*/
+func pidGainsAtCrossover(pidType PIDType, wc float64, g complex128, phaseMargin float64) ([4]float64, bool) {
+	// Constants
+	if (g == 0) || cmplx.IsInf(g) || cmplx.IsNaN(g) {
+		return [4]float64{}, false
+	}
+	target := cmplx.Rect(1, (phaseMargin-180)*math.Pi/180) / g
+
+	// Normalize the sign so that the proportional gain is positive
+	sign := 1.0
+	if real(target) < 0 {
+		sign, target = -1, -target
+	}
+	if real(target) == 0 {
+		return [4]float64{}, false
+	}
+
+	// Algorithm
+	var gains [4]float64
+	switch pidType {
+	case PIDTypeP:
+		gains = [4]float64{cmplx.Abs(target), 0, 0, 0}
+	case PIDTypePI:
+		gains = [4]float64{real(target), -wc * imag(target), 0, 0}
+	case PIDTypePD:
+		gains = [4]float64{real(target), 0, imag(target) / wc, 0}
+	case PIDTypePID, PIDTypePIDF:
+		N := math.Inf(1)
+		if pidType == PIDTypePIDF {
+			N = pidFilterDivisor
+		}
+		// shape(Td) = 1 + 1 / (j wc 4 Td) + j wc Td / (1 + j wc Td / N)
+		shape := func(Td float64) complex128 {
+			jw := complex(0, wc)
+			return 1 + 1/(jw*complex(4*Td, 0)) + jw*complex(Td, 0)/(1+jw*complex(Td/N, 0))
+		}
+		phase := cmplx.Phase(target)
+		mismatch := func(Td float64) float64 { return cmplx.Phase(shape(Td)) - phase }
+
+		// Bracket the first crossing of the (initially increasing) phase of the shape
+		tds := logspace(math.Log10(1e-4/wc), math.Log10(1e4/wc), 20)
+		lowTd, highTd := -1.0, -1.0
+		for k := 1; k < len(tds); k++ {
+			if (mismatch(tds[k-1]) < 0) && (mismatch(tds[k]) >= 0) {
+				lowTd, highTd = tds[k-1], tds[k]
+				break
+			}
+		}
+		if lowTd < 0 {
+			return [4]float64{}, false
+		}
+		for iteration := 0; iteration < 60; iteration++ {
+			mid := math.Sqrt(lowTd * highTd)
+			if mismatch(mid) < 0 {
+				lowTd = mid
+			} else {
+				highTd = mid
+			}
+		}
+		Td := math.Sqrt(lowTd * highTd)
+		Kp := cmplx.Abs(target) / cmplx.Abs(shape(Td))
+		Tf := 0.0
+		if pidType == PIDTypePIDF {
+			Tf = Td / N
+		}
+		gains = [4]float64{Kp, Kp / (4 * Td), Kp * Td, Tf}
+	}
+
+	if (gains[0] < 0) || (gains[1] < 0) || (gains[2] < 0) || !isFinite(gains[0]+gains[1]+gains[2]) {
+		return [4]float64{}, false
+	}
+	for i := 0; i < 3; i++ {
+		gains[i] *= sign
+	}
+	return gains, true
+}
+
+/*
+pidLoopInfo
+Description:
+
+	Evaluates the loop L = C G of the controller with gains [Kp, Ki, Kd, Tf] and the plant num / den: the stability
+	of the closed loop (from the roots of den_C den + num_C num), the gain crossover frequency with the smallest
+	phase margin and the peak of the sensitivity function on the grid.
+*/
+func pidLoopInfo(gains [4]float64, num, den []float64, plant func(w float64) complex128, grid []float64) (PIDTuneInfo, error) {
+	// Constants
+	numC, denC := pidPolynomials(gains[0], gains[1], gains[2], gains[3])
+	loop := func(w float64) complex128 {
+		s := complex(0, w)
+		return polyEval(numC, s) / polyEval(denC, s) * plant(w)
+	}
+
+	// Stability of the closed loop
+	characteristic := polyAdd(polyMul(denC, den), polyMul(numC, num))
+	roots, err := polyRoots(characteristic)
+	if err != nil {
+		return PIDTuneInfo{}, err
+	}
+	info := PIDTuneInfo{
+		CrossoverFrequency: math.NaN(),
+		PhaseMargin:        math.Inf(1),
+		Stable:             true,
+	}
+	for _, root := range roots {
+		if real(root) >= -1e-9 {
+			info.Stable = false
+		}
+	}
+
+	// Phase margins and sensitivity peak
+	for _, w := range findCrossings(grid, func(w float64) float64 { return math.Log(cmplx.Abs(loop(w))) }) {
+		pm := cmplx.Phase(loop(w))*180/math.Pi + 180
+		if pm > 180 {
+			pm -= 360
+		}
+		if math.Abs(pm) < math.Abs(info.PhaseMargin) {
+			info.PhaseMargin, info.CrossoverFrequency = pm, w
+		}
+	}
+	for _, w := range grid {
+		if sensitivity := 1 / cmplx.Abs(1+loop(w)); isFinite(sensitivity) {
+			info.PeakSensitivity = math.Max(info.PeakSensitivity, sensitivity)
+		}
+	}
+
+	return info, nil
+}
+
+/*
+pidTypeName
+Description:
+
+	Returns the name of the controller type.
+*/
+func pidTypeName(pidType PIDType) string {
+	return [...]string{"P", "PI", "PD", "PID", "PIDF"}[pidType]
+}
@@ -0,0 +1,491 @@
+/*
+   pole_placement.go
+   Description:
+       Pole placement by state feedback "like" MATLAB's place and acker functions.
+       Observer gains are obtained by duality: the gain L that places the eigenvalues of A - L C
+       is the transpose of the state feedback gain computed for the pair (A^T, C^T).
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// placeMaxSweeps is the maximum number of sweeps of the Kautsky-Nichols-Van Dooren iteration.
+const placeMaxSweeps = 50
+
+/*
+Acker
+Description:
+
+	Computes the gain K such that the eigenvalues of A - B K are the given poles, using Ackermann's formula
+		K = [0, ..., 0, 1] Ctrb(A,B)^{-1} phi(A),
+	where phi is the desired characteristic polynomial. Only single-input models are supported. The formula is
+	numerically unreliable for models of high order or that are close to uncontrollable; prefer Place in that case.
+*/
+func Acker(A, B mat.Matrix, poles []complex128) (*mat.Dense, error) {
+	// Input Processing
+	n, m, err := checkPlacementInputs(A, B, poles)
+	if err != nil {
+		return nil, err
+	}
+	if m != 1 {
+		return nil, fmt.Errorf("Acker only supports single-input models; B has %v columns. Use Place instead.", m)
+	}
+
+	// Algorithm
+	Co, err := Ctrb(A, B)
+	if err != nil {
+		return nil, err
+	}
+
+	// phi(A) by Horner's method
+	phi := polyFromRoots(poles)
+	phiA := mat.NewDense(n, n, nil)
+	for _, coefficient := range phi {
+		var product mat.Dense
+		product.Mul(phiA, A)
+		phiA.Add(&product, scaledEye(n, coefficient))
+	}
+
+	// K is the last row of Co^{-1} phi(A)
+	var CoInvPhi mat.Dense
+	if err := CoInvPhi.Solve(Co, phiA); err != nil {
+		return nil, fmt.Errorf("The pair (A,B) is not controllable: %v", err)
+	}
+
+	return mat.DenseCopyOf(CoInvPhi.Slice(n-1, n, 0, n)), nil
+}
+
+/*
+Place
+Description:
+
+	Computes the gain K such that the eigenvalues of A - B K are the given poles, using the robust eigenstructure
+	assignment method of Kautsky, Nichols and Van Dooren (method 0). Among all of the gains that place the poles,
+	the method looks for one whose closed-loop eigenvector matrix is well conditioned, which makes the closed-loop
+	eigenvalues insensitive to perturbations.
+	A pole can be repeated at most rank(B) times. Complex poles must appear in conjugate pairs.
+*/
+func Place(A, B mat.Matrix, poles []complex128) (*mat.Dense, error) {
+	// Input Processing
+	n, m, err := checkPlacementInputs(A, B, poles)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := groupPlacementPoles(poles)
+	for _, group := range groups {
+		if group.multiplicity > m {
+			return nil, fmt.Errorf("The pole %v is repeated %v times, but a pole can be repeated at most rank(B) = %v times.", group.pole, group.multiplicity, m)
+		}
+	}
+
+	// Decompose B = [U0, U1] [Z; 0]
+	var qr mat.QR
+	qr.Factorize(mat.DenseCopyOf(B))
+	var Q, RB mat.Dense
+	qr.QTo(&Q)
+	qr.RTo(&RB)
+	U0 := Q.Slice(0, n, 0, m)
+	Z := RB.Slice(0, m, 0, m)
+
+	// Compute the subspaces S_j in which the closed-loop eigenvectors must lie
+	var U1 mat.Matrix
+	if m < n {
+		U1 = Q.Slice(0, n, m, n)
+	}
+	for i := range groups {
+		groups[i].basis, err = placementSubspace(A, U1, groups[i].pole, n, m)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Choose the eigenvectors
+	X := knvEigenvectors(groups, n)
+
+	// Form M = X Lambda X^{-1}, which will be the closed-loop matrix A - B K
+	Lambda := mat.NewDense(n, n, nil)
+	for _, group := range groups {
+		j := group.column
+		if group.size == 1 {
+			Lambda.Set(j, j, real(group.pole))
+			continue
+		}
+		sigma, omega := real(group.pole), imag(group.pole)
+		Lambda.Set(j, j, sigma)
+		Lambda.Set(j, j+1, omega)
+		Lambda.Set(j+1, j, -omega)
+		Lambda.Set(j+1, j+1, sigma)
+	}
+
+	var XLambda, Mt mat.Dense
+	XLambda.Mul(X, Lambda)
+	if err := Mt.Solve(X.T(), XLambda.T()); err != nil {
+		return nil, fmt.Errorf("The closed-loop eigenvectors are linearly dependent; the poles cannot be placed: %v", err)
+	}
+
+	// K = Z^{-1} U0^T (A - M)
+	var AMinusM, U0tAM, K mat.Dense
+	AMinusM.Sub(A, Mt.T())
+	U0tAM.Mul(U0.T(), &AMinusM)
+	if err := K.Solve(Z, &U0tAM); err != nil {
+		return nil, err
+	}
+
+	// Check the result
+	if err := checkPlacedPoles(A, B, &K, poles); err != nil {
+		return nil, err
+	}
+
+	return &K, nil
+}
+
+/*
+checkPlacementInputs
+Description:
+
+	Verifies the dimensions of A and B, that the poles are conjugate symmetric and that (A,B) is controllable
+	with an input matrix of full column rank. Returns the number of states n and inputs m.
+*/
+func checkPlacementInputs(A, B mat.Matrix, poles []complex128) (n, m int, err error) {
+	// Check dimensions
+	n, nA := A.Dims()
+	nB, m := B.Dims()
+	if (n != nA) || (nB != n) {
+		return 0, 0, errors.New("The dimensions of A and B are not compatible.")
+	}
+	if len(poles) != n {
+		return 0, 0, fmt.Errorf("Received %v poles; expected one pole per state (%v).", len(poles), n)
+	}
+	if !hasConjugateSymmetry(poles) {
+		return 0, 0, errors.New("Complex poles must appear in conjugate pairs.")
+	}
+
+	// Check the input matrix and controllability
+	rankB, err := Rank(B, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	if rankB < m {
+		return 0, 0, fmt.Errorf("The matrix B must have full column rank; its rank is %v but it has %v columns.", rankB, m)
+	}
+
+	uncontrollable, err := PBHUncontrollableModes(A, B, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(uncontrollable) > 0 {
+		return 0, 0, fmt.Errorf("The pair (A,B) has the uncontrollable modes %v, which cannot be moved by state feedback.", uncontrollable)
+	}
+
+	return n, m, nil
+}
+
+type placementGroup struct {
+	pole         complex128 // Real pole, or the member of a conjugate pair with positive imaginary part
+	size         int        // 1 for a real pole and 2 for a conjugate pair
+	column       int        // Index of the first column of the group in the eigenvector matrix
+	occurrence   int        // Number of earlier groups with the same pole
+	multiplicity int        // Number of groups with the same pole
+	basis        *mat.Dense // Orthonormal basis of S_j (real, or the real embedding [Re; Im] of a complex subspace)
+}
+
+/*
+groupPlacementPoles
+Description:
+
+	Groups the (conjugate symmetric) poles into real poles and conjugate pairs, and counts how many times
+	each pole is repeated.
+*/
+func groupPlacementPoles(poles []complex128) []placementGroup {
+	// Algorithm
+	var groups []placementGroup
+	column := 0
+	for _, pole := range poles {
+		tol := 1e-9 * math.Max(1, cmplx.Abs(pole))
+		switch {
+		case math.Abs(imag(pole)) <= tol:
+			groups = append(groups, placementGroup{pole: complex(real(pole), 0), size: 1, column: column})
+			column++
+		case imag(pole) > 0:
+			groups = append(groups, placementGroup{pole: pole, size: 2, column: column})
+			column += 2
+		}
+	}
+
+	for i := range groups {
+		for k := range groups {
+			if cmplx.Abs(groups[k].pole-groups[i].pole) > 1e-9*math.Max(1, cmplx.Abs(groups[i].pole)) {
+				continue
+			}
+			groups[i].multiplicity++
+			if k < i {
+				groups[i].occurrence++
+			}
+		}
+	}
+
+	return groups
+}
+
+/*
+placementSubspace
+Description:
+
+	Computes an orthonormal basis of S = { x : U1^T (A - lambda I) x = 0 }, the set of possible closed-loop
+	eigenvectors for the pole lambda (S is the whole space when m = n, in which case U1 is nil). For complex poles, the basis is computed for the real embedding
+	[Re x; Im x] of the complex subspace and the columns are ordered as complex-orthonormal pairs (v, J v),
+	where J [a; b] = [-b; a] is multiplication by i.
+*/
+func placementSubspace(A mat.Matrix, U1 mat.Matrix, lambda complex128, n, m int) (*mat.Dense, error) {
+	// Real poles
+	if imag(lambda) == 0 {
+		if m == n {
+			return eye(n), nil
+		}
+		var M mat.Dense
+		M.Sub(A, scaledEye(n, real(lambda)))
+		var U1tM mat.Dense
+		U1tM.Mul(U1.T(), &M)
+		return nullSpace(&U1tM, 0)
+	}
+
+	// Complex poles: null space of the real embedding of U1^T (A - lambda I)
+	var N *mat.Dense
+	if m == n {
+		N = eye(2 * n)
+	} else {
+		var Mr, U1tMr, U1tI mat.Dense
+		Mr.Sub(A, scaledEye(n, real(lambda)))
+		U1tMr.Mul(U1.T(), &Mr)
+		U1tI.Scale(-imag(lambda), U1.T())
+
+		negU1tI := mat.NewDense(n-m, n, nil)
+		negU1tI.Scale(-1, &U1tI)
+		embedding := vstack(hstack(&U1tMr, negU1tI), hstack(&U1tI, &U1tMr))
+
+		var err error
+		N, err = nullSpace(embedding, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Complex Gram-Schmidt: build pairs (v, J v) that are orthonormal in the real embedding
+	var basis []*mat.VecDense
+	_, cols := N.Dims()
+	for c := 0; c < cols && len(basis) < 2*m; c++ {
+		v := mat.VecDenseCopyOf(N.ColView(c))
+		for _, w := range basis {
+			v.AddScaledVec(v, -mat.Dot(v, w), w)
+		}
+		if norm := v.Norm(2); norm > 1e-8 {
+			v.ScaleVec(1/norm, v)
+			basis = append(basis, v, multiplyByI(v))
+		}
+	}
+
+	out := mat.NewDense(2*n, len(basis), nil)
+	for c, v := range basis {
+		out.SetCol(c, v.RawVector().Data)
+	}
+	return out, nil
+}
+
+/*
+knvEigenvectors
+Description:
+
+	Chooses the closed-loop eigenvectors with the Kautsky-Nichols-Van Dooren method 0: each column (or pair of
+	columns for a complex pole) is replaced, in turn, by the projection onto S_j of a vector orthogonal to all of the
+	other columns, until the determinant of the (column normalized) eigenvector matrix stops increasing.
+	For a complex pole with eigenvector x, the columns are Re x and Im x.
+*/
+func knvEigenvectors(groups []placementGroup, n int) *mat.Dense {
+	// Initial eigenvectors: a different basis vector for each repetition of a pole
+	X := mat.NewDense(n, n, nil)
+	for _, group := range groups {
+		_, cols := group.basis.Dims()
+		c := (group.size * group.occurrence) % cols
+		setPlacementColumns(X, group, mat.VecDenseCopyOf(group.basis.ColView(c)), n)
+	}
+
+	// Sweeps
+	previousDet := 0.0
+	for sweep := 0; sweep < placeMaxSweeps; sweep++ {
+		for _, group := range groups {
+			Y := complementOfOtherColumns(X, group.column, group.size, n)
+
+			if group.size == 1 {
+				var coordinates, projection mat.VecDense
+				coordinates.MulVec(group.basis.T(), Y.ColView(0))
+				projection.MulVec(group.basis, &coordinates)
+				if projection.Norm(2) > 1e-10 {
+					setPlacementColumns(X, group, &projection, n)
+				}
+				continue
+			}
+
+			// Project y1 + i y2 and y1 - i y2 onto S_j and keep the larger projection
+			var best *mat.VecDense
+			for _, sign := range []float64{1, -1} {
+				z := mat.NewVecDense(2*n, nil)
+				for i := 0; i < n; i++ {
+					z.SetVec(i, Y.At(i, 0))
+					z.SetVec(n+i, sign*Y.At(i, 1))
+				}
+				var coordinates, projection mat.VecDense
+				coordinates.MulVec(group.basis.T(), z)
+				projection.MulVec(group.basis, &coordinates)
+				if (best == nil) || (projection.Norm(2) > best.Norm(2)) {
+					best = &projection
+				}
+			}
+			if best.Norm(2) > 1e-10 {
+				setPlacementColumns(X, group, best, n)
+			}
+		}
+
+		det := math.Abs(mat.Det(X))
+		if math.Abs(det-previousDet) <= 1e-8*math.Max(det, eps) {
+			break
+		}
+		previousDet = det
+	}
+
+	return X
+}
+
+/*
+setPlacementColumns
+Description:
+
+	Normalizes the eigenvector v and stores it in the columns of X that belong to group. For complex poles, v is
+	the real embedding [Re x; Im x] and the phase of x is chosen so that Re x and Im x are orthogonal.
+*/
+func setPlacementColumns(X *mat.Dense, group placementGroup, v *mat.VecDense, n int) {
+	if group.size == 1 {
+		for i := 0; i < n; i++ {
+			X.Set(i, group.column, v.AtVec(i)/v.Norm(2))
+		}
+		return
+	}
+
+	xr := mat.NewVecDense(n, nil)
+	xi := mat.NewVecDense(n, nil)
+	for i := 0; i < n; i++ {
+		xr.SetVec(i, v.AtVec(i))
+		xi.SetVec(i, v.AtVec(n+i))
+	}
+
+	// Rotate x by exp(i phi) so that its real and imaginary parts are orthogonal
+	phi := 0.5 * math.Atan2(-2*mat.Dot(xr, xi), mat.Dot(xr, xr)-mat.Dot(xi, xi))
+	c, s := math.Cos(phi), math.Sin(phi)
+	norm := v.Norm(2)
+	for i := 0; i < n; i++ {
+		re, im := xr.AtVec(i), xi.AtVec(i)
+		X.Set(i, group.column, (c*re-s*im)/norm)
+		X.Set(i, group.column+1, (s*re+c*im)/norm)
+	}
+}
+
+/*
+complementOfOtherColumns
+Description:
+
+	Returns size orthonormal vectors that are orthogonal to every column of X except for the columns
+	start, ..., start+size-1.
+*/
+func complementOfOtherColumns(X *mat.Dense, start, size, n int) *mat.Dense {
+	if size == n {
+		return eye(n)
+	}
+
+	others := mat.NewDense(n, n-size, nil)
+	for j, k := 0, 0; j < n; j++ {
+		if (j >= start) && (j < start+size) {
+			continue
+		}
+		others.SetCol(k, mat.Col(nil, j, X))
+		k++
+	}
+
+	complement, _ := nullSpace(others.T(), 0)
+	return mat.DenseCopyOf(complement.Slice(0, n, 0, size))
+}
+
+/*
+checkPlacedPoles
+Description:
+
+	Returns an error if an eigenvalue of A - B K differs from the nearest requested pole by more than 10%.
+*/
+func checkPlacedPoles(A, B, K mat.Matrix, poles []complex128) error {
+	// Constants
+	var BK, Acl mat.Dense
+	BK.Mul(B, K)
+	Acl.Sub(A, &BK)
+
+	// Algorithm
+	achieved, err := eigenvalues(&Acl)
+	if err != nil {
+		return err
+	}
+
+	used := make([]bool, len(achieved))
+	for _, pole := range poles {
+		nearest := -1
+		for k, value := range achieved {
+			if !used[k] && ((nearest < 0) || (cmplx.Abs(value-pole) < cmplx.Abs(achieved[nearest]-pole))) {
+				nearest = k
+			}
+		}
+		used[nearest] = true
+
+		if cmplx.Abs(achieved[nearest]-pole) > 0.1*math.Max(cmplx.Abs(pole), 1) {
+			return fmt.Errorf("The achieved pole %v is more than 10%% away from the requested pole %v.", achieved[nearest], pole)
+		}
+	}
+
+	return nil
+}
+
+/*
+multiplyByI
+Description:
+
+	Returns J v = [-b; a] for the real embedding v = [a; b] of a complex vector, i.e. the embedding of i (a + i b).
+*/
+func multiplyByI(v *mat.VecDense) *mat.VecDense {
+	// Constants
+	twoN := v.Len()
+	n := twoN / 2
+
+	// Algorithm
+	out := mat.NewVecDense(twoN, nil)
+	for i := 0; i < n; i++ {
+		out.SetVec(i, -v.AtVec(n+i))
+		out.SetVec(n+i, v.AtVec(i))
+	}
+	return out
+}
+
+/*
+scaledEye
+Description:
+
+	Returns alpha times the n x n identity matrix.
+*/
+func scaledEye(n int, alpha float64) *mat.Dense {
+	I := eye(n)
+	I.Scale(alpha, I)
+	return I
+}
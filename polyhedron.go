@@ -9,10 +9,14 @@ package goControl
 import (
 	"errors"
 	"fmt"
+	"math"
 
 	"gonum.org/v1/gonum/mat"
 )
 
+// polyhedronTolerance is the tolerance of the containment and redundancy tests.
+const polyhedronTolerance = 1e-9
+
 type Polyhedron struct {
 	A mat.Matrix
 	b mat.Vector
@@ -106,19 +110,49 @@ func (polyhedronIn Polyhedron) Check() error {
 }
 
 /*
-	Contains
-	Description:
-		Returns true if:
-		- the provided point is in the the target polyhedron, ...
+Contains
+Description:
 
+	Returns true if:
+	- the provided point (a mat.Vector) is in the target polyhedron, or
+	- the provided Polyhedron is a subset of the target polyhedron (the support function of the provided
+	  Polyhedron in the direction of each row of A is at most the corresponding entry of b).
+	The comparisons allow a small tolerance relative to the entries of b.
 */
-
 func (polyhedronIn Polyhedron) Contains(targetObject interface{}) bool {
+	// Input Processing
+	if polyhedronIn.Check() != nil {
+		return false
+	}
+	M, N := polyhedronIn.A.Dims()
 
 	// Check the type of the input object
 	switch v := targetObject.(type) {
 	case mat.Vector:
-		print(v)
+		if v.Len() != N {
+			return false
+		}
+		var Ax mat.VecDense
+		Ax.MulVec(polyhedronIn.A, v)
+		for i := 0; i < M; i++ {
+			if Ax.AtVec(i) > polyhedronIn.b.AtVec(i)+polyhedronTolerance*(1+math.Abs(polyhedronIn.b.AtVec(i))) {
+				return false
+			}
+		}
+		return true
+	case Polyhedron:
+		if v.Dimension() != N {
+			return false
+		}
+		if empty, err := v.IsEmptySet(); (err == nil) && empty {
+			return true
+		}
+		for i := 0; i < M; i++ {
+			support, err := v.Support(polyhedronIn.row(i))
+			if (err != nil) || (support > polyhedronIn.b.AtVec(i)+polyhedronTolerance*(1+math.Abs(polyhedronIn.b.AtVec(i)))) {
+				return false
+			}
+		}
 		return true
 	default:
 		//
@@ -127,3 +161,476 @@ func (polyhedronIn Polyhedron) Contains(targetObject interface{}) bool {
 	}
 
 }
+
+/*
+Support
+Description:
+
+	Computes the support function max { d^T x : x in P } of the Polyhedron in the direction d.
+	Returns +Inf if the Polyhedron is unbounded in that direction and -Inf if it is empty.
+*/
+func (polyhedronIn Polyhedron) Support(d mat.Vector) (float64, error) {
+	// Input Processing
+	if err := polyhedronIn.Check(); err != nil {
+		return 0, err
+	}
+	if d.Len() != polyhedronIn.Dimension() {
+		return 0, fmt.Errorf("The direction has length %v; expected %v.", d.Len(), polyhedronIn.Dimension())
+	}
+
+	// Algorithm
+	var negative mat.VecDense
+	negative.ScaleVec(-1, d)
+	_, value, err := Linprog(&negative, polyhedronIn.A, polyhedronIn.b, nil, nil)
+	switch {
+	case errors.Is(err, ErrInfeasible):
+		return math.Inf(-1), nil
+	case errors.Is(err, ErrUnbounded):
+		return math.Inf(1), nil
+	case err != nil:
+		return 0, err
+	}
+	return -value, nil
+}
+
+/*
+ChebyshevBall
+Description:
+
+	Computes the center and radius of the largest Euclidean ball inside the Polyhedron by solving
+		maximize r   subject to   a_i^T x + r ||a_i|| <= b_i,   r >= 0.
+	The radius is -Inf (and the center nil) if the Polyhedron is empty, +Inf if it contains arbitrarily large balls,
+	and zero if it is not full-dimensional.
+*/
+func (polyhedronIn Polyhedron) ChebyshevBall() (*mat.VecDense, float64, error) {
+	// Input Processing
+	if err := polyhedronIn.Check(); err != nil {
+		return nil, 0, err
+	}
+	M, N := polyhedronIn.A.Dims()
+
+	// Build the linear program over [x; r]
+	A := mat.NewDense(M+1, N+1, nil)
+	b := mat.NewVecDense(M+1, nil)
+	for i := 0; i < M; i++ {
+		row := polyhedronIn.row(i)
+		for j := 0; j < N; j++ {
+			A.Set(i, j, row.AtVec(j))
+		}
+		A.Set(i, N, mat.Norm(row, 2))
+		b.SetVec(i, polyhedronIn.b.AtVec(i))
+	}
+	A.Set(M, N, -1)
+
+	c := mat.NewVecDense(N+1, nil)
+	c.SetVec(N, -1)
+
+	// Algorithm
+	solution, _, err := Linprog(c, A, b, nil, nil)
+	switch {
+	case errors.Is(err, ErrInfeasible):
+		return nil, math.Inf(-1), nil
+	case errors.Is(err, ErrUnbounded):
+		return nil, math.Inf(1), nil
+	case err != nil:
+		return nil, 0, err
+	}
+
+	return mat.VecDenseCopyOf(solution.SliceVec(0, N)), solution.AtVec(N), nil
+}
+
+/*
+IsEmptySet
+Description:
+
+	Returns true if the Polyhedron contains no points.
+*/
+func (polyhedronIn Polyhedron) IsEmptySet() (bool, error) {
+	_, radius, err := polyhedronIn.ChebyshevBall()
+	if err != nil {
+		return false, err
+	}
+	return math.IsInf(radius, -1), nil
+}
+
+/*
+IsFullDimensional
+Description:
+
+	Returns true if the Polyhedron contains a ball of radius larger than a small tolerance.
+*/
+func (polyhedronIn Polyhedron) IsFullDimensional() (bool, error) {
+	_, radius, err := polyhedronIn.ChebyshevBall()
+	if err != nil {
+		return false, err
+	}
+	return radius > polyhedronTolerance, nil
+}
+
+/*
+Intersect
+Description:
+
+	Returns the intersection of the two Polyhedra, whose inequalities are the inequalities of both.
+*/
+func (polyhedronIn Polyhedron) Intersect(other Polyhedron) (Polyhedron, error) {
+	// Input Processing
+	if err := polyhedronIn.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	if err := other.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	if polyhedronIn.Dimension() != other.Dimension() {
+		return Polyhedron{}, fmt.Errorf("Cannot intersect Polyhedra of dimensions %v and %v.", polyhedronIn.Dimension(), other.Dimension())
+	}
+
+	// Algorithm
+	b := mat.NewVecDense(polyhedronIn.b.Len()+other.b.Len(), nil)
+	for i := 0; i < polyhedronIn.b.Len(); i++ {
+		b.SetVec(i, polyhedronIn.b.AtVec(i))
+	}
+	for i := 0; i < other.b.Len(); i++ {
+		b.SetVec(polyhedronIn.b.Len()+i, other.b.AtVec(i))
+	}
+
+	return GetPolyhedron(vstack(mat.DenseCopyOf(polyhedronIn.A), mat.DenseCopyOf(other.A)), b), nil
+}
+
+/*
+MinHRep
+Description:
+
+	Returns an equivalent Polyhedron without redundant inequalities (a minimal H-representation).
+	Duplicate inequalities are removed first; then inequality i is redundant if maximizing a_i^T x subject to the
+	remaining inequalities (and a_i^T x <= b_i + 1, to keep the problem bounded) does not exceed b_i.
+	Returns an error if the Polyhedron is empty.
+	If every inequality is trivial, the whole space is returned as the single inequality 0 x <= 1.
+*/
+func (polyhedronIn Polyhedron) MinHRep() (Polyhedron, error) {
+	// Input Processing
+	if err := polyhedronIn.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	empty, err := polyhedronIn.IsEmptySet()
+	if err != nil {
+		return Polyhedron{}, err
+	}
+	if empty {
+		return Polyhedron{}, errors.New("The Polyhedron is empty and has no minimal representation.")
+	}
+	M, N := polyhedronIn.A.Dims()
+
+	// Normalize the rows and remove the trivial and duplicate inequalities
+	A := mat.NewDense(M, N, nil)
+	b := make([]float64, M)
+	var kept []int
+	for i := 0; i < M; i++ {
+		row := polyhedronIn.row(i)
+		norm := mat.Norm(row, 2)
+		if norm == 0 {
+			continue
+		}
+		A.RowView(i).(*mat.VecDense).ScaleVec(1/norm, row)
+		b[i] = polyhedronIn.b.AtVec(i) / norm
+
+		duplicate := false
+		for _, k := range kept {
+			if mat.EqualApprox(A.RowView(i), A.RowView(k), polyhedronTolerance) {
+				duplicate = true
+				b[k] = math.Min(b[k], b[i])
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, i)
+		}
+	}
+
+	// Remove the redundant inequalities
+	for position := 0; position < len(kept); {
+		i := kept[position]
+		others := append(append([]int(nil), kept[:position]...), kept[position+1:]...)
+
+		candidateA := mat.NewDense(len(others)+1, N, nil)
+		candidateB := mat.NewVecDense(len(others)+1, nil)
+		for k, j := range append(others, i) {
+			candidateA.SetRow(k, A.RawRowView(j))
+			candidateB.SetVec(k, b[j])
+		}
+		candidateB.SetVec(len(others), b[i]+1)
+
+		support, err := GetPolyhedron(candidateA, candidateB).Support(A.RowView(i))
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		if support <= b[i]+polyhedronTolerance*(1+math.Abs(b[i])) {
+			kept = others
+			continue
+		}
+		position++
+	}
+
+	// Build the minimal representation (the whole space is represented by 0 x <= 1)
+	if len(kept) == 0 {
+		return GetPolyhedron(mat.NewDense(1, N, nil), mat.NewVecDense(1, []float64{1})), nil
+	}
+	minimalA := mat.NewDense(len(kept), N, nil)
+	minimalB := mat.NewVecDense(len(kept), nil)
+	for k, i := range kept {
+		minimalA.SetRow(k, A.RawRowView(i))
+		minimalB.SetVec(k, b[i])
+	}
+
+	return GetPolyhedron(minimalA, minimalB), nil
+}
+
+/*
+Projection
+Description:
+
+	Computes the projection of the Polyhedron onto the coordinates dims (in the given order), i.e. the set of
+	x_dims for which some value of the other coordinates gives a point of the Polyhedron. The other coordinates are
+	eliminated one at a time with Fourier-Motzkin elimination: every pair of inequalities with coefficients of
+	opposite signs on the eliminated coordinate is combined into one inequality without it, and the redundant
+	inequalities are removed after each elimination.
+*/
+func (polyhedronIn Polyhedron) Projection(dims []int) (Polyhedron, error) {
+	// Input Processing
+	if err := polyhedronIn.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	N := polyhedronIn.Dimension()
+	if len(dims) == 0 {
+		return Polyhedron{}, errors.New("The projection requires at least one coordinate.")
+	}
+	keep := make([]bool, N)
+	for _, dim := range dims {
+		if (dim < 0) || (dim >= N) || keep[dim] {
+			return Polyhedron{}, fmt.Errorf("The coordinates %v are not distinct coordinates of a Polyhedron of dimension %v.", dims, N)
+		}
+		keep[dim] = true
+	}
+
+	// Algorithm
+	current, err := polyhedronIn.MinHRep()
+	if err != nil {
+		return Polyhedron{}, err
+	}
+	for eliminated := N - 1; eliminated >= 0; eliminated-- {
+		if keep[eliminated] {
+			continue
+		}
+		current, err = current.eliminate(eliminated)
+		if err != nil {
+			return Polyhedron{}, err
+		}
+	}
+
+	// Reorder the remaining coordinates
+	M, _ := current.A.Dims()
+	A := mat.NewDense(M, len(dims), nil)
+	for i := 0; i < M; i++ {
+		for k, dim := range dims {
+			A.Set(i, k, current.A.At(i, dim))
+		}
+	}
+	return GetPolyhedron(A, current.b), nil
+}
+
+/*
+eliminate
+Description:
+
+	Eliminates the coordinate j of the Polyhedron with one step of Fourier-Motzkin elimination. The coefficient of
+	coordinate j is zero in the result, which keeps its dimension; the result is in minimal representation.
+*/
+func (polyhedronIn Polyhedron) eliminate(j int) (Polyhedron, error) {
+	// Constants
+	M, N := polyhedronIn.A.Dims()
+
+	// Algorithm
+	var rows [][]float64
+	var rhs []float64
+	var positive, negative []int
+	for i := 0; i < M; i++ {
+		switch coefficient := polyhedronIn.A.At(i, j); {
+		case coefficient > polyhedronTolerance:
+			positive = append(positive, i)
+		case coefficient < -polyhedronTolerance:
+			negative = append(negative, i)
+		default:
+			row := mat.Row(nil, i, polyhedronIn.A)
+			row[j] = 0
+			rows = append(rows, row)
+			rhs = append(rhs, polyhedronIn.b.AtVec(i))
+		}
+	}
+	for _, p := range positive {
+		for _, q := range negative {
+			ap, aq := polyhedronIn.A.At(p, j), -polyhedronIn.A.At(q, j)
+			row := make([]float64, N)
+			for k := 0; k < N; k++ {
+				row[k] = aq*polyhedronIn.A.At(p, k) + ap*polyhedronIn.A.At(q, k)
+			}
+			row[j] = 0
+			rows = append(rows, row)
+			rhs = append(rhs, aq*polyhedronIn.b.AtVec(p)+ap*polyhedronIn.b.AtVec(q))
+		}
+	}
+
+	if len(rows) == 0 {
+		return GetPolyhedron(mat.NewDense(1, N, nil), mat.NewVecDense(1, []float64{1})), nil
+	}
+	A := mat.NewDense(len(rows), N, nil)
+	for i, row := range rows {
+		A.SetRow(i, row)
+	}
+	return GetPolyhedron(A, mat.NewVecDense(len(rhs), rhs)).MinHRep()
+}
+
+/*
+AffineMap
+Description:
+
+	Computes the image {M x : x in P} of the Polyhedron under the p x n matrix M. If M is square and invertible the
+	image is {y : A M^{-1} y <= b}; otherwise it is the projection onto y of {(y, x) : y = M x, A x <= b}.
+*/
+func (polyhedronIn Polyhedron) AffineMap(M mat.Matrix) (Polyhedron, error) {
+	// Input Processing
+	if err := polyhedronIn.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	N := polyhedronIn.Dimension()
+	p, cols := M.Dims()
+	if cols != N {
+		return Polyhedron{}, fmt.Errorf("The matrix of the map must have %v columns; received %v.", N, cols)
+	}
+
+	// Invertible maps
+	if p == N {
+		var Minv mat.Dense
+		if err := Minv.Inverse(M); err == nil {
+			var A mat.Dense
+			A.Mul(polyhedronIn.A, &Minv)
+			return GetPolyhedron(&A, mat.VecDenseCopyOf(polyhedronIn.b)), nil
+		}
+	}
+
+	// Other maps: project {(y, x) : y - M x = 0, A x <= b}
+	rows, _ := polyhedronIn.A.Dims()
+	lifted := mat.NewDense(2*p+rows, p+N, nil)
+	rhs := mat.NewVecDense(2*p+rows, nil)
+	for i := 0; i < p; i++ {
+		for j := 0; j < N; j++ {
+			lifted.Set(i, p+j, -M.At(i, j))
+			lifted.Set(p+i, p+j, M.At(i, j))
+		}
+		lifted.Set(i, i, 1)
+		lifted.Set(p+i, i, -1)
+	}
+	lifted.Slice(2*p, 2*p+rows, p, p+N).(*mat.Dense).Copy(polyhedronIn.A)
+	for i := 0; i < rows; i++ {
+		rhs.SetVec(2*p+i, polyhedronIn.b.AtVec(i))
+	}
+
+	dims := make([]int, p)
+	for i := range dims {
+		dims[i] = i
+	}
+	return GetPolyhedron(lifted, rhs).Projection(dims)
+}
+
+/*
+Plus
+Description:
+
+	Computes the Minkowski sum {x + y : x in P, y in Q} of the two Polyhedra as the projection onto z of
+	{(z, y) : A_P (z - y) <= b_P,   A_Q y <= b_Q}.
+*/
+func (polyhedronIn Polyhedron) Plus(other Polyhedron) (Polyhedron, error) {
+	// Input Processing
+	if err := polyhedronIn.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	if err := other.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	N := polyhedronIn.Dimension()
+	if other.Dimension() != N {
+		return Polyhedron{}, fmt.Errorf("Cannot add Polyhedra of dimensions %v and %v.", N, other.Dimension())
+	}
+
+	// Build the lifted Polyhedron
+	rowsP, _ := polyhedronIn.A.Dims()
+	rowsQ, _ := other.A.Dims()
+	lifted := mat.NewDense(rowsP+rowsQ, 2*N, nil)
+	rhs := mat.NewVecDense(rowsP+rowsQ, nil)
+	for i := 0; i < rowsP; i++ {
+		for j := 0; j < N; j++ {
+			lifted.Set(i, j, polyhedronIn.A.At(i, j))
+			lifted.Set(i, N+j, -polyhedronIn.A.At(i, j))
+		}
+		rhs.SetVec(i, polyhedronIn.b.AtVec(i))
+	}
+	lifted.Slice(rowsP, rowsP+rowsQ, N, 2*N).(*mat.Dense).Copy(other.A)
+	for i := 0; i < rowsQ; i++ {
+		rhs.SetVec(rowsP+i, other.b.AtVec(i))
+	}
+
+	// Algorithm
+	dims := make([]int, N)
+	for i := range dims {
+		dims[i] = i
+	}
+	return GetPolyhedron(lifted, rhs).Projection(dims)
+}
+
+/*
+Minus
+Description:
+
+	Computes the Pontryagin difference {x : x + y in P for all y in Q} of the two Polyhedra, which is
+	{x : a_i^T x <= b_i - h_Q(a_i)} where h_Q is the support function of Q. The result may be empty.
+	Returns an error if Q is empty or unbounded in the direction of one of the rows of A.
+*/
+func (polyhedronIn Polyhedron) Minus(other Polyhedron) (Polyhedron, error) {
+	// Input Processing
+	if err := polyhedronIn.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	if err := other.Check(); err != nil {
+		return Polyhedron{}, err
+	}
+	N := polyhedronIn.Dimension()
+	if other.Dimension() != N {
+		return Polyhedron{}, fmt.Errorf("Cannot subtract a Polyhedron of dimension %v from one of dimension %v.", other.Dimension(), N)
+	}
+
+	// Algorithm
+	rows, _ := polyhedronIn.A.Dims()
+	b := mat.NewVecDense(rows, nil)
+	for i := 0; i < rows; i++ {
+		support, err := other.Support(polyhedronIn.row(i))
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		if math.IsInf(support, 0) {
+			return Polyhedron{}, errors.New("The subtracted Polyhedron must be nonempty and bounded in the directions of the inequalities.")
+		}
+		b.SetVec(i, polyhedronIn.b.AtVec(i)-support)
+	}
+
+	return GetPolyhedron(mat.DenseCopyOf(polyhedronIn.A), b), nil
+}
+
+/*
+row
+Description:
+
+	Returns a copy of row i of the matrix A.
+*/
+func (polyhedronIn Polyhedron) row(i int) *mat.VecDense {
+	_, N := polyhedronIn.A.Dims()
+	return mat.NewVecDense(N, mat.Row(nil, i, polyhedronIn.A))
+}
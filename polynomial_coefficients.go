@@ -0,0 +1,314 @@
+/*
+   polynomial_coefficients.go
+   Description:
+       Helper functions for working with polynomials that are stored as slices of coefficients
+       in DESCENDING powers (i.e. the MATLAB convention). For example, s^2 + 3s + 2 is stored as
+       []float64{1, 3, 2}.
+*/
+
+package goControl
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+polyTrim
+Description:
+
+	Removes the leading zeros of a coefficient slice. The zero polynomial is returned as []float64{0}.
+*/
+func polyTrim(p []float64) []float64 {
+	for len(p) > 1 && p[0] == 0 {
+		p = p[1:]
+	}
+	if len(p) == 0 {
+		return []float64{0}
+	}
+
+	pOut := make([]float64, len(p))
+	copy(pOut, p)
+	return pOut
+}
+
+/*
+polyDegree
+Description:
+
+	Returns the degree of the polynomial p (after leading zeros are removed).
+*/
+func polyDegree(p []float64) int {
+	return len(polyTrim(p)) - 1
+}
+
+/*
+polyIsZero
+Description:
+
+	Returns true if every coefficient of p is zero.
+*/
+func polyIsZero(p []float64) bool {
+	for _, coeff := range p {
+		if coeff != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+polyMul
+Description:
+
+	Computes the product of two polynomials (i.e. the convolution of their coefficients).
+*/
+func polyMul(p, q []float64) []float64 {
+	if len(p) == 0 || len(q) == 0 {
+		return []float64{0}
+	}
+
+	prod := make([]float64, len(p)+len(q)-1)
+	for i, pi := range p {
+		for j, qj := range q {
+			prod[i+j] += pi * qj
+		}
+	}
+	return prod
+}
+
+/*
+polyAdd
+Description:
+
+	Computes the sum of two polynomials, aligning their coefficients by power.
+*/
+func polyAdd(p, q []float64) []float64 {
+	// Constants
+	n := len(p)
+	if len(q) > n {
+		n = len(q)
+	}
+
+	// Algorithm
+	sum := make([]float64, n)
+	for i := range p {
+		sum[n-len(p)+i] += p[i]
+	}
+	for i := range q {
+		sum[n-len(q)+i] += q[i]
+	}
+	return sum
+}
+
+/*
+polyScale
+Description:
+
+	Multiplies every coefficient of p by alpha.
+*/
+func polyScale(alpha float64, p []float64) []float64 {
+	pOut := make([]float64, len(p))
+	for i, coeff := range p {
+		pOut[i] = alpha * coeff
+	}
+	return pOut
+}
+
+/*
+polyPad
+Description:
+
+	Left pads the polynomial p with zeros so that it has exactly length coefficients.
+*/
+func polyPad(p []float64, length int) []float64 {
+	if len(p) >= length {
+		return p
+	}
+	pOut := make([]float64, length)
+	copy(pOut[length-len(p):], p)
+	return pOut
+}
+
+/*
+polyEqual
+Description:
+
+	Returns true if the two polynomials have the same coefficients (up to leading zeros).
+*/
+func polyEqual(p, q []float64) bool {
+	pTrimmed, qTrimmed := polyTrim(p), polyTrim(q)
+	if len(pTrimmed) != len(qTrimmed) {
+		return false
+	}
+	for i := range pTrimmed {
+		if pTrimmed[i] != qTrimmed[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+polyEval
+Description:
+
+	Evaluates the real polynomial p at the complex point s using Horner's method.
+*/
+func polyEval(p []float64, s complex128) complex128 {
+	value := complex(0, 0)
+	for _, coeff := range p {
+		value = value*s + complex(coeff, 0)
+	}
+	return value
+}
+
+/*
+polyDerivative
+Description:
+
+	Computes the coefficients of dp/ds.
+*/
+func polyDerivative(p []float64) []float64 {
+	// Constants
+	degree := len(p) - 1
+
+	// Algorithm
+	if degree < 1 {
+		return []float64{0}
+	}
+	deriv := make([]float64, degree)
+	for i := 0; i < degree; i++ {
+		deriv[i] = float64(degree-i) * p[i]
+	}
+	return deriv
+}
+
+/*
+polyFromRoots
+Description:
+
+	Creates the monic polynomial whose roots are given by the input slice (i.e. MATLAB's poly function).
+	Complex roots should appear in conjugate pairs for the result to be real; any imaginary residue is
+	discarded.
+*/
+func polyFromRoots(roots []complex128) []float64 {
+	// Algorithm
+	coeffs := []complex128{1}
+	for _, r := range roots {
+		next := make([]complex128, len(coeffs)+1)
+		for i, c := range coeffs {
+			next[i] += c
+			next[i+1] -= c * r
+		}
+		coeffs = next
+	}
+
+	pOut := make([]float64, len(coeffs))
+	for i, c := range coeffs {
+		pOut[i] = real(c)
+	}
+	return pOut
+}
+
+/*
+polyRoots
+Description:
+
+	Computes the roots of the polynomial p using the eigenvalues of its companion matrix.
+*/
+func polyRoots(p []float64) ([]complex128, error) {
+	// Input Processing
+	p = polyTrim(p)
+	if polyIsZero(p) {
+		return nil, fmt.Errorf("The zero polynomial does not have a finite set of roots.")
+	}
+
+	// Remove roots at zero (trailing zeros) to improve conditioning.
+	numZeroRoots := 0
+	for len(p) > 1 && p[len(p)-1] == 0 {
+		p = p[:len(p)-1]
+		numZeroRoots++
+	}
+
+	// Constants
+	degree := len(p) - 1
+	roots := make([]complex128, 0, degree+numZeroRoots)
+
+	// Algorithm
+	if degree > 0 {
+		companion := mat.NewDense(degree, degree, nil)
+		for j := 0; j < degree; j++ {
+			companion.Set(0, j, -p[j+1]/p[0])
+		}
+		for i := 1; i < degree; i++ {
+			companion.Set(i, i-1, 1)
+		}
+
+		var eig mat.Eigen
+		if ok := eig.Factorize(companion, mat.EigenNone); !ok {
+			return nil, fmt.Errorf("The eigenvalue decomposition of the companion matrix did not converge.")
+		}
+		roots = append(roots, eig.Values(nil)...)
+	}
+
+	for i := 0; i < numZeroRoots; i++ {
+		roots = append(roots, 0)
+	}
+
+	sortComplex(roots)
+	return roots, nil
+}
+
+/*
+sortComplex
+Description:
+
+	Sorts a slice of complex numbers by real part and then by imaginary part.
+	This gives a deterministic ordering for poles and zeros.
+*/
+func sortComplex(values []complex128) {
+	sort.SliceStable(values, func(i, j int) bool {
+		if real(values[i]) != real(values[j]) {
+			return real(values[i]) < real(values[j])
+		}
+		return imag(values[i]) < imag(values[j])
+	})
+}
+
+/*
+cancelCommonRoots
+Description:
+
+	Removes the pairs of roots that appear (within tolerance tol) in both input slices.
+	Returns the remaining roots of each slice.
+*/
+func cancelCommonRoots(zeros, poles []complex128, tol float64) ([]complex128, []complex128) {
+	// Constants
+	zerosOut := []complex128{}
+	polesOut := make([]complex128, len(poles))
+	copy(polesOut, poles)
+
+	// Algorithm
+	for _, z := range zeros {
+		matchIndex := -1
+		for pIndex, p := range polesOut {
+			if cmplx.Abs(z-p) <= tol*math.Max(1, cmplx.Abs(p)) {
+				matchIndex = pIndex
+				break
+			}
+		}
+
+		if matchIndex == -1 {
+			zerosOut = append(zerosOut, z)
+		} else {
+			polesOut = append(polesOut[:matchIndex], polesOut[matchIndex+1:]...)
+		}
+	}
+
+	return zerosOut, polesOut
+}
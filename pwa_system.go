@@ -0,0 +1,607 @@
+/*
+   pwa_system.go
+   Description:
+       Discrete-time piecewise-affine (PWA) systems "like" MPT3's PWASystem class,
+           x+ = A_i x + B_i u + f_i   when (x, u) is in the region of mode i,
+       with well-posedness checks, simulation and conversion to mixed logical dynamical (MLD) form.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type PWAMode struct {
+	A      *mat.Dense    // State matrix A_i
+	B      *mat.Dense    // Input matrix B_i
+	F      *mat.VecDense // Affine term f_i (nil means zero)
+	Region Polyhedron    // Guard region of the mode in the joint (x, u) space
+}
+
+type PWASystem struct {
+	Modes []PWAMode
+	Ts    float64 // Sample time
+}
+
+type PWAResponse struct {
+	State *mat.Dense // (N + 1) x n matrix; row k is the state x_k
+	Mode  []int      // Index of the mode active at each of the N steps
+}
+
+type MLDSystem struct {
+	// Dynamics x+ = A x + B1 u + B2 delta + B3 z + B5
+	A  *mat.Dense
+	B1 *mat.Dense
+	B2 *mat.Dense
+	B3 *mat.Dense
+	B5 *mat.VecDense
+
+	// Constraints E2 delta + E3 z <= E1 u + E4 x + E5
+	E1 *mat.Dense
+	E2 *mat.Dense
+	E3 *mat.Dense
+	E4 *mat.Dense
+	E5 *mat.VecDense
+
+	Ts float64 // Sample time
+}
+
+/*
+GetPWASystem
+Description:
+
+	Creates a PWA system from its modes and sample time. The matrices of the modes are copied.
+*/
+func GetPWASystem(modes []PWAMode, Ts float64) (PWASystem, error) {
+	// Create Model
+	sys := PWASystem{Ts: Ts}
+	for _, mode := range modes {
+		copied := PWAMode{Region: mode.Region}
+		if mode.A != nil {
+			copied.A = mat.DenseCopyOf(mode.A)
+		}
+		if mode.B != nil {
+			copied.B = mat.DenseCopyOf(mode.B)
+		}
+		if mode.F != nil {
+			copied.F = mat.VecDenseCopyOf(mode.F)
+		} else if mode.A != nil {
+			n, _ := mode.A.Dims()
+			copied.F = mat.NewVecDense(n, nil)
+		}
+		sys.Modes = append(sys.Modes, copied)
+	}
+
+	// Check it
+	if err := sys.Check(); err != nil {
+		return PWASystem{}, err
+	}
+
+	return sys, nil
+}
+
+/*
+Check
+Description:
+
+	Verifies that the PWA system has at least one mode, that all modes have the same dimensions and that each region
+	is a Polyhedron in the (x, u) space.
+*/
+func (sys PWASystem) Check() error {
+	// Input Processing
+	if len(sys.Modes) == 0 {
+		return errors.New("The PWA system has no modes.")
+	}
+	if !(sys.Ts > 0) || math.IsInf(sys.Ts, 0) {
+		return fmt.Errorf("The sample time of a PWA system must be positive; received %v.", sys.Ts)
+	}
+	if (sys.Modes[0].A == nil) || (sys.Modes[0].B == nil) {
+		return errors.New("The matrices A and B of mode 0 are not defined.")
+	}
+	n, m := sys.Modes[0].B.Dims()
+
+	// Algorithm
+	for i, mode := range sys.Modes {
+		if (mode.A == nil) || (mode.B == nil) || (mode.F == nil) {
+			return fmt.Errorf("The matrices of mode %v are not defined.", i)
+		}
+		if rows, cols := mode.A.Dims(); (rows != n) || (cols != n) {
+			return fmt.Errorf("The matrix A of mode %v is %v x %v; expected %v x %v.", i, rows, cols, n, n)
+		}
+		if rows, cols := mode.B.Dims(); (rows != n) || (cols != m) {
+			return fmt.Errorf("The matrix B of mode %v is %v x %v; expected %v x %v.", i, rows, cols, n, m)
+		}
+		if mode.F.Len() != n {
+			return fmt.Errorf("The affine term of mode %v has length %v; expected %v.", i, mode.F.Len(), n)
+		}
+		if err := mode.Region.Check(); err != nil {
+			return fmt.Errorf("The region of mode %v is not valid: %v", i, err)
+		}
+		if mode.Region.Dimension() != n+m {
+			return fmt.Errorf("The region of mode %v has dimension %v; expected %v.", i, mode.Region.Dimension(), n+m)
+		}
+	}
+
+	return nil
+}
+
+/*
+Dims
+Description:
+
+	Returns the number of states, the number of inputs and the number of modes of the PWA system.
+*/
+func (sys PWASystem) Dims() (int, int, int) {
+	n, m := sys.Modes[0].B.Dims()
+	return n, m, len(sys.Modes)
+}
+
+/*
+Mode
+Description:
+
+	Returns the index of the first mode whose region contains (x, u). Points on the boundary shared by two regions are
+	assigned to the mode with the smallest index.
+*/
+func (sys PWASystem) Mode(x, u mat.Vector) (int, error) {
+	// Input Processing
+	n, m, _ := sys.Dims()
+	if x.Len() != n {
+		return -1, fmt.Errorf("The state has length %v; expected %v.", x.Len(), n)
+	}
+	if u.Len() != m {
+		return -1, fmt.Errorf("The input has length %v; expected %v.", u.Len(), m)
+	}
+
+	// Algorithm
+	point := stackVectors(x, u)
+	for i, mode := range sys.Modes {
+		if mode.Region.Contains(point) {
+			return i, nil
+		}
+	}
+
+	return -1, fmt.Errorf("The point (x, u) = (%v, %v) is not in any region of the PWA system.", mat.Formatted(x.T()), mat.Formatted(u.T()))
+}
+
+/*
+Step
+Description:
+
+	Computes the successor state x+ = A_i x + B_i u + f_i, where i is the mode of (x, u), and returns it with i.
+*/
+func (sys PWASystem) Step(x, u mat.Vector) (*mat.VecDense, int, error) {
+	// Input Processing
+	i, err := sys.Mode(x, u)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	// Algorithm
+	var next, Bu mat.VecDense
+	next.MulVec(sys.Modes[i].A, x)
+	Bu.MulVec(sys.Modes[i].B, u)
+	next.AddVec(&next, &Bu)
+	next.AddVec(&next, sys.Modes[i].F)
+
+	return &next, i, nil
+}
+
+/*
+Simulate
+Description:
+
+	Simulates the PWA system from the initial state x0 with the input sequence u (an N x m matrix whose row k is
+	applied at step k). Returns an error if the trajectory leaves the union of the regions.
+*/
+func (sys PWASystem) Simulate(u mat.Matrix, x0 mat.Vector) (PWAResponse, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return PWAResponse{}, err
+	}
+	n, m, _ := sys.Dims()
+	N, uCols := u.Dims()
+	if uCols != m {
+		return PWAResponse{}, fmt.Errorf("The input has %v columns; expected %v.", uCols, m)
+	}
+	if x0.Len() != n {
+		return PWAResponse{}, fmt.Errorf("The initial state has length %v; expected %v.", x0.Len(), n)
+	}
+
+	// Algorithm
+	response := PWAResponse{
+		State: mat.NewDense(N+1, n, nil),
+		Mode:  make([]int, N),
+	}
+	x := mat.VecDenseCopyOf(x0)
+	response.State.SetRow(0, x.RawVector().Data)
+	for k := 0; k < N; k++ {
+		next, mode, err := sys.Step(x, mat.NewVecDense(m, mat.Row(nil, k, u)))
+		if err != nil {
+			return PWAResponse{}, fmt.Errorf("Step %v: %v", k, err)
+		}
+		x = next
+		response.State.SetRow(k+1, x.RawVector().Data)
+		response.Mode[k] = mode
+	}
+
+	return response, nil
+}
+
+/*
+IsDisjoint
+Description:
+
+	Returns true if the regions of the modes do not overlap, i.e. if the intersection of each pair of regions is not
+	full-dimensional (regions may share boundaries).
+*/
+func (sys PWASystem) IsDisjoint() (bool, error) {
+	// Algorithm
+	for i := range sys.Modes {
+		for j := i + 1; j < len(sys.Modes); j++ {
+			intersection, err := sys.Modes[i].Region.Intersect(sys.Modes[j].Region)
+			if err != nil {
+				return false, err
+			}
+			overlap, err := intersection.IsFullDimensional()
+			if err != nil {
+				return false, err
+			}
+			if overlap {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+/*
+Covers
+Description:
+
+	Returns true if the union of the regions of the modes contains the Polyhedron domain in the (x, u) space.
+	The regions are removed from the domain one at a time (see regionDifference); the domain is covered if no
+	full-dimensional piece remains.
+*/
+func (sys PWASystem) Covers(domain Polyhedron) (bool, error) {
+	// Input Processing
+	if err := domain.Check(); err != nil {
+		return false, err
+	}
+	n, m, _ := sys.Dims()
+	if domain.Dimension() != n+m {
+		return false, fmt.Errorf("The domain has dimension %v; expected %v.", domain.Dimension(), n+m)
+	}
+
+	// Algorithm
+	remaining := []Polyhedron{domain}
+	for _, mode := range sys.Modes {
+		var next []Polyhedron
+		for _, piece := range remaining {
+			difference, err := regionDifference(piece, mode.Region)
+			if err != nil {
+				return false, err
+			}
+			next = append(next, difference...)
+		}
+		remaining = next
+		if len(remaining) == 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+/*
+CheckWellPosedness
+Description:
+
+	Returns an error if the regions of the modes overlap or do not cover the domain, so that the successor state is
+	uniquely defined for every (x, u) in the domain.
+*/
+func (sys PWASystem) CheckWellPosedness(domain Polyhedron) error {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return err
+	}
+
+	// Algorithm
+	disjoint, err := sys.IsDisjoint()
+	if err != nil {
+		return err
+	}
+	if !disjoint {
+		return errors.New("The regions of the PWA system overlap.")
+	}
+
+	covers, err := sys.Covers(domain)
+	if err != nil {
+		return err
+	}
+	if !covers {
+		return errors.New("The regions of the PWA system do not cover the domain.")
+	}
+
+	return nil
+}
+
+/*
+ToMLD
+Description:
+
+	Converts the PWA system on the bounded domain (a Polyhedron in the (x, u) space) into the equivalent MLD system
+	with one binary variable delta_i per mode and the auxiliary variables z_i = (A_i x + B_i u + f_i) delta_i:
+		x+ = z_1 + ... + z_s,   delta_1 + ... + delta_s = 1,
+		H_i (x, u) <= K_i + M_i (1 - delta_i),
+		m_i delta_i <= z_i <= M_i delta_i,
+		A_i x + B_i u + f_i - M_i (1 - delta_i) <= z_i <= A_i x + B_i u + f_i - m_i (1 - delta_i),
+	and the domain constraint. The big-M constants are the maxima and minima of the expressions over the domain,
+	computed with linear programs. Returns an error if the domain is unbounded.
+*/
+func (sys PWASystem) ToMLD(domain Polyhedron) (MLDSystem, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return MLDSystem{}, err
+	}
+	n, m, s := sys.Dims()
+	if err := domain.Check(); err != nil {
+		return MLDSystem{}, err
+	}
+	if domain.Dimension() != n+m {
+		return MLDSystem{}, fmt.Errorf("The domain has dimension %v; expected %v.", domain.Dimension(), n+m)
+	}
+
+	// Constants
+	nz := s * n
+	domainRows, _ := domain.A.Dims()
+	numRows := 2 + domainRows
+	for _, mode := range sys.Modes {
+		regionRows, _ := mode.Region.A.Dims()
+		numRows += regionRows + 4*n
+	}
+
+	mld := MLDSystem{
+		A:  mat.NewDense(n, n, nil),
+		B1: mat.NewDense(n, m, nil),
+		B2: mat.NewDense(n, s, nil),
+		B3: mat.NewDense(n, nz, nil),
+		B5: mat.NewVecDense(n, nil),
+		E1: mat.NewDense(numRows, m, nil),
+		E2: mat.NewDense(numRows, s, nil),
+		E3: mat.NewDense(numRows, nz, nil),
+		E4: mat.NewDense(numRows, n, nil),
+		E5: mat.NewVecDense(numRows, nil),
+		Ts: sys.Ts,
+	}
+	for i := 0; i < s; i++ {
+		for j := 0; j < n; j++ {
+			mld.B3.Set(j, i*n+j, 1)
+		}
+	}
+
+	// The row r of the constraints with coefficients a on (x, u): a_x x + a_u u appears on the right hand side
+	setRight := func(r int, a []float64, sign float64) {
+		for j := 0; j < n; j++ {
+			mld.E4.Set(r, j, sign*a[j])
+		}
+		for j := 0; j < m; j++ {
+			mld.E1.Set(r, j, sign*a[n+j])
+		}
+	}
+	bound := func(a []float64) (float64, error) {
+		support, err := domain.Support(mat.NewVecDense(n+m, a))
+		if err != nil {
+			return 0, err
+		}
+		if math.IsInf(support, 1) {
+			return 0, errors.New("The domain of the MLD conversion must be bounded.")
+		}
+		if math.IsInf(support, -1) {
+			return 0, errors.New("The domain of the MLD conversion is empty.")
+		}
+		return support, nil
+	}
+
+	// Algorithm
+	r := 0
+
+	// delta_1 + ... + delta_s = 1
+	for i := 0; i < s; i++ {
+		mld.E2.Set(r, i, 1)
+		mld.E2.Set(r+1, i, -1)
+	}
+	mld.E5.SetVec(r, 1)
+	mld.E5.SetVec(r+1, -1)
+	r += 2
+
+	// Domain: 0 <= K - H (x, u)
+	for i := 0; i < domainRows; i++ {
+		setRight(r, mat.Row(nil, i, domain.A), -1)
+		mld.E5.SetVec(r, domain.b.AtVec(i))
+		r++
+	}
+
+	for i, mode := range sys.Modes {
+		// Region: M delta_i <= K + M - H (x, u)
+		regionRows, _ := mode.Region.A.Dims()
+		for k := 0; k < regionRows; k++ {
+			a := mat.Row(nil, k, mode.Region.A)
+			maximum, err := bound(a)
+			if err != nil {
+				return MLDSystem{}, err
+			}
+			bigM := math.Max(maximum-mode.Region.b.AtVec(k), 0)
+
+			mld.E2.Set(r, i, bigM)
+			setRight(r, a, -1)
+			mld.E5.SetVec(r, mode.Region.b.AtVec(k)+bigM)
+			r++
+		}
+
+		// Auxiliary variables
+		for j := 0; j < n; j++ {
+			a := make([]float64, n+m)
+			mat.Row(a[:n], j, mode.A)
+			mat.Row(a[n:], j, mode.B)
+			f := mode.F.AtVec(j)
+
+			upper, err := bound(a)
+			if err != nil {
+				return MLDSystem{}, err
+			}
+			negated := make([]float64, n+m)
+			for k := range a {
+				negated[k] = -a[k]
+			}
+			lower, err := bound(negated)
+			if err != nil {
+				return MLDSystem{}, err
+			}
+			upper, lower = upper+f, -lower+f
+			z := i*n + j
+
+			// z <= M delta
+			mld.E3.Set(r, z, 1)
+			mld.E2.Set(r, i, -upper)
+			r++
+
+			// -z <= -m delta
+			mld.E3.Set(r, z, -1)
+			mld.E2.Set(r, i, lower)
+			r++
+
+			// z - m delta <= a (x, u) + f - m
+			mld.E3.Set(r, z, 1)
+			mld.E2.Set(r, i, -lower)
+			setRight(r, a, 1)
+			mld.E5.SetVec(r, f-lower)
+			r++
+
+			// -z + M delta <= -a (x, u) - f + M
+			mld.E3.Set(r, z, -1)
+			mld.E2.Set(r, i, upper)
+			setRight(r, a, -1)
+			mld.E5.SetVec(r, -f+upper)
+			r++
+		}
+	}
+
+	return mld, nil
+}
+
+/*
+Satisfies
+Description:
+
+	Returns true if (x, u, delta, z) satisfies the constraints E2 delta + E3 z <= E1 u + E4 x + E5 of the MLD system
+	up to a small tolerance.
+*/
+func (mld MLDSystem) Satisfies(x, u, delta, z mat.Vector) bool {
+	// Algorithm
+	var left, right, term mat.VecDense
+	left.MulVec(mld.E2, delta)
+	term.MulVec(mld.E3, z)
+	left.AddVec(&left, &term)
+
+	right.MulVec(mld.E1, u)
+	term.MulVec(mld.E4, x)
+	right.AddVec(&right, &term)
+	right.AddVec(&right, mld.E5)
+
+	for i := 0; i < left.Len(); i++ {
+		if left.AtVec(i) > right.AtVec(i)+polyhedronTolerance*(1+math.Abs(right.AtVec(i))) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Next
+Description:
+
+	Computes the successor state x+ = A x + B1 u + B2 delta + B3 z + B5 of the MLD system.
+*/
+func (mld MLDSystem) Next(x, u, delta, z mat.Vector) *mat.VecDense {
+	// Algorithm
+	var next, term mat.VecDense
+	next.MulVec(mld.A, x)
+	term.MulVec(mld.B1, u)
+	next.AddVec(&next, &term)
+	term.MulVec(mld.B2, delta)
+	next.AddVec(&next, &term)
+	term.MulVec(mld.B3, z)
+	next.AddVec(&next, &term)
+	next.AddVec(&next, mld.B5)
+
+	return &next
+}
+
+/*
+regionDifference
+Description:
+
+	Computes the set difference P \ Q as a list of Polyhedra whose union covers it (up to boundaries): piece k is
+		P intersected with {q_k^T x >= c_k} and {q_j^T x <= c_j for j < k}
+	for the rows q_k^T x <= c_k of Q. Pieces that are not full-dimensional are dropped.
+*/
+func regionDifference(P, Q Polyhedron) ([]Polyhedron, error) {
+	// Constants
+	rows, N := Q.A.Dims()
+
+	// Algorithm
+	var pieces []Polyhedron
+	for k := 0; k < rows; k++ {
+		A := mat.NewDense(k+1, N, nil)
+		b := mat.NewVecDense(k+1, nil)
+		for j := 0; j < k; j++ {
+			A.SetRow(j, mat.Row(nil, j, Q.A))
+			b.SetVec(j, Q.b.AtVec(j))
+		}
+		for j := 0; j < N; j++ {
+			A.Set(k, j, -Q.A.At(k, j))
+		}
+		b.SetVec(k, -Q.b.AtVec(k))
+
+		piece, err := P.Intersect(GetPolyhedron(A, b))
+		if err != nil {
+			return nil, err
+		}
+		full, err := piece.IsFullDimensional()
+		if err != nil {
+			return nil, err
+		}
+		if full {
+			if piece, err = piece.MinHRep(); err != nil {
+				return nil, err
+			}
+			pieces = append(pieces, piece)
+		}
+	}
+
+	return pieces, nil
+}
+
+/*
+stackVectors
+Description:
+
+	Returns the vector (x, u).
+*/
+func stackVectors(x, u mat.Vector) *mat.VecDense {
+	stacked := mat.NewVecDense(x.Len()+u.Len(), nil)
+	for i := 0; i < x.Len(); i++ {
+		stacked.SetVec(i, x.AtVec(i))
+	}
+	for i := 0; i < u.Len(); i++ {
+		stacked.SetVec(x.Len()+i, u.AtVec(i))
+	}
+	return stacked
+}
@@ -0,0 +1,400 @@
+/*
+   reachable_sets.go
+   Description:
+       Forward and backward reachable sets of discrete-time linear systems
+           x+ = A x + B u + w,   u in U,   w in W,
+       computed exactly with polyhedral operations or approximated with boxes or template polyhedra (from outside for
+       the forward sets and from inside for the backward sets, so that both approximations stay conservative).
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type ReachMethod int
+
+const (
+	ReachExact    ReachMethod = iota // Exact polyhedral propagation (the number of inequalities may grow quickly)
+	ReachBox                         // Approximation of each set by a box (outer for forward sets, inner for backward sets)
+	ReachTemplate                    // Approximation of each set by a template polyhedron {x : D x <= h} (outer or inner, as for ReachBox)
+)
+
+type ReachOptions struct {
+	Method     ReachMethod // ReachExact (default), ReachBox or ReachTemplate
+	Directions mat.Matrix  // Template directions D (one per row) for ReachTemplate
+}
+
+/*
+ForwardReach
+Description:
+
+	Computes the forward reachable sets X_0 = X0, X_1, ..., X_N of the discrete-time system sys (with the disturbance
+	x+ = A x + B u + w) where u ranges over U and w over W. A nil W means that there is no disturbance.
+	X_k is the set of states that can be reached at step k from some initial state in X0.
+*/
+func ForwardReach(sys StateSpace, X0, U Polyhedron, W *Polyhedron, N int) ([]Polyhedron, error) {
+	return ForwardReachWithOptions(sys, X0, U, W, N, ReachOptions{})
+}
+
+/*
+ForwardReachWithOptions
+Description:
+
+	Computes the forward reachable sets with the recursion
+		X_{k+1} = A X_k + B U + W   (Minkowski sums).
+	ReachExact computes the recursion with affine maps and Minkowski sums. ReachBox and ReachTemplate bound X_{k+1}
+	in each template direction d with the support function
+		h_{X_{k+1}}(d) = h_{X_k}(A^T d) + h_U(B^T d) + h_W(d),
+	which avoids the Minkowski sums, so the returned sets contain the exact reachable sets.
+*/
+func ForwardReachWithOptions(sys StateSpace, X0, U Polyhedron, W *Polyhedron, N int, options ReachOptions) ([]Polyhedron, error) {
+	// Input Processing
+	D, err := checkReachInputs(sys, X0, U, W, N, options)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := X0.MinHRep()
+	if err != nil {
+		return nil, errors.New("The initial set X0 is empty.")
+	}
+	if D != nil {
+		if current, err = templateHull(current, D); err != nil {
+			return nil, err
+		}
+	}
+
+	// Algorithm
+	sets := []Polyhedron{current}
+	for k := 0; k < N; k++ {
+		if D == nil {
+			current, err = forwardStep(sys, current, U, W)
+		} else {
+			current, err = forwardTemplateStep(sys, current, U, W, D)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, current)
+	}
+
+	return sets, nil
+}
+
+/*
+BackwardReach
+Description:
+
+	Computes the backward reachable sets T_0 = target, T_1, ..., T_N of the discrete-time system sys (with the
+	disturbance x+ = A x + B u + w). T_k is the set of states from which some sequence of inputs in U brings the
+	state into target in k steps for every sequence of disturbances in W. A nil W means that there is no disturbance.
+*/
+func BackwardReach(sys StateSpace, target, U Polyhedron, W *Polyhedron, N int) ([]Polyhedron, error) {
+	return BackwardReachWithOptions(sys, target, U, W, N, ReachOptions{})
+}
+
+/*
+BackwardReachWithOptions
+Description:
+
+	Computes the backward reachable sets with the robust pre-set recursion
+		T_{k+1} = {x : A x + B u in T_k - W for some u in U},
+	where T_k - W is the Pontryagin difference and the pre-set is the projection of a Polyhedron in (x, u).
+	ReachBox and ReachTemplate replace the target and each T_{k+1} by a template polyhedron contained in it (see
+	templateInner), so that every state of a returned set can still be steered into the target; the returned sets
+	are then subsets of the exact backward reachable sets. Returns an error if some T_k is empty (or has no inner
+	template approximation).
+*/
+func BackwardReachWithOptions(sys StateSpace, target, U Polyhedron, W *Polyhedron, N int, options ReachOptions) ([]Polyhedron, error) {
+	// Input Processing
+	D, err := checkReachInputs(sys, target, U, W, N, options)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := target.MinHRep()
+	if err != nil {
+		return nil, errors.New("The target set is empty.")
+	}
+	if D != nil {
+		if current, err = templateInner(current, D); err != nil {
+			return nil, fmt.Errorf("The target set: %v", err)
+		}
+	}
+
+	// Algorithm
+	sets := []Polyhedron{current}
+	for k := 0; k < N; k++ {
+		tightened := current
+		if W != nil {
+			if tightened, err = current.Minus(*W); err != nil {
+				return nil, err
+			}
+		}
+		if empty, err := tightened.IsEmptySet(); err != nil {
+			return nil, err
+		} else if empty {
+			return nil, fmt.Errorf("The backward reachable set at step %v is empty.", k+1)
+		}
+
+		if current, err = controlPreSet(sys.A, sys.B, tightened, U, 1); err != nil {
+			return nil, err
+		}
+		if D != nil {
+			if current, err = templateInner(current, D); err != nil {
+				return nil, fmt.Errorf("The backward reachable set at step %v: %v", k+1, err)
+			}
+		}
+		sets = append(sets, current)
+	}
+
+	return sets, nil
+}
+
+/*
+checkReachInputs
+Description:
+
+	Verifies the system, the sets and the options of the reachability computations, and returns the template
+	directions (nil for ReachExact).
+*/
+func checkReachInputs(sys StateSpace, X, U Polyhedron, W *Polyhedron, N int, options ReachOptions) (*mat.Dense, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return nil, err
+	}
+	if !sys.IsDiscrete() {
+		return nil, errors.New("Reachable sets require a discrete-time model.")
+	}
+	n, m := sys.B.Dims()
+
+	if err := X.Check(); err != nil {
+		return nil, err
+	}
+	if X.Dimension() != n {
+		return nil, fmt.Errorf("The state set has dimension %v; expected %v.", X.Dimension(), n)
+	}
+	if err := U.Check(); err != nil {
+		return nil, err
+	}
+	if U.Dimension() != m {
+		return nil, fmt.Errorf("The set U has dimension %v; expected %v.", U.Dimension(), m)
+	}
+	if W != nil {
+		if err := W.Check(); err != nil {
+			return nil, err
+		}
+		if W.Dimension() != n {
+			return nil, fmt.Errorf("The set W has dimension %v; expected %v.", W.Dimension(), n)
+		}
+	}
+	if N < 0 {
+		return nil, fmt.Errorf("The number of steps must be nonnegative; received %v.", N)
+	}
+
+	// Template directions
+	switch options.Method {
+	case ReachExact:
+		return nil, nil
+	case ReachBox:
+		D := mat.NewDense(2*n, n, nil)
+		for i := 0; i < n; i++ {
+			D.Set(2*i, i, 1)
+			D.Set(2*i+1, i, -1)
+		}
+		return D, nil
+	case ReachTemplate:
+		if options.Directions == nil {
+			return nil, errors.New("The template method requires the template directions.")
+		}
+		if _, cols := options.Directions.Dims(); cols != n {
+			return nil, fmt.Errorf("The template directions must have %v columns; received %v.", n, cols)
+		}
+		return mat.DenseCopyOf(options.Directions), nil
+	}
+
+	return nil, fmt.Errorf("The reachability method %v is not recognized.", options.Method)
+}
+
+/*
+forwardStep
+Description:
+
+	Computes A X + B U + W exactly.
+*/
+func forwardStep(sys StateSpace, X, U Polyhedron, W *Polyhedron) (Polyhedron, error) {
+	// Algorithm
+	AX, err := X.AffineMap(sys.A)
+	if err != nil {
+		return Polyhedron{}, err
+	}
+	BU, err := U.AffineMap(sys.B)
+	if err != nil {
+		return Polyhedron{}, err
+	}
+	next, err := AX.Plus(BU)
+	if err != nil {
+		return Polyhedron{}, err
+	}
+	if W != nil {
+		if next, err = next.Plus(*W); err != nil {
+			return Polyhedron{}, err
+		}
+	}
+	return next, nil
+}
+
+/*
+forwardTemplateStep
+Description:
+
+	Computes the template polyhedron {x : D x <= h} containing A X + B U + W with the support functions
+	h_i = h_X(A^T d_i) + h_U(B^T d_i) + h_W(d_i). Returns an error if the set is unbounded in some direction.
+*/
+func forwardTemplateStep(sys StateSpace, X, U Polyhedron, W *Polyhedron, D *mat.Dense) (Polyhedron, error) {
+	// Constants
+	rows, _ := D.Dims()
+
+	// Algorithm
+	h := mat.NewVecDense(rows, nil)
+	for i := 0; i < rows; i++ {
+		d := D.RowView(i)
+
+		var ATd, BTd mat.VecDense
+		ATd.MulVec(sys.A.T(), d)
+		BTd.MulVec(sys.B.T(), d)
+
+		hX, err := X.Support(&ATd)
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		hU, err := U.Support(&BTd)
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		value := hX + hU
+		if W != nil {
+			hW, err := W.Support(d)
+			if err != nil {
+				return Polyhedron{}, err
+			}
+			value += hW
+		}
+
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return Polyhedron{}, fmt.Errorf("The reachable set is unbounded in the template direction %v.", i)
+		}
+		h.SetVec(i, value)
+	}
+
+	return GetPolyhedron(mat.DenseCopyOf(D), h), nil
+}
+
+/*
+templateHull
+Description:
+
+	Computes the smallest template polyhedron {x : D x <= h} containing P, with h_i the support function of P in the
+	direction d_i. Directions in which P is unbounded are dropped (the whole space is 0 x <= 1).
+*/
+func templateHull(P Polyhedron, D *mat.Dense) (Polyhedron, error) {
+	// Constants
+	rows, n := D.Dims()
+
+	// Algorithm
+	var kept []int
+	var h []float64
+	for i := 0; i < rows; i++ {
+		support, err := P.Support(D.RowView(i))
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		if math.IsInf(support, -1) {
+			return Polyhedron{}, errors.New("Cannot compute the template hull of an empty Polyhedron.")
+		}
+		if !math.IsInf(support, 1) {
+			kept, h = append(kept, i), append(h, support)
+		}
+	}
+
+	if len(kept) == 0 {
+		return GetPolyhedron(mat.NewDense(1, n, nil), mat.NewVecDense(1, []float64{1})), nil
+	}
+	A := mat.NewDense(len(kept), n, nil)
+	for k, i := range kept {
+		A.SetRow(k, mat.Row(nil, i, D))
+	}
+	return GetPolyhedron(A, mat.NewVecDense(len(h), h)), nil
+}
+
+/*
+templateInner
+Description:
+
+	Returns a template polyhedron contained in P: the largest copy c + alpha H of the template hull
+	H = {x : D x <= h} of P (scaled by alpha and translated by c) that fits in P, which is the template polyhedron
+	{x : D x <= alpha h + D c}. Since the support of c + alpha H in a direction a is a^T c + alpha h_H(a), the copy
+	is found with the linear program
+		maximize alpha   subject to   a_i^T c + alpha h_H(a_i) <= b_i   (each inequality a_i^T x <= b_i of P).
+	Returns an error if P has no full-dimensional inner approximation of this form (alpha = 0), for example if H is
+	unbounded in the direction of an inequality of P.
+*/
+func templateInner(P Polyhedron, D *mat.Dense) (Polyhedron, error) {
+	// Constants
+	P, err := P.MinHRep()
+	if err != nil {
+		return Polyhedron{}, err
+	}
+	H, err := templateHull(P, D)
+	if err != nil {
+		return Polyhedron{}, err
+	}
+	M, n := P.A.Dims()
+	rows, _ := H.A.Dims()
+
+	// Build the linear program over [c; alpha]
+	A := mat.NewDense(M+1, n+1, nil)
+	b := mat.NewVecDense(M+1, nil)
+	for i := 0; i < M; i++ {
+		row := P.row(i)
+		support, err := H.Support(row)
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		if math.IsInf(support, 1) {
+			return Polyhedron{}, errors.New("The template directions do not bound the set, so no template polyhedron fits in it.")
+		}
+		for j := 0; j < n; j++ {
+			A.Set(i, j, row.AtVec(j))
+		}
+		A.Set(i, n, support)
+		b.SetVec(i, P.b.AtVec(i))
+	}
+	A.Set(M, n, -1)
+
+	c := mat.NewVecDense(n+1, nil)
+	c.SetVec(n, -1)
+
+	// Algorithm
+	solution, _, err := Linprog(c, A, b, nil, nil)
+	if err != nil {
+		return Polyhedron{}, err
+	}
+	alpha := solution.AtVec(n)
+	if alpha <= polyhedronTolerance {
+		return Polyhedron{}, errors.New("No full-dimensional template polyhedron fits in the set.")
+	}
+
+	// Output
+	center := solution.SliceVec(0, n)
+	offsets := mat.NewVecDense(rows, nil)
+	offsets.MulVec(H.A, center)
+	offsets.AddScaledVec(offsets, alpha, H.b)
+	return GetPolyhedron(H.A, offsets), nil
+}
@@ -0,0 +1,434 @@
+/*
+   riccati.go
+   Description:
+       Solvers for the continuous-time and discrete-time algebraic Riccati equations "like" MATLAB's icare and idare.
+       The stabilizing solution is computed from the stable invariant subspace of the Hamiltonian matrix (continuous-time)
+       or of the symplectic pencil (discrete-time), found with an ordered real Schur decomposition. The solution can
+       optionally be refined with Newton-Kleinman iterations.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type RiccatiOptions struct {
+	NewtonRefinement bool    // Refine the Schur solution with Newton-Kleinman iterations.
+	MaxIterations    int     // Maximum number of Newton-Kleinman iterations. 0 means 50.
+	Tolerance        float64 // Relative change in X at which the Newton-Kleinman iterations stop. 0 means 1e-12.
+}
+
+type RiccatiSolution struct {
+	X                     *mat.SymDense // Stabilizing solution of the Riccati equation
+	K                     *mat.Dense    // Gain of the state feedback u = -K x
+	ClosedLoopEigenvalues []complex128  // Eigenvalues of A - B K
+}
+
+/*
+Care
+Description:
+
+	Computes the stabilizing solution X of the continuous-time algebraic Riccati equation
+		A^T X + X A - (X B + N) R^{-1} (B^T X + N^T) + Q = 0
+	together with the gain K = R^{-1} (B^T X + N^T) and the eigenvalues of A - B K.
+	The cross weight N may be nil, in which case it is taken to be zero.
+*/
+func Care(A, B, Q, R, N mat.Matrix) (RiccatiSolution, error) {
+	return CareWithOptions(A, B, Q, R, N, RiccatiOptions{})
+}
+
+/*
+CareWithOptions
+Description:
+
+	Computes the stabilizing solution of the continuous-time algebraic Riccati equation (see Care).
+	The solution is read off the stable invariant subspace of the Hamiltonian matrix
+		H = [ Ab, -G; -Qb, -Ab^T ]
+	where Ab = A - B R^{-1} N^T, G = B R^{-1} B^T and Qb = Q - N R^{-1} N^T.
+*/
+func CareWithOptions(A, B, Q, R, N mat.Matrix, options RiccatiOptions) (RiccatiSolution, error) {
+	// Input Processing
+	NDense, err := checkRiccatiInputs(A, B, Q, R, N)
+	if err != nil {
+		return RiccatiSolution{}, err
+	}
+	n, _ := A.Dims()
+
+	Ab, G, Qb, err := riccatiReducedMatrices(A, B, Q, R, NDense)
+	if err != nil {
+		return RiccatiSolution{}, err
+	}
+
+	// Build the Hamiltonian matrix
+	negG, negQb, negAbt := mat.NewDense(n, n, nil), mat.NewDense(n, n, nil), mat.NewDense(n, n, nil)
+	negG.Scale(-1, G)
+	negQb.Scale(-1, Qb)
+	negAbt.Scale(-1, Ab.T())
+	H := vstack(hstack(Ab, negG), hstack(negQb, negAbt))
+
+	// Solve with the stable invariant subspace
+	X, err := riccatiFromSubspace(H, func(lambda complex128) bool { return real(lambda) < 0 })
+	if err != nil {
+		return RiccatiSolution{}, err
+	}
+
+	if options.NewtonRefinement {
+		X, err = newtonKleinman(A, B, Q, R, NDense, X, false, options)
+		if err != nil {
+			return RiccatiSolution{}, err
+		}
+	}
+
+	return riccatiSolution(A, B, Q, R, NDense, X, false)
+}
+
+/*
+Dare
+Description:
+
+	Computes the stabilizing solution X of the discrete-time algebraic Riccati equation
+		A^T X A - X - (A^T X B + N) (R + B^T X B)^{-1} (B^T X A + N^T) + Q = 0
+	together with the gain K = (R + B^T X B)^{-1} (B^T X A + N^T) and the eigenvalues of A - B K.
+	The cross weight N may be nil, in which case it is taken to be zero.
+*/
+func Dare(A, B, Q, R, N mat.Matrix) (RiccatiSolution, error) {
+	return DareWithOptions(A, B, Q, R, N, RiccatiOptions{})
+}
+
+/*
+DareWithOptions
+Description:
+
+	Computes the stabilizing solution of the discrete-time algebraic Riccati equation (see Dare).
+	The solution is read off the stable deflating subspace of the symplectic pencil
+		M - z L = [ Ab, 0; -Qb, I ] - z [ I, G; 0, Ab^T ].
+	Because A may be singular, the pencil is not inverted directly. Instead, the Cayley transform
+	(M + L)^{-1} (M - L) is used, which maps the eigenvalues inside the unit circle to the open left half plane.
+*/
+func DareWithOptions(A, B, Q, R, N mat.Matrix, options RiccatiOptions) (RiccatiSolution, error) {
+	// Input Processing
+	NDense, err := checkRiccatiInputs(A, B, Q, R, N)
+	if err != nil {
+		return RiccatiSolution{}, err
+	}
+	n, _ := A.Dims()
+
+	Ab, G, Qb, err := riccatiReducedMatrices(A, B, Q, R, NDense)
+	if err != nil {
+		return RiccatiSolution{}, err
+	}
+
+	// Build the symplectic pencil and its Cayley transform
+	negQb := mat.NewDense(n, n, nil)
+	negQb.Scale(-1, Qb)
+	M := vstack(hstack(Ab, mat.NewDense(n, n, nil)), hstack(negQb, eye(n)))
+	L := vstack(hstack(eye(n), G), hstack(mat.NewDense(n, n, nil), mat.DenseCopyOf(Ab.T())))
+
+	var MPlusL, MMinusL, H mat.Dense
+	MPlusL.Add(M, L)
+	MMinusL.Sub(M, L)
+	if err := H.Solve(&MPlusL, &MMinusL); err != nil {
+		return RiccatiSolution{}, fmt.Errorf("The symplectic pencil has an eigenvalue at -1; no stabilizing solution exists: %v", err)
+	}
+
+	// Solve with the stable deflating subspace
+	X, err := riccatiFromSubspace(&H, func(lambda complex128) bool { return real(lambda) < 0 })
+	if err != nil {
+		return RiccatiSolution{}, err
+	}
+
+	if options.NewtonRefinement {
+		X, err = newtonKleinman(A, B, Q, R, NDense, X, true, options)
+		if err != nil {
+			return RiccatiSolution{}, err
+		}
+	}
+
+	return riccatiSolution(A, B, Q, R, NDense, X, true)
+}
+
+/*
+checkRiccatiInputs
+Description:
+
+	Verifies the dimensions of the Riccati equation data, that Q and R are symmetric and that R is positive definite.
+	Returns the cross weight N as a dense matrix (a zero matrix when N is nil).
+*/
+func checkRiccatiInputs(A, B, Q, R, N mat.Matrix) (*mat.Dense, error) {
+	// Constants
+	n, nA := A.Dims()
+	nB, m := B.Dims()
+	nQ, mQ := Q.Dims()
+	nR, mR := R.Dims()
+
+	// Check dimensions
+	if n != nA {
+		return nil, fmt.Errorf("The matrix A must be square; received %v x %v.", n, nA)
+	}
+	if nB != n {
+		return nil, fmt.Errorf("The matrix B has %v rows; expected %v.", nB, n)
+	}
+	if (nQ != n) || (mQ != n) {
+		return nil, fmt.Errorf("The matrix Q has dimensions %v x %v; expected %v x %v.", nQ, mQ, n, n)
+	}
+	if (nR != m) || (mR != m) {
+		return nil, fmt.Errorf("The matrix R has dimensions %v x %v; expected %v x %v.", nR, mR, m, m)
+	}
+
+	NDense := mat.NewDense(n, m, nil)
+	if N != nil {
+		nN, mN := N.Dims()
+		if (nN != n) || (mN != m) {
+			return nil, fmt.Errorf("The matrix N has dimensions %v x %v; expected %v x %v.", nN, mN, n, m)
+		}
+		NDense.Copy(N)
+	}
+
+	// Check symmetry and definiteness
+	if !mat.EqualApprox(Q, Q.T(), 1e-12*math.Max(mat.Norm(Q, 1), 1)) {
+		return nil, errors.New("The matrix Q must be symmetric.")
+	}
+	if !mat.EqualApprox(R, R.T(), 1e-12*math.Max(mat.Norm(R, 1), 1)) {
+		return nil, errors.New("The matrix R must be symmetric.")
+	}
+	var chol mat.Cholesky
+	if ok := chol.Factorize(symmetrize(R)); !ok {
+		return nil, errors.New("The matrix R must be positive definite.")
+	}
+
+	return NDense, nil
+}
+
+/*
+riccatiReducedMatrices
+Description:
+
+	Removes the cross weight N from the Riccati equation data by computing
+		Ab = A - B R^{-1} N^T,    G = B R^{-1} B^T,    Qb = Q - N R^{-1} N^T.
+*/
+func riccatiReducedMatrices(A, B, Q, R mat.Matrix, N *mat.Dense) (Ab, G, Qb *mat.Dense, err error) {
+	// Constants
+	var chol mat.Cholesky
+	chol.Factorize(symmetrize(R))
+
+	// Algorithm
+	var RinvNt, RinvBt mat.Dense
+	if err := chol.SolveTo(&RinvNt, N.T()); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := chol.SolveTo(&RinvBt, B.T()); err != nil {
+		return nil, nil, nil, err
+	}
+
+	Ab, G, Qb = mat.DenseCopyOf(A), &mat.Dense{}, mat.DenseCopyOf(Q)
+
+	var BRinvNt, NRinvNt mat.Dense
+	BRinvNt.Mul(B, &RinvNt)
+	Ab.Sub(Ab, &BRinvNt)
+
+	G.Mul(B, &RinvBt)
+
+	NRinvNt.Mul(N, &RinvNt)
+	Qb.Sub(Qb, &NRinvNt)
+
+	return Ab, G, Qb, nil
+}
+
+/*
+riccatiFromSubspace
+Description:
+
+	Computes X = U21 U11^{-1}, where the columns of [U11; U21] form an orthonormal basis of the invariant subspace
+	of the 2n x 2n matrix H associated with the selected eigenvalues. Exactly n eigenvalues must be selected.
+*/
+func riccatiFromSubspace(H mat.Matrix, selected func(lambda complex128) bool) (*mat.SymDense, error) {
+	// Constants
+	twoN, _ := H.Dims()
+	n := twoN / 2
+
+	// Algorithm
+	_, Z, k, err := orderedSchur(H, selected)
+	if err != nil {
+		return nil, err
+	}
+	if k != n {
+		return nil, fmt.Errorf("Found %v stable eigenvalues in the Hamiltonian/symplectic problem; expected %v. No stabilizing solution exists.", k, n)
+	}
+
+	// Solve X U11 = U21 (i.e. U11^T X^T = U21^T)
+	U11 := Z.Slice(0, n, 0, n)
+	U21 := Z.Slice(n, twoN, 0, n)
+
+	var Xt mat.Dense
+	if err := Xt.Solve(U11.T(), U21.T()); err != nil {
+		return nil, fmt.Errorf("The stable subspace is not a graph subspace; no stabilizing solution exists: %v", err)
+	}
+
+	return symmetrize(&Xt), nil
+}
+
+/*
+riccatiGain
+Description:
+
+	Computes the gain K = R^{-1} (B^T X + N^T) (continuous-time) or K = (R + B^T X B)^{-1} (B^T X A + N^T)
+	(discrete-time) associated with the candidate solution X.
+*/
+func riccatiGain(A, B, R mat.Matrix, N *mat.Dense, X mat.Matrix, discrete bool) (*mat.Dense, error) {
+	// Constants
+	_, m := B.Dims()
+
+	// Algorithm
+	var BtX mat.Dense
+	BtX.Mul(B.T(), X)
+
+	W := mat.DenseCopyOf(R)
+	rhs := mat.DenseCopyOf(N.T())
+	if discrete {
+		var BtXB, BtXA mat.Dense
+		BtXB.Mul(&BtX, B)
+		BtXA.Mul(&BtX, A)
+		W.Add(W, &BtXB)
+		rhs.Add(rhs, &BtXA)
+	} else {
+		rhs.Add(rhs, &BtX)
+	}
+
+	var chol mat.Cholesky
+	if ok := chol.Factorize(symmetrize(W)); !ok {
+		return nil, fmt.Errorf("The %v x %v matrix R + B^T X B is not positive definite.", m, m)
+	}
+
+	var K mat.Dense
+	if err := chol.SolveTo(&K, rhs); err != nil {
+		return nil, err
+	}
+	return &K, nil
+}
+
+/*
+riccatiSolution
+Description:
+
+	Checks the residual of the Riccati equation at X and assembles the solution, the gain and
+	the closed-loop eigenvalues.
+*/
+func riccatiSolution(A, B, Q, R mat.Matrix, N *mat.Dense, X *mat.SymDense, discrete bool) (RiccatiSolution, error) {
+	// Compute the gain
+	K, err := riccatiGain(A, B, R, N, X, discrete)
+	if err != nil {
+		return RiccatiSolution{}, err
+	}
+
+	// Check the residual
+	var cross, residual mat.Dense
+	normA, normX := mat.Norm(A, 1), mat.Norm(X, 1)
+	var scale float64
+	if discrete {
+		// A^T X A - X - (A^T X B + N) K + Q
+		var AtX, AtXA, AtXB mat.Dense
+		AtX.Mul(A.T(), X)
+		AtXA.Mul(&AtX, A)
+		AtXB.Mul(&AtX, B)
+		AtXB.Add(&AtXB, N)
+		cross.Mul(&AtXB, K)
+
+		residual.Sub(&AtXA, X)
+		scale = (normA*normA+1)*normX + mat.Norm(&AtXB, 1)*mat.Norm(K, 1) + mat.Norm(Q, 1)
+	} else {
+		// A^T X + X A - (X B + N) K + Q
+		var AtX, XA, XB mat.Dense
+		AtX.Mul(A.T(), X)
+		XA.Mul(X, A)
+		XB.Mul(X, B)
+		XB.Add(&XB, N)
+		cross.Mul(&XB, K)
+
+		residual.Add(&AtX, &XA)
+		scale = 2*normA*normX + mat.Norm(&XB, 1)*mat.Norm(K, 1) + mat.Norm(Q, 1)
+	}
+	residual.Sub(&residual, &cross)
+	residual.Add(&residual, Q)
+
+	if err := checkResidual(&residual, scale); err != nil {
+		return RiccatiSolution{}, err
+	}
+
+	// Compute the closed-loop eigenvalues
+	var BK, Acl mat.Dense
+	BK.Mul(B, K)
+	Acl.Sub(A, &BK)
+	closedLoopEigenvalues, err := eigenvalues(&Acl)
+	if err != nil {
+		return RiccatiSolution{}, err
+	}
+
+	return RiccatiSolution{X: X, K: K, ClosedLoopEigenvalues: closedLoopEigenvalues}, nil
+}
+
+/*
+newtonKleinman
+Description:
+
+	Refines the stabilizing solution X0 of the Riccati equation with Newton-Kleinman iterations. Each iteration
+	computes the gain K of the current solution and solves the Lyapunov equation
+		Ak^T X + X Ak + Qk = 0          (continuous-time) or
+		Ak^T X Ak - X + Qk = 0          (discrete-time),
+	where Ak = A - B K and Qk = Q - N K - K^T N^T + K^T R K.
+*/
+func newtonKleinman(A, B, Q, R mat.Matrix, N *mat.Dense, X0 *mat.SymDense, discrete bool, options RiccatiOptions) (*mat.SymDense, error) {
+	// Constants
+	maxIterations := options.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = 50
+	}
+	tolerance := options.Tolerance
+	if tolerance <= 0 {
+		tolerance = 1e-12
+	}
+
+	// Algorithm
+	X := X0
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		K, err := riccatiGain(A, B, R, N, X, discrete)
+		if err != nil {
+			return nil, err
+		}
+
+		var BK, Ak, NK, KtRK, RK mat.Dense
+		BK.Mul(B, K)
+		Ak.Sub(A, &BK)
+
+		NK.Mul(N, K)
+		RK.Mul(R, K)
+		KtRK.Mul(K.T(), &RK)
+		Qk := mat.DenseCopyOf(Q)
+		Qk.Sub(Qk, &NK)
+		Qk.Sub(Qk, NK.T())
+		Qk.Add(Qk, &KtRK)
+
+		var XNext *mat.Dense
+		if discrete {
+			XNext, err = Dlyap(Ak.T(), Qk)
+		} else {
+			XNext, err = Lyap(Ak.T(), Qk)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("The Newton-Kleinman iteration failed: %v", err)
+		}
+
+		var change mat.Dense
+		change.Sub(XNext, X)
+		X = symmetrize(XNext)
+		if mat.Norm(&change, 1) <= tolerance*math.Max(mat.Norm(X, 1), eps) {
+			break
+		}
+	}
+
+	return X, nil
+}
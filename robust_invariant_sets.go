@@ -0,0 +1,172 @@
+/*
+   robust_invariant_sets.go
+   Description:
+       Outer approximations of the minimal robust positively invariant set of linear systems with additive
+       disturbances, following the algorithm of Rakovic, Kerrigan, Kouramas and Mayne (2005).
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// defaultMinimalRPIIterations is the default limit on the number of terms of the Minkowski sum.
+const defaultMinimalRPIIterations = 1000
+
+type MinimalRPIOptions struct {
+	MaxIterations int // Maximum value of s searched for (0 selects 1000)
+}
+
+type MinimalRPIApproximation struct {
+	Set   Polyhedron // The outer approximation (1 - Alpha)^{-1} F_s of the minimal RPI set F_inf
+	S     int        // Number of terms of the Minkowski sum F_s = W + A W + ... + A^{s-1} W
+	Alpha float64    // Smallest alpha with A^s W contained in alpha W
+	Bound float64    // A-priori error bound: Set is contained in F_inf + {x : ||x||_inf <= Bound}
+}
+
+/*
+MinimalRPI
+Description:
+
+	Computes an epsilon outer approximation of the minimal robust positively invariant set of x+ = A x + w with
+	w in the Polyhedron W, where A is the closed loop matrix (e.g. A + B K) and W contains the origin in its interior.
+*/
+func MinimalRPI(A mat.Matrix, W Polyhedron, epsilon float64) (MinimalRPIApproximation, error) {
+	return MinimalRPIWithOptions(A, W, epsilon, MinimalRPIOptions{})
+}
+
+/*
+MinimalRPIWithOptions
+Description:
+
+	Computes an epsilon outer approximation of the minimal robust positively invariant set F_inf of x+ = A x + w,
+	w in W = {w : f_i^T w <= g_i}. The algorithm finds the smallest s such that
+		alpha(s) = max_i h_W((A^s)^T f_i) / g_i <= epsilon / (epsilon + M(s)),
+		M(s) = max_j max( sum_{k<s} h_W((A^k)^T e_j), sum_{k<s} h_W(-(A^k)^T e_j) ),
+	where h_W is the support function of W, and returns the robust positively invariant set (1 - alpha)^{-1} F_s
+	with F_s = W + A W + ... + A^{s-1} W computed with Minkowski sums. The result contains F_inf and is contained
+	in F_inf + {x : ||x||_inf <= alpha (1 - alpha)^{-1} M(s)}, and the bound is at most epsilon.
+	A must be strictly stable; otherwise no s satisfies the condition and an error is returned.
+*/
+func MinimalRPIWithOptions(A mat.Matrix, W Polyhedron, epsilon float64, options MinimalRPIOptions) (MinimalRPIApproximation, error) {
+	// Input Processing
+	n, nA := A.Dims()
+	if n != nA {
+		return MinimalRPIApproximation{}, fmt.Errorf("The matrix A must be square; received %v x %v.", n, nA)
+	}
+	if err := W.Check(); err != nil {
+		return MinimalRPIApproximation{}, err
+	}
+	if W.Dimension() != n {
+		return MinimalRPIApproximation{}, fmt.Errorf("The set W has dimension %v; expected %v.", W.Dimension(), n)
+	}
+	if !(epsilon > 0) {
+		return MinimalRPIApproximation{}, fmt.Errorf("The error bound epsilon must be positive; received %v.", epsilon)
+	}
+
+	W, err := W.MinHRep()
+	if err != nil {
+		return MinimalRPIApproximation{}, err
+	}
+	rowsW, _ := W.A.Dims()
+	for i := 0; i < rowsW; i++ {
+		if !(W.b.AtVec(i) > 0) {
+			return MinimalRPIApproximation{}, errors.New("The set W must contain the origin in its interior.")
+		}
+	}
+
+	maxIterations := options.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultMinimalRPIIterations
+	}
+
+	// Algorithm
+	// The partial sums of h_W(A^k^T e_j) and h_W(-A^k^T e_j) over k < s
+	upper := make([]float64, n)
+	lower := make([]float64, n)
+	Ak := eye(n) // A^s at the start of each iteration, after A^{s-1} has been used in the partial sums
+	var Ar mat.Dense
+
+	for s := 1; s <= maxIterations; s++ {
+		// Update M(s) with the term k = s - 1
+		for j := 0; j < n; j++ {
+			direction := mat.VecDenseCopyOf(Ak.RowView(j))
+			hPositive, err := W.Support(direction)
+			if err != nil {
+				return MinimalRPIApproximation{}, err
+			}
+			direction.ScaleVec(-1, direction)
+			hNegative, err := W.Support(direction)
+			if err != nil {
+				return MinimalRPIApproximation{}, err
+			}
+			upper[j] += hPositive
+			lower[j] += hNegative
+		}
+		M := 0.0
+		for j := 0; j < n; j++ {
+			M = math.Max(M, math.Max(upper[j], lower[j]))
+		}
+
+		Ar.Mul(Ak, A)
+		Ak = mat.DenseCopyOf(&Ar)
+
+		// alpha(s) with A^s
+		alpha := 0.0
+		for i := 0; i < rowsW; i++ {
+			var direction mat.VecDense
+			direction.MulVec(Ak.T(), W.row(i))
+			h, err := W.Support(&direction)
+			if err != nil {
+				return MinimalRPIApproximation{}, err
+			}
+			alpha = math.Max(alpha, h/W.b.AtVec(i))
+		}
+
+		if alpha > epsilon/(epsilon+M) {
+			continue
+		}
+
+		set, err := minkowskiPowerSum(A, W, s)
+		if err != nil {
+			return MinimalRPIApproximation{}, err
+		}
+		var b mat.VecDense
+		b.ScaleVec(1/(1-alpha), set.b)
+
+		return MinimalRPIApproximation{
+			Set:   GetPolyhedron(set.A, &b),
+			S:     s,
+			Alpha: alpha,
+			Bound: alpha / (1 - alpha) * M,
+		}, nil
+	}
+
+	return MinimalRPIApproximation{}, fmt.Errorf("The minimal RPI approximation did not reach the error bound with s <= %v; check that A is strictly stable.", maxIterations)
+}
+
+/*
+minkowskiPowerSum
+Description:
+
+	Computes F_s = W + A W + ... + A^{s-1} W with the recursion F_{k+1} = W + A F_k, F_1 = W.
+*/
+func minkowskiPowerSum(A mat.Matrix, W Polyhedron, s int) (Polyhedron, error) {
+	// Algorithm
+	set := W
+	for k := 1; k < s; k++ {
+		image, err := set.AffineMap(A)
+		if err != nil {
+			return Polyhedron{}, err
+		}
+		if set, err = image.Plus(W); err != nil {
+			return Polyhedron{}, err
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,186 @@
+/*
+   schur.go
+   Description:
+       The real Schur decomposition A = Z T Z^T (T upper quasi-triangular, Z orthogonal) and the Hessenberg
+       decomposition, computed with the LAPACK routines of gonum (Dgehrd, Dorghr and Dhseqr).
+*/
+
+package goControl
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/lapack"
+	"gonum.org/v1/gonum/lapack/gonum"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+realSchur
+Description:
+
+	Computes the real Schur decomposition A = Z T Z^T, where T is upper quasi-triangular (with 1 x 1 and 2 x 2
+	diagonal blocks) and Z is orthogonal.
+*/
+func realSchur(A mat.Matrix) (T, Z *mat.Dense, err error) {
+	// Constants
+	n, nA := A.Dims()
+	if n != nA {
+		return nil, nil, errors.New("The Schur decomposition is only defined for square matrices.")
+	}
+	impl := gonum.Implementation{}
+
+	// Reduce to Hessenberg form
+	T, Z = hessenberg(A)
+	tRaw, zRaw := T.RawMatrix(), Z.RawMatrix()
+
+	// Compute the Schur form with the QR algorithm
+	wr, wi := make([]float64, n), make([]float64, n)
+	work := make([]float64, 1)
+	impl.Dhseqr(lapack.EigenvaluesAndSchur, lapack.SchurOrig, n, 0, n-1, tRaw.Data, tRaw.Stride, wr, wi, zRaw.Data, zRaw.Stride, work, -1)
+	lwork := int(work[0])
+	if lwork < n {
+		lwork = n
+	}
+	work = make([]float64, lwork)
+	unconverged := impl.Dhseqr(lapack.EigenvaluesAndSchur, lapack.SchurOrig, n, 0, n-1, tRaw.Data, tRaw.Stride, wr, wi, zRaw.Data, zRaw.Stride, work, len(work))
+	if unconverged > 0 {
+		return nil, nil, errors.New("The QR algorithm for the Schur decomposition did not converge.")
+	}
+
+	return T, Z, nil
+}
+
+/*
+hessenberg
+Description:
+
+	Computes the Hessenberg decomposition A = Q H Q^T, where H is upper Hessenberg (zero below the first subdiagonal)
+	and Q is orthogonal.
+*/
+func hessenberg(A mat.Matrix) (H, Q *mat.Dense) {
+	// Constants
+	n, _ := A.Dims()
+	impl := gonum.Implementation{}
+
+	// Reduce to Hessenberg form
+	H = mat.DenseCopyOf(A)
+	hRaw := H.RawMatrix()
+	tau := make([]float64, n)
+
+	work := make([]float64, 1)
+	impl.Dgehrd(n, 0, n-1, hRaw.Data, hRaw.Stride, tau[:n-1], work, -1)
+	work = make([]float64, int(work[0]))
+	impl.Dgehrd(n, 0, n-1, hRaw.Data, hRaw.Stride, tau[:n-1], work, len(work))
+
+	// Form the orthogonal matrix of the reduction
+	Q = mat.DenseCopyOf(H)
+	qRaw := Q.RawMatrix()
+	work = make([]float64, 1)
+	impl.Dorghr(n, 0, n-1, qRaw.Data, qRaw.Stride, tau[:n-1], work, -1)
+	work = make([]float64, int(work[0]))
+	impl.Dorghr(n, 0, n-1, qRaw.Data, qRaw.Stride, tau[:n-1], work, len(work))
+
+	for i := 2; i < n; i++ {
+		for j := 0; j < i-1; j++ {
+			H.Set(i, j, 0)
+		}
+	}
+
+	return H, Q
+}
+
+/*
+schurBlocks
+Description:
+
+	Returns the starting index and size of each diagonal block of the upper quasi-triangular matrix T.
+*/
+func schurBlocks(T mat.Matrix) (starts, sizes []int) {
+	// Constants
+	n, _ := T.Dims()
+
+	// Algorithm
+	for i := 0; i < n; {
+		starts = append(starts, i)
+		if (i+1 < n) && (T.At(i+1, i) != 0) {
+			sizes = append(sizes, 2)
+			i += 2
+		} else {
+			sizes = append(sizes, 1)
+			i++
+		}
+	}
+	return starts, sizes
+}
+
+/*
+orderedSchur
+Description:
+
+	Computes the real Schur decomposition A = Z T Z^T and reorders it (with Dtrexc) so that the eigenvalues for which
+	selected returns true appear in the leading diagonal blocks of T. The number of selected eigenvalues is returned
+	as k, so that the first k columns of Z span the corresponding invariant subspace.
+*/
+func orderedSchur(A mat.Matrix, selected func(lambda complex128) bool) (T, Z *mat.Dense, k int, err error) {
+	// Constants
+	n, _ := A.Dims()
+	impl := gonum.Implementation{}
+
+	// Algorithm
+	T, Z, err = realSchur(A)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	tRaw, zRaw := T.RawMatrix(), Z.RawMatrix()
+	work := make([]float64, n)
+
+	for {
+		// Find the first selected block that is not yet in the leading part of T
+		starts, sizes := schurBlocks(T)
+		found := false
+		for b := range starts {
+			if starts[b] < k || !selected(schurBlockEigenvalue(T, starts[b], sizes[b])) {
+				continue
+			}
+			if starts[b] == k {
+				k += sizes[b]
+				found = true
+				break
+			}
+
+			_, ilst, ok := impl.Dtrexc(lapack.UpdateSchur, n, tRaw.Data, tRaw.Stride, zRaw.Data, zRaw.Stride, starts[b], k, work)
+			if !ok {
+				return nil, nil, 0, errors.New("The Schur form could not be reordered; two of its eigenvalues are too close.")
+			}
+			_, newSizes := schurBlocks(T.Slice(ilst, n, ilst, n))
+			k = ilst + newSizes[0]
+			found = true
+			break
+		}
+		if !found {
+			break
+		}
+	}
+
+	return T, Z, k, nil
+}
+
+/*
+schurBlockEigenvalue
+Description:
+
+	Returns an eigenvalue of the diagonal block of T that starts at index i and has the given size.
+	For 2 x 2 blocks, the eigenvalue with positive imaginary part is returned.
+*/
+func schurBlockEigenvalue(T mat.Matrix, i, size int) complex128 {
+	if size == 1 {
+		return complex(T.At(i, i), 0)
+	}
+
+	a, b, c, d := T.At(i, i), T.At(i, i+1), T.At(i+1, i), T.At(i+1, i+1)
+	halfTrace := 0.5 * (a + d)
+	discriminant := 0.25*(a-d)*(a-d) + b*c
+	return complex(halfTrace, math.Sqrt(math.Abs(discriminant)))
+}
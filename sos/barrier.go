@@ -0,0 +1,344 @@
+/*
+   barrier.go
+   Description:
+       Synthesis of barrier certificates for the safety of polynomial dynamics dx/dt = f(x): a polynomial B with
+       B <= 0 on the initial set, B > 0 on the unsafe set and dB/dt <= 0 on the domain, so that no trajectory starting
+       in the initial set reaches the unsafe set. The set conditions are relaxed to SOS conditions with SOS
+       multipliers (the S-procedure), and the result can be checked independently of the solver.
+*/
+
+package sos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kwesiRutledge/goControl/symbolic"
+)
+
+type BarrierProblem struct {
+	Variables []symbolic.Variable   // State x
+	Dynamics  []symbolic.Polynomial // Vector field f(x)
+	Initial   []symbolic.Polynomial // Initial set {x : g(x) >= 0 for each g}
+	Unsafe    []symbolic.Polynomial // Unsafe set {x : h(x) >= 0 for each h}
+	Domain    []symbolic.Polynomial // Set {x : d(x) >= 0 for each d} where dB/dt <= 0 is required (empty for everywhere)
+}
+
+type BarrierOptions struct {
+	Margin           float64    // eps > 0 in B >= eps on the unsafe set (0 selects 0.1)
+	MultiplierDegree int        // Degree of the SOS multipliers (0 selects the largest even degree that fits each condition)
+	SDP              SDPOptions // Options of the semidefinite programming solver
+}
+
+type BarrierCertificate struct {
+	Problem            BarrierProblem
+	B                  symbolic.Polynomial   // Barrier function
+	Derivative         symbolic.Polynomial   // dB/dt = grad B(x) . f(x)
+	Margin             float64               // B >= Margin on the unsafe set
+	InitialMultipliers []symbolic.Polynomial // SOS multipliers s_i of the inequalities of the initial set
+	UnsafeMultipliers  []symbolic.Polynomial // SOS multipliers t_j of the inequalities of the unsafe set
+	DomainMultipliers  []symbolic.Polynomial // SOS multipliers u_k of the inequalities of the domain
+	Initial            GramCertificate       // -B - sum_i s_i g_i = z^T Q z
+	Unsafe             GramCertificate       // B - Margin - sum_j t_j h_j = z^T Q z
+	Decrease           GramCertificate       // -dB/dt - sum_k u_k d_k = z^T Q z
+	Multipliers        []GramCertificate     // Gram matrices of the multipliers (initial, unsafe, then domain)
+	Solution           SDPSolution           // Solution of the underlying semidefinite program
+}
+
+/*
+Check
+Description:
+
+	Verifies that the dynamics have one component per variable and that all the polynomials of the problem are in
+	its variables.
+*/
+func (problem BarrierProblem) Check() error {
+	// Input Processing
+	if len(problem.Dynamics) != len(problem.Variables) {
+		return fmt.Errorf("The dynamics have %v components, but there are %v variables.", len(problem.Dynamics), len(problem.Variables))
+	}
+	if len(problem.Initial) == 0 {
+		return errors.New("The initial set must be described by at least one inequality.")
+	}
+	if len(problem.Unsafe) == 0 {
+		return errors.New("The unsafe set must be described by at least one inequality.")
+	}
+
+	prog, err := GetProgram(problem.Variables)
+	if err != nil {
+		return err
+	}
+	for _, group := range []struct {
+		polynomials []symbolic.Polynomial
+		name        string
+	}{
+		{problem.Dynamics, "dynamics"},
+		{problem.Initial, "initial set"},
+		{problem.Unsafe, "unsafe set"},
+		{problem.Domain, "domain"},
+	} {
+		for i, p := range group.polynomials {
+			if _, err := prog.Constant(p); err != nil {
+				return fmt.Errorf("Polynomial %v of the %v: %v", i+1, group.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+FindBarrier
+Description:
+
+	Searches for a barrier certificate of the given degree with the default options.
+*/
+func FindBarrier(problem BarrierProblem, degree int) (BarrierCertificate, error) {
+	return FindBarrierWithOptions(problem, degree, BarrierOptions{})
+}
+
+/*
+FindBarrierWithOptions
+Description:
+
+	Searches for a polynomial B of the given degree and SOS multipliers s_i, t_j, u_k such that
+		-B - sum_i s_i g_i            is SOS (B <= 0 on the initial set),
+		B - eps - sum_j t_j h_j       is SOS (B >= eps on the unsafe set),
+		-grad B . f - sum_k u_k d_k   is SOS (dB/dt <= 0 on the domain),
+	by building the Gram matrix semidefinite program of these conditions and solving it. When the domain is the
+	whole space (or contains the reachable set), the zero level set of B separates the trajectories from the initial
+	set from the unsafe set. Returns an error if no such certificate of that degree exists or if the solution does
+	not pass Verify.
+*/
+func FindBarrierWithOptions(problem BarrierProblem, degree int, options BarrierOptions) (BarrierCertificate, error) {
+	// Input Processing
+	if err := problem.Check(); err != nil {
+		return BarrierCertificate{}, err
+	}
+	if degree < 1 {
+		return BarrierCertificate{}, fmt.Errorf("The degree of the barrier certificate must be positive; received %v.", degree)
+	}
+	if (options.Margin < 0) || (options.MultiplierDegree < 0) {
+		return BarrierCertificate{}, errors.New("The margin and the degree of the multipliers must be nonnegative.")
+	}
+	if options.Margin == 0 {
+		options.Margin = 0.1
+	}
+
+	// Algorithm
+	prog, _ := GetProgram(problem.Variables)
+	dynamics, err := programPolynomials(&prog, problem.Dynamics)
+	if err != nil {
+		return BarrierCertificate{}, err
+	}
+	B, err := prog.NewPolynomial(0, degree)
+	if err != nil {
+		return BarrierCertificate{}, err
+	}
+	Bdot, err := lieDerivative(B, problem.Variables, dynamics)
+	if err != nil {
+		return BarrierCertificate{}, err
+	}
+	margin, _ := prog.Constant(options.Margin)
+
+	var multipliers [][]AffinePolynomial
+	var multiplierConstraints []int
+	conditions := []AffinePolynomial{B.Scale(-1), B.Minus(margin), Bdot.Scale(-1)}
+	for c, set := range [][]symbolic.Polynomial{problem.Initial, problem.Unsafe, problem.Domain} {
+		setMultipliers := make([]AffinePolynomial, len(set))
+		for i, g := range set {
+			gi, _ := prog.Constant(g)
+			multiplierDegree := options.MultiplierDegree
+			if multiplierDegree == 0 {
+				multiplierDegree = maxInt(0, conditions[c].Degree()-gi.Degree())
+				multiplierDegree -= multiplierDegree % 2
+			}
+
+			if setMultipliers[i], err = prog.NewPolynomial(0, multiplierDegree); err != nil {
+				return BarrierCertificate{}, err
+			}
+			index, err := prog.AddSOSConstraint(setMultipliers[i])
+			if err != nil {
+				return BarrierCertificate{}, err
+			}
+			multiplierConstraints = append(multiplierConstraints, index)
+
+			product, err := setMultipliers[i].Multiply(gi)
+			if err != nil {
+				return BarrierCertificate{}, err
+			}
+			conditions[c] = conditions[c].Minus(product)
+		}
+		multipliers = append(multipliers, setMultipliers)
+	}
+
+	constraints := make([]int, len(conditions))
+	for c, condition := range conditions {
+		if constraints[c], err = prog.AddSOSConstraint(condition); err != nil {
+			return BarrierCertificate{}, fmt.Errorf("No barrier certificate of degree %v exists for this problem: %v", degree, err)
+		}
+	}
+
+	solution, err := prog.SolveWithOptions(options.SDP)
+	if err != nil {
+		return BarrierCertificate{}, err
+	}
+	if err := statusError(solution.SDP); err != nil {
+		return BarrierCertificate{}, fmt.Errorf("No barrier certificate of degree %v was found: %v", degree, err)
+	}
+
+	// Output
+	certificate := BarrierCertificate{
+		Problem:    problem,
+		B:          solution.Value(B),
+		Derivative: solution.Value(Bdot),
+		Margin:     options.Margin,
+		Solution:   solution.SDP,
+	}
+	for c, setMultipliers := range multipliers {
+		values := make([]symbolic.Polynomial, len(setMultipliers))
+		for i, multiplier := range setMultipliers {
+			values[i] = solution.Value(multiplier)
+		}
+		switch c {
+		case 0:
+			certificate.InitialMultipliers = values
+		case 1:
+			certificate.UnsafeMultipliers = values
+		default:
+			certificate.DomainMultipliers = values
+		}
+	}
+	for _, index := range multiplierConstraints {
+		gram, err := solution.Certificate(index)
+		if err != nil {
+			return BarrierCertificate{}, err
+		}
+		certificate.Multipliers = append(certificate.Multipliers, gram)
+	}
+	for c, target := range []*GramCertificate{&certificate.Initial, &certificate.Unsafe, &certificate.Decrease} {
+		if *target, err = solution.Certificate(constraints[c]); err != nil {
+			return BarrierCertificate{}, err
+		}
+	}
+	if err := certificate.Verify(certificateTolerance); err != nil {
+		return BarrierCertificate{}, fmt.Errorf("The barrier certificate of degree %v found by the solver is not certified: %v", degree, err)
+	}
+	return certificate, nil
+}
+
+/*
+Verify
+Description:
+
+	Checks the three claims of the certificate on the problem it was built for: B <= 0 on the initial set,
+	B >= Margin on the unsafe set and dB/dt <= 0 on the domain. Each claim is rebuilt from B, the dynamics and the
+	inequalities of its set together with their stored multipliers, so that a multiplier that is not a sum of squares
+	or that was changed after the search invalidates the claim of its set. The tolerance is the one of verifyGram.
+	Returns an error naming the first claim or multiplier that fails.
+*/
+func (certificate BarrierCertificate) Verify(tolerance float64) error {
+	// Input Processing
+	if !(tolerance > 0) {
+		return fmt.Errorf("The tolerance must be positive; received %v.", tolerance)
+	}
+	if err := certificate.Problem.Check(); err != nil {
+		return err
+	}
+	sets := [][]symbolic.Polynomial{certificate.Problem.Initial, certificate.Problem.Unsafe, certificate.Problem.Domain}
+	setMultipliers := [][]symbolic.Polynomial{certificate.InitialMultipliers, certificate.UnsafeMultipliers, certificate.DomainMultipliers}
+	count := 0
+	for c := range sets {
+		if len(setMultipliers[c]) != len(sets[c]) {
+			return fmt.Errorf("The certificate has %v multipliers for a set with %v inequalities.", len(setMultipliers[c]), len(sets[c]))
+		}
+		count += len(sets[c])
+	}
+	if len(certificate.Multipliers) != count {
+		return fmt.Errorf("The certificate has %v Gram matrices for %v multipliers.", len(certificate.Multipliers), count)
+	}
+
+	// Constants
+	prog, _ := GetProgram(certificate.Problem.Variables)
+	B, err := prog.Constant(certificate.B)
+	if err != nil {
+		return fmt.Errorf("The barrier function: %v", err)
+	}
+	dynamics, _ := programPolynomials(&prog, certificate.Problem.Dynamics)
+	Bdot, err := lieDerivative(B, certificate.Problem.Variables, dynamics)
+	if err != nil {
+		return err
+	}
+	margin, _ := prog.Constant(certificate.Margin)
+
+	// Algorithm
+	names := []string{"initial set", "unsafe set", "domain"}
+	conditions := []AffinePolynomial{B.Scale(-1), B.Minus(margin), Bdot.Scale(-1)}
+	grams := []GramCertificate{certificate.Initial, certificate.Unsafe, certificate.Decrease}
+	index := 0
+	for c, set := range sets {
+		for i, g := range set {
+			multiplier, err := prog.Constant(setMultipliers[c][i])
+			if err != nil {
+				return fmt.Errorf("Multiplier %v of the %v: %v", i+1, names[c], err)
+			}
+			if err := verifyGram(&prog, multiplier, certificate.Multipliers[index], tolerance); err != nil {
+				return fmt.Errorf("Multiplier %v of the %v is not certified: %v", i+1, names[c], err)
+			}
+			index++
+
+			gi, _ := prog.Constant(g)
+			product, _ := multiplier.Multiply(gi)
+			conditions[c] = conditions[c].Minus(product)
+		}
+		if err := verifyGram(&prog, conditions[c], grams[c], tolerance); err != nil {
+			return fmt.Errorf("The condition on the %v is not certified: %v", names[c], err)
+		}
+	}
+	return nil
+}
+
+/*
+verifyGram
+Description:
+
+	Returns an error if the Gram matrix is not positive semidefinite or if the fixed polynomial p differs from
+	z^T Q z in some coefficient. Every Verify method of the package reduces to this check, and this is where their
+	tolerance is interpreted: both bounds are the tolerance times the largest coefficient of p (or the tolerance
+	itself if no coefficient exceeds 1), so that they follow the scale of each condition, which the multipliers
+	and the levels can change arbitrarily.
+*/
+func verifyGram(prog *Program, p AffinePolynomial, gram GramCertificate, tolerance float64) error {
+	// Constants
+	tolerance *= coefficientScale(p)
+
+	// Algorithm
+	if eigenvalue := gram.MinEigenvalue(); !(eigenvalue >= -tolerance) {
+		return fmt.Errorf("the Gram matrix has the negative eigenvalue %v.", eigenvalue)
+	}
+	expansion, err := prog.Constant(gram.Polynomial())
+	if err != nil {
+		return err
+	}
+	for _, term := range p.Minus(expansion).terms {
+		if difference := term.coefficient.constant; !(math.Abs(difference) <= tolerance) {
+			return fmt.Errorf("the coefficient of %v differs from the Gram polynomial by %v.", exponentsToMonomial(prog.Variables, term.exponents, 1), difference)
+		}
+	}
+	return nil
+}
+
+/*
+coefficientScale
+Description:
+
+	Returns the largest absolute value of the coefficients of a polynomial without decision variables, or 1 if it is
+	larger.
+*/
+func coefficientScale(p AffinePolynomial) float64 {
+	scale := 1.0
+	for _, term := range p.terms {
+		scale = math.Max(scale, math.Abs(term.coefficient.constant))
+	}
+	return scale
+}
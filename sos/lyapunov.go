@@ -0,0 +1,289 @@
+/*
+   lyapunov.go
+   Description:
+       Search for polynomial Lyapunov functions of polynomial dynamics dx/dt = f(x) with sum of squares programming:
+       V - eps1 |x|^2 and -dV/dt - eps2 |x|^2 must both be sums of squares.
+*/
+
+package sos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kwesiRutledge/goControl/symbolic"
+)
+
+// certificateTolerance is the tolerance, relative to the largest coefficient, with which the certificates returned by
+// the searches are verified.
+const certificateTolerance = 1e-6
+
+type LyapunovOptions struct {
+	PositivityMargin float64    // eps1 > 0 in V - eps1 |x|^2 SOS (0 selects 1)
+	DecreaseMargin   float64    // eps2 >= 0 in -dV/dt - eps2 |x|^2 SOS (0 only requires -dV/dt to be SOS)
+	SDP              SDPOptions // Options of the semidefinite programming solver
+}
+
+type LyapunovCertificate struct {
+	Variables        []symbolic.Variable   // State variables x
+	Dynamics         []symbolic.Polynomial // f(x)
+	V                symbolic.Polynomial   // Lyapunov function (V(0) = 0)
+	Derivative       symbolic.Polynomial   // dV/dt = grad V(x) . f(x)
+	Positivity       GramCertificate       // V - PositivityMargin |x|^2 = z^T P z
+	Decrease         GramCertificate       // -dV/dt - DecreaseMargin |x|^2 = w^T Q w
+	PositivityMargin float64
+	DecreaseMargin   float64
+	Solution         SDPSolution // Solution of the underlying semidefinite program
+}
+
+/*
+FindLyapunov
+Description:
+
+	Searches for a polynomial Lyapunov function V of the given (even) degree for the dynamics dx/dt = f(x), where
+	f(0) = 0, with the default options. For a NonlinearSystem without inputs, use its States and Dynamics.
+*/
+func FindLyapunov(x []symbolic.Variable, f []symbolic.Polynomial, degree int) (LyapunovCertificate, error) {
+	return FindLyapunovWithOptions(x, f, degree, LyapunovOptions{})
+}
+
+/*
+FindLyapunovWithOptions
+Description:
+
+	Searches for a polynomial V(x) = sum_(2 <= |a| <= degree) c_a x^a such that
+		V - eps1 |x|^2          is SOS (so V is positive definite),
+		-grad V . f - eps2 |x|^2 is SOS (so dV/dt is negative semidefinite, or negative definite if eps2 > 0),
+	by building the Gram matrix semidefinite program of the two constraints and solving it. Returns V with the Gram
+	matrices that certify both constraints, or an error if no such V of that degree exists (the SOS conditions are
+	sufficient only, so a higher degree may succeed) or if the solution does not pass Verify.
+*/
+func FindLyapunovWithOptions(x []symbolic.Variable, f []symbolic.Polynomial, degree int, options LyapunovOptions) (LyapunovCertificate, error) {
+	// Input Processing
+	if len(f) != len(x) {
+		return LyapunovCertificate{}, fmt.Errorf("The dynamics have %v components, but there are %v variables.", len(f), len(x))
+	}
+	if (degree < 2) || (degree%2 != 0) {
+		return LyapunovCertificate{}, fmt.Errorf("The degree of the Lyapunov function must be even and at least 2; received %v.", degree)
+	}
+	if options.PositivityMargin < 0 || options.DecreaseMargin < 0 {
+		return LyapunovCertificate{}, errors.New("The positivity and decrease margins must be nonnegative.")
+	}
+	if options.PositivityMargin == 0 {
+		options.PositivityMargin = 1
+	}
+
+	prog, err := GetProgram(x)
+	if err != nil {
+		return LyapunovCertificate{}, err
+	}
+	dynamics, err := programPolynomials(&prog, f)
+	if err != nil {
+		return LyapunovCertificate{}, err
+	}
+	for i, fi := range dynamics {
+		if fi.constantTerm() != 0 {
+			return LyapunovCertificate{}, fmt.Errorf("The origin must be an equilibrium, but f%v(0) = %v.", i+1, fi.constantTerm())
+		}
+	}
+
+	// Algorithm
+	V, err := prog.NewPolynomial(2, degree)
+	if err != nil {
+		return LyapunovCertificate{}, err
+	}
+	Vdot, err := lieDerivative(V, x, dynamics)
+	if err != nil {
+		return LyapunovCertificate{}, err
+	}
+	normSquared, err := prog.Constant(squaredNorm(x))
+	if err != nil {
+		return LyapunovCertificate{}, err
+	}
+
+	positivity, err := prog.AddSOSConstraint(V.Minus(normSquared.Scale(options.PositivityMargin)))
+	if err != nil {
+		return LyapunovCertificate{}, err
+	}
+	decrease, err := prog.AddSOSConstraint(Vdot.Scale(-1).Minus(normSquared.Scale(options.DecreaseMargin)))
+	if err != nil {
+		return LyapunovCertificate{}, fmt.Errorf("No Lyapunov function of degree %v exists for these dynamics: %v", degree, err)
+	}
+
+	solution, err := prog.SolveWithOptions(options.SDP)
+	if err != nil {
+		return LyapunovCertificate{}, err
+	}
+	if err := statusError(solution.SDP); err != nil {
+		return LyapunovCertificate{}, fmt.Errorf("No Lyapunov function of degree %v was found: %v", degree, err)
+	}
+
+	// Output
+	certificate := LyapunovCertificate{
+		Variables:        x,
+		Dynamics:         f,
+		V:                solution.Value(V),
+		Derivative:       solution.Value(Vdot),
+		PositivityMargin: options.PositivityMargin,
+		DecreaseMargin:   options.DecreaseMargin,
+		Solution:         solution.SDP,
+	}
+	if certificate.Positivity, err = solution.Certificate(positivity); err != nil {
+		return LyapunovCertificate{}, err
+	}
+	if certificate.Decrease, err = solution.Certificate(decrease); err != nil {
+		return LyapunovCertificate{}, err
+	}
+	if err := certificate.Verify(certificateTolerance); err != nil {
+		return LyapunovCertificate{}, fmt.Errorf("The Lyapunov function of degree %v found by the solver is not certified: %v", degree, err)
+	}
+	return certificate, nil
+}
+
+/*
+Verify
+Description:
+
+	Checks that V is a Lyapunov function of the stored dynamics. The decrease condition is certified for dV/dt
+	recomputed as grad V . f, so the stored Derivative, which callers evaluate to inspect the decrease, must agree
+	with it; a certificate copied onto other dynamics, or whose Derivative was edited, is rejected here even when its
+	Gram matrices are valid. The derivative is compared with the same relative tolerance as the Gram polynomials
+	(see verifyGram).
+*/
+func (certificate LyapunovCertificate) Verify(tolerance float64) error {
+	// Input Processing
+	if !(tolerance > 0) {
+		return fmt.Errorf("The tolerance must be positive; received %v.", tolerance)
+	}
+	if len(certificate.Dynamics) != len(certificate.Variables) {
+		return fmt.Errorf("The dynamics have %v components, but there are %v variables.", len(certificate.Dynamics), len(certificate.Variables))
+	}
+
+	// Constants
+	prog, err := GetProgram(certificate.Variables)
+	if err != nil {
+		return err
+	}
+	V, err := prog.Constant(certificate.V)
+	if err != nil {
+		return fmt.Errorf("The Lyapunov function: %v", err)
+	}
+	dynamics, err := programPolynomials(&prog, certificate.Dynamics)
+	if err != nil {
+		return err
+	}
+	Vdot, err := lieDerivative(V, certificate.Variables, dynamics)
+	if err != nil {
+		return err
+	}
+	normSquared, _ := prog.Constant(squaredNorm(certificate.Variables))
+	derivative, err := prog.Constant(certificate.Derivative)
+	if err != nil {
+		return fmt.Errorf("The derivative: %v", err)
+	}
+
+	// Algorithm
+	bound := tolerance * coefficientScale(Vdot)
+	for _, term := range derivative.Minus(Vdot).terms {
+		if difference := term.coefficient.constant; !(math.Abs(difference) <= bound) {
+			return fmt.Errorf("The derivative differs from grad V . f by %v in the coefficient of %v.", difference, exponentsToMonomial(prog.Variables, term.exponents, 1))
+		}
+	}
+	if err := verifyGram(&prog, V.Minus(normSquared.Scale(certificate.PositivityMargin)), certificate.Positivity, tolerance); err != nil {
+		return fmt.Errorf("The positivity of V is not certified: %v", err)
+	}
+	if err := verifyGram(&prog, Vdot.Scale(-1).Minus(normSquared.Scale(certificate.DecreaseMargin)), certificate.Decrease, tolerance); err != nil {
+		return fmt.Errorf("The decrease of V is not certified: %v", err)
+	}
+	return nil
+}
+
+/*
+programPolynomials
+Description:
+
+	Converts the polynomials f into AffinePolynomials of the program.
+*/
+func programPolynomials(prog *Program, f []symbolic.Polynomial) ([]AffinePolynomial, error) {
+	// Algorithm
+	result := make([]AffinePolynomial, len(f))
+	for i, fi := range f {
+		var err error
+		if result[i], err = prog.Constant(fi); err != nil {
+			return nil, fmt.Errorf("Component %v of the dynamics: %v", i+1, err)
+		}
+	}
+	return result, nil
+}
+
+/*
+lieDerivative
+Description:
+
+	Returns the derivative dV/dt = sum_i dV/dx_i f_i of V along the dynamics f.
+*/
+func lieDerivative(V AffinePolynomial, x []symbolic.Variable, f []AffinePolynomial) (AffinePolynomial, error) {
+	// Algorithm
+	result := V.Scale(0)
+	for i, xi := range x {
+		partial, err := V.Derivative(xi)
+		if err != nil {
+			return AffinePolynomial{}, err
+		}
+		product, err := partial.Multiply(f[i])
+		if err != nil {
+			return AffinePolynomial{}, err
+		}
+		result = result.Plus(product)
+	}
+	return result, nil
+}
+
+/*
+squaredNorm
+Description:
+
+	Returns the polynomial |x|^2 = sum_i x_i^2.
+*/
+func squaredNorm(x []symbolic.Variable) symbolic.Polynomial {
+	result := symbolic.Polynomial{Monomials: []symbolic.Monomial{}}
+	for _, xi := range x {
+		result.Monomials = append(result.Monomials, symbolic.Monomial{Coefficient: 1, Variables: []symbolic.Variable{xi}, Exponents: []int{2}})
+	}
+	return result
+}
+
+/*
+statusError
+Description:
+
+	Returns an error describing the status of the solution of a sum of squares program, or nil if it is (nearly)
+	optimal. The messages are lowercase on purpose: they are always wrapped after a colon by a capitalized sentence
+	of the caller (e.g. "No Lyapunov function of degree 4 was found: the sum of squares program is infeasible.").
+*/
+func statusError(solution SDPSolution) error {
+	switch solution.Status {
+	case SDPOptimal, SDPNearOptimal:
+		return nil
+	case SDPPrimalInfeasible:
+		return errors.New("the sum of squares program is infeasible.")
+	case SDPDualInfeasible:
+		return errors.New("the sum of squares program is unbounded.")
+	default:
+		return fmt.Errorf("the semidefinite program did not converge in %v iterations.", solution.Iterations)
+	}
+}
+
+/*
+constantTerm
+Description:
+
+	Returns the constant coefficient of a polynomial without decision variables.
+*/
+func (p AffinePolynomial) constantTerm() float64 {
+	if term, ok := p.terms[exponentKey(make([]int, len(p.variables)))]; ok {
+		return term.coefficient.constant
+	}
+	return 0
+}
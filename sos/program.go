@@ -0,0 +1,1001 @@
+/*
+   program.go
+   Description:
+       Sum of squares programs "like" those of SOSTOOLS: polynomials in the variables x whose coefficients are affine
+       in decision variables, constrained to be sums of squares (through Gram matrices) or identically zero, and
+       converted into a SemidefiniteProgram.
+*/
+
+package sos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kwesiRutledge/goControl/symbolic"
+	"gonum.org/v1/gonum/mat"
+)
+
+// defaultFeasibilityTolerance is the size of a constant coefficient beyond which a constraint without decision
+// variables is violated.
+const defaultFeasibilityTolerance = 1e-9
+
+type Program struct {
+	Variables []symbolic.Variable // Indeterminates x of the polynomials
+
+	bases      [][][]int          // Monomial basis (as exponent vectors) of each Gram matrix
+	numFree    int                // Number of free decision variables
+	equalities []AffinePolynomial // Polynomials constrained to be identically zero
+	sosBlocks  []int              // Gram matrix of each SOS constraint (-1 if the polynomial is zero)
+	objective  *linearForm        // Objective to minimize (nil for a feasibility problem)
+}
+
+type AffinePolynomial struct {
+	variables []symbolic.Variable
+	terms     map[string]polynomialTerm
+}
+
+type GramCertificate struct {
+	Basis []symbolic.Monomial // Monomial basis z
+	Gram  *mat.SymDense       // Positive semidefinite Gram matrix Q, such that the polynomial is z^T Q z
+}
+
+type ProgramSolution struct {
+	SDP     SDPSolution // Solution of the underlying semidefinite program
+	program Program
+}
+
+/*
+polynomialTerm
+Description:
+
+	A term c(d) x^exponents of an AffinePolynomial, whose coefficient c is affine in the decision variables d.
+*/
+type polynomialTerm struct {
+	exponents   []int
+	coefficient linearForm
+}
+
+/*
+gramIndex
+Description:
+
+	The entry (row, column) of the Gram matrix block, with row <= column.
+*/
+type gramIndex struct {
+	block, row, column int
+}
+
+/*
+linearForm
+Description:
+
+	The affine function constant + sum_i free[i] d_i + sum_(k,r,c) gram[(k,r,c)] X_k(r,c) of the decision variables.
+*/
+type linearForm struct {
+	constant float64
+	free     map[int]float64
+	gram     map[gramIndex]float64
+}
+
+/*
+GetProgram
+Description:
+
+	Creates an empty sum of squares program whose polynomials are in the variables x.
+*/
+func GetProgram(x []symbolic.Variable) (Program, error) {
+	// Input Processing
+	if len(x) == 0 {
+		return Program{}, errors.New("A sum of squares program needs at least one variable.")
+	}
+	for i, v := range x {
+		if v.FoundIn(x[:i]) != -1 {
+			return Program{}, fmt.Errorf("The variable %v appears more than once.", v)
+		}
+	}
+
+	return Program{Variables: append([]symbolic.Variable{}, x...)}, nil
+}
+
+/*
+Constant
+Description:
+
+	Converts a constant, Variable, Monomial or Polynomial in the variables of the program into an AffinePolynomial
+	without decision variables.
+*/
+func (prog *Program) Constant(p interface{}) (AffinePolynomial, error) {
+	// Input Processing
+	polynomial, err := symbolic.ToPolynomial(p)
+	if err != nil {
+		return AffinePolynomial{}, err
+	}
+
+	// Algorithm
+	result := prog.zero()
+	for _, monomial := range polynomial.Monomials {
+		exponents := make([]int, len(prog.Variables))
+		for varIndex, v := range monomial.Variables {
+			index := v.FoundIn(prog.Variables)
+			if index == -1 {
+				return AffinePolynomial{}, fmt.Errorf("The variable %v is not a variable of the program.", v)
+			}
+			exponents[index] += monomial.Exponents[varIndex]
+		}
+		result.addTerm(exponents, linearForm{constant: monomial.Coefficient})
+	}
+	return result, nil
+}
+
+/*
+NewVariable
+Description:
+
+	Creates a scalar (degree zero) decision variable.
+*/
+func (prog *Program) NewVariable() AffinePolynomial {
+	// Algorithm
+	result := prog.zero()
+	result.addTerm(make([]int, len(prog.Variables)), linearForm{free: map[int]float64{prog.numFree: 1}})
+	prog.numFree++
+	return result
+}
+
+/*
+NewPolynomial
+Description:
+
+	Creates a polynomial with a free decision variable as the coefficient of each monomial whose degree is between
+	minDegree and maxDegree.
+*/
+func (prog *Program) NewPolynomial(minDegree, maxDegree int) (AffinePolynomial, error) {
+	// Input Processing
+	if (minDegree < 0) || (maxDegree < minDegree) {
+		return AffinePolynomial{}, fmt.Errorf("The degrees must satisfy 0 <= minDegree <= maxDegree; received %v and %v.", minDegree, maxDegree)
+	}
+
+	// Algorithm
+	result := prog.zero()
+	for _, exponents := range monomialExponents(len(prog.Variables), minDegree, maxDegree, nil, nil) {
+		result.addTerm(exponents, linearForm{free: map[int]float64{prog.numFree: 1}})
+		prog.numFree++
+	}
+	return result, nil
+}
+
+/*
+NewSOSPolynomial
+Description:
+
+	Creates a sum of squares polynomial z^T Q z with a new positive semidefinite Gram matrix Q, where z contains the
+	monomials whose degree is between ceil(minDegree / 2) and floor(maxDegree / 2).
+*/
+func (prog *Program) NewSOSPolynomial(minDegree, maxDegree int) (AffinePolynomial, error) {
+	// Input Processing
+	if (minDegree < 0) || (maxDegree < minDegree) {
+		return AffinePolynomial{}, fmt.Errorf("The degrees must satisfy 0 <= minDegree <= maxDegree; received %v and %v.", minDegree, maxDegree)
+	}
+	basis := monomialExponents(len(prog.Variables), (minDegree+1)/2, maxDegree/2, nil, nil)
+	if len(basis) == 0 {
+		return AffinePolynomial{}, fmt.Errorf("There is no monomial of degree between %v and %v.", (minDegree+1)/2, maxDegree/2)
+	}
+
+	// Algorithm
+	return prog.gramPolynomial(prog.addGram(basis)), nil
+}
+
+/*
+AddEqualityConstraint
+Description:
+
+	Constrains the polynomial p to be identically zero (each of its coefficients is zero).
+*/
+func (prog *Program) AddEqualityConstraint(p AffinePolynomial) error {
+	// Input Processing
+	if err := prog.checkPolynomial(p); err != nil {
+		return err
+	}
+
+	// Algorithm
+	prog.equalities = append(prog.equalities, p.copy())
+	return nil
+}
+
+/*
+AddSOSConstraint
+Description:
+
+	Constrains the polynomial p to be a sum of squares, p = z^T Q z with Q positive semidefinite, and returns the
+	index of the constraint (used to retrieve its GramCertificate). The basis z is pruned with the Newton polytope of
+	p: its monomials have a total degree between half of the smallest and of the largest degree of p, and the
+	exponent of each variable is at most half of its largest exponent in p. Monomials whose square cannot appear in
+	p are then removed (see pruneBasis).
+*/
+func (prog *Program) AddSOSConstraint(p AffinePolynomial) (int, error) {
+	// Input Processing
+	if err := prog.checkPolynomial(p); err != nil {
+		return -1, err
+	}
+
+	// Algorithm
+	n := len(prog.Variables)
+	minDegree, maxDegree := math.MaxInt32, -1
+	lower, upper := make([]int, n), make([]int, n)
+	for i := range lower {
+		lower[i] = math.MaxInt32
+	}
+	for _, term := range p.terms {
+		if term.coefficient.isZero() {
+			continue
+		}
+		degree := 0
+		for i, exponent := range term.exponents {
+			degree += exponent
+			lower[i], upper[i] = minInt(lower[i], exponent), maxInt(upper[i], exponent)
+		}
+		minDegree, maxDegree = minInt(minDegree, degree), maxInt(maxDegree, degree)
+	}
+
+	block := -1
+	constraint := p.copy()
+	if maxDegree >= 0 {
+		for i := range lower {
+			lower[i], upper[i] = (lower[i]+1)/2, upper[i]/2
+		}
+		basis := pruneBasis(monomialExponents(n, (minDegree+1)/2, maxDegree/2, lower, upper), p)
+		if len(basis) == 0 {
+			return -1, errors.New("The polynomial cannot be a sum of squares: its Newton polytope contains no monomial basis.")
+		}
+		block = prog.addGram(basis)
+		constraint = constraint.Minus(prog.gramPolynomial(block))
+	}
+
+	prog.equalities = append(prog.equalities, constraint)
+	prog.sosBlocks = append(prog.sosBlocks, block)
+	return len(prog.sosBlocks) - 1, nil
+}
+
+/*
+Minimize
+Description:
+
+	Sets the objective of the program to the scalar (degree zero) affine function objective of the decision
+	variables. Without an objective, the program is a feasibility problem, and its solution is a point in the
+	interior of the feasible set (strictly positive definite Gram matrices whenever possible).
+*/
+func (prog *Program) Minimize(objective AffinePolynomial) error {
+	// Input Processing
+	if err := prog.checkPolynomial(objective); err != nil {
+		return err
+	}
+	if objective.Degree() > 0 {
+		return fmt.Errorf("The objective must be a scalar (degree zero); received a polynomial of degree %v.", objective.Degree())
+	}
+
+	// Algorithm
+	form := linearForm{}
+	if term, ok := objective.terms[exponentKey(make([]int, len(objective.variables)))]; ok {
+		form = term.coefficient.copy()
+	}
+	prog.objective = &form
+	return nil
+}
+
+/*
+Solve
+Description:
+
+	Solves the program with the default options of the semidefinite programming solver.
+*/
+func (prog Program) Solve() (ProgramSolution, error) {
+	return prog.SolveWithOptions(SDPOptions{})
+}
+
+/*
+SolveWithOptions
+Description:
+
+	Converts the program into a SemidefiniteProgram (one equality constraint per coefficient of each constrained
+	polynomial) and solves it. The status of the SDP solution tells whether the program is feasible. Returns an error
+	if a constraint without decision variables is violated.
+*/
+func (prog Program) SolveWithOptions(options SDPOptions) (ProgramSolution, error) {
+	// Algorithm
+	sdp := SemidefiniteProgram{NumFree: prog.numFree}
+	for _, basis := range prog.bases {
+		sdp.BlockSizes = append(sdp.BlockSizes, len(basis))
+	}
+
+	for _, equality := range prog.equalities {
+		for _, key := range equality.sortedKeys() {
+			term := equality.terms[key]
+			constraint := term.coefficient.sdpConstraint()
+			if (len(constraint.Entries) == 0) && (len(constraint.FreeEntries) == 0) {
+				if math.Abs(constraint.B) > defaultFeasibilityTolerance {
+					return ProgramSolution{}, fmt.Errorf("A constraint requires the constant coefficient %v of the monomial %v to be zero.", -constraint.B, exponentsToMonomial(prog.Variables, term.exponents, 1))
+				}
+				continue
+			}
+			sdp.Constraints = append(sdp.Constraints, constraint)
+		}
+	}
+	if len(sdp.Constraints) == 0 {
+		return ProgramSolution{}, errors.New("The sum of squares program has no constraint on its decision variables.")
+	}
+
+	// Objective
+	sdp.C = make([]*mat.SymDense, len(prog.bases))
+	for k, basis := range prog.bases {
+		sdp.C[k] = mat.NewSymDense(len(basis), nil)
+	}
+	if prog.objective != nil {
+		for index, value := range prog.objective.gram {
+			if index.row == index.column {
+				sdp.C[index.block].SetSym(index.row, index.column, sdp.C[index.block].At(index.row, index.column)+value)
+			} else {
+				sdp.C[index.block].SetSym(index.row, index.column, sdp.C[index.block].At(index.row, index.column)+value/2)
+			}
+		}
+		if prog.numFree > 0 {
+			sdp.CFree = mat.NewVecDense(prog.numFree, nil)
+			for index, value := range prog.objective.free {
+				sdp.CFree.SetVec(index, value)
+			}
+		}
+	}
+
+	solution, err := sdp.SolveWithOptions(options)
+	if err != nil {
+		return ProgramSolution{}, err
+	}
+	return ProgramSolution{SDP: solution, program: prog}, nil
+}
+
+/*
+Value
+Description:
+
+	Evaluates the decision variables of p at the solution and returns the resulting polynomial.
+*/
+func (sol ProgramSolution) Value(p AffinePolynomial) symbolic.Polynomial {
+	// Algorithm
+	result := symbolic.Polynomial{Monomials: []symbolic.Monomial{}}
+	for _, key := range p.sortedKeys() {
+		term := p.terms[key]
+		coefficient := sol.evaluate(term.coefficient)
+		if coefficient != 0 {
+			result.Monomials = append(result.Monomials, exponentsToMonomial(p.variables, term.exponents, coefficient))
+		}
+	}
+	return result
+}
+
+/*
+Scalar
+Description:
+
+	Evaluates the constant coefficient of p (for example a scalar decision variable) at the solution.
+*/
+func (sol ProgramSolution) Scalar(p AffinePolynomial) float64 {
+	// Algorithm
+	if term, ok := p.terms[exponentKey(make([]int, len(p.variables)))]; ok {
+		return sol.evaluate(term.coefficient)
+	}
+	return 0
+}
+
+/*
+Certificate
+Description:
+
+	Returns the monomial basis and the Gram matrix that certify that the polynomial of the SOS constraint with the
+	given index is a sum of squares. The certificate of a constraint on a zero polynomial has an empty basis.
+*/
+func (sol ProgramSolution) Certificate(index int) (GramCertificate, error) {
+	// Input Processing
+	if (index < 0) || (index >= len(sol.program.sosBlocks)) {
+		return GramCertificate{}, fmt.Errorf("There is no SOS constraint with index %v.", index)
+	}
+
+	// Algorithm
+	block := sol.program.sosBlocks[index]
+	if block == -1 {
+		return GramCertificate{Basis: []symbolic.Monomial{}}, nil
+	}
+	basis := make([]symbolic.Monomial, len(sol.program.bases[block]))
+	for i, exponents := range sol.program.bases[block] {
+		basis[i] = exponentsToMonomial(sol.program.Variables, exponents, 1)
+	}
+	gram := mat.NewSymDense(len(basis), nil)
+	gram.CopySym(sol.SDP.X[block])
+	return GramCertificate{Basis: basis, Gram: gram}, nil
+}
+
+/*
+evaluate
+Description:
+
+	Evaluates a linear form at the decision variables of the solution.
+*/
+func (sol ProgramSolution) evaluate(form linearForm) float64 {
+	// Algorithm
+	value := form.constant
+	for index, coefficient := range form.free {
+		value += coefficient * sol.SDP.Free.AtVec(index)
+	}
+	for index, coefficient := range form.gram {
+		value += coefficient * sol.SDP.X[index.block].At(index.row, index.column)
+	}
+	return value
+}
+
+/*
+Polynomial
+Description:
+
+	Expands z^T Q z into a polynomial.
+*/
+func (certificate GramCertificate) Polynomial() symbolic.Polynomial {
+	// Algorithm
+	result := symbolic.Polynomial{Monomials: []symbolic.Monomial{}}
+	for i := range certificate.Basis {
+		for j := range certificate.Basis {
+			product := certificate.Basis[i].Copy()
+			product.Coefficient = certificate.Gram.At(i, j)
+			product.Variables = append(product.Variables, certificate.Basis[j].Variables...)
+			product.Exponents = append(product.Exponents, certificate.Basis[j].Exponents...)
+			result.Monomials = append(result.Monomials, product)
+		}
+	}
+	return result
+}
+
+/*
+MinEigenvalue
+Description:
+
+	Returns the smallest eigenvalue of the Gram matrix, which is nonnegative (up to the solver tolerance) for a valid
+	certificate.
+*/
+func (certificate GramCertificate) MinEigenvalue() float64 {
+	// Algorithm
+	if certificate.Gram == nil {
+		return 0
+	}
+	var eig mat.EigenSym
+	if ok := eig.Factorize(certificate.Gram, false); !ok {
+		return math.NaN()
+	}
+	return eig.Values(nil)[0]
+}
+
+/*
+Plus
+Description:
+
+	Returns the sum p + q.
+*/
+func (p AffinePolynomial) Plus(q AffinePolynomial) AffinePolynomial {
+	// Algorithm
+	result := p.copy()
+	if result.variables == nil {
+		result.variables = q.variables
+	}
+	for _, term := range q.terms {
+		result.addTerm(term.exponents, term.coefficient)
+	}
+	return result
+}
+
+/*
+Minus
+Description:
+
+	Returns the difference p - q.
+*/
+func (p AffinePolynomial) Minus(q AffinePolynomial) AffinePolynomial {
+	return p.Plus(q.Scale(-1))
+}
+
+/*
+Scale
+Description:
+
+	Returns the polynomial alpha p.
+*/
+func (p AffinePolynomial) Scale(alpha float64) AffinePolynomial {
+	// Algorithm
+	result := AffinePolynomial{variables: p.variables, terms: map[string]polynomialTerm{}}
+	for key, term := range p.terms {
+		result.terms[key] = polynomialTerm{exponents: term.exponents, coefficient: term.coefficient.scale(alpha)}
+	}
+	return result
+}
+
+/*
+Multiply
+Description:
+
+	Returns the product p q. Since the program must stay linear in the decision variables, at least one of the
+	polynomials must not depend on them.
+*/
+func (p AffinePolynomial) Multiply(q AffinePolynomial) (AffinePolynomial, error) {
+	// Input Processing
+	if !p.isFixed() && !q.isFixed() {
+		return AffinePolynomial{}, errors.New("Cannot multiply two polynomials that both depend on decision variables.")
+	}
+	if !p.isFixed() {
+		p, q = q, p
+	}
+
+	// Algorithm
+	result := AffinePolynomial{variables: p.variables, terms: map[string]polynomialTerm{}}
+	if result.variables == nil {
+		result.variables = q.variables
+	}
+	for _, fixed := range p.terms {
+		for _, term := range q.terms {
+			result.addTerm(sumExponents(fixed.exponents, term.exponents), term.coefficient.scale(fixed.coefficient.constant))
+		}
+	}
+	return result, nil
+}
+
+/*
+Derivative
+Description:
+
+	Returns the partial derivative of p with respect to the variable v of the program.
+*/
+func (p AffinePolynomial) Derivative(v symbolic.Variable) (AffinePolynomial, error) {
+	// Input Processing
+	index := v.FoundIn(p.variables)
+	if index == -1 {
+		return AffinePolynomial{}, fmt.Errorf("The variable %v is not a variable of the polynomial.", v)
+	}
+
+	// Algorithm
+	result := AffinePolynomial{variables: p.variables, terms: map[string]polynomialTerm{}}
+	for _, term := range p.terms {
+		if term.exponents[index] == 0 {
+			continue
+		}
+		exponents := append([]int{}, term.exponents...)
+		exponents[index]--
+		result.addTerm(exponents, term.coefficient.scale(float64(term.exponents[index])))
+	}
+	return result, nil
+}
+
+/*
+Degree
+Description:
+
+	Returns the largest total degree of the monomials of p whose coefficient is not identically zero.
+*/
+func (p AffinePolynomial) Degree() int {
+	// Algorithm
+	degree := 0
+	for _, term := range p.terms {
+		if term.coefficient.isZero() {
+			continue
+		}
+		termDegree := 0
+		for _, exponent := range term.exponents {
+			termDegree += exponent
+		}
+		degree = maxInt(degree, termDegree)
+	}
+	return degree
+}
+
+/*
+zero
+Description:
+
+	Returns the zero polynomial in the variables of the program.
+*/
+func (prog *Program) zero() AffinePolynomial {
+	return AffinePolynomial{variables: prog.Variables, terms: map[string]polynomialTerm{}}
+}
+
+/*
+checkPolynomial
+Description:
+
+	Returns an error if the polynomial was not created by a program with the same variables.
+*/
+func (prog *Program) checkPolynomial(p AffinePolynomial) error {
+	// Input Processing
+	if p.terms == nil {
+		return errors.New("The polynomial was not created by a sum of squares program.")
+	}
+	if len(p.variables) != len(prog.Variables) {
+		return errors.New("The polynomial is not in the variables of the program.")
+	}
+	for i, v := range p.variables {
+		if v != prog.Variables[i] {
+			return errors.New("The polynomial is not in the variables of the program.")
+		}
+	}
+	return nil
+}
+
+/*
+addGram
+Description:
+
+	Adds a Gram matrix with the given monomial basis and returns its index.
+*/
+func (prog *Program) addGram(basis [][]int) int {
+	prog.bases = append(prog.bases, basis)
+	return len(prog.bases) - 1
+}
+
+/*
+gramPolynomial
+Description:
+
+	Returns z^T Q z for the Gram matrix Q with the given index, whose off-diagonal entries appear twice.
+*/
+func (prog *Program) gramPolynomial(block int) AffinePolynomial {
+	// Algorithm
+	basis := prog.bases[block]
+	result := prog.zero()
+	for i := range basis {
+		for j := i; j < len(basis); j++ {
+			exponents := sumExponents(basis[i], basis[j])
+			coefficient := 2.0
+			if i == j {
+				coefficient = 1
+			}
+			result.addTerm(exponents, linearForm{gram: map[gramIndex]float64{{block, i, j}: coefficient}})
+		}
+	}
+	return result
+}
+
+/*
+addTerm
+Description:
+
+	Adds the coefficient to the term of p with the given exponents.
+*/
+func (p *AffinePolynomial) addTerm(exponents []int, coefficient linearForm) {
+	// Algorithm
+	key := exponentKey(exponents)
+	term, ok := p.terms[key]
+	if !ok {
+		p.terms[key] = polynomialTerm{exponents: append([]int{}, exponents...), coefficient: coefficient.copy()}
+		return
+	}
+	term.coefficient = term.coefficient.plus(coefficient)
+	p.terms[key] = term
+}
+
+/*
+copy
+Description:
+
+	Returns a copy of p that does not share its terms.
+*/
+func (p AffinePolynomial) copy() AffinePolynomial {
+	return p.Scale(1)
+}
+
+/*
+isFixed
+Description:
+
+	Returns true if p does not depend on decision variables.
+*/
+func (p AffinePolynomial) isFixed() bool {
+	for _, term := range p.terms {
+		if (len(term.coefficient.free) > 0) || (len(term.coefficient.gram) > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+sortedKeys
+Description:
+
+	Returns the keys of the terms of p in graded order (by total degree, then by decreasing exponents).
+*/
+func (p AffinePolynomial) sortedKeys() []string {
+	// Algorithm
+	keys := make([]string, 0, len(p.terms))
+	for key := range p.terms {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		return exponentsLess(p.terms[keys[a]].exponents, p.terms[keys[b]].exponents)
+	})
+	return keys
+}
+
+/*
+copy
+Description:
+
+	Returns a copy of the linear form that does not share its maps.
+*/
+func (form linearForm) copy() linearForm {
+	return form.scale(1)
+}
+
+/*
+scale
+Description:
+
+	Returns the linear form alpha form.
+*/
+func (form linearForm) scale(alpha float64) linearForm {
+	// Algorithm
+	result := linearForm{constant: alpha * form.constant}
+	if len(form.free) > 0 {
+		result.free = make(map[int]float64, len(form.free))
+		for index, value := range form.free {
+			result.free[index] = alpha * value
+		}
+	}
+	if len(form.gram) > 0 {
+		result.gram = make(map[gramIndex]float64, len(form.gram))
+		for index, value := range form.gram {
+			result.gram[index] = alpha * value
+		}
+	}
+	return result
+}
+
+/*
+plus
+Description:
+
+	Returns the sum of two linear forms.
+*/
+func (form linearForm) plus(other linearForm) linearForm {
+	// Algorithm
+	result := form.copy()
+	result.constant += other.constant
+	for index, value := range other.free {
+		if result.free == nil {
+			result.free = map[int]float64{}
+		}
+		result.free[index] += value
+	}
+	for index, value := range other.gram {
+		if result.gram == nil {
+			result.gram = map[gramIndex]float64{}
+		}
+		result.gram[index] += value
+	}
+	return result
+}
+
+/*
+isZero
+Description:
+
+	Returns true if the linear form is identically zero.
+*/
+func (form linearForm) isZero() bool {
+	if form.constant != 0 {
+		return false
+	}
+	for _, value := range form.free {
+		if value != 0 {
+			return false
+		}
+	}
+	for _, value := range form.gram {
+		if value != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+sdpConstraint
+Description:
+
+	Converts the equation form = 0 into a constraint of a SemidefiniteProgram, with its entries in a fixed order.
+*/
+func (form linearForm) sdpConstraint() SDPConstraint {
+	// Algorithm
+	constraint := SDPConstraint{B: -form.constant}
+	for index, value := range form.gram {
+		if value != 0 {
+			constraint.Entries = append(constraint.Entries, SDPEntry{Block: index.block, Row: index.row, Column: index.column, Value: value})
+		}
+	}
+	for index, value := range form.free {
+		if value != 0 {
+			constraint.FreeEntries = append(constraint.FreeEntries, SDPFreeEntry{Index: index, Value: value})
+		}
+	}
+	sort.Slice(constraint.Entries, func(a, b int) bool {
+		ea, eb := constraint.Entries[a], constraint.Entries[b]
+		if ea.Block != eb.Block {
+			return ea.Block < eb.Block
+		}
+		if ea.Row != eb.Row {
+			return ea.Row < eb.Row
+		}
+		return ea.Column < eb.Column
+	})
+	sort.Slice(constraint.FreeEntries, func(a, b int) bool {
+		return constraint.FreeEntries[a].Index < constraint.FreeEntries[b].Index
+	})
+	return constraint
+}
+
+/*
+monomialExponents
+Description:
+
+	Returns the exponent vectors of the monomials in n variables whose total degree is between minDegree and
+	maxDegree (and whose exponents are between lower and upper when they are given), in graded order.
+*/
+func monomialExponents(n, minDegree, maxDegree int, lower, upper []int) [][]int {
+	// Algorithm
+	var result [][]int
+	exponents := make([]int, n)
+	var recurse func(i, remaining int)
+	recurse = func(i, remaining int) {
+		if i == n-1 {
+			exponents[i] = remaining
+			if ((lower == nil) || (remaining >= lower[i])) && ((upper == nil) || (remaining <= upper[i])) {
+				result = append(result, append([]int{}, exponents...))
+			}
+			return
+		}
+		for e := remaining; e >= 0; e-- {
+			if ((lower != nil) && (e < lower[i])) || ((upper != nil) && (e > upper[i])) {
+				continue
+			}
+			exponents[i] = e
+			recurse(i+1, remaining-e)
+		}
+	}
+	for degree := minDegree; degree <= maxDegree; degree++ {
+		recurse(0, degree)
+	}
+	return result
+}
+
+/*
+pruneBasis
+Description:
+
+	Removes from the basis each monomial z_i whose square z_i^2 is neither a term of p nor a product z_j z_k of two
+	other monomials of the basis, until no such monomial remains. The diagonal entry Q_ii of a Gram matrix of p
+	would have to be zero, and so would row i, so removing z_i keeps the program equivalent while giving it strictly
+	feasible points.
+*/
+func pruneBasis(basis [][]int, p AffinePolynomial) [][]int {
+	// Algorithm
+	for {
+		products := map[string]int{}
+		for i := range basis {
+			for j := i + 1; j < len(basis); j++ {
+				products[exponentKey(sumExponents(basis[i], basis[j]))]++
+			}
+		}
+
+		pruned := [][]int{}
+		for _, monomial := range basis {
+			key := exponentKey(sumExponents(monomial, monomial))
+			if term, ok := p.terms[key]; (ok && !term.coefficient.isZero()) || (products[key] > 0) {
+				pruned = append(pruned, monomial)
+			}
+		}
+		if len(pruned) == len(basis) {
+			return pruned
+		}
+		basis = pruned
+	}
+}
+
+/*
+sumExponents
+Description:
+
+	Returns the exponents of the product of the monomials with exponents a and b.
+*/
+func sumExponents(a, b []int) []int {
+	result := make([]int, len(a))
+	for i := range a {
+		result[i] = a[i] + b[i]
+	}
+	return result
+}
+
+/*
+exponentsLess
+Description:
+
+	Orders exponent vectors by total degree, then by decreasing exponents (x1^2 before x1 x2 before x2^2).
+*/
+func exponentsLess(a, b []int) bool {
+	// Algorithm
+	degreeA, degreeB := 0, 0
+	for i := range a {
+		degreeA, degreeB = degreeA+a[i], degreeB+b[i]
+	}
+	if degreeA != degreeB {
+		return degreeA < degreeB
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return false
+}
+
+/*
+exponentKey
+Description:
+
+	Returns a string that identifies the exponent vector.
+*/
+func exponentKey(exponents []int) string {
+	parts := make([]string, len(exponents))
+	for i, exponent := range exponents {
+		parts[i] = strconv.Itoa(exponent)
+	}
+	return strings.Join(parts, ",")
+}
+
+/*
+exponentsToMonomial
+Description:
+
+	Returns the monomial coefficient x^exponents, which lists only the variables with a positive exponent.
+*/
+func exponentsToMonomial(variables []symbolic.Variable, exponents []int, coefficient float64) symbolic.Monomial {
+	// Algorithm
+	monomial := symbolic.Monomial{Coefficient: coefficient, Variables: []symbolic.Variable{}, Exponents: []int{}}
+	for i, exponent := range exponents {
+		if exponent > 0 {
+			monomial.Variables = append(monomial.Variables, variables[i])
+			monomial.Exponents = append(monomial.Exponents, exponent)
+		}
+	}
+	return monomial
+}
+
+/*
+minInt
+Description:
+
+	Returns the smaller of two integers.
+*/
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+/*
+maxInt
+Description:
+
+	Returns the larger of two integers.
+*/
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,623 @@
+/*
+   region_of_attraction.go
+   Description:
+       Estimation of the region of attraction of the origin of polynomial dynamics dx/dt = f(x) with sum of squares
+       programming. A sublevel set {V <= gamma} of a Lyapunov function on which dV/dt < 0 is invariant and contained in
+       the region of attraction; the level gamma and the function V are enlarged in turn by the V-s iteration, which
+       alternates between searches for the SOS multipliers s (with V fixed) and for V (with the multipliers fixed).
+*/
+
+package sos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kwesiRutledge/goControl/symbolic"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Constants
+const (
+	roaBisectionSteps = 20 // Maximum number of doublings or halvings of a level before bisection
+)
+
+type RegionOfAttractionOptions struct {
+	Degree           int                 // Degree of V in the V-s iteration (0 selects the degree of the candidate)
+	Shape            symbolic.Polynomial // p(x) whose sublevel set {p <= beta} inside {V <= gamma} is enlarged (no monomials selects |x|^2)
+	MultiplierDegree int                 // Degree of the SOS multipliers (0 selects the degree of V, or the even degree that matches a condition if larger)
+	Margin           float64             // eps in the margins eps |x|^2 of the positivity of V and the decrease of V (0 selects 1e-6)
+	Iterations       int                 // Maximum number of V-s iterations (0 selects 10; 1 only certifies the candidate)
+	Tolerance        float64             // Relative tolerance of the bisections and of the improvement of beta (0 selects 1e-3)
+	SDP              SDPOptions          // Options of the semidefinite programming solver
+}
+
+type RegionOfAttraction struct {
+	Variables       []symbolic.Variable   // State variables x
+	Dynamics        []symbolic.Polynomial // f(x)
+	V               symbolic.Polynomial   // Lyapunov function (V(0) = 0)
+	Derivative      symbolic.Polynomial   // dV/dt = grad V(x) . f(x)
+	Level           float64               // gamma: {x : V(x) <= Level} is an invariant subset of the region of attraction
+	Shape           symbolic.Polynomial   // p(x)
+	ShapeLevel      float64               // beta: {x : p(x) <= ShapeLevel} is contained in {x : V(x) <= Level}
+	Margin          float64               // eps
+	Multiplier      symbolic.Polynomial   // SOS multiplier s1 of the decrease condition
+	ShapeMultiplier symbolic.Polynomial   // SOS multiplier s2 of the containment condition
+	Positivity      GramCertificate       // V - eps |x|^2 = z^T P z
+	Decrease        GramCertificate       // -dV/dt - eps |x|^2 - s1 (Level - V) = w^T Q w
+	Containment     GramCertificate       // (Level - V) - s2 (ShapeLevel - p) = v^T R v
+	Multipliers     []GramCertificate     // Gram matrices of s1 and s2
+	Iterations      int                   // Number of Lyapunov functions certified by the iteration (1 for the candidate alone)
+}
+
+type roaSearch struct {
+	x       []symbolic.Variable
+	f       []symbolic.Polynomial
+	shape   symbolic.Polynomial
+	degree  int // Degree of V
+	options RegionOfAttractionOptions
+}
+
+type roaStep struct {
+	solution   ProgramSolution
+	V          AffinePolynomial
+	derivative AffinePolynomial
+	multiplier AffinePolynomial
+	level      AffinePolynomial
+	positivity int
+	constraint int
+	sos        int                // Index of the SOS constraint on the multiplier
+	conditions []AffinePolynomial // Polynomial of each SOS constraint of the program, by index
+}
+
+/*
+EstimateRegionOfAttraction
+Description:
+
+	Estimates the region of attraction of the origin of dx/dt = f(x), starting from the Lyapunov candidate V (for
+	example the quadratic Lyapunov function of the linearization), with the default options.
+*/
+func EstimateRegionOfAttraction(x []symbolic.Variable, f []symbolic.Polynomial, V symbolic.Polynomial) (RegionOfAttraction, error) {
+	return EstimateRegionOfAttractionWithOptions(x, f, V, RegionOfAttractionOptions{})
+}
+
+/*
+EstimateRegionOfAttractionWithOptions
+Description:
+
+	Estimates the region of attraction of the origin of dx/dt = f(x) by the V-s iteration, starting from the
+	Lyapunov candidate V. Each iteration
+		1. finds the largest gamma (by bisection) such that -dV/dt - eps |x|^2 - s1 (gamma - V) is SOS for an SOS s1,
+		   so that dV/dt < 0 on {V <= gamma} away from the origin,
+		2. finds the largest beta (by bisection) such that (gamma - V) - s2 (beta - p) is SOS for an SOS s2, so that
+		   {p <= beta} is contained in {V <= gamma},
+		3. with s1, s2, gamma and beta fixed, finds a V of the degree of the options (V - eps |x|^2 SOS) in the
+		   interior of the feasible set of both conditions,
+	and stops when beta no longer improves. The solution of every program is checked independently of the solver
+	(see solve), and the result is checked with Verify. Returns the last V with its certified levels gamma and beta.
+*/
+func EstimateRegionOfAttractionWithOptions(x []symbolic.Variable, f []symbolic.Polynomial, V symbolic.Polynomial, options RegionOfAttractionOptions) (RegionOfAttraction, error) {
+	// Input Processing
+	if len(f) != len(x) {
+		return RegionOfAttraction{}, fmt.Errorf("The dynamics have %v components, but there are %v variables.", len(f), len(x))
+	}
+	if (options.Degree < 0) || (options.Margin < 0) || (options.MultiplierDegree < 0) || (options.Iterations < 0) || (options.Tolerance < 0) {
+		return RegionOfAttraction{}, errors.New("The margin, the degree of the multipliers, the number of iterations and the tolerance must be nonnegative.")
+	}
+	if options.Margin == 0 {
+		options.Margin = 1e-6
+	}
+	if options.Iterations == 0 {
+		options.Iterations = 10
+	}
+	if options.Tolerance == 0 {
+		options.Tolerance = 1e-3
+	}
+	if len(options.Shape.Monomials) == 0 {
+		options.Shape = squaredNorm(x)
+	}
+
+	prog, err := GetProgram(x)
+	if err != nil {
+		return RegionOfAttraction{}, err
+	}
+	dynamics, err := programPolynomials(&prog, f)
+	if err != nil {
+		return RegionOfAttraction{}, err
+	}
+	for i, fi := range dynamics {
+		if fi.constantTerm() != 0 {
+			return RegionOfAttraction{}, fmt.Errorf("The origin must be an equilibrium, but f%v(0) = %v.", i+1, fi.constantTerm())
+		}
+	}
+	candidate, err := prog.Constant(V)
+	if err != nil {
+		return RegionOfAttraction{}, fmt.Errorf("The Lyapunov candidate: %v", err)
+	}
+	if candidate.constantTerm() != 0 || candidate.Degree() < 2 {
+		return RegionOfAttraction{}, errors.New("The Lyapunov candidate must vanish at the origin and have a degree of at least 2.")
+	}
+	if options.Degree == 0 {
+		options.Degree = candidate.Degree()
+	}
+	if (options.Degree < candidate.Degree()) || (options.Degree%2 != 0) {
+		return RegionOfAttraction{}, fmt.Errorf("The degree of V must be even and at least the degree %v of the candidate; received %v.", candidate.Degree(), options.Degree)
+	}
+	shape, err := prog.Constant(options.Shape)
+	if err != nil {
+		return RegionOfAttraction{}, fmt.Errorf("The shape function: %v", err)
+	}
+	if shape.Degree() < 1 {
+		return RegionOfAttraction{}, errors.New("The shape function must not be constant.")
+	}
+
+	search := roaSearch{x: x, f: f, shape: options.Shape, degree: options.Degree, options: options}
+
+	// Algorithm
+	decrease, level, err := search.certify(V, 1)
+	if err != nil {
+		return RegionOfAttraction{}, err
+	}
+	containment, shapeLevel, ok := search.bisect(func(beta float64) (roaStep, bool) {
+		return search.containment(V, level, beta)
+	}, 1)
+	if !ok {
+		return RegionOfAttraction{}, errors.New("No sublevel set of the shape function is contained in the certified region.")
+	}
+
+	iterations := 1
+	for ; iterations < options.Iterations; iterations++ {
+		improved, ok := search.improve(decrease, containment, level, shapeLevel)
+		if !ok {
+			break
+		}
+		nextV := improved.solution.Value(improved.V)
+		nextDecrease, nextLevel, err := search.certify(nextV, level)
+		if err != nil {
+			break
+		}
+		nextContainment, nextShapeLevel, ok := search.bisect(func(beta float64) (roaStep, bool) {
+			return search.containment(nextV, nextLevel, beta)
+		}, shapeLevel)
+		if !ok || (nextShapeLevel <= shapeLevel) {
+			break
+		}
+
+		V, decrease, level, containment = nextV, nextDecrease, nextLevel, nextContainment
+		converged := nextShapeLevel <= shapeLevel*(1+options.Tolerance)
+		shapeLevel = nextShapeLevel
+		if converged {
+			iterations++
+			break
+		}
+	}
+
+	// Output
+	region := RegionOfAttraction{
+		Variables:       x,
+		Dynamics:        f,
+		V:               V,
+		Derivative:      decrease.solution.Value(decrease.derivative),
+		Level:           level,
+		Shape:           options.Shape,
+		ShapeLevel:      shapeLevel,
+		Margin:          options.Margin,
+		Multiplier:      decrease.solution.Value(decrease.multiplier),
+		ShapeMultiplier: containment.solution.Value(containment.multiplier),
+		Multipliers:     make([]GramCertificate, 2),
+		Iterations:      iterations,
+	}
+	for _, certificate := range []struct {
+		gram  *GramCertificate
+		step  roaStep
+		index int
+	}{
+		{&region.Positivity, decrease, decrease.positivity},
+		{&region.Decrease, decrease, decrease.constraint},
+		{&region.Containment, containment, containment.constraint},
+		{&region.Multipliers[0], decrease, decrease.sos},
+		{&region.Multipliers[1], containment, containment.sos},
+	} {
+		if *certificate.gram, err = certificate.step.solution.Certificate(certificate.index); err != nil {
+			return RegionOfAttraction{}, err
+		}
+	}
+	if err := region.Verify(certificateTolerance); err != nil {
+		return RegionOfAttraction{}, fmt.Errorf("The estimated region of attraction is not certified: %v", err)
+	}
+	return region, nil
+}
+
+/*
+Verify
+Description:
+
+	Checks the two levels of the estimate with the five SOS identities of the last iteration,
+		s1,   s2,   V - eps |x|^2,   -dV/dt - eps |x|^2 - s1 (Level - V),   (Level - V) - s2 (ShapeLevel - p),
+	where dV/dt is recomputed from V and the stored dynamics. Level is certified by the fourth identity (dV/dt < 0
+	on {V <= Level}) and ShapeLevel by the fifth ({p <= ShapeLevel} inside {V <= Level}), so raising either level,
+	or changing a multiplier, makes the corresponding condition fail. Each identity is checked by verifyGram.
+*/
+func (region RegionOfAttraction) Verify(tolerance float64) error {
+	// Input Processing
+	if !(tolerance > 0) {
+		return fmt.Errorf("The tolerance must be positive; received %v.", tolerance)
+	}
+	if len(region.Dynamics) != len(region.Variables) {
+		return fmt.Errorf("The dynamics have %v components, but there are %v variables.", len(region.Dynamics), len(region.Variables))
+	}
+	if len(region.Multipliers) != 2 {
+		return fmt.Errorf("The estimate has %v Gram matrices for 2 multipliers.", len(region.Multipliers))
+	}
+
+	// Constants
+	prog, err := GetProgram(region.Variables)
+	if err != nil {
+		return err
+	}
+	fixed := make([]AffinePolynomial, 4)
+	for i, p := range []symbolic.Polynomial{region.V, region.Shape, region.Multiplier, region.ShapeMultiplier} {
+		if fixed[i], err = prog.Constant(p); err != nil {
+			return fmt.Errorf("The estimate depends on a foreign variable: %v", err)
+		}
+	}
+	V, shape, s1, s2 := fixed[0], fixed[1], fixed[2], fixed[3]
+	dynamics, err := programPolynomials(&prog, region.Dynamics)
+	if err != nil {
+		return err
+	}
+	Vdot, err := lieDerivative(V, region.Variables, dynamics)
+	if err != nil {
+		return err
+	}
+	margin, _ := prog.Constant(squaredNorm(region.Variables))
+	margin = margin.Scale(region.Margin)
+	level, _ := prog.Constant(region.Level)
+	shapeLevel, _ := prog.Constant(region.ShapeLevel)
+	decrease, err := s1.Multiply(level.Minus(V))
+	if err != nil {
+		return err
+	}
+	containment, err := s2.Multiply(shapeLevel.Minus(shape))
+	if err != nil {
+		return err
+	}
+
+	// Algorithm
+	for _, condition := range []struct {
+		p    AffinePolynomial
+		gram GramCertificate
+		name string
+	}{
+		{s1, region.Multipliers[0], "nonnegativity of the decrease multiplier"},
+		{s2, region.Multipliers[1], "nonnegativity of the containment multiplier"},
+		{V.Minus(margin), region.Positivity, "positivity of V"},
+		{Vdot.Scale(-1).Minus(margin).Minus(decrease), region.Decrease, "decrease of V on the sublevel set"},
+		{level.Minus(V).Minus(containment), region.Containment, "containment of the shape sublevel set"},
+	} {
+		if err := verifyGram(&prog, condition.p, condition.gram, tolerance); err != nil {
+			return fmt.Errorf("The %v is not certified: %v", condition.name, err)
+		}
+	}
+	return nil
+}
+
+/*
+certify
+Description:
+
+	Finds the largest level gamma, starting the search at start, for which the decrease condition holds on
+	{V <= gamma}. Returns an error if V is not positive definite or if no positive level is certified.
+*/
+func (search roaSearch) certify(V symbolic.Polynomial, start float64) (roaStep, float64, error) {
+	// Algorithm
+	if !search.decreasesNearOrigin(V) {
+		return roaStep{}, 0, errors.New("The Lyapunov candidate does not decrease near the origin: the quadratic part of -dV/dt is not positive definite.")
+	}
+	step, level, ok := search.bisect(func(gamma float64) (roaStep, bool) {
+		return search.decrease(V, gamma)
+	}, start)
+	if !ok {
+		return roaStep{}, 0, errors.New("The Lyapunov candidate is not positive definite or does not decrease on any of its sublevel sets.")
+	}
+	return step, level, nil
+}
+
+/*
+decreasesNearOrigin
+Description:
+
+	Reports whether the quadratic part of -dV/dt - eps |x|^2 is positive definite. The decrease condition requires it
+	on every sublevel set, and without this check its violation, which shrinks with the level, would be mistaken for
+	a numerical error of the solver at small levels.
+*/
+func (search roaSearch) decreasesNearOrigin(V symbolic.Polynomial) bool {
+	// Algorithm
+	prog, _ := GetProgram(search.x)
+	candidate, _ := prog.Constant(V)
+	dynamics, _ := programPolynomials(&prog, search.f)
+	derivative, err := lieDerivative(candidate, search.x, dynamics)
+	if err != nil {
+		return false
+	}
+
+	n := len(search.x)
+	Q := mat.NewSymDense(n, nil)
+	for _, term := range derivative.terms {
+		var indices []int
+		for i, exponent := range term.exponents {
+			for k := 0; k < exponent; k++ {
+				indices = append(indices, i)
+			}
+		}
+		if len(indices) != 2 {
+			continue
+		}
+		i, j := indices[0], indices[1]
+		if i == j {
+			Q.SetSym(i, i, Q.At(i, i)-term.coefficient.constant)
+		} else {
+			Q.SetSym(i, j, Q.At(i, j)-term.coefficient.constant/2)
+		}
+	}
+	for i := 0; i < n; i++ {
+		Q.SetSym(i, i, Q.At(i, i)-search.options.Margin)
+	}
+	return GramCertificate{Gram: Q}.MinEigenvalue() > 0
+}
+
+/*
+decrease
+Description:
+
+	Solves the feasibility program in the multiplier s1 of
+		V - eps |x|^2 SOS,   s1 SOS,   -dV/dt - eps |x|^2 - s1 (gamma - V) SOS,
+	for the fixed V and gamma.
+*/
+func (search roaSearch) decrease(V symbolic.Polynomial, gamma float64) (roaStep, bool) {
+	// Algorithm
+	prog, _ := GetProgram(search.x)
+	step := roaStep{}
+	step.V, _ = prog.Constant(V)
+	step.level, _ = prog.Constant(gamma)
+	dynamics, _ := programPolynomials(&prog, search.f)
+	step.derivative, _ = lieDerivative(step.V, search.x, dynamics)
+
+	var err error
+	if step.multiplier, step.sos, err = search.multiplier(&prog, &step, step.derivative.Degree()-step.V.Degree()); err != nil {
+		return roaStep{}, false
+	}
+	if step.positivity, step.constraint, err = search.lyapunovConstraints(&prog, &step, step.V, step.derivative, step.multiplier, step.level); err != nil {
+		return roaStep{}, false
+	}
+	return search.solve(prog, step)
+}
+
+/*
+containment
+Description:
+
+	Solves the feasibility program in the multiplier s2 of
+		s2 SOS,   (gamma - V) - s2 (beta - p) SOS,
+	for the fixed V, gamma and beta.
+*/
+func (search roaSearch) containment(V symbolic.Polynomial, gamma, beta float64) (roaStep, bool) {
+	// Algorithm
+	prog, _ := GetProgram(search.x)
+	step := roaStep{}
+	step.V, _ = prog.Constant(V)
+	step.level, _ = prog.Constant(beta)
+	shape, _ := prog.Constant(search.shape)
+	levelGamma, _ := prog.Constant(gamma)
+
+	var err error
+	if step.multiplier, step.sos, err = search.multiplier(&prog, &step, search.degree-shape.Degree()); err != nil {
+		return roaStep{}, false
+	}
+	if step.constraint, err = search.containmentConstraint(&prog, &step, step.V, step.multiplier, levelGamma, step.level, shape); err != nil {
+		return roaStep{}, false
+	}
+	return search.solve(prog, step)
+}
+
+/*
+improve
+Description:
+
+	With the multipliers of the last decrease and containment programs and the levels gamma and beta fixed, finds a
+	V of the degree of the search such that
+		V - eps |x|^2 SOS,   -dV/dt - eps |x|^2 - s1 (gamma - V) SOS,   (gamma - V) - s2 (beta - p) SOS.
+	This is a feasibility program, so V lies in the interior of its feasible set and leaves slack in every condition
+	for the next decrease and containment programs to enlarge gamma and beta.
+*/
+func (search roaSearch) improve(decrease, containment roaStep, gamma, beta float64) (roaStep, bool) {
+	// Algorithm
+	prog, _ := GetProgram(search.x)
+	s1, _ := prog.Constant(decrease.solution.Value(decrease.multiplier))
+	s2, _ := prog.Constant(containment.solution.Value(containment.multiplier))
+	shape, _ := prog.Constant(search.shape)
+	levelGamma, _ := prog.Constant(gamma)
+	levelBeta, _ := prog.Constant(beta)
+	dynamics, _ := programPolynomials(&prog, search.f)
+
+	step := roaStep{}
+	var err error
+	if step.V, err = prog.NewPolynomial(2, search.degree); err != nil {
+		return roaStep{}, false
+	}
+	if step.derivative, err = lieDerivative(step.V, search.x, dynamics); err != nil {
+		return roaStep{}, false
+	}
+	if _, _, err = search.lyapunovConstraints(&prog, &step, step.V, step.derivative, s1, levelGamma); err != nil {
+		return roaStep{}, false
+	}
+	if _, err = search.containmentConstraint(&prog, &step, step.V, s2, levelGamma, levelBeta, shape); err != nil {
+		return roaStep{}, false
+	}
+	return search.solve(prog, step)
+}
+
+/*
+multiplier
+Description:
+
+	Adds an SOS multiplier to the program. Its degree is the one of the options or else the degree of V, raised to
+	the smallest even degree that is at least the given degree gap between the condition and the polynomial it
+	multiplies. Multipliers of lower degree keep V close to the candidate and slow the iteration down. Returns the
+	multiplier and the index of its SOS constraint.
+*/
+func (search roaSearch) multiplier(prog *Program, step *roaStep, gap int) (AffinePolynomial, int, error) {
+	// Algorithm
+	degree := search.options.MultiplierDegree
+	if degree == 0 {
+		degree = maxInt(search.degree, gap)
+		degree += degree % 2
+	}
+	s, err := prog.NewPolynomial(0, degree)
+	if err != nil {
+		return AffinePolynomial{}, -1, err
+	}
+	index, err := step.addSOSConstraint(prog, s)
+	if err != nil {
+		return AffinePolynomial{}, -1, err
+	}
+	return s, index, nil
+}
+
+/*
+lyapunovConstraints
+Description:
+
+	Adds the constraints V - eps |x|^2 SOS and -dV/dt - eps |x|^2 - s (gamma - V) SOS to the program, and returns
+	their indices.
+*/
+func (search roaSearch) lyapunovConstraints(prog *Program, step *roaStep, V, derivative, s, gamma AffinePolynomial) (int, int, error) {
+	// Algorithm
+	margin, _ := prog.Constant(squaredNorm(search.x))
+	margin = margin.Scale(search.options.Margin)
+
+	positivity, err := step.addSOSConstraint(prog, V.Minus(margin))
+	if err != nil {
+		return -1, -1, err
+	}
+	product, err := s.Multiply(gamma.Minus(V))
+	if err != nil {
+		return -1, -1, err
+	}
+	decrease, err := step.addSOSConstraint(prog, derivative.Scale(-1).Minus(margin).Minus(product))
+	if err != nil {
+		return -1, -1, err
+	}
+	return positivity, decrease, nil
+}
+
+/*
+containmentConstraint
+Description:
+
+	Adds the constraint (gamma - V) - s (beta - p) SOS to the program, and returns its index.
+*/
+func (search roaSearch) containmentConstraint(prog *Program, step *roaStep, V, s, gamma, beta, shape AffinePolynomial) (int, error) {
+	// Algorithm
+	product, err := s.Multiply(beta.Minus(shape))
+	if err != nil {
+		return -1, err
+	}
+	return step.addSOSConstraint(prog, gamma.Minus(V).Minus(product))
+}
+
+/*
+addSOSConstraint
+Description:
+
+	Adds the constraint p SOS to the program and records p, so that the solution can be checked by solve.
+*/
+func (step *roaStep) addSOSConstraint(prog *Program, p AffinePolynomial) (int, error) {
+	// Algorithm
+	index, err := prog.AddSOSConstraint(p)
+	if err != nil {
+		return -1, err
+	}
+	step.conditions = append(step.conditions, p)
+	return index, nil
+}
+
+/*
+solve
+Description:
+
+	Solves the program of a step and reports whether it was solved to (near) optimality with a solution that passes
+	an independent check: the Gram matrix of every SOS constraint must be positive semidefinite and its polynomial
+	must match the constraint, evaluated at the solution, coefficient by coefficient (see verifyGram). The residuals
+	of nearly optimal solutions can be too large for this check, in which case the level is treated as infeasible.
+*/
+func (search roaSearch) solve(prog Program, step roaStep) (roaStep, bool) {
+	// Algorithm
+	solution, err := prog.SolveWithOptions(search.options.SDP)
+	if err != nil || statusError(solution.SDP) != nil {
+		return roaStep{}, false
+	}
+	for i, condition := range step.conditions {
+		gram, err := solution.Certificate(i)
+		if err != nil {
+			return roaStep{}, false
+		}
+		fixed, err := prog.Constant(solution.Value(condition))
+		if err != nil {
+			return roaStep{}, false
+		}
+		if verifyGram(&prog, fixed, gram, certificateTolerance) != nil {
+			return roaStep{}, false
+		}
+	}
+	step.solution = solution
+	return step, true
+}
+
+/*
+bisect
+Description:
+
+	Finds the largest level for which the feasibility program is solved, to the relative tolerance of the options:
+	the level is doubled from start until the program becomes infeasible (or halved until it becomes feasible), and
+	the bracket is then bisected. Returns the step of the largest feasible level, and false if no level is feasible.
+*/
+func (search roaSearch) bisect(feasible func(level float64) (roaStep, bool), start float64) (roaStep, float64, bool) {
+	// Constants
+	tolerance := search.options.Tolerance
+
+	// Algorithm
+	var best roaStep
+	found := false
+	lower, upper := 0.0, math.Inf(1)
+	level := start
+	for i := 0; (i < roaBisectionSteps) && (!found || math.IsInf(upper, 1)); i++ {
+		if step, ok := feasible(level); ok {
+			best, found, lower = step, true, level
+			level *= 2
+		} else {
+			upper = level
+			if found {
+				break
+			}
+			level /= 2
+		}
+	}
+	if !found {
+		return roaStep{}, 0, false
+	}
+	if math.IsInf(upper, 1) {
+		return best, lower, true
+	}
+
+	for upper-lower > tolerance*upper {
+		middle := (lower + upper) / 2
+		if step, ok := feasible(middle); ok {
+			best, lower = step, middle
+		} else {
+			upper = middle
+		}
+	}
+	return best, lower, true
+}
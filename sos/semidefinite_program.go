@@ -0,0 +1,1075 @@
+/*
+   semidefinite_program.go
+   Description:
+       A dense primal-dual interior point solver for the semidefinite programs that appear in sum of squares
+       programming, with block diagonal positive semidefinite variables and free scalar variables.
+*/
+
+package sos
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	defaultSDPTolerance     = 1e-8                  // Default relative tolerance of the residuals and of the duality gap
+	defaultSDPMaxIterations = 100                   // Default maximum number of interior point iterations
+	sdpStepFraction         = 0.98                  // Fraction of the step to the boundary of the cone taken at each iteration
+	sdpInfeasibilityBound   = 1e8                   // Size of the iterates beyond which the problem is declared infeasible
+	sdpStallIterations      = 8                     // Number of iterations without progress after which the method stops
+	machineEpsilon          = 2.220446049250313e-16 // Spacing of the floating point numbers around 1
+)
+
+type SDPStatus int
+
+const (
+	SDPOptimal          SDPStatus = iota // The residuals and the duality gap are below the tolerance
+	SDPNearOptimal                       // The method stalled with residuals and gap below the square root of the tolerance
+	SDPPrimalInfeasible                  // The dual objective grows without bound: no X satisfies the constraints
+	SDPDualInfeasible                    // The primal objective decreases without bound
+	SDPMaxIterations                     // The maximum number of iterations was reached
+)
+
+type SDPEntry struct {
+	Block  int     // Index of the matrix variable X_k
+	Row    int     // Row of the entry of X_k
+	Column int     // Column of the entry of X_k
+	Value  float64 // Coefficient of X_k(Row, Column)
+}
+
+type SDPFreeEntry struct {
+	Index int     // Index of the free variable
+	Value float64 // Coefficient of the free variable
+}
+
+type SDPConstraint struct {
+	Entries     []SDPEntry     // Terms Value X_Block(Row, Column); an off-diagonal entry counts X(Row, Column) once
+	FreeEntries []SDPFreeEntry // Terms Value x_Index
+	B           float64        // Right hand side
+}
+
+type SemidefiniteProgram struct {
+	BlockSizes  []int           // Sizes of the positive semidefinite matrix variables X_1, ..., X_k
+	NumFree     int             // Number of free scalar variables x
+	C           []*mat.SymDense // Objective matrices <C_k, X_k> (nil or a nil entry means zero)
+	CFree       *mat.VecDense   // Objective c^T x of the free variables (nil means zero)
+	Constraints []SDPConstraint // Linear equality constraints sum_k <A_ik, X_k> + f_i^T x = b_i
+}
+
+type SDPOptions struct {
+	MaxIterations int     // Maximum number of interior point iterations (0 selects 100)
+	Tolerance     float64 // Relative tolerance of the residuals and of the duality gap (0 selects 1e-8)
+}
+
+type SDPSolution struct {
+	Status          SDPStatus
+	X               []*mat.SymDense // Primal matrix variables
+	Free            *mat.VecDense   // Primal free variables
+	Y               *mat.VecDense   // Multipliers of the equality constraints
+	Z               []*mat.SymDense // Dual slack matrices C_k - sum_i y_i A_ik
+	PrimalObjective float64         // sum_k <C_k, X_k> + c^T x
+	DualObjective   float64         // b^T y
+	PrimalResidual  float64         // Relative norm of b - A(X) - F x
+	DualResidual    float64         // Relative norm of C - Z - A^T(y) and c - F^T y
+	Iterations      int             // Number of interior point iterations
+}
+
+/*
+sdpBlockEntry
+Description:
+
+	An entry of a symmetric constraint matrix A_ik; off-diagonal entries appear twice (at (r, c) and (c, r)) with
+	half of the coefficient, so that <A_ik, X_k> = sum Value X_k(Row, Column).
+*/
+type sdpBlockEntry struct {
+	row, column int
+	value       float64
+}
+
+/*
+sdpData
+Description:
+
+	The problem data in the form used by the solver: the constraint matrices as lists of symmetric entries per
+	constraint and block, the free variable matrix F and the right hand side b.
+*/
+type sdpData struct {
+	sizes   []int
+	entries [][][]sdpBlockEntry // entries[i][k] are the entries of A_ik
+	F       *mat.Dense          // m x p (nil if there are no free variables)
+	b       *mat.VecDense
+	C       []*mat.SymDense
+	c       *mat.VecDense
+}
+
+/*
+sdpElimination
+Description:
+
+	The elimination of the free variables with the singular value decomposition F = U S V^T (of rank r): the first r
+	rotated constraints U_r^T (A(X) + F x) = U_r^T b determine x = V_r S_r^-1 U_r^T (b - A(X)), and the others,
+	U_perp^T A(X) = U_perp^T b, form an SDP without free variables whose objective is shifted by A^T(g), with
+	g = U_r S_r^-1 V_r^T c.
+*/
+type sdpElimination struct {
+	recoverX *mat.Dense    // p x m matrix V_r S_r^-1 U_r^T
+	rows     *mat.Dense    // m x q matrix whose columns combine the constraints kept in the reduced SDP
+	g        *mat.VecDense // Multipliers that cancel the objective of the free variables
+	status   SDPStatus     // SDPPrimalInfeasible or SDPDualInfeasible if the elimination shows it, SDPOptimal else
+	reduced  sdpData       // SDP without free variables
+}
+
+/*
+sdpIterate
+Description:
+
+	An iterate of the interior point method with the largest of its relative residuals and duality gap.
+*/
+type sdpIterate struct {
+	X     []*mat.SymDense
+	y     *mat.VecDense
+	Z     []*mat.SymDense
+	merit float64
+}
+
+/*
+Check
+Description:
+
+	Verifies that the blocks, the objective and the constraint entries of the SDP have consistent dimensions.
+*/
+func (sdp SemidefiniteProgram) Check() error {
+	// Input Processing
+	if len(sdp.BlockSizes) == 0 && sdp.NumFree == 0 {
+		return errors.New("The semidefinite program has no variables.")
+	}
+	if len(sdp.Constraints) == 0 {
+		return errors.New("The semidefinite program has no constraints.")
+	}
+	for k, size := range sdp.BlockSizes {
+		if size < 1 {
+			return fmt.Errorf("Block %v has size %v; the blocks must have a positive size.", k, size)
+		}
+	}
+	if sdp.NumFree < 0 {
+		return fmt.Errorf("The number of free variables must be nonnegative; received %v.", sdp.NumFree)
+	}
+	if sdp.C != nil {
+		if len(sdp.C) != len(sdp.BlockSizes) {
+			return fmt.Errorf("The objective has %v blocks; expected %v.", len(sdp.C), len(sdp.BlockSizes))
+		}
+		for k, Ck := range sdp.C {
+			if (Ck != nil) && (Ck.SymmetricDim() != sdp.BlockSizes[k]) {
+				return fmt.Errorf("The objective of block %v has dimension %v; expected %v.", k, Ck.SymmetricDim(), sdp.BlockSizes[k])
+			}
+		}
+	}
+	if (sdp.CFree != nil) && (sdp.CFree.Len() != sdp.NumFree) {
+		return fmt.Errorf("The objective of the free variables has length %v; expected %v.", sdp.CFree.Len(), sdp.NumFree)
+	}
+	for i, constraint := range sdp.Constraints {
+		for _, entry := range constraint.Entries {
+			if (entry.Block < 0) || (entry.Block >= len(sdp.BlockSizes)) {
+				return fmt.Errorf("Constraint %v refers to the block %v, which does not exist.", i, entry.Block)
+			}
+			size := sdp.BlockSizes[entry.Block]
+			if (entry.Row < 0) || (entry.Row >= size) || (entry.Column < 0) || (entry.Column >= size) {
+				return fmt.Errorf("Constraint %v refers to the entry (%v, %v) of block %v, which has size %v.", i, entry.Row, entry.Column, entry.Block, size)
+			}
+		}
+		for _, entry := range constraint.FreeEntries {
+			if (entry.Index < 0) || (entry.Index >= sdp.NumFree) {
+				return fmt.Errorf("Constraint %v refers to the free variable %v, which does not exist.", i, entry.Index)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Solve
+Description:
+
+	Solves the semidefinite program with the default options (see SolveWithOptions).
+*/
+func (sdp SemidefiniteProgram) Solve() (SDPSolution, error) {
+	return sdp.SolveWithOptions(SDPOptions{})
+}
+
+/*
+SolveWithOptions
+Description:
+
+	Solves the semidefinite program
+		minimize sum_k <C_k, X_k> + c^T x
+		subject to sum_k <A_ik, X_k> + f_i^T x = b_i,   X_k positive semidefinite,
+	and its dual
+		maximize b^T y   subject to   C_k - sum_i y_i A_ik = Z_k positive semidefinite,   F^T y = c.
+	The free variables are first eliminated (see sdpElimination), which also removes redundant constraints; the
+	remaining SDP is solved with an infeasible primal-dual path-following method using the HKM search direction and
+	Mehrotra's predictor-corrector steps, whose Newton system is reduced to the Schur complement
+	M_ij = <A_i, X A_j Z^-1>. The status of the returned solution tells whether the problem was solved or found to be
+	infeasible; an error is returned only for invalid problems or numerical failures.
+*/
+func (sdp SemidefiniteProgram) SolveWithOptions(options SDPOptions) (SDPSolution, error) {
+	// Input Processing
+	if err := sdp.Check(); err != nil {
+		return SDPSolution{}, err
+	}
+	if options.MaxIterations <= 0 {
+		options.MaxIterations = defaultSDPMaxIterations
+	}
+	if options.Tolerance <= 0 {
+		options.Tolerance = defaultSDPTolerance
+	}
+
+	// Algorithm
+	data := sdp.data()
+	elimination := data.eliminateFree(options.Tolerance)
+
+	X, y, Z := elimination.reduced.zeroPoint()
+	status, iterations := elimination.status, 0
+	if status == SDPOptimal {
+		var err error
+		if X, y, Z, status, iterations, err = elimination.reduced.interiorPoint(options); err != nil {
+			return SDPSolution{}, err
+		}
+	}
+
+	return data.solution(elimination, X, y, Z, status, iterations), nil
+}
+
+/*
+data
+Description:
+
+	Converts the SDP into the internal form used by the solver.
+*/
+func (sdp SemidefiniteProgram) data() sdpData {
+	// Constants
+	m, p := len(sdp.Constraints), sdp.NumFree
+
+	// Algorithm
+	data := sdpData{
+		sizes:   sdp.BlockSizes,
+		entries: make([][][]sdpBlockEntry, m),
+		b:       zeroVector(m),
+		C:       make([]*mat.SymDense, len(sdp.BlockSizes)),
+		c:       zeroVector(p),
+	}
+	if p > 0 {
+		data.F = mat.NewDense(m, p, nil)
+	}
+	for k, size := range sdp.BlockSizes {
+		data.C[k] = mat.NewSymDense(size, nil)
+		if (sdp.C != nil) && (sdp.C[k] != nil) {
+			data.C[k].CopySym(sdp.C[k])
+		}
+	}
+	if sdp.CFree != nil {
+		data.c.CopyVec(sdp.CFree)
+	}
+
+	for i, constraint := range sdp.Constraints {
+		data.entries[i] = make([][]sdpBlockEntry, len(sdp.BlockSizes))
+		for _, entry := range constraint.Entries {
+			blockEntries := data.entries[i][entry.Block]
+			if entry.Row == entry.Column {
+				blockEntries = append(blockEntries, sdpBlockEntry{entry.Row, entry.Column, entry.Value})
+			} else {
+				blockEntries = append(blockEntries,
+					sdpBlockEntry{entry.Row, entry.Column, entry.Value / 2},
+					sdpBlockEntry{entry.Column, entry.Row, entry.Value / 2},
+				)
+			}
+			data.entries[i][entry.Block] = blockEntries
+		}
+		for _, entry := range constraint.FreeEntries {
+			data.F.Set(i, entry.Index, data.F.At(i, entry.Index)+entry.Value)
+		}
+		data.b.SetVec(i, constraint.B)
+	}
+
+	return data
+}
+
+/*
+eliminateFree
+Description:
+
+	Eliminates the free variables (see sdpElimination) and the combinations of the remaining constraints whose
+	matrices vanish; such a combination is either redundant or, if its right hand side does not vanish,
+	inconsistent. The objective c of the free variables must lie in the row space of F, or the problem is unbounded
+	whenever it is feasible.
+*/
+func (data sdpData) eliminateFree(tolerance float64) sdpElimination {
+	// Constants
+	m, p := len(data.entries), data.c.Len()
+	normB := norm(data.b)
+
+	// Singular value decomposition of F (the identity rotation if there are no free variables)
+	rank := 0
+	U := mat.NewDense(m, m, nil)
+	elimination := sdpElimination{g: zeroVector(m), status: SDPOptimal}
+	if p > 0 {
+		var svd mat.SVD
+		svd.Factorize(data.F, mat.SVDFull)
+		values := svd.Values(nil)
+		var V mat.Dense
+		svd.UTo(U)
+		svd.VTo(&V)
+		for _, value := range values {
+			if value > float64(maxInt(m, p))*machineEpsilon*values[0] {
+				rank++
+			}
+		}
+
+		// x = V_r S_r^-1 U_r^T (b - A(X)),   g = U_r S_r^-1 V_r^T c
+		elimination.recoverX = mat.NewDense(p, m, nil)
+		projected := mat.NewVecDense(p, nil)
+		for j := 0; j < rank; j++ {
+			u, v := U.ColView(j), V.ColView(j)
+			var outer mat.Dense
+			outer.Outer(1/values[j], v, u)
+			elimination.recoverX.Add(elimination.recoverX, &outer)
+			vc := mat.Dot(v, data.c)
+			elimination.g.AddScaledVec(elimination.g, vc/values[j], u)
+			projected.AddScaledVec(projected, vc, v)
+		}
+		projected.SubVec(data.c, projected)
+		if norm(projected) > math.Sqrt(tolerance)*(1+norm(data.c)) {
+			elimination.status = SDPDualInfeasible
+		}
+	} else {
+		for i := 0; i < m; i++ {
+			U.Set(i, i, 1)
+		}
+	}
+
+	// Reduced objective C - A^T(g)
+	C := data.applyAT(elimination.g)
+	for k := range C {
+		C[k] = subSym(data.C[k], C[k])
+	}
+	reduced := sdpData{sizes: data.sizes, C: C, b: zeroVector(0), c: zeroVector(0)}
+
+	// Reduced constraints W^T U_perp^T A(X) = W^T U_perp^T b, where the orthonormal columns of W span the
+	// combinations of the rotated constraints whose matrices do not vanish
+	q := m - rank
+	combined, rotatedB := make([][]*mat.SymDense, q), make([]float64, q)
+	for j := 0; j < q; j++ {
+		combined[j] = data.applyAT(U.ColView(rank + j))
+		rotatedB[j] = mat.Dot(U.ColView(rank+j), data.b)
+	}
+	kept := 0
+	if q > 0 {
+		gram := mat.NewSymDense(q, nil)
+		for j := 0; j < q; j++ {
+			for l := j; l < q; l++ {
+				gram.SetSym(j, l, blockInner(combined[j], combined[l]))
+			}
+		}
+		var eig mat.EigenSym
+		eig.Factorize(gram, true)
+		values := eig.Values(nil)
+		var W mat.Dense
+		eig.VectorsTo(&W)
+
+		var rows [][]float64
+		var right []float64
+		for t := q - 1; t >= 0; t-- {
+			w := W.ColView(t)
+			bt := mat.Dot(w, mat.NewVecDense(q, rotatedB))
+			if values[t] <= 1e-20*math.Max(values[q-1], 1) {
+				if math.Abs(bt) > math.Sqrt(tolerance)*(1+normB) {
+					elimination.status = SDPPrimalInfeasible
+				}
+				continue
+			}
+
+			scale := 1 / math.Sqrt(values[t])
+			A := make([]*mat.SymDense, len(data.sizes))
+			for k, size := range data.sizes {
+				A[k] = mat.NewSymDense(size, nil)
+				for j := 0; j < q; j++ {
+					if wj := w.AtVec(j); wj != 0 {
+						A[k].AddSym(A[k], scaledSym(scale*wj, combined[j][k]))
+					}
+				}
+			}
+			reduced.entries = append(reduced.entries, blockEntries(A))
+			right = append(right, scale*bt)
+
+			row := make([]float64, m)
+			for j := 0; j < q; j++ {
+				for i := 0; i < m; i++ {
+					row[i] += scale * w.AtVec(j) * U.At(i, rank+j)
+				}
+			}
+			rows = append(rows, row)
+		}
+
+		kept = len(rows)
+		if kept > 0 {
+			reduced.b = mat.NewVecDense(kept, right)
+			elimination.rows = mat.NewDense(m, kept, nil)
+			for column, row := range rows {
+				elimination.rows.SetCol(column, row)
+			}
+		}
+	}
+
+	// Without constraints left, X = 0 is optimal if the objective is positive semidefinite
+	if (elimination.status == SDPOptimal) && (kept == 0) {
+		for k := range C {
+			var eig mat.EigenSym
+			if ok := eig.Factorize(C[k], false); ok && (eig.Values(nil)[0] < -math.Sqrt(tolerance)*(1+blockNorm(C))) {
+				elimination.status = SDPDualInfeasible
+			}
+		}
+	}
+
+	elimination.reduced = reduced
+	return elimination
+}
+
+/*
+blockEntries
+Description:
+
+	Returns the nonzero entries of the symmetric blocks A_k.
+*/
+func blockEntries(A []*mat.SymDense) [][]sdpBlockEntry {
+	// Algorithm
+	result := make([][]sdpBlockEntry, len(A))
+	for k, Ak := range A {
+		n := Ak.SymmetricDim()
+		for r := 0; r < n; r++ {
+			for c := 0; c < n; c++ {
+				if value := Ak.At(r, c); value != 0 {
+					result[k] = append(result[k], sdpBlockEntry{r, c, value})
+				}
+			}
+		}
+	}
+	return result
+}
+
+/*
+zeroPoint
+Description:
+
+	Returns X = 0, y = 0 and Z = C, the solution of an SDP without constraints and with a positive semidefinite
+	objective.
+*/
+func (data sdpData) zeroPoint() ([]*mat.SymDense, *mat.VecDense, []*mat.SymDense) {
+	// Algorithm
+	X, Z := make([]*mat.SymDense, len(data.sizes)), make([]*mat.SymDense, len(data.sizes))
+	for k, size := range data.sizes {
+		X[k] = mat.NewSymDense(size, nil)
+		Z[k] = mat.NewSymDense(size, nil)
+		Z[k].CopySym(data.C[k])
+	}
+	return X, zeroVector(len(data.entries)), Z
+}
+
+/*
+interiorPoint
+Description:
+
+	Runs the path-following method, from multiples of the identity, on an SDP without free variables.
+*/
+func (data sdpData) interiorPoint(options SDPOptions) (X []*mat.SymDense, y *mat.VecDense, Z []*mat.SymDense, status SDPStatus, iterations int, err error) {
+	// Constants
+	m := len(data.entries)
+	if m == 0 {
+		X, y, Z = data.zeroPoint()
+		return X, y, Z, SDPOptimal, 0, nil
+	}
+	N := 0
+	for _, size := range data.sizes {
+		N += size
+	}
+	normB := norm(data.b)
+	normC := blockNorm(data.C)
+
+	// Starting point
+	scaleX, scaleZ := 10.0, math.Max(10, normC)
+	for i := 0; i < m; i++ {
+		normA := 0.0
+		for _, blockEntries := range data.entries[i] {
+			for _, entry := range blockEntries {
+				normA += entry.value * entry.value
+			}
+		}
+		scaleX = math.Max(scaleX, math.Sqrt(float64(N))*(1+math.Abs(data.b.AtVec(i)))/(1+math.Sqrt(normA)))
+	}
+	X, Z = make([]*mat.SymDense, len(data.sizes)), make([]*mat.SymDense, len(data.sizes))
+	for k, size := range data.sizes {
+		X[k], Z[k] = scaledIdentity(size, scaleX), scaledIdentity(size, scaleZ)
+	}
+	y = zeroVector(m)
+
+	// Algorithm
+	best := sdpIterate{merit: math.Inf(1)}
+	progress, stalled := math.Inf(1), 0
+	for iteration := 0; ; iteration++ {
+		// Residuals
+		rp := data.residualPrimal(X)
+		Rd := data.residualDual(Z, y)
+		primalObjective := blockInner(data.C, X)
+		dualObjective := dot(data.b, y)
+		mu := blockInner(X, Z) / float64(N)
+
+		primalResidual := norm(rp) / (1 + normB)
+		dualResidual := blockNorm(Rd) / (1 + normC)
+		gap := float64(N) * mu / (1 + math.Abs(primalObjective) + math.Abs(dualObjective))
+		merit := math.Max(primalResidual, math.Max(dualResidual, gap))
+		if merit < best.merit {
+			best = sdpIterate{X: X, y: y, Z: Z, merit: merit}
+		}
+		if merit < 0.9*progress {
+			progress, stalled = merit, 0
+		} else {
+			stalled++
+		}
+
+		switch {
+		case merit < options.Tolerance:
+			return X, y, Z, SDPOptimal, iteration, nil
+		case (dualObjective > sdpInfeasibilityBound*(1+normC)) && (dualResidual < options.Tolerance*math.Max(1, dualObjective)):
+			return X, y, Z, SDPPrimalInfeasible, iteration, nil
+		case (primalObjective < -sdpInfeasibilityBound*(1+normB)) && (primalResidual < options.Tolerance*math.Max(1, -primalObjective)):
+			return X, y, Z, SDPDualInfeasible, iteration, nil
+		case (iteration >= options.MaxIterations) || (stalled >= sdpStallIterations):
+			return best.result(iteration, options.Tolerance, nil)
+		}
+
+		// Predictor
+		Zinv, err := blockInverse(Z)
+		if err != nil {
+			return best.result(iteration, options.Tolerance, err)
+		}
+		schur := data.schurSystem(X, Zinv)
+		dXa, _, dZa, err := data.direction(schur, X, Zinv, Rd, rp, 0, nil, nil)
+		if err != nil {
+			return best.result(iteration, options.Tolerance, err)
+		}
+		alphaP, alphaD := maxStep(X, dXa), maxStep(Z, dZa)
+		muAffine := blockInner(blockAxpy(X, alphaP, dXa), blockAxpy(Z, alphaD, dZa)) / float64(N)
+		sigma := 0.0
+		if mu > 0 {
+			sigma = math.Min(1, math.Pow(muAffine/mu, 3))
+		}
+
+		// Corrector
+		dX, dy, dZ, err := data.direction(schur, X, Zinv, Rd, rp, sigma*mu, dXa, dZa)
+		if err != nil {
+			return best.result(iteration, options.Tolerance, err)
+		}
+		alphaP, alphaD = maxStep(X, dX), maxStep(Z, dZ)
+
+		X = blockAxpy(X, alphaP, dX)
+		Z = blockAxpy(Z, alphaD, dZ)
+		y = axpy(y, alphaD, dy)
+	}
+}
+
+/*
+result
+Description:
+
+	Returns the best iterate when the method stops without converging (after the maximum number of iterations, a
+	stall or a numerical failure). Problems without strictly feasible points, which are common in sum of squares
+	programming, make the Newton systems ill-conditioned close to the solution, so the residuals stop decreasing
+	before reaching the tolerance; the best iterate is then SDPNearOptimal if its residuals and gap are below the
+	square root of the tolerance. Otherwise the numerical failure err is returned, or SDPMaxIterations without one.
+*/
+func (best sdpIterate) result(iterations int, tolerance float64, err error) ([]*mat.SymDense, *mat.VecDense, []*mat.SymDense, SDPStatus, int, error) {
+	// Algorithm
+	if best.merit < math.Sqrt(tolerance) {
+		return best.X, best.y, best.Z, SDPNearOptimal, iterations, nil
+	}
+	if err != nil {
+		return nil, nil, nil, SDPMaxIterations, iterations, err
+	}
+	return best.X, best.y, best.Z, SDPMaxIterations, iterations, nil
+}
+
+/*
+solution
+Description:
+
+	Recovers the free variables and the multipliers of the original SDP from a point of the reduced SDP, and
+	computes the objectives and the residuals of the original SDP.
+*/
+func (data sdpData) solution(elimination sdpElimination, X []*mat.SymDense, yReduced *mat.VecDense, Z []*mat.SymDense, status SDPStatus, iterations int) SDPSolution {
+	// Constants
+	p := data.c.Len()
+
+	// x = V_r S_r^-1 U_r^T (b - A(X)),   y = U_kept y_reduced + g
+	rp := data.residualPrimal(X)
+	x := zeroVector(p)
+	if p > 0 {
+		x.MulVec(elimination.recoverX, rp)
+	}
+	y := mat.VecDenseCopyOf(elimination.g)
+	if elimination.rows != nil {
+		var combined mat.VecDense
+		combined.MulVec(elimination.rows, yReduced)
+		y.AddVec(y, &combined)
+	}
+
+	// Residuals of the original problem
+	rf := zeroVector(p)
+	if p > 0 {
+		var Fx, FTy mat.VecDense
+		Fx.MulVec(data.F, x)
+		rp.SubVec(rp, &Fx)
+		FTy.MulVec(data.F.T(), y)
+		rf.SubVec(data.c, &FTy)
+	}
+	Rd := data.residualDual(Z, y)
+
+	return SDPSolution{
+		Status:          status,
+		X:               X,
+		Free:            x,
+		Y:               y,
+		Z:               Z,
+		PrimalObjective: blockInner(data.C, X) + dot(data.c, x),
+		DualObjective:   dot(data.b, y),
+		PrimalResidual:  norm(rp) / (1 + norm(data.b)),
+		DualResidual:    (blockNorm(Rd) + norm(rf)) / (1 + blockNorm(data.C) + norm(data.c)),
+		Iterations:      iterations,
+	}
+}
+
+/*
+applyA
+Description:
+
+	Computes the vector A(X) with entries sum_k <A_ik, X_k>.
+*/
+func (data sdpData) applyA(X []*mat.SymDense) *mat.VecDense {
+	// Algorithm
+	m := len(data.entries)
+	result := zeroVector(m)
+	for i, blocks := range data.entries {
+		value := 0.0
+		for k, blockEntries := range blocks {
+			for _, entry := range blockEntries {
+				value += entry.value * X[k].At(entry.row, entry.column)
+			}
+		}
+		result.SetVec(i, value)
+	}
+	return result
+}
+
+/*
+applyAT
+Description:
+
+	Computes the blocks of A^T(y) = sum_i y_i A_ik.
+*/
+func (data sdpData) applyAT(y mat.Vector) []*mat.SymDense {
+	// Algorithm
+	result := make([]*mat.SymDense, len(data.sizes))
+	for k, size := range data.sizes {
+		result[k] = mat.NewSymDense(size, nil)
+	}
+	for i, blocks := range data.entries {
+		yi := y.AtVec(i)
+		if yi == 0 {
+			continue
+		}
+		for k, blockEntries := range blocks {
+			for _, entry := range blockEntries {
+				if entry.row <= entry.column {
+					result[k].SetSym(entry.row, entry.column, result[k].At(entry.row, entry.column)+yi*entry.value)
+				}
+			}
+		}
+	}
+	return result
+}
+
+/*
+residualPrimal
+Description:
+
+	Computes b - A(X).
+*/
+func (data sdpData) residualPrimal(X []*mat.SymDense) *mat.VecDense {
+	// Algorithm
+	rp := data.applyA(X)
+	if rp.Len() > 0 {
+		rp.SubVec(data.b, rp)
+	}
+	return rp
+}
+
+/*
+residualDual
+Description:
+
+	Computes the dual residual C - Z - A^T(y).
+*/
+func (data sdpData) residualDual(Z []*mat.SymDense, y mat.Vector) []*mat.SymDense {
+	// Algorithm
+	Rd := data.applyAT(y)
+	for k := range Rd {
+		Rd[k] = subSym(subSym(data.C[k], Rd[k]), Z[k])
+	}
+	return Rd
+}
+
+/*
+schurSystem
+Description:
+
+	Factorizes the Schur complement M_ij = <A_i, X A_j Z^-1> of the Newton system. The product X A_j Z^-1 is formed
+	once per constraint and block, since the constraints of a reduced program are dense.
+*/
+func (data sdpData) schurSystem(X, Zinv []*mat.SymDense) *mat.LU {
+	// Constants
+	m := len(data.entries)
+
+	// Algorithm
+	M := mat.NewDense(m, m, nil)
+	for k, size := range data.sizes {
+		if size == 0 {
+			continue
+		}
+		A := mat.NewDense(size, size, nil)
+		var XA, G mat.Dense
+		for j := 0; j < m; j++ {
+			if len(data.entries[j][k]) == 0 {
+				continue
+			}
+			A.Zero()
+			for _, f := range data.entries[j][k] {
+				A.Set(f.row, f.column, A.At(f.row, f.column)+f.value)
+			}
+			XA.Mul(X[k], A)
+			G.Mul(&XA, Zinv[k])
+			raw := G.RawMatrix()
+			for i := 0; i <= j; i++ {
+				value := 0.0
+				for _, e := range data.entries[i][k] {
+					value += e.value * raw.Data[e.row*raw.Stride+e.column]
+				}
+				M.Set(i, j, M.At(i, j)+value)
+			}
+		}
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j < i; j++ {
+			M.Set(i, j, M.At(j, i))
+		}
+	}
+
+	// Keep the diagonal away from zero, so that nearly redundant constraints do not make M singular
+	largest := 0.0
+	for i := 0; i < m; i++ {
+		largest = math.Max(largest, M.At(i, i))
+	}
+	floor := 1e-14 * math.Max(largest, 1)
+	for i := 0; i < m; i++ {
+		M.Set(i, i, math.Max(M.At(i, i), floor))
+	}
+
+	var lu mat.LU
+	lu.Factorize(M)
+	return &lu
+}
+
+/*
+direction
+Description:
+
+	Computes the HKM search direction towards the point of the central path with X Z = target I, with the
+	second-order correction dXa dZa of the predictor when it is given.
+*/
+func (data sdpData) direction(schur *mat.LU, X, Zinv, Rd []*mat.SymDense, rp *mat.VecDense, target float64, dXa, dZa []*mat.SymDense) (dX []*mat.SymDense, dy *mat.VecDense, dZ []*mat.SymDense, err error) {
+	// Constants
+	m := rp.Len()
+
+	// T = target Z^-1 - X - X Rd Z^-1 - dXa dZa Z^-1
+	T := make([]*mat.Dense, len(data.sizes))
+	for k, size := range data.sizes {
+		var XRd, W mat.Dense
+		XRd.Mul(X[k], Rd[k])
+		if dXa != nil {
+			var corrector mat.Dense
+			corrector.Mul(dXa[k], dZa[k])
+			XRd.Add(&XRd, &corrector)
+		}
+		W.Mul(&XRd, Zinv[k])
+		T[k] = mat.NewDense(size, size, nil)
+		T[k].Scale(target, Zinv[k])
+		T[k].Sub(T[k], X[k])
+		T[k].Sub(T[k], &W)
+	}
+
+	// M dy = rp - A(T)
+	rhs := mat.NewVecDense(m, nil)
+	for i, blocks := range data.entries {
+		value := rp.AtVec(i)
+		for k, blockEntries := range blocks {
+			for _, entry := range blockEntries {
+				value -= entry.value * T[k].At(entry.row, entry.column)
+			}
+		}
+		rhs.SetVec(i, value)
+	}
+
+	dy = mat.NewVecDense(m, nil)
+	if err := schur.SolveVecTo(dy, false, rhs); err != nil {
+		var condition mat.Condition
+		if !errors.As(err, &condition) {
+			return nil, nil, nil, fmt.Errorf("The Newton system of the interior point method could not be solved: %v", err)
+		}
+	}
+
+	// dZ = Rd - A^T(dy),  dX = T + X A^T(dy) Z^-1 (symmetrized)
+	ATdy := data.applyAT(dy)
+	dZ = make([]*mat.SymDense, len(data.sizes))
+	dX = make([]*mat.SymDense, len(data.sizes))
+	for k := range data.sizes {
+		dZ[k] = subSym(Rd[k], ATdy[k])
+
+		var XA, XAZ mat.Dense
+		XA.Mul(X[k], ATdy[k])
+		XAZ.Mul(&XA, Zinv[k])
+		XAZ.Add(&XAZ, T[k])
+		dX[k] = symmetricPart(&XAZ)
+	}
+
+	return dX, dy, dZ, nil
+}
+
+/*
+maxStep
+Description:
+
+	Returns the largest step alpha <= 1 (times sdpStepFraction) such that X + alpha dX stays positive definite.
+*/
+func maxStep(X, dX []*mat.SymDense) float64 {
+	// Algorithm
+	alpha := 1.0
+	for k := range X {
+		var chol mat.Cholesky
+		if ok := chol.Factorize(X[k]); !ok {
+			return 0
+		}
+		var L mat.TriDense
+		chol.LTo(&L)
+
+		// Smallest eigenvalue of L^-1 dX L^-T
+		var LinvDX, S mat.Dense
+		if err := LinvDX.Solve(&L, dX[k]); err != nil {
+			return 0
+		}
+		if err := S.Solve(&L, LinvDX.T()); err != nil {
+			return 0
+		}
+		var eig mat.EigenSym
+		if ok := eig.Factorize(symmetricPart(&S), false); !ok {
+			return 0
+		}
+		smallest := eig.Values(nil)[0]
+		if smallest < 0 {
+			alpha = math.Min(alpha, -sdpStepFraction/smallest)
+		}
+	}
+	return alpha
+}
+
+/*
+blockInverse
+Description:
+
+	Inverts each block of a positive definite block diagonal matrix; ill-conditioned blocks (which appear close to
+	the solution) are inverted as well.
+*/
+func blockInverse(Z []*mat.SymDense) ([]*mat.SymDense, error) {
+	// Algorithm
+	result := make([]*mat.SymDense, len(Z))
+	for k, Zk := range Z {
+		var chol mat.Cholesky
+		if ok := chol.Factorize(Zk); !ok {
+			return nil, errors.New("The dual slack matrix of the interior point method lost positive definiteness.")
+		}
+		result[k] = mat.NewSymDense(Zk.SymmetricDim(), nil)
+		if err := chol.InverseTo(result[k]); err != nil {
+			var condition mat.Condition
+			if !errors.As(err, &condition) {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+/*
+blockInner
+Description:
+
+	Returns the inner product sum_k <A_k, B_k> = sum_k trace(A_k B_k) of two symmetric block diagonal matrices.
+*/
+func blockInner(A, B []*mat.SymDense) float64 {
+	// Algorithm
+	result := 0.0
+	for k := range A {
+		n := A[k].SymmetricDim()
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				result += A[k].At(i, j) * B[k].At(i, j)
+			}
+		}
+	}
+	return result
+}
+
+/*
+blockNorm
+Description:
+
+	Returns the Frobenius norm of a symmetric block diagonal matrix.
+*/
+func blockNorm(A []*mat.SymDense) float64 {
+	return math.Sqrt(blockInner(A, A))
+}
+
+/*
+blockAxpy
+Description:
+
+	Returns the blocks of X + alpha dX.
+*/
+func blockAxpy(X []*mat.SymDense, alpha float64, dX []*mat.SymDense) []*mat.SymDense {
+	// Algorithm
+	result := make([]*mat.SymDense, len(X))
+	for k := range X {
+		result[k] = mat.NewSymDense(X[k].SymmetricDim(), nil)
+		result[k].AddSym(X[k], scaledSym(alpha, dX[k]))
+	}
+	return result
+}
+
+/*
+axpy
+Description:
+
+	Returns the vector x + alpha dx.
+*/
+func axpy(x *mat.VecDense, alpha float64, dx *mat.VecDense) *mat.VecDense {
+	// Algorithm
+	if x.Len() == 0 {
+		return x
+	}
+	result := mat.NewVecDense(x.Len(), nil)
+	result.AddScaledVec(x, alpha, dx)
+	return result
+}
+
+/*
+scaledSym
+Description:
+
+	Returns the symmetric matrix alpha A.
+*/
+func scaledSym(alpha float64, A *mat.SymDense) *mat.SymDense {
+	result := mat.NewSymDense(A.SymmetricDim(), nil)
+	result.ScaleSym(alpha, A)
+	return result
+}
+
+/*
+subSym
+Description:
+
+	Returns the symmetric matrix A - B.
+*/
+func subSym(A, B *mat.SymDense) *mat.SymDense {
+	result := mat.NewSymDense(A.SymmetricDim(), nil)
+	result.AddSym(A, scaledSym(-1, B))
+	return result
+}
+
+/*
+scaledIdentity
+Description:
+
+	Returns the n x n matrix alpha I.
+*/
+func scaledIdentity(n int, alpha float64) *mat.SymDense {
+	result := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		result.SetSym(i, i, alpha)
+	}
+	return result
+}
+
+/*
+symmetricPart
+Description:
+
+	Returns (M + M^T) / 2 for the square matrix M.
+*/
+func symmetricPart(M mat.Matrix) *mat.SymDense {
+	// Algorithm
+	n, _ := M.Dims()
+	result := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			result.SetSym(i, j, (M.At(i, j)+M.At(j, i))/2)
+		}
+	}
+	return result
+}
+
+/*
+zeroVector
+Description:
+
+	Returns the zero vector of length n (an empty vector if n is zero).
+*/
+func zeroVector(n int) *mat.VecDense {
+	if n == 0 {
+		return &mat.VecDense{}
+	}
+	return mat.NewVecDense(n, nil)
+}
+
+/*
+dot
+Description:
+
+	Returns the inner product of two vectors of the same length (zero if they are empty).
+*/
+func dot(a, b *mat.VecDense) float64 {
+	if a.Len() == 0 {
+		return 0
+	}
+	return mat.Dot(a, b)
+}
+
+/*
+norm
+Description:
+
+	Returns the Euclidean norm of a vector (zero if it is empty).
+*/
+func norm(a *mat.VecDense) float64 {
+	if a.Len() == 0 {
+		return 0
+	}
+	return mat.Norm(a, 2)
+}
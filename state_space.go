@@ -0,0 +1,267 @@
+/*
+   state_space.go
+   Description:
+       An implementation "like" MATLAB's ss object. A StateSpace model describes the linear system
+           dx/dt = A x + B u   (or x+ = A x + B u when the sample time Ts is positive)
+               y = C x + D u
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type StateSpace struct {
+	A  *mat.Dense
+	B  *mat.Dense
+	C  *mat.Dense
+	D  *mat.Dense
+	Ts float64 // Sample time. Ts = 0 means that the model is continuous-time.
+
+	InputDelay []float64 // Delay on each input (in seconds). A nil slice means that there are no delays.
+}
+
+/*
+GetStateSpace
+Description:
+
+	Creates a continuous-time state space model from the four matrices A, B, C and D.
+	The matrices are copied, so later changes to the inputs do not modify the model.
+*/
+func GetStateSpace(AIn, BIn, CIn, DIn mat.Matrix) (StateSpace, error) {
+	return GetDiscreteStateSpace(AIn, BIn, CIn, DIn, 0)
+}
+
+/*
+GetDiscreteStateSpace
+Description:
+
+	Creates a state space model with sample time TsIn from the four matrices A, B, C and D.
+	A sample time of zero creates a continuous-time model.
+*/
+func GetDiscreteStateSpace(AIn, BIn, CIn, DIn mat.Matrix, TsIn float64) (StateSpace, error) {
+	// Create Model
+	sys := StateSpace{
+		A:  mat.DenseCopyOf(AIn),
+		B:  mat.DenseCopyOf(BIn),
+		C:  mat.DenseCopyOf(CIn),
+		D:  mat.DenseCopyOf(DIn),
+		Ts: TsIn,
+	}
+
+	// Check it
+	if err := sys.Check(); err != nil {
+		return StateSpace{}, err
+	}
+
+	return sys, nil
+}
+
+/*
+Check
+Description:
+
+	Returns an error if the state space model's matrices do not have compatible dimensions
+	or if the sample time is invalid.
+*/
+func (sys StateSpace) Check() error {
+	// Check that all matrices are defined
+	if sys.A == nil || sys.B == nil || sys.C == nil || sys.D == nil {
+		return errors.New("One of the matrices A, B, C or D of the state space model is not defined.")
+	}
+
+	// Constants
+	nA1, nA2 := sys.A.Dims()
+	nB, mB := sys.B.Dims()
+	pC, nC := sys.C.Dims()
+	pD, mD := sys.D.Dims()
+
+	// Check dimensions
+	if nA1 != nA2 {
+		return fmt.Errorf("The A matrix must be square; received a %v x %v matrix.", nA1, nA2)
+	}
+
+	if nB != nA1 {
+		return fmt.Errorf("The B matrix has %v rows; expected %v.", nB, nA1)
+	}
+
+	if nC != nA1 {
+		return fmt.Errorf("The C matrix has %v columns; expected %v.", nC, nA1)
+	}
+
+	if (pD != pC) || (mD != mB) {
+		return fmt.Errorf("The D matrix has dimensions %v x %v; expected %v x %v.", pD, mD, pC, mB)
+	}
+
+	// Check sample time
+	if sys.Ts < 0 || math.IsNaN(sys.Ts) || math.IsInf(sys.Ts, 0) {
+		return fmt.Errorf("The sample time must be a nonnegative, finite number; received %v.", sys.Ts)
+	}
+
+	// Check input delays
+	if (sys.InputDelay != nil) && (len(sys.InputDelay) != mB) {
+		return fmt.Errorf("The model has %v input delays; expected %v.", len(sys.InputDelay), mB)
+	}
+
+	for j, delay := range sys.InputDelay {
+		if delay < 0 || math.IsNaN(delay) || math.IsInf(delay, 0) {
+			return fmt.Errorf("Input delay %v must be a nonnegative, finite number; received %v.", j, delay)
+		}
+	}
+
+	// If nothing is wrong, return nil
+	return nil
+}
+
+/*
+Dims
+Description:
+
+	Returns the number of states, inputs and outputs of the model, in that order.
+*/
+func (sys StateSpace) Dims() (n, m, p int) {
+	n, m = sys.B.Dims()
+	p, _ = sys.C.Dims()
+	return n, m, p
+}
+
+/*
+IsDiscrete
+Description:
+
+	Returns true if the model is a discrete-time model (i.e. it has a positive sample time).
+*/
+func (sys StateSpace) IsDiscrete() bool {
+	return sys.Ts > 0
+}
+
+/*
+Copy
+Description:
+
+	Creates a deep copy of the state space model.
+*/
+func (sys StateSpace) Copy() StateSpace {
+	return StateSpace{
+		A:  mat.DenseCopyOf(sys.A),
+		B:  mat.DenseCopyOf(sys.B),
+		C:  mat.DenseCopyOf(sys.C),
+		D:  mat.DenseCopyOf(sys.D),
+		Ts: sys.Ts,
+
+		InputDelay: append([]float64(nil), sys.InputDelay...),
+	}
+}
+
+/*
+HasInputDelay
+Description:
+
+	Returns true if any of the model's inputs is delayed.
+*/
+func (sys StateSpace) HasInputDelay() bool {
+	for _, delay := range sys.InputDelay {
+		if delay > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Poles
+Description:
+
+	Computes the poles of the model, i.e. the eigenvalues of A.
+*/
+func (sys StateSpace) Poles() ([]complex128, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return nil, err
+	}
+
+	// Algorithm
+	return eigenvalues(sys.A)
+}
+
+/*
+DCGain
+Description:
+
+	Computes the steady-state gain of the model.
+	For continuous-time models this is D - C A^{-1} B and for discrete-time models it is
+	D + C (I - A)^{-1} B. An error is returned when the model has a pole at s = 0 (or z = 1).
+*/
+func (sys StateSpace) DCGain() (*mat.Dense, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return nil, err
+	}
+
+	// Constants
+	n, m, p := sys.Dims()
+
+	// Algorithm
+	gain := mat.DenseCopyOf(sys.D)
+
+	M := mat.NewDense(n, n, nil)
+	if sys.IsDiscrete() {
+		M.Sub(eye(n), sys.A)
+	} else {
+		M.Scale(-1, sys.A)
+	}
+
+	X := mat.NewDense(n, m, nil)
+	if err := X.Solve(M, sys.B); err != nil {
+		return nil, fmt.Errorf("The model has a pole at the DC point, so its DC gain is infinite: %v", err)
+	}
+
+	CX := mat.NewDense(p, m, nil)
+	CX.Mul(sys.C, X)
+	gain.Add(gain, CX)
+
+	return gain, nil
+}
+
+/*
+eye
+Description:
+
+	Creates an n x n identity matrix.
+*/
+func eye(n int) *mat.Dense {
+	I := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		I.Set(i, i, 1)
+	}
+	return I
+}
+
+/*
+eigenvalues
+Description:
+
+	Computes the eigenvalues of the square matrix M, sorted by real part and then by imaginary part.
+*/
+func eigenvalues(M mat.Matrix) ([]complex128, error) {
+	// Constants
+	n, _ := M.Dims()
+	if n == 0 {
+		return []complex128{}, nil
+	}
+
+	// Algorithm
+	var eig mat.Eigen
+	if ok := eig.Factorize(M, mat.EigenNone); !ok {
+		return nil, errors.New("The eigenvalue decomposition did not converge.")
+	}
+
+	values := eig.Values(nil)
+	sortComplex(values)
+	return values, nil
+}
@@ -250,3 +250,63 @@ func (m Monomial) String() string {
 	return stringOut
 
 }
+
+/*
+Evaluate
+Description:
+
+	Evaluates the monomial at the point given by values (a value for each of its variables).
+	Returns an error if a variable of the monomial has no value.
+*/
+func (m Monomial) Evaluate(values map[Variable]float64) (float64, error) {
+	// Algorithm
+	result := m.Coefficient
+	for varIndex, v := range m.Variables {
+		value, ok := values[v]
+		if !ok {
+			return 0, fmt.Errorf("The variable %v has no value.", v)
+		}
+		for k := 0; k < m.Exponents[varIndex]; k++ {
+			result *= value
+		}
+	}
+
+	return result, nil
+}
+
+/*
+Derivative
+Description:
+
+	Computes the partial derivative of the monomial with respect to the variable v, which is a polynomial with
+	one term per occurrence of v in the monomial (and no terms if v does not appear).
+*/
+func (m Monomial) Derivative(v Variable) Polynomial {
+	// Algorithm
+	derivative := Polynomial{Monomials: []Monomial{}}
+	for varIndex, tempVar := range m.Variables {
+		if (tempVar != v) || (m.Exponents[varIndex] == 0) {
+			continue
+		}
+		term := m.Copy()
+		term.Coefficient *= float64(m.Exponents[varIndex])
+		term.Exponents[varIndex]--
+		derivative.Monomials = append(derivative.Monomials, term)
+	}
+
+	return derivative
+}
+
+/*
+Degree
+Description:
+
+	Returns the total degree of the monomial (the sum of its exponents).
+*/
+func (m Monomial) Degree() int {
+	degree := 0
+	for _, exponent := range m.Exponents {
+		degree += exponent
+	}
+	return degree
+}
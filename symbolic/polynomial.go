@@ -205,3 +205,118 @@ func (p Polynomial) Multiply(terms ...interface{}) (Expression, error) {
 		return &Monomial{}, fmt.Errorf("The input type %T was not expected!", term1)
 	}
 }
+
+/*
+ToPolynomial
+Description:
+
+	Converts a constant (float64), a Variable, a Monomial or a Polynomial (or a pointer to one of them) into a
+	Polynomial.
+*/
+func ToPolynomial(e interface{}) (Polynomial, error) {
+	// Algorithm
+	switch term := e.(type) {
+	case float64:
+		return Polynomial{Monomials: []Monomial{{Coefficient: term, Variables: []Variable{}, Exponents: []int{}}}}, nil
+	case Variable:
+		return Polynomial{Monomials: []Monomial{{Coefficient: 1.0, Variables: []Variable{term}, Exponents: []int{1}}}}, nil
+	case *Variable:
+		return ToPolynomial(*term)
+	case Monomial:
+		return Polynomial{Monomials: []Monomial{term.Copy()}}, nil
+	case *Monomial:
+		return ToPolynomial(*term)
+	case Polynomial:
+		return term.Copy(), nil
+	case *Polynomial:
+		return ToPolynomial(*term)
+	default:
+		return Polynomial{}, fmt.Errorf("The input type %T cannot be converted to a Polynomial.", e)
+	}
+}
+
+/*
+Copy
+Description:
+
+	Returns a copy of the polynomial that does not share memory with the original.
+*/
+func (p Polynomial) Copy() Polynomial {
+	// Algorithm
+	pOut := Polynomial{Monomials: make([]Monomial, len(p.Monomials))}
+	for i, monomial := range p.Monomials {
+		pOut.Monomials[i] = monomial.Copy()
+	}
+	return pOut
+}
+
+/*
+Evaluate
+Description:
+
+	Evaluates the polynomial at the point given by values (a value for each of its variables).
+	The empty polynomial evaluates to zero.
+*/
+func (p Polynomial) Evaluate(values map[Variable]float64) (float64, error) {
+	// Algorithm
+	result := 0.0
+	for _, monomial := range p.Monomials {
+		value, err := monomial.Evaluate(values)
+		if err != nil {
+			return 0, err
+		}
+		result += value
+	}
+	return result, nil
+}
+
+/*
+Derivative
+Description:
+
+	Computes the partial derivative of the polynomial with respect to the variable v.
+*/
+func (p Polynomial) Derivative(v Variable) Polynomial {
+	// Algorithm
+	derivative := Polynomial{Monomials: []Monomial{}}
+	for _, monomial := range p.Monomials {
+		derivative.Monomials = append(derivative.Monomials, monomial.Derivative(v).Monomials...)
+	}
+	return derivative
+}
+
+/*
+Variables
+Description:
+
+	Returns the distinct variables that appear in the polynomial, in order of first appearance.
+*/
+func (p Polynomial) Variables() []Variable {
+	// Algorithm
+	variables := []Variable{}
+	for _, monomial := range p.Monomials {
+		for varIndex, v := range monomial.Variables {
+			if (monomial.Exponents[varIndex] != 0) && (v.FoundIn(variables) == -1) {
+				variables = append(variables, v)
+			}
+		}
+	}
+	return variables
+}
+
+/*
+Degree
+Description:
+
+	Returns the largest total degree of the monomials of the polynomial (zero for the empty polynomial).
+*/
+func (p Polynomial) Degree() int {
+	// Algorithm
+	degree := 0
+	for _, monomial := range p.Monomials {
+		if monomial.Degree() > degree {
+			degree = monomial.Degree()
+		}
+	}
+	return degree
+}
@@ -0,0 +1,199 @@
+/*
+   controllability_test.go
+   Description:
+	   Tests for the controllability and observability functions defined in controllability.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestPartiallyControllableSystem
+Description:
+
+	Creates a three state model in which the third state is not controllable and the second state
+	is not observable.
+*/
+func getTestPartiallyControllableSystem() goControl.StateSpace {
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(3, 3, []float64{
+			-1, 0, 0,
+			0, -2, 0,
+			0, 0, -3,
+		}),
+		mat.NewDense(3, 1, []float64{1, 1, 0}),
+		mat.NewDense(1, 3, []float64{1, 0, 1}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+	return sys
+}
+
+/*
+TestControllability_Ctrb1
+Description:
+
+	Computes the controllability matrix of a double integrator.
+*/
+func TestControllability_Ctrb1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{0, 1, 0, 0})
+	B := mat.NewDense(2, 1, []float64{0, 1})
+
+	// Algorithm
+	controllabilityMatrix, err := goControl.Ctrb(A, B)
+	if err != nil {
+		t.Errorf("There was an error computing the controllability matrix: %v", err)
+	}
+
+	expected := mat.NewDense(2, 2, []float64{0, 1, 1, 0})
+	if !mat.Equal(controllabilityMatrix, expected) {
+		t.Errorf("Ctrb = %v; want %v", mat.Formatted(controllabilityMatrix), mat.Formatted(expected))
+	}
+
+	rank, _ := goControl.Rank(controllabilityMatrix, 0)
+	if rank != 2 {
+		t.Errorf("rank(Ctrb) = %v; want 2", rank)
+	}
+}
+
+/*
+TestControllability_IsControllable1
+Description:
+
+	Verifies that the rank tests detect the uncontrollable and unobservable states.
+*/
+func TestControllability_IsControllable1(t *testing.T) {
+	// Constants
+	sys := getTestPartiallyControllableSystem()
+
+	// Algorithm
+	controllable, err := sys.IsControllable(0)
+	if err != nil {
+		t.Errorf("There was an error testing controllability: %v", err)
+	}
+	if controllable {
+		t.Errorf("The model was found to be controllable; want uncontrollable")
+	}
+
+	observable, err := sys.IsObservable(0)
+	if err != nil {
+		t.Errorf("There was an error testing observability: %v", err)
+	}
+	if observable {
+		t.Errorf("The model was found to be observable; want unobservable")
+	}
+}
+
+/*
+TestControllability_PBH1
+Description:
+
+	Verifies that the PBH tests find the uncontrollable mode at -3 and the unobservable mode at -2.
+*/
+func TestControllability_PBH1(t *testing.T) {
+	// Constants
+	sys := getTestPartiallyControllableSystem()
+
+	// Algorithm
+	uncontrollable, err := goControl.PBHUncontrollableModes(sys.A, sys.B, 0)
+	if err != nil {
+		t.Errorf("There was an error applying the PBH test: %v", err)
+	}
+	if len(uncontrollable) != 1 || cmplx.Abs(uncontrollable[0]+3) > 1e-9 {
+		t.Errorf("Uncontrollable modes = %v; want [-3]", uncontrollable)
+	}
+
+	unobservable, err := goControl.PBHUnobservableModes(sys.A, sys.C, 0)
+	if err != nil {
+		t.Errorf("There was an error applying the PBH test: %v", err)
+	}
+	if len(unobservable) != 1 || cmplx.Abs(unobservable[0]+2) > 1e-9 {
+		t.Errorf("Unobservable modes = %v; want [-2]", unobservable)
+	}
+}
+
+/*
+TestControllability_Ctrbf1
+Description:
+
+	Verifies that the staircase form finds a two dimensional controllable subspace and that the
+	uncontrollable block of the transformed model has the expected zero structure.
+*/
+func TestControllability_Ctrbf1(t *testing.T) {
+	// Constants
+	sys := getTestPartiallyControllableSystem()
+
+	// Algorithm
+	form, err := goControl.Ctrbf(sys.A, sys.B, sys.C, 0)
+	if err != nil {
+		t.Errorf("There was an error computing the staircase form: %v", err)
+	}
+
+	if form.NumReached() != 2 {
+		t.Errorf("The controllable subspace has dimension %v; want 2", form.NumReached())
+	}
+	for j := 0; j < 2; j++ {
+		if form.A.At(2, j) != 0 {
+			t.Errorf("Abar[2,%v] = %v; want 0", j, form.A.At(2, j))
+		}
+	}
+	if form.B.At(2, 0) != 0 {
+		t.Errorf("Bbar[2,0] = %v; want 0", form.B.At(2, 0))
+	}
+	if math.Abs(form.A.At(2, 2)+3) > 1e-12 {
+		t.Errorf("The uncontrollable block is %v; want -3", form.A.At(2, 2))
+	}
+}
+
+/*
+TestControllability_KalmanDecomposition1
+Description:
+
+	Verifies the dimensions of the four subspaces of the Kalman decomposition and that the eigenvalue of
+	each block is the expected one.
+*/
+func TestControllability_KalmanDecomposition1(t *testing.T) {
+	// Constants
+	sys := getTestPartiallyControllableSystem()
+
+	// Algorithm
+	decomposition, err := sys.KalmanDecomposition(0)
+	if err != nil {
+		t.Errorf("There was an error computing the Kalman decomposition: %v", err)
+	}
+
+	if decomposition.NumControllableObservable != 1 ||
+		decomposition.NumControllableUnobservable != 1 ||
+		decomposition.NumUncontrollableObservable != 1 ||
+		decomposition.NumUncontrollableUnobservable != 0 {
+		t.Errorf(
+			"The subspace dimensions are (%v,%v,%v,%v); want (1,1,1,0)",
+			decomposition.NumControllableObservable,
+			decomposition.NumControllableUnobservable,
+			decomposition.NumUncontrollableObservable,
+			decomposition.NumUncontrollableUnobservable,
+		)
+	}
+
+	expectedDiagonal := []float64{-1, -2, -3}
+	for i, expected := range expectedDiagonal {
+		if math.Abs(decomposition.Sys.A.At(i, i)-expected) > 1e-9 {
+			t.Errorf("Abar[%v,%v] = %v; want %v", i, i, decomposition.Sys.A.At(i, i), expected)
+		}
+	}
+
+	if math.Abs(decomposition.Sys.B.At(2, 0)) > 1e-9 {
+		t.Errorf("Bbar[2,0] = %v; want 0", decomposition.Sys.B.At(2, 0))
+	}
+	if math.Abs(decomposition.Sys.C.At(0, 1)) > 1e-9 {
+		t.Errorf("Cbar[0,1] = %v; want 0", decomposition.Sys.C.At(0, 1))
+	}
+}
@@ -0,0 +1,254 @@
+/*
+   discretization_test.go
+   Description:
+	   Tests for the C2d and D2c functions defined in discretization.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestSecondOrderSystem
+Description:
+
+	Creates a lightly damped, two-input, one-output, second order continuous-time model that
+	is used by several of the discretization tests.
+*/
+func getTestSecondOrderSystem() goControl.StateSpace {
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(2, 2, []float64{0, 1, -4, -0.8}),
+		mat.NewDense(2, 2, []float64{0, 1, 1, 0.5}),
+		mat.NewDense(1, 2, []float64{1, 0.2}),
+		mat.NewDense(1, 2, []float64{0, 0.1}),
+	)
+	return sys
+}
+
+/*
+checkModelsAreEqual
+Description:
+
+	Reports an error if any of the matrices of the two models are different.
+*/
+func checkModelsAreEqual(t *testing.T, sys1, sys2 goControl.StateSpace, tol float64) {
+	if !mat.EqualApprox(sys1.A, sys2.A, tol) {
+		t.Errorf("A = %v; want %v", mat.Formatted(sys1.A), mat.Formatted(sys2.A))
+	}
+	if !mat.EqualApprox(sys1.B, sys2.B, tol) {
+		t.Errorf("B = %v; want %v", mat.Formatted(sys1.B), mat.Formatted(sys2.B))
+	}
+	if !mat.EqualApprox(sys1.C, sys2.C, tol) {
+		t.Errorf("C = %v; want %v", mat.Formatted(sys1.C), mat.Formatted(sys2.C))
+	}
+	if !mat.EqualApprox(sys1.D, sys2.D, tol) {
+		t.Errorf("D = %v; want %v", mat.Formatted(sys1.D), mat.Formatted(sys2.D))
+	}
+}
+
+/*
+TestDiscretization_C2d1
+Description:
+
+	Discretizes 1/(s + 1) with a zero-order hold and compares with the analytic result
+	x+ = e^{-Ts} x + (1 - e^{-Ts}) u.
+*/
+func TestDiscretization_C2d1(t *testing.T) {
+	// Constants
+	Ts := 0.1
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(1, 1, []float64{-1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+
+	// Algorithm
+	sysD, err := goControl.C2d(sys, Ts, goControl.ZeroOrderHold)
+	if err != nil {
+		t.Errorf("There was an error discretizing the model: %v", err)
+	}
+
+	if !sysD.IsDiscrete() || sysD.Ts != Ts {
+		t.Errorf("The sample time of the discrete model is %v; want %v", sysD.Ts, Ts)
+	}
+	if math.Abs(sysD.A.At(0, 0)-math.Exp(-Ts)) > 1e-12 {
+		t.Errorf("Ad = %v; want %v", sysD.A.At(0, 0), math.Exp(-Ts))
+	}
+	if math.Abs(sysD.B.At(0, 0)-(1-math.Exp(-Ts))) > 1e-12 {
+		t.Errorf("Bd = %v; want %v", sysD.B.At(0, 0), 1-math.Exp(-Ts))
+	}
+}
+
+/*
+TestDiscretization_RoundTrip1
+Description:
+
+	Verifies that D2c inverts C2d for the zero-order hold, first-order hold and Tustin methods.
+*/
+func TestDiscretization_RoundTrip1(t *testing.T) {
+	// Constants
+	sys := getTestSecondOrderSystem()
+	Ts := 0.05
+
+	// Algorithm
+	for _, method := range []goControl.DiscretizationMethod{goControl.ZeroOrderHold, goControl.FirstOrderHold, goControl.Tustin} {
+		sysD, err := goControl.C2d(sys, Ts, method)
+		if err != nil {
+			t.Errorf("There was an error discretizing the model with %v: %v", method, err)
+			continue
+		}
+
+		sysC, err := goControl.D2c(sysD, method)
+		if err != nil {
+			t.Errorf("There was an error converting the model back to continuous time with %v: %v", method, err)
+			continue
+		}
+
+		checkModelsAreEqual(t, sysC, sys, 1e-8)
+	}
+}
+
+/*
+TestDiscretization_Tustin1
+Description:
+
+	Verifies that the Tustin method with prewarping matches the continuous-time frequency
+	response exactly at the prewarp frequency, and that the round trip recovers the model.
+*/
+func TestDiscretization_Tustin1(t *testing.T) {
+	// Constants
+	sys := getTestSecondOrderSystem()
+	Ts := 0.2
+	w := 2.0
+	options := goControl.DiscretizationOptions{Method: goControl.Tustin, PrewarpFrequency: w}
+
+	// Algorithm
+	sysD, err := goControl.C2dWithOptions(sys, Ts, options)
+	if err != nil {
+		t.Errorf("There was an error discretizing the model: %v", err)
+	}
+
+	tfC, _ := goControl.Ss2tf(sys)
+	tfD, _ := goControl.Ss2tf(sysD)
+	GC := tfC.Evaluate(complex(0, w))
+	GD := tfD.Evaluate(cmplx.Exp(complex(0, w*Ts)))
+	for j := 0; j < 2; j++ {
+		if cmplx.Abs(GC.At(0, j)-GD.At(0, j)) > 1e-8 {
+			t.Errorf("G_d(e^{jwTs})[0,%v] = %v; want %v", j, GD.At(0, j), GC.At(0, j))
+		}
+	}
+
+	sysC, err := goControl.D2cWithOptions(sysD, options)
+	if err != nil {
+		t.Errorf("There was an error converting the model back to continuous time: %v", err)
+	}
+	checkModelsAreEqual(t, sysC, sys, 1e-8)
+}
+
+/*
+TestDiscretization_Matched1
+Description:
+
+	Discretizes 1/(s + 1) with the matched pole-zero method which should give (1 - e^{-Ts})/(z - e^{-Ts}),
+	and checks that D2c recovers the original DC gain and pole.
+*/
+func TestDiscretization_Matched1(t *testing.T) {
+	// Constants
+	Ts := 0.1
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(1, 1, []float64{-1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+
+	// Algorithm
+	sysD, err := goControl.C2d(sys, Ts, goControl.MatchedPoleZero)
+	if err != nil {
+		t.Errorf("There was an error discretizing the model: %v", err)
+	}
+
+	tfD, _ := goControl.Ss2tf(sysD)
+	zpkD, _ := goControl.Tf2zpk(tfD)
+	if cmplx.Abs(zpkD.Poles[0][0][0]-complex(math.Exp(-Ts), 0)) > 1e-10 {
+		t.Errorf("Discrete pole = %v; want %v", zpkD.Poles[0][0][0], math.Exp(-Ts))
+	}
+	if math.Abs(zpkD.Gain[0][0]-(1-math.Exp(-Ts))) > 1e-10 {
+		t.Errorf("Discrete gain = %v; want %v", zpkD.Gain[0][0], 1-math.Exp(-Ts))
+	}
+
+	sysC, err := goControl.D2c(sysD, goControl.MatchedPoleZero)
+	if err != nil {
+		t.Errorf("There was an error converting the model back to continuous time: %v", err)
+	}
+	poles, _ := sysC.Poles()
+	gain, _ := sysC.DCGain()
+	if cmplx.Abs(poles[0]+1) > 1e-10 {
+		t.Errorf("Continuous pole = %v; want -1", poles[0])
+	}
+	if math.Abs(gain.At(0, 0)-1) > 1e-10 {
+		t.Errorf("Continuous DC gain = %v; want 1", gain.At(0, 0))
+	}
+}
+
+/*
+TestDiscretization_InputDelay1
+Description:
+
+	Discretizes 1/(s + 1) with an input delay of 1.5 samples and verifies the step response of
+	the discrete model against the analytic response y(t) = 1 - e^{-(t - 1.5 Ts)} for t >= 1.5 Ts.
+*/
+func TestDiscretization_InputDelay1(t *testing.T) {
+	// Constants
+	Ts := 0.1
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(1, 1, []float64{-1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+	sys.InputDelay = []float64{1.5 * Ts}
+
+	// Algorithm
+	sysD, err := goControl.C2d(sys, Ts, goControl.ZeroOrderHold)
+	if err != nil {
+		t.Errorf("There was an error discretizing the model: %v", err)
+	}
+
+	n, _, _ := sysD.Dims()
+	if n != 3 {
+		t.Errorf("The discrete model has %v states; want 3", n)
+	}
+
+	x := mat.NewVecDense(n, nil)
+	u := mat.NewVecDense(1, []float64{1})
+	for k := 0; k < 20; k++ {
+		tk := float64(k) * Ts
+		expected := 0.0
+		if tk >= 1.5*Ts {
+			expected = 1 - math.Exp(-(tk - 1.5*Ts))
+		}
+
+		y := mat.NewVecDense(1, nil)
+		y.MulVec(sysD.C, x)
+		y.AddVec(y, mat.NewVecDense(1, []float64{sysD.D.At(0, 0) * u.AtVec(0)}))
+		if math.Abs(y.AtVec(0)-expected) > 1e-12 {
+			t.Errorf("y[%v] = %v; want %v", k, y.AtVec(0), expected)
+		}
+
+		xNext := mat.NewVecDense(n, nil)
+		xNext.MulVec(sysD.A, x)
+		Bu := mat.NewVecDense(n, nil)
+		Bu.MulVec(sysD.B, u)
+		xNext.AddVec(xNext, Bu)
+		x = xNext
+	}
+}
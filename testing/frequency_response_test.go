@@ -0,0 +1,166 @@
+/*
+   frequency_response_test.go
+   Description:
+	   Tests for the frequency response functions defined in frequency_response.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestFrequencyResponse_FreqResp1
+Description:
+
+	Compares the frequency response of a MIMO state space model (computed with the Hessenberg form) with the
+	evaluation of its transfer function.
+*/
+func TestFrequencyResponse_FreqResp1(t *testing.T) {
+	// Constants
+	sys := getTestSecondOrderSystem()
+	tf, _ := goControl.Ss2tf(sys)
+	w := []float64{0, 0.1, 1, 2, 10, 100}
+
+	// Algorithm
+	responses, err := goControl.FreqResp(sys, w)
+	if err != nil {
+		t.Errorf("There was an error computing the frequency response: %v", err)
+	}
+
+	for k, frequency := range w {
+		expected := tf.Evaluate(complex(0, frequency))
+		p, m := expected.Dims()
+		for i := 0; i < p; i++ {
+			for j := 0; j < m; j++ {
+				if cmplx.Abs(responses[k].At(i, j)-expected.At(i, j)) > 1e-10 {
+					t.Errorf("H(j %v)[%v,%v] = %v; want %v", frequency, i, j, responses[k].At(i, j), expected.At(i, j))
+				}
+			}
+		}
+	}
+}
+
+/*
+TestFrequencyResponse_FreqResp2
+Description:
+
+	Verifies the frequency response of the discrete-time model 1 / (z - 0.5) and of the continuous-time model
+	1 / (s + 1) with an input delay of 0.3 s.
+*/
+func TestFrequencyResponse_FreqResp2(t *testing.T) {
+	// Constants
+	Ts := 0.1
+	sysD, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(1, 1, []float64{0.5}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+		Ts,
+	)
+	sysDelay := getTestFirstOrderSystem()
+	sysDelay.InputDelay = []float64{0.3}
+	w := 2.0
+
+	// Algorithm
+	responses, err := goControl.FreqResp(sysD, []float64{w})
+	if err != nil {
+		t.Errorf("There was an error computing the frequency response: %v", err)
+	}
+	expected := 1 / (cmplx.Exp(complex(0, w*Ts)) - 0.5)
+	if cmplx.Abs(responses[0].At(0, 0)-expected) > 1e-12 {
+		t.Errorf("H = %v; want %v", responses[0].At(0, 0), expected)
+	}
+
+	responses, err = goControl.FreqResp(sysDelay, []float64{w})
+	if err != nil {
+		t.Errorf("There was an error computing the frequency response: %v", err)
+	}
+	expected = cmplx.Exp(complex(0, -0.3*w)) / complex(1, w)
+	if cmplx.Abs(responses[0].At(0, 0)-expected) > 1e-12 {
+		t.Errorf("H = %v; want %v", responses[0].At(0, 0), expected)
+	}
+}
+
+/*
+TestFrequencyResponse_Bode1
+Description:
+
+	Verifies that the Bode response of 1 / (s + 1) has a gain of 1/sqrt(2) and a phase of -45 degrees at 1 rad/s,
+	and that the automatic grid is increasing and contains the corner frequency.
+*/
+func TestFrequencyResponse_Bode1(t *testing.T) {
+	// Constants
+	sys := getTestFirstOrderSystem()
+
+	// Algorithm
+	response, err := goControl.Bode(sys, []float64{1})
+	if err != nil {
+		t.Errorf("There was an error computing the Bode response: %v", err)
+	}
+	if math.Abs(response.Magnitude[0][0][0]-1/math.Sqrt2) > 1e-12 {
+		t.Errorf("The magnitude is %v; want %v", response.Magnitude[0][0][0], 1/math.Sqrt2)
+	}
+	if math.Abs(response.Phase[0][0][0]+45) > 1e-10 {
+		t.Errorf("The phase is %v; want -45", response.Phase[0][0][0])
+	}
+
+	automatic, err := goControl.Bode(sys, nil)
+	if err != nil {
+		t.Errorf("There was an error computing the Bode response: %v", err)
+	}
+	w := automatic.Frequency
+	if (w[0] > 0.1) || (w[len(w)-1] < 10) {
+		t.Errorf("The automatic grid [%v, %v] does not cover [0.1, 10]", w[0], w[len(w)-1])
+	}
+	for k := 1; k < len(w); k++ {
+		if w[k] <= w[k-1] {
+			t.Errorf("The automatic grid is not increasing at index %v", k)
+		}
+	}
+}
+
+/*
+TestFrequencyResponse_Bode2
+Description:
+
+	Verifies that the phase of 1 / (s + 1)^3 is unwrapped (it decreases continuously to -270 degrees) and that the
+	Nyquist and Nichols data are consistent with the Bode data.
+*/
+func TestFrequencyResponse_Bode2(t *testing.T) {
+	// Constants
+	tf, _ := goControl.GetTransferFunction([]float64{1}, []float64{1, 3, 3, 1})
+	sys, _ := goControl.Tf2ss(tf)
+
+	// Algorithm
+	bode, err := goControl.Bode(sys, nil)
+	if err != nil {
+		t.Errorf("There was an error computing the Bode response: %v", err)
+	}
+	nyquist, _ := goControl.Nyquist(sys, bode.Frequency)
+	nichols, _ := goControl.Nichols(sys, bode.Frequency)
+
+	phase := bode.Phase[0][0]
+	last := len(phase) - 1
+	if math.Abs(phase[last]+270) > 5 {
+		t.Errorf("The final phase is %v; want approximately -270", phase[last])
+	}
+	for k := range bode.Frequency {
+		if (k > 0) && (phase[k] > phase[k-1]+1e-9) {
+			t.Errorf("The phase increases at index %v", k)
+		}
+		H := complex(nyquist.Real[0][0][k], nyquist.Imag[0][0][k])
+		if math.Abs(cmplx.Abs(H)-bode.Magnitude[0][0][k]) > 1e-12 {
+			t.Errorf("The Nyquist and Bode data differ at index %v", k)
+		}
+		if math.Abs(nichols.MagnitudeDB[0][0][k]-20*math.Log10(bode.Magnitude[0][0][k])) > 1e-9 {
+			t.Errorf("The Nichols and Bode data differ at index %v", k)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+   gramians_test.go
+   Description:
+	   Tests for the Gramian functions defined in gramians.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestGramians_ControllabilityGramian1
+Description:
+
+	Computes the controllability Gramian of 1/(s + 1), which is 1/2, and the Gramian of the discrete-time model
+	x+ = 0.5 x + u, which is 1/(1 - 0.25) = 4/3.
+*/
+func TestGramians_ControllabilityGramian1(t *testing.T) {
+	// Constants
+	sysC := getTestFirstOrderSystem()
+	sysD, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(1, 1, []float64{0.5}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+		0.1,
+	)
+
+	// Algorithm
+	Wc, err := goControl.ControllabilityGramian(sysC)
+	if err != nil {
+		t.Errorf("There was an error computing the Gramian: %v", err)
+	}
+	if math.Abs(Wc.At(0, 0)-0.5) > 1e-12 {
+		t.Errorf("Wc = %v; want 0.5", Wc.At(0, 0))
+	}
+
+	Wd, err := goControl.ControllabilityGramian(sysD)
+	if err != nil {
+		t.Errorf("There was an error computing the Gramian: %v", err)
+	}
+	if math.Abs(Wd.At(0, 0)-4.0/3.0) > 1e-12 {
+		t.Errorf("Wd = %v; want 4/3", Wd.At(0, 0))
+	}
+}
+
+/*
+TestGramians_ObservabilityGramian1
+Description:
+
+	Verifies that the observability Gramian of a second order model satisfies its Lyapunov equation.
+*/
+func TestGramians_ObservabilityGramian1(t *testing.T) {
+	// Constants
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(2, 2, []float64{0, 1, -2, -3}),
+		mat.NewDense(2, 1, []float64{0, 1}),
+		mat.NewDense(1, 2, []float64{1, 0.5}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+
+	// Algorithm
+	Wo, err := goControl.ObservabilityGramian(sys)
+	if err != nil {
+		t.Errorf("There was an error computing the Gramian: %v", err)
+	}
+
+	var AtW, WA, CtC, residual mat.Dense
+	AtW.Mul(sys.A.T(), Wo)
+	WA.Mul(Wo, sys.A)
+	CtC.Mul(sys.C.T(), sys.C)
+	residual.Add(&AtW, &WA)
+	residual.Add(&residual, &CtC)
+	if mat.Norm(&residual, 1) > 1e-10 {
+		t.Errorf("The Lyapunov equation residual is %v; want 0", mat.Formatted(&residual))
+	}
+}
+
+/*
+TestGramians_ControllabilityGramian2
+Description:
+
+	Verifies that the Gramian of an unstable model is rejected.
+*/
+func TestGramians_ControllabilityGramian2(t *testing.T) {
+	// Constants
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+
+	// Algorithm
+	_, err := goControl.ControllabilityGramian(sys)
+	if err == nil {
+		t.Errorf("Expected an error for an unstable model; received nil")
+	}
+}
@@ -0,0 +1,221 @@
+/*
+   invariant_sets_test.go
+   Description:
+	   Tests for the invariant set computations defined in invariant_sets.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestBoxPolyhedron
+Description:
+
+	Creates the Polyhedron {x : |x_i| <= bounds[i]}.
+*/
+func getTestBoxPolyhedron(bounds ...float64) goControl.Polyhedron {
+	n := len(bounds)
+	A := mat.NewDense(2*n, n, nil)
+	b := mat.NewVecDense(2*n, nil)
+	for i, bound := range bounds {
+		A.Set(2*i, i, 1)
+		A.Set(2*i+1, i, -1)
+		b.SetVec(2*i, bound)
+		b.SetVec(2*i+1, bound)
+	}
+	return goControl.GetPolyhedron(A, b)
+}
+
+/*
+checkPositiveInvariance
+Description:
+
+	Verifies that A S is contained in lambda S for S = {x : H x <= h}, i.e. that the support function of S in the
+	direction A^T h_i is at most lambda h_i for each row h_i of H.
+*/
+func checkPositiveInvariance(t *testing.T, A mat.Matrix, S goControl.Polyhedron, lambda float64) {
+	rows, n := S.A.Dims()
+	for i := 0; i < rows; i++ {
+		var direction mat.VecDense
+		direction.MulVec(A.T(), mat.NewVecDense(n, mat.Row(nil, i, S.A)))
+		support, err := S.Support(&direction)
+		if err != nil {
+			t.Errorf("There was an error computing the support function: %v", err)
+		}
+		if bound := lambda * S.Get_b().AtVec(i); support > bound+1e-8 {
+			t.Errorf("The set is not invariant: the support in direction %v is %v > %v.", i, support, bound)
+		}
+	}
+}
+
+/*
+TestInvariantSets_MaxPositiveInvariant1
+Description:
+
+	Verifies that the maximal positively invariant set of x+ = 0.5 x inside a box is the box itself.
+*/
+func TestInvariantSets_MaxPositiveInvariant1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{0.5, 0, 0, 0.5})
+	X := getTestBoxPolyhedron(1, 2)
+
+	// Algorithm
+	O, err := goControl.MaxPositiveInvariant(A, X)
+	if err != nil {
+		t.Errorf("There was an error computing the invariant set: %v", err)
+	}
+
+	if !O.Contains(X) || !X.Contains(O) {
+		t.Errorf("The invariant set is not the box.")
+	}
+}
+
+/*
+TestInvariantSets_MaxPositiveInvariant2
+Description:
+
+	Computes the maximal positively invariant set of a stable oscillating system inside a box and verifies that it is
+	invariant, contained in the box, strictly smaller than the box, and that its lambda-contractive version is
+	contained in it and contractive.
+*/
+func TestInvariantSets_MaxPositiveInvariant2(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{0.8, 0.5, -0.4, 0.9})
+	X := getTestBoxPolyhedron(1, 1)
+
+	// Algorithm
+	O, err := goControl.MaxPositiveInvariant(A, X)
+	if err != nil {
+		t.Fatalf("There was an error computing the invariant set: %v", err)
+	}
+	checkPositiveInvariance(t, A, O, 1)
+	if !X.Contains(O) || O.Contains(X) {
+		t.Errorf("The invariant set should be a strict subset of X.")
+	}
+
+	contractive, err := goControl.MaxPositiveInvariantWithOptions(A, X, goControl.InvariantSetOptions{Lambda: 0.98})
+	if err != nil {
+		t.Fatalf("There was an error computing the contractive set: %v", err)
+	}
+	checkPositiveInvariance(t, A, contractive, 0.98)
+	if !O.Contains(contractive) {
+		t.Errorf("The contractive set is not contained in the invariant set.")
+	}
+}
+
+/*
+TestInvariantSets_MaxPositiveInvariant3
+Description:
+
+	Verifies that the iteration limit is reported for the unstable system x+ = 2 x, whose maximal positively
+	invariant set {0} is not finitely determined.
+*/
+func TestInvariantSets_MaxPositiveInvariant3(t *testing.T) {
+	// Constants
+	A := mat.NewDense(1, 1, []float64{2})
+	X := getTestBoxPolyhedron(1)
+
+	// Algorithm
+	_, err := goControl.MaxPositiveInvariantWithOptions(A, X, goControl.InvariantSetOptions{MaxIterations: 20})
+	if err == nil {
+		t.Errorf("Expected an error for a set that does not converge.")
+	}
+}
+
+/*
+TestInvariantSets_MaxControlInvariant1
+Description:
+
+	Computes the maximal control invariant set of a double integrator with |x_1| <= 5, |x_2| <= 2 and |u| <= 1,
+	and verifies that every point of a grid inside it has an admissible input that keeps the next state inside it.
+*/
+func TestInvariantSets_MaxControlInvariant1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{1, 1, 0, 1})
+	B := mat.NewDense(2, 1, []float64{0.5, 1})
+	X := getTestBoxPolyhedron(5, 2)
+	U := getTestBoxPolyhedron(1)
+
+	// Algorithm
+	C, err := goControl.MaxControlInvariant(A, B, X, U)
+	if err != nil {
+		t.Fatalf("There was an error computing the control invariant set: %v", err)
+	}
+	if !X.Contains(C) || C.Contains(X) {
+		t.Errorf("The control invariant set should be a strict subset of X.")
+	}
+
+	rows, _ := C.A.Dims()
+	for x1 := -5.0; x1 <= 5; x1 += 0.5 {
+		for x2 := -2.0; x2 <= 2; x2 += 0.25 {
+			x := mat.NewVecDense(2, []float64{x1, x2})
+			if !C.Contains(x) {
+				continue
+			}
+
+			// Find u with H (A x + B u) <= h and |u| <= 1
+			var Ax, HAx, HB mat.VecDense
+			Ax.MulVec(A, x)
+			HAx.MulVec(C.A, &Ax)
+			HB.MulVec(C.A, B.ColView(0))
+			G := mat.NewDense(rows+2, 1, nil)
+			w := mat.NewVecDense(rows+2, nil)
+			for i := 0; i < rows; i++ {
+				G.Set(i, 0, HB.AtVec(i))
+				w.SetVec(i, C.Get_b().AtVec(i)-HAx.AtVec(i)+1e-9)
+			}
+			G.Set(rows, 0, 1)
+			G.Set(rows+1, 0, -1)
+			w.SetVec(rows, 1)
+			w.SetVec(rows+1, 1)
+
+			if _, _, err := goControl.Linprog(mat.NewVecDense(1, nil), G, w, nil, nil); err != nil {
+				t.Errorf("No admissible input keeps x = (%v, %v) in the set: %v", x1, x2, err)
+			}
+		}
+	}
+
+	// The origin is an equilibrium, so the set contains it, and the set is bounded and full-dimensional
+	if !C.Contains(mat.NewVecDense(2, nil)) {
+		t.Errorf("The control invariant set does not contain the origin.")
+	}
+	_, radius, _ := C.ChebyshevBall()
+	if !(radius > 1) || math.IsInf(radius, 1) {
+		t.Errorf("The Chebyshev radius of the set is %v.", radius)
+	}
+}
+
+/*
+TestInvariantSets_MaxControlInvariant2
+Description:
+
+	Verifies that the lambda-contractive control invariant set is contained in the maximal control invariant set.
+*/
+func TestInvariantSets_MaxControlInvariant2(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{1, 1, 0, 1})
+	B := mat.NewDense(2, 1, []float64{0.5, 1})
+	X := getTestBoxPolyhedron(5, 2)
+	U := getTestBoxPolyhedron(1)
+
+	// Algorithm
+	C, err := goControl.MaxControlInvariant(A, B, X, U)
+	if err != nil {
+		t.Fatalf("There was an error computing the control invariant set: %v", err)
+	}
+	contractive, err := goControl.MaxControlInvariantWithOptions(A, B, X, U, goControl.InvariantSetOptions{Lambda: 0.9})
+	if err != nil {
+		t.Fatalf("There was an error computing the contractive set: %v", err)
+	}
+
+	if !C.Contains(contractive) || contractive.Contains(C) {
+		t.Errorf("The contractive set should be a strict subset of the control invariant set.")
+	}
+}
@@ -0,0 +1,159 @@
+/*
+   kalman_filter_test.go
+   Description:
+	   Tests for the Kalman filters defined in kalman_filter.go.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestFilterModel
+Description:
+
+	Returns a discrete-time model of a damped oscillator with one input and one output, which is used to
+	compare the different filters.
+*/
+func getTestFilterModel() goControl.StateSpace {
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(2, 2, []float64{0.9, 0.2, -0.2, 0.9}),
+		mat.NewDense(2, 1, []float64{0, 0.1}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, []float64{0}),
+		0.1,
+	)
+	return sys
+}
+
+/*
+TestKalmanFilter_KalmanFilter1
+Description:
+
+	Runs the Kalman filter until its covariance converges and compares the a priori covariance with the
+	steady-state covariance computed by Dkalman.
+*/
+func TestKalmanFilter_KalmanFilter1(t *testing.T) {
+	// Constants
+	sys := getTestFilterModel()
+	Q := mat.NewDense(2, 2, []float64{0.1, 0, 0, 0.2})
+	R := mat.NewDense(1, 1, []float64{0.5})
+
+	kf, err := goControl.GetKalmanFilter(sys, Q, R, mat.NewVecDense(2, nil), eye(2))
+	if err != nil {
+		t.Errorf("There was an error creating the filter: %v", err)
+	}
+
+	// Algorithm
+	for k := 0; k < 500; k++ {
+		if err := kf.Update(mat.NewVecDense(1, []float64{1}), nil); err != nil {
+			t.Errorf("There was an error in the update step: %v", err)
+		}
+		if err := kf.Predict(mat.NewVecDense(1, []float64{0.5})); err != nil {
+			t.Errorf("There was an error in the prediction step: %v", err)
+		}
+	}
+
+	estimator, err := goControl.Dkalman(sys.A, nil, sys.C, Q, R, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the filter: %v", err)
+	}
+	if !mat.EqualApprox(kf.P, estimator.P, 1e-8) {
+		t.Errorf("P = %v; want %v", mat.Formatted(kf.P), mat.Formatted(estimator.P))
+	}
+}
+
+/*
+TestKalmanFilter_ExtendedKalmanFilter1
+Description:
+
+	Verifies that the extended and unscented Kalman filters reproduce the linear Kalman filter when the model
+	is linear.
+*/
+func TestKalmanFilter_ExtendedKalmanFilter1(t *testing.T) {
+	// Constants
+	sys := getTestFilterModel()
+	Q := mat.NewDense(2, 2, []float64{0.1, 0, 0, 0.2})
+	R := mat.NewDense(1, 1, []float64{0.5})
+	x0 := mat.NewVecDense(2, []float64{1, -1})
+
+	F := func(x, u mat.Vector) *mat.VecDense {
+		var Ax, Bu mat.VecDense
+		Ax.MulVec(sys.A, x)
+		Bu.MulVec(sys.B, u)
+		Ax.AddVec(&Ax, &Bu)
+		return &Ax
+	}
+	H := func(x mat.Vector) *mat.VecDense {
+		var Cx mat.VecDense
+		Cx.MulVec(sys.C, x)
+		return &Cx
+	}
+	FJ := func(x, u mat.Vector) *mat.Dense { return mat.DenseCopyOf(sys.A) }
+	HJ := func(x mat.Vector) *mat.Dense { return mat.DenseCopyOf(sys.C) }
+
+	kf, _ := goControl.GetKalmanFilter(sys, Q, R, x0, eye(2))
+	ekf, err := goControl.GetExtendedKalmanFilter(F, H, FJ, HJ, Q, R, x0, eye(2))
+	if err != nil {
+		t.Errorf("There was an error creating the extended Kalman filter: %v", err)
+	}
+	ukf, err := goControl.GetUnscentedKalmanFilter(F, H, Q, R, x0, eye(2))
+	if err != nil {
+		t.Errorf("There was an error creating the unscented Kalman filter: %v", err)
+	}
+
+	// Algorithm
+	measurements := []float64{1, 0.8, 0.3, -0.1, -0.4, -0.2, 0.1}
+	u := mat.NewVecDense(1, []float64{0.5})
+	for _, measurement := range measurements {
+		y := mat.NewVecDense(1, []float64{measurement})
+
+		if err := kf.Update(y, nil); err != nil {
+			t.Errorf("There was an error in the Kalman filter update: %v", err)
+		}
+		if err := ekf.Update(y); err != nil {
+			t.Errorf("There was an error in the extended Kalman filter update: %v", err)
+		}
+		if err := ukf.Update(y); err != nil {
+			t.Errorf("There was an error in the unscented Kalman filter update: %v", err)
+		}
+
+		kf.Predict(u)
+		ekf.Predict(u)
+		ukf.Predict(u)
+	}
+
+	if !mat.EqualApprox(kf.X, ekf.X, 1e-10) || !mat.EqualApprox(kf.P, ekf.P, 1e-10) {
+		t.Errorf("The extended Kalman filter estimate %v differs from %v", mat.Formatted(ekf.X.T()), mat.Formatted(kf.X.T()))
+	}
+	if !mat.EqualApprox(kf.X, ukf.X, 1e-6) || !mat.EqualApprox(kf.P, ukf.P, 1e-6) {
+		t.Errorf("The unscented Kalman filter estimate %v differs from %v", mat.Formatted(ukf.X.T()), mat.Formatted(kf.X.T()))
+	}
+}
+
+/*
+TestKalmanFilter_KalmanFilter2
+Description:
+
+	Verifies that continuous-time models and measurements of the wrong size are rejected.
+*/
+func TestKalmanFilter_KalmanFilter2(t *testing.T) {
+	// Constants
+	Q := eye(2)
+	R := mat.NewDense(1, 1, []float64{1})
+
+	// Algorithm
+	if _, err := goControl.GetKalmanFilter(getTestSecondOrderSystem(), Q, R, mat.NewVecDense(2, nil), eye(2)); err == nil {
+		t.Errorf("Expected an error for a continuous-time model; received nil")
+	}
+
+	kf, _ := goControl.GetKalmanFilter(getTestFilterModel(), Q, R, mat.NewVecDense(2, nil), eye(2))
+	if err := kf.Update(mat.NewVecDense(2, nil), nil); err == nil {
+		t.Errorf("Expected an error for a measurement of the wrong size; received nil")
+	}
+}
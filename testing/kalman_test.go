@@ -0,0 +1,97 @@
+/*
+   kalman_test.go
+   Description:
+	   Tests for the steady-state Kalman estimator design functions defined in kalman.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestKalman_Kalman1
+Description:
+
+	Designs the Kalman estimator of dx/dt = -x + w, y = x + v with unit noise covariances. The error covariance
+	solves -2 P - P^2 + 1 = 0, so P = L = sqrt(2) - 1 and the estimator pole is -sqrt(2).
+*/
+func TestKalman_Kalman1(t *testing.T) {
+	// Constants
+	sys := getTestFirstOrderSystem()
+	one := mat.NewDense(1, 1, []float64{1})
+
+	// Algorithm
+	estimator, err := goControl.Kalman(sys, nil, one, one, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the estimator: %v", err)
+	}
+
+	if math.Abs(estimator.P.At(0, 0)-(math.Sqrt2-1)) > 1e-10 {
+		t.Errorf("P = %v; want %v", estimator.P.At(0, 0), math.Sqrt2-1)
+	}
+	if math.Abs(estimator.L.At(0, 0)-(math.Sqrt2-1)) > 1e-10 {
+		t.Errorf("L = %v; want %v", estimator.L.At(0, 0), math.Sqrt2-1)
+	}
+	if math.Abs(real(estimator.Poles[0])+math.Sqrt2) > 1e-10 {
+		t.Errorf("The estimator pole is %v; want %v", estimator.Poles[0], -math.Sqrt2)
+	}
+
+	// The estimator has inputs [u; y] and outputs [y_hat; x_hat]
+	n, m, p := estimator.Estimator.Dims()
+	if (n != 1) || (m != 2) || (p != 2) {
+		t.Errorf("The estimator has dimensions (%v, %v, %v); want (1, 2, 2)", n, m, p)
+	}
+}
+
+/*
+TestKalman_Dkalman1
+Description:
+
+	Designs the Kalman filter of the random walk x+ = x + w, y = x + v with unit noise covariances. The a priori
+	covariance solves P^2 - P - 1 = 0, so P is the golden ratio phi and L = M = 1 / phi.
+*/
+func TestKalman_Dkalman1(t *testing.T) {
+	// Constants
+	one := mat.NewDense(1, 1, []float64{1})
+	phi := (1 + math.Sqrt(5)) / 2
+
+	// Algorithm
+	estimator, err := goControl.Dkalman(one, nil, one, one, one, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the filter: %v", err)
+	}
+
+	if math.Abs(estimator.P.At(0, 0)-phi) > 1e-10 {
+		t.Errorf("P = %v; want %v", estimator.P.At(0, 0), phi)
+	}
+	if math.Abs(estimator.L.At(0, 0)-1/phi) > 1e-10 {
+		t.Errorf("L = %v; want %v", estimator.L.At(0, 0), 1/phi)
+	}
+	if math.Abs(estimator.M.At(0, 0)-1/phi) > 1e-10 {
+		t.Errorf("M = %v; want %v", estimator.M.At(0, 0), 1/phi)
+	}
+}
+
+/*
+TestKalman_Dkalman2
+Description:
+
+	Verifies that a model whose unstable mode is not observable is rejected.
+*/
+func TestKalman_Dkalman2(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{2, 0, 0, 0.5})
+	C := mat.NewDense(1, 2, []float64{0, 1})
+
+	// Algorithm
+	_, err := goControl.Dkalman(A, nil, C, eye(2), mat.NewDense(1, 1, []float64{1}), nil)
+	if err == nil {
+		t.Errorf("Expected an error for a model that is not detectable; received nil")
+	}
+}
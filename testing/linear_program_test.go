@@ -0,0 +1,93 @@
+/*
+   linear_program_test.go
+   Description:
+	   Tests for the linear programming function defined in linear_program.go.
+*/
+
+package testing
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestLinearProgram_Linprog1
+Description:
+
+	Solves minimize -x1 - 2 x2 subject to x1 + x2 <= 4, x1 + 3 x2 <= 6, x1 >= 0, x2 >= 0,
+	whose solution is (3, 1) with value -5.
+*/
+func TestLinearProgram_Linprog1(t *testing.T) {
+	// Constants
+	c := mat.NewVecDense(2, []float64{-1, -2})
+	A := mat.NewDense(4, 2, []float64{
+		1, 1,
+		1, 3,
+		-1, 0,
+		0, -1,
+	})
+	b := mat.NewVecDense(4, []float64{4, 6, 0, 0})
+
+	// Algorithm
+	x, value, err := goControl.Linprog(c, A, b, nil, nil)
+	if err != nil {
+		t.Errorf("There was an error solving the linear program: %v", err)
+	}
+
+	if (math.Abs(x.AtVec(0)-3) > 1e-9) || (math.Abs(x.AtVec(1)-1) > 1e-9) || (math.Abs(value+5) > 1e-9) {
+		t.Errorf("The solution is %v with value %v; want (3, 1) with value -5", mat.Formatted(x.T()), value)
+	}
+}
+
+/*
+TestLinearProgram_Linprog2
+Description:
+
+	Solves a linear program with an equality constraint and free variables:
+	minimize x1 + x2 subject to x1 - x2 = 1, x1 >= -2 and x2 >= -3, whose solution is (-2, -3).
+*/
+func TestLinearProgram_Linprog2(t *testing.T) {
+	// Constants
+	c := mat.NewVecDense(2, []float64{1, 1})
+	A := mat.NewDense(2, 2, []float64{-1, 0, 0, -1})
+	b := mat.NewVecDense(2, []float64{2, 3})
+	Aeq := mat.NewDense(1, 2, []float64{1, -1})
+	beq := mat.NewVecDense(1, []float64{1})
+
+	// Algorithm
+	x, value, err := goControl.Linprog(c, A, b, Aeq, beq)
+	if err != nil {
+		t.Errorf("There was an error solving the linear program: %v", err)
+	}
+
+	if (math.Abs(x.AtVec(0)+2) > 1e-9) || (math.Abs(x.AtVec(1)+3) > 1e-9) || (math.Abs(value+5) > 1e-9) {
+		t.Errorf("The solution is %v with value %v; want (-2, -3) with value -5", mat.Formatted(x.T()), value)
+	}
+}
+
+/*
+TestLinearProgram_Linprog3
+Description:
+
+	Verifies that infeasible and unbounded linear programs are reported with ErrInfeasible and ErrUnbounded.
+*/
+func TestLinearProgram_Linprog3(t *testing.T) {
+	// Constants
+	c := mat.NewVecDense(1, []float64{1})
+
+	// Algorithm
+	_, _, err := goControl.Linprog(c, mat.NewDense(2, 1, []float64{1, -1}), mat.NewVecDense(2, []float64{-1, -1}), nil, nil)
+	if !errors.Is(err, goControl.ErrInfeasible) {
+		t.Errorf("The error for an infeasible problem is %v; want ErrInfeasible", err)
+	}
+
+	_, _, err = goControl.Linprog(c, mat.NewDense(1, 1, []float64{1}), mat.NewVecDense(1, []float64{1}), nil, nil)
+	if !errors.Is(err, goControl.ErrUnbounded) {
+		t.Errorf("The error for an unbounded problem is %v; want ErrUnbounded", err)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+   lqr_test.go
+   Description:
+	   Tests for the LQR design functions defined in lqr.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestLqr_Lqr1
+Description:
+
+	Designs the LQR gain of a double integrator with Q = I and R = 1, which is K = [1, sqrt(3)].
+*/
+func TestLqr_Lqr1(t *testing.T) {
+	// Constants
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(2, 2, []float64{0, 1, 0, 0}),
+		mat.NewDense(2, 1, []float64{0, 1}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+
+	// Algorithm
+	K, S, P, err := goControl.Lqr(sys, eye(2), mat.NewDense(1, 1, []float64{1}), nil)
+	if err != nil {
+		t.Errorf("There was an error designing the LQR gain: %v", err)
+	}
+
+	if !mat.EqualApprox(K, mat.NewDense(1, 2, []float64{1, math.Sqrt(3)}), 1e-10) {
+		t.Errorf("K = %v; want [1, sqrt(3)]", mat.Formatted(K))
+	}
+	if r, c := S.Dims(); (r != 2) || (c != 2) {
+		t.Errorf("S has dimensions %v x %v; want 2 x 2", r, c)
+	}
+	for _, pole := range P {
+		if real(pole) >= 0 {
+			t.Errorf("The closed-loop pole %v is not stable", pole)
+		}
+	}
+}
+
+/*
+TestLqr_Dlqr1
+Description:
+
+	Verifies that Dlqr and Lqr (applied to a discrete-time model) give the same gain, and that the gain of
+	the scalar problem x[k+1] = 2 x[k] + u[k] with Q = R = 1 is 2 X / (1 + X) with X = 2 + sqrt(5).
+*/
+func TestLqr_Dlqr1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(1, 1, []float64{2})
+	B := mat.NewDense(1, 1, []float64{1})
+	sys, _ := goControl.GetDiscreteStateSpace(A, B, mat.NewDense(1, 1, []float64{1}), mat.NewDense(1, 1, []float64{0}), 0.1)
+	one := mat.NewDense(1, 1, []float64{1})
+	X := 2 + math.Sqrt(5)
+
+	// Algorithm
+	K, S, _, err := goControl.Dlqr(A, B, one, one, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the LQR gain: %v", err)
+	}
+	if math.Abs(K.At(0, 0)-2*X/(1+X)) > 1e-10 {
+		t.Errorf("K = %v; want %v", K.At(0, 0), 2*X/(1+X))
+	}
+	if math.Abs(S.At(0, 0)-X) > 1e-10 {
+		t.Errorf("S = %v; want %v", S.At(0, 0), X)
+	}
+
+	K2, _, _, err := goControl.Lqr(sys, one, one, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the LQR gain: %v", err)
+	}
+	if !mat.EqualApprox(K, K2, 1e-12) {
+		t.Errorf("Dlqr gave %v but Lqr gave %v", mat.Formatted(K), mat.Formatted(K2))
+	}
+}
+
+/*
+TestLqr_Lqi1
+Description:
+
+	Designs an LQR gain with integral action for 1/(s + 1) and compares it to the LQR gain of the augmented model
+	[x; xi]' = [-1, 0; -1, 0] [x; xi] + [1; 0] u.
+*/
+func TestLqr_Lqi1(t *testing.T) {
+	// Constants
+	sys := getTestFirstOrderSystem()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 10})
+	R := mat.NewDense(1, 1, []float64{1})
+
+	augmented, _ := goControl.GetStateSpace(
+		mat.NewDense(2, 2, []float64{-1, 0, -1, 0}),
+		mat.NewDense(2, 1, []float64{1, 0}),
+		eye(2),
+		mat.NewDense(2, 1, nil),
+	)
+
+	// Algorithm
+	K, _, P, err := goControl.Lqi(sys, Q, R, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the LQI gain: %v", err)
+	}
+
+	KExpected, _, _, err := goControl.Lqr(augmented, Q, R, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the LQR gain: %v", err)
+	}
+
+	if !mat.EqualApprox(K, KExpected, 1e-10) {
+		t.Errorf("K = %v; want %v", mat.Formatted(K), mat.Formatted(KExpected))
+	}
+	if len(P) != 2 {
+		t.Errorf("Expected 2 closed-loop poles; received %v", len(P))
+	}
+}
+
+/*
+TestLqr_Lqi2
+Description:
+
+	Verifies that the integrator of a discrete-time model is scaled by the sample time, by comparing against
+	the LQR gain of the augmented model built by hand.
+*/
+func TestLqr_Lqi2(t *testing.T) {
+	// Constants
+	Ts := 0.5
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(1, 1, []float64{0.9}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{2}),
+		mat.NewDense(1, 1, []float64{0}),
+		Ts,
+	)
+	augmentedA := mat.NewDense(2, 2, []float64{0.9, 0, -2 * Ts, 1})
+	augmentedB := mat.NewDense(2, 1, []float64{1, 0})
+	Q := eye(2)
+	R := mat.NewDense(1, 1, []float64{1})
+
+	// Algorithm
+	K, _, P, err := goControl.Lqi(sys, Q, R, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the LQI gain: %v", err)
+	}
+
+	KExpected, _, _, err := goControl.Dlqr(augmentedA, augmentedB, Q, R, nil)
+	if err != nil {
+		t.Errorf("There was an error designing the LQR gain: %v", err)
+	}
+
+	if !mat.EqualApprox(K, KExpected, 1e-10) {
+		t.Errorf("K = %v; want %v", mat.Formatted(K), mat.Formatted(KExpected))
+	}
+	for _, pole := range P {
+		if real(pole)*real(pole)+imag(pole)*imag(pole) >= 1 {
+			t.Errorf("The closed-loop pole %v is not stable", pole)
+		}
+	}
+}
+
+/*
+eye
+Description:
+
+	Returns the n x n identity matrix.
+*/
+func eye(n int) *mat.Dense {
+	I := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		I.Set(i, i, 1)
+	}
+	return I
+}
@@ -0,0 +1,144 @@
+/*
+   lyapunov_test.go
+   Description:
+	   Tests for the Sylvester and Lyapunov equation solvers defined in lyapunov.go.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestLyapunov_Sylvester1
+Description:
+
+	Solves a Sylvester equation with a 3 x 3 matrix A (with complex eigenvalues) and a 2 x 2 matrix B,
+	and checks the residual A X + X B - C.
+*/
+func TestLyapunov_Sylvester1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(3, 3, []float64{
+		1, 2, 0,
+		-3, 1, 1,
+		0, 0.5, 2,
+	})
+	B := mat.NewDense(2, 2, []float64{4, 1, 0, 3})
+	C := mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+
+	// Algorithm
+	X, err := goControl.Sylvester(A, B, C)
+	if err != nil {
+		t.Errorf("There was an error solving the Sylvester equation: %v", err)
+	}
+
+	var AX, XB, residual mat.Dense
+	AX.Mul(A, X)
+	XB.Mul(X, B)
+	residual.Add(&AX, &XB)
+	residual.Sub(&residual, C)
+	if mat.Norm(&residual, 1) > 1e-10 {
+		t.Errorf("The residual is %v; want 0", mat.Formatted(&residual))
+	}
+}
+
+/*
+TestLyapunov_Sylvester2
+Description:
+
+	Verifies that a singular Sylvester equation (A and -B share the eigenvalue 1) is rejected.
+*/
+func TestLyapunov_Sylvester2(t *testing.T) {
+	// Constants
+	A := mat.NewDense(1, 1, []float64{1})
+	B := mat.NewDense(1, 1, []float64{-1})
+	C := mat.NewDense(1, 1, []float64{1})
+
+	// Algorithm
+	_, err := goControl.Sylvester(A, B, C)
+	if err == nil {
+		t.Errorf("Expected an error for a singular Sylvester equation; received nil")
+	}
+}
+
+/*
+TestLyapunov_Lyap1
+Description:
+
+	Solves the continuous-time Lyapunov equation for a stable matrix with complex eigenvalues and checks that
+	the solution is symmetric and satisfies the equation.
+*/
+func TestLyapunov_Lyap1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(4, 4, []float64{
+		-1, 2, 0, 0,
+		-2, -1, 0.3, 0,
+		0, 0, -0.5, 1,
+		0.1, 0, -4, -0.5,
+	})
+	Q := mat.NewDense(4, 4, []float64{
+		2, 1, 0, 0,
+		1, 2, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	})
+
+	// Algorithm
+	X, err := goControl.Lyap(A, Q)
+	if err != nil {
+		t.Errorf("There was an error solving the Lyapunov equation: %v", err)
+	}
+
+	if !mat.Equal(X, X.T()) {
+		t.Errorf("The solution is not symmetric: %v", mat.Formatted(X))
+	}
+
+	var AX, XAt, residual mat.Dense
+	AX.Mul(A, X)
+	XAt.Mul(X, A.T())
+	residual.Add(&AX, &XAt)
+	residual.Add(&residual, Q)
+	if mat.Norm(&residual, 1) > 1e-10 {
+		t.Errorf("The residual is %v; want 0", mat.Formatted(&residual))
+	}
+}
+
+/*
+TestLyapunov_Dlyap1
+Description:
+
+	Solves the discrete-time Lyapunov equation for a Schur stable matrix with complex eigenvalues and
+	checks the residual A X A^T - X + Q.
+*/
+func TestLyapunov_Dlyap1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(3, 3, []float64{
+		0.5, 0.4, 0,
+		-0.4, 0.5, 0.1,
+		0.2, 0, -0.3,
+	})
+	Q := mat.NewDense(3, 3, []float64{
+		1, 0, 0.5,
+		0, 2, 0,
+		0.5, 0, 1,
+	})
+
+	// Algorithm
+	X, err := goControl.Dlyap(A, Q)
+	if err != nil {
+		t.Errorf("There was an error solving the Lyapunov equation: %v", err)
+	}
+
+	var AX, AXAt, residual mat.Dense
+	AX.Mul(A, X)
+	AXAt.Mul(&AX, A.T())
+	residual.Sub(&AXAt, X)
+	residual.Add(&residual, Q)
+	if mat.Norm(&residual, 1) > 1e-10 {
+		t.Errorf("The residual is %v; want 0", mat.Formatted(&residual))
+	}
+}
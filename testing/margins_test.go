@@ -0,0 +1,110 @@
+/*
+   margins_test.go
+   Description:
+	   Tests for the stability margin functions defined in margins.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+)
+
+/*
+TestMargins_Margin1
+Description:
+
+	Computes the margins of L(s) = 4 / (s + 1)^3. The phase crosses -180 degrees at w = sqrt(3), where the gain is
+	1/2 (so the gain margin is 2). The gain crosses 1 at w = sqrt(4^(2/3) - 1), where the phase margin is
+	180 - 3 atan(w) degrees.
+*/
+func TestMargins_Margin1(t *testing.T) {
+	// Constants
+	tf, _ := goControl.GetTransferFunction([]float64{4}, []float64{1, 3, 3, 1})
+	sys, _ := goControl.Tf2ss(tf)
+
+	wcp := math.Sqrt(math.Pow(4, 2.0/3.0) - 1)
+	pm := 180 - 3*math.Atan(wcp)*180/math.Pi
+
+	// Algorithm
+	margins, err := goControl.Margin(sys)
+	if err != nil {
+		t.Errorf("There was an error computing the margins: %v", err)
+	}
+
+	if math.Abs(margins.GainMargin-2) > 1e-8 {
+		t.Errorf("The gain margin is %v; want 2", margins.GainMargin)
+	}
+	if math.Abs(margins.PhaseCrossoverFrequency-math.Sqrt(3)) > 1e-8 {
+		t.Errorf("The phase crossover frequency is %v; want %v", margins.PhaseCrossoverFrequency, math.Sqrt(3))
+	}
+	if math.Abs(margins.PhaseMargin-pm) > 1e-6 {
+		t.Errorf("The phase margin is %v; want %v", margins.PhaseMargin, pm)
+	}
+	if math.Abs(margins.GainCrossoverFrequency-wcp) > 1e-8 {
+		t.Errorf("The gain crossover frequency is %v; want %v", margins.GainCrossoverFrequency, wcp)
+	}
+	if math.Abs(margins.DelayMargin-pm*math.Pi/180/wcp) > 1e-6 {
+		t.Errorf("The delay margin is %v; want %v", margins.DelayMargin, pm*math.Pi/180/wcp)
+	}
+	if !margins.Stable {
+		t.Errorf("The closed loop should be stable")
+	}
+}
+
+/*
+TestMargins_Margin2
+Description:
+
+	Verifies that an input delay reduces the phase margin by w_c tau (in degrees) without changing the gain
+	crossover frequency, and that a loop with a gain of 10 / (s + 1)^3 is reported as unstable.
+*/
+func TestMargins_Margin2(t *testing.T) {
+	// Constants
+	tf, _ := goControl.GetTransferFunction([]float64{4}, []float64{1, 3, 3, 1})
+	sys, _ := goControl.Tf2ss(tf)
+	delayed := sys.Copy()
+	delayed.InputDelay = []float64{0.1}
+
+	tfUnstable, _ := goControl.GetTransferFunction([]float64{10}, []float64{1, 3, 3, 1})
+	sysUnstable, _ := goControl.Tf2ss(tfUnstable)
+
+	// Algorithm
+	margins, _ := goControl.Margin(sys)
+	delayedMargins, err := goControl.Margin(delayed)
+	if err != nil {
+		t.Errorf("There was an error computing the margins: %v", err)
+	}
+
+	expected := margins.PhaseMargin - margins.GainCrossoverFrequency*0.1*180/math.Pi
+	if math.Abs(delayedMargins.PhaseMargin-expected) > 1e-6 {
+		t.Errorf("The phase margin with delay is %v; want %v", delayedMargins.PhaseMargin, expected)
+	}
+
+	unstableMargins, err := goControl.AllMargin(sysUnstable)
+	if err != nil {
+		t.Errorf("There was an error computing the margins: %v", err)
+	}
+	if unstableMargins.Stable {
+		t.Errorf("The closed loop should be unstable")
+	}
+	if (len(unstableMargins.GainMargins) != 1) || (unstableMargins.GainMargins[0] >= 1) {
+		t.Errorf("The gain margins are %v; want a single margin smaller than 1", unstableMargins.GainMargins)
+	}
+}
+
+/*
+TestMargins_Margin3
+Description:
+
+	Verifies that the margins of MIMO models are rejected.
+*/
+func TestMargins_Margin3(t *testing.T) {
+	// Algorithm
+	if _, err := goControl.Margin(getTestSecondOrderSystem()); err == nil {
+		t.Errorf("Expected an error for a MIMO model; received nil")
+	}
+}
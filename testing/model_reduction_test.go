@@ -0,0 +1,274 @@
+/*
+   model_reduction_test.go
+   Description:
+	   Tests for the minimal realizations and balanced truncation defined in model_reduction.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestReductionSystem
+Description:
+
+	Creates the stable fourth order model 1/(s+1) + 1/(s+2) + 1/(s+5) + 1/(s+10) with two outputs.
+*/
+func getTestReductionSystem() goControl.StateSpace {
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(4, 4, []float64{
+			-1, 0, 0, 0,
+			0, -2, 0, 0,
+			0, 0, -5, 0,
+			0, 0, 0, -10,
+		}),
+		mat.NewDense(4, 1, []float64{1, 1, 1, 1}),
+		mat.NewDense(2, 4, []float64{
+			1, 1, 1, 1,
+			1, -1, 2, 0,
+		}),
+		mat.NewDense(2, 1, []float64{0, 0.5}),
+	)
+	return sys
+}
+
+/*
+differenceModel
+Description:
+
+	Creates a model of sys1 - sys2 for two models with the same inputs and outputs.
+*/
+func differenceModel(sys1, sys2 goControl.StateSpace) goControl.StateSpace {
+	n1, m, p := sys1.Dims()
+	n2, _, _ := sys2.Dims()
+
+	A := mat.NewDense(n1+n2, n1+n2, nil)
+	B := mat.NewDense(n1+n2, m, nil)
+	C := mat.NewDense(p, n1+n2, nil)
+	D := mat.NewDense(p, m, nil)
+	A.Slice(0, n1, 0, n1).(*mat.Dense).Copy(sys1.A)
+	A.Slice(n1, n1+n2, n1, n1+n2).(*mat.Dense).Copy(sys2.A)
+	B.Slice(0, n1, 0, m).(*mat.Dense).Copy(sys1.B)
+	B.Slice(n1, n1+n2, 0, m).(*mat.Dense).Copy(sys2.B)
+	C.Slice(0, p, 0, n1).(*mat.Dense).Copy(sys1.C)
+	C.Slice(0, p, n1, n1+n2).(*mat.Dense).Scale(-1, sys2.C)
+	D.Sub(sys1.D, sys2.D)
+
+	sys, _ := goControl.GetDiscreteStateSpace(A, B, C, D, sys1.Ts)
+	return sys
+}
+
+/*
+TestModelReduction_HSVD1
+Description:
+
+	Verifies that the Hankel singular value of 1 / (s + 1) is 1/2.
+*/
+func TestModelReduction_HSVD1(t *testing.T) {
+	// Constants
+	sys := getTestFirstOrderSystem()
+
+	// Algorithm
+	hsv, err := goControl.HSVD(sys)
+	if err != nil {
+		t.Errorf("There was an error computing the Hankel singular values: %v", err)
+	}
+
+	if (len(hsv) != 1) || (math.Abs(hsv[0]-0.5) > 1e-12) {
+		t.Errorf("The Hankel singular values are %v; want [0.5]", hsv)
+	}
+}
+
+/*
+TestModelReduction_Balreal1
+Description:
+
+	Verifies that both Gramians of the balanced realization are equal to diag(HSV), and that the transfer function
+	is unchanged.
+*/
+func TestModelReduction_Balreal1(t *testing.T) {
+	// Constants
+	sys := getTestReductionSystem()
+
+	// Algorithm
+	balanced, err := goControl.Balreal(sys)
+	if err != nil {
+		t.Errorf("There was an error computing the balanced realization: %v", err)
+	}
+
+	Wc, err := goControl.ControllabilityGramian(balanced.Sys)
+	if err != nil {
+		t.Errorf("There was an error computing the controllability Gramian: %v", err)
+	}
+	Wo, err := goControl.ObservabilityGramian(balanced.Sys)
+	if err != nil {
+		t.Errorf("There was an error computing the observability Gramian: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if i > 0 && balanced.HSV[i] > balanced.HSV[i-1] {
+			t.Errorf("The Hankel singular values %v are not in decreasing order.", balanced.HSV)
+		}
+		for j := 0; j < 4; j++ {
+			expected := 0.0
+			if i == j {
+				expected = balanced.HSV[i]
+			}
+			if (math.Abs(Wc.At(i, j)-expected) > 1e-9) || (math.Abs(Wo.At(i, j)-expected) > 1e-9) {
+				t.Errorf("The Gramians at (%v,%v) are %v and %v; want %v", i, j, Wc.At(i, j), Wo.At(i, j), expected)
+			}
+		}
+	}
+
+	var product mat.Dense
+	product.Mul(balanced.T, balanced.Tinv)
+	if !mat.EqualApprox(&product, eye(4), 1e-9) {
+		t.Errorf("T Tinv is not the identity: %v", mat.Formatted(&product))
+	}
+
+	hinf, _, _ := goControl.Norm(differenceModel(sys, balanced.Sys), "inf")
+	if hinf > 1e-8 {
+		t.Errorf("The balanced realization changes the transfer function by %v.", hinf)
+	}
+}
+
+/*
+TestModelReduction_Balreal2
+Description:
+
+	Verifies that Balreal rejects a model that is not minimal.
+*/
+func TestModelReduction_Balreal2(t *testing.T) {
+	// Constants
+	sys := getTestPartiallyControllableSystem()
+
+	// Algorithm
+	_, err := goControl.Balreal(sys)
+	if err == nil {
+		t.Errorf("Expected an error for a model that is not minimal.")
+	}
+}
+
+/*
+TestModelReduction_BalancedTruncation1
+Description:
+
+	Reduces a fourth order model to second order and verifies that the H-infinity norm of the error is below the bound.
+*/
+func TestModelReduction_BalancedTruncation1(t *testing.T) {
+	// Constants
+	sys := getTestReductionSystem()
+
+	// Algorithm
+	reduced, bound, err := goControl.BalancedTruncation(sys, 2)
+	if err != nil {
+		t.Errorf("There was an error reducing the model: %v", err)
+	}
+	if n, _, _ := reduced.Dims(); n != 2 {
+		t.Errorf("The reduced model has %v states; want 2", n)
+	}
+
+	stable, _ := reduced.IsStable()
+	if !stable {
+		t.Errorf("The reduced model is not stable.")
+	}
+
+	hinf, _, err := goControl.Norm(differenceModel(sys, reduced), "inf")
+	if err != nil {
+		t.Errorf("There was an error computing the norm of the error: %v", err)
+	}
+	if (hinf > bound*(1+1e-9)) || (bound <= 0) {
+		t.Errorf("The error %v is not below the bound %v.", hinf, bound)
+	}
+}
+
+/*
+TestModelReduction_BalancedTruncation2
+Description:
+
+	Verifies that the reduced model matches the DC gain of the original model with the MatchDCGain option,
+	and that the error bound still holds.
+*/
+func TestModelReduction_BalancedTruncation2(t *testing.T) {
+	// Constants
+	sys := getTestReductionSystem()
+
+	// Algorithm
+	reduced, bound, err := goControl.BalancedTruncationWithOptions(sys, 1, goControl.BalancedTruncationOptions{MatchDCGain: true})
+	if err != nil {
+		t.Errorf("There was an error reducing the model: %v", err)
+	}
+
+	dc, _ := sys.DCGain()
+	dcReduced, _ := reduced.DCGain()
+	if !mat.EqualApprox(dc, dcReduced, 1e-9) {
+		t.Errorf("The DC gain of the reduced model is %v; want %v", mat.Formatted(dcReduced), mat.Formatted(dc))
+	}
+
+	hinf, _, _ := goControl.Norm(differenceModel(sys, reduced), "inf")
+	if hinf > bound*(1+1e-9) {
+		t.Errorf("The error %v is not below the bound %v.", hinf, bound)
+	}
+}
+
+/*
+TestModelReduction_BalancedTruncation3
+Description:
+
+	Verifies that the reduction of a model that is not minimal to its minimal order is exact.
+*/
+func TestModelReduction_BalancedTruncation3(t *testing.T) {
+	// Constants
+	sys := getTestPartiallyControllableSystem()
+
+	// Algorithm
+	reduced, bound, err := goControl.BalancedTruncation(sys, 3)
+	if err != nil {
+		t.Errorf("There was an error reducing the model: %v", err)
+	}
+	if n, _, _ := reduced.Dims(); n != 1 {
+		t.Errorf("The reduced model has %v states; want 1", n)
+	}
+	if bound > 1e-6 {
+		t.Errorf("The error bound is %v; want 0", bound)
+	}
+
+	hinf, _, _ := goControl.Norm(differenceModel(sys, reduced), "inf")
+	if hinf > 1e-6 {
+		t.Errorf("The reduction changes the transfer function by %v.", hinf)
+	}
+}
+
+/*
+TestModelReduction_Minreal1
+Description:
+
+	Verifies that Minreal removes the uncontrollable and the unobservable states without changing the transfer function.
+*/
+func TestModelReduction_Minreal1(t *testing.T) {
+	// Constants
+	sys := getTestPartiallyControllableSystem()
+
+	// Algorithm
+	minimal, err := sys.Minreal(0)
+	if err != nil {
+		t.Errorf("There was an error computing the minimal realization: %v", err)
+	}
+	if n, _, _ := minimal.Dims(); n != 1 {
+		t.Errorf("The minimal realization has %v states; want 1", n)
+	}
+	if math.Abs(minimal.A.At(0, 0)+1) > 1e-12 {
+		t.Errorf("The pole of the minimal realization is %v; want -1", minimal.A.At(0, 0))
+	}
+
+	hinf, _, _ := goControl.Norm(differenceModel(sys, minimal), "inf")
+	if hinf > 1e-10 {
+		t.Errorf("Minreal changes the transfer function by %v.", hinf)
+	}
+}
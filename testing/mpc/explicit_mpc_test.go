@@ -0,0 +1,234 @@
+package mpc_test
+
+/*
+explicit_mpc_test.go
+Description:
+	Tests for the multiparametric QP solver and the explicit controller defined in explicit_mpc.go.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/mpc"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestExplicitMPC_Solve1
+Description:
+
+	Solves the scalar multiparametric QP minimize 1/2 z^2 + x z subject to |z| <= 1 over |x| <= 3, whose solution
+	is z = -x for |x| <= 1 and saturates otherwise (three regions).
+*/
+func TestExplicitMPC_Solve1(t *testing.T) {
+	// Constants
+	mpqp := mpc.MultiparametricQP{
+		H: mat.NewSymDense(1, []float64{1}),
+		F: mat.NewDense(1, 1, []float64{1}),
+		G: mat.NewDense(2, 1, []float64{1, -1}),
+		W: mat.NewVecDense(2, []float64{1, 1}),
+		E: mat.NewDense(2, 1, nil),
+	}
+
+	// Algorithm
+	regions, err := mpqp.Solve(*getTestBox(3))
+	if err != nil {
+		t.Errorf("There was an error solving the multiparametric QP: %v", err)
+	}
+	if len(regions) != 3 {
+		t.Errorf("The partition has %v regions; want 3", len(regions))
+	}
+
+	for _, x := range []float64{-2.5, -1, -0.3, 0, 0.7, 1.5, 3} {
+		expected := math.Max(-1, math.Min(1, -x))
+		found := false
+		for _, region := range regions {
+			point := mat.NewVecDense(1, []float64{x})
+			if !region.Region.Contains(point) {
+				continue
+			}
+			found = true
+			z := region.Gain.At(0, 0)*x + region.Offset.AtVec(0)
+			if math.Abs(z-expected) > 1e-9 {
+				t.Errorf("The optimizer at x = %v is %v; want %v", x, z, expected)
+			}
+		}
+		if !found {
+			t.Errorf("The point x = %v is not in the partition.", x)
+		}
+	}
+}
+
+/*
+TestExplicitMPC_ToExplicit1
+Description:
+
+	Verifies that the explicit controller of a constrained double integrator applies the same input as the online
+	controller on a grid of states, and that sequential and tree point location agree.
+*/
+func TestExplicitMPC_ToExplicit1(t *testing.T) {
+	// Constants
+	sys := getTestDoubleIntegrator()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 0.1})
+	R := mat.NewDense(1, 1, []float64{0.1})
+
+	controller, err := mpc.GetController(sys, Q, R, 4, getTestBox(5, 2), getTestBox(1))
+	if err != nil {
+		t.Errorf("There was an error creating the controller: %v", err)
+	}
+
+	// Algorithm
+	explicit, err := controller.ToExplicit(nil)
+	if err != nil {
+		t.Fatalf("There was an error computing the explicit controller: %v", err)
+	}
+	if explicit.NumRegions() < 3 {
+		t.Errorf("The partition has %v regions; expected the saturated and unsaturated regions at least.", explicit.NumRegions())
+	}
+
+	for x1 := -4.75; x1 <= 4.75; x1 += 0.5 {
+		for x2 := -1.9; x2 <= 1.9; x2 += 0.2 {
+			x := mat.NewVecDense(2, []float64{x1, x2})
+			online, onlineErr := controller.Control(x)
+			u, index, err := explicit.Evaluate(x)
+			if (onlineErr != nil) != (err != nil) {
+				t.Errorf("At x = (%v, %v), the online error is %v and the explicit error is %v.", x1, x2, onlineErr, err)
+				continue
+			}
+			if err != nil {
+				continue
+			}
+			if math.Abs(u.AtVec(0)-online.AtVec(0)) > 1e-6 {
+				t.Errorf("At x = (%v, %v), the explicit input is %v; want %v", x1, x2, u.AtVec(0), online.AtVec(0))
+			}
+			if sequential := explicit.LocateSequential(x); !explicit.Regions[sequential].Region.Contains(x) || !explicit.Regions[index].Region.Contains(x) {
+				t.Errorf("The located regions %v and %v do not contain x = (%v, %v).", index, sequential, x1, x2)
+			}
+		}
+	}
+}
+
+/*
+checkEvaluate
+Description:
+
+	Verifies on a grid of states that Evaluate returns the region index given by locate and the input of the affine
+	law of that region.
+*/
+func checkEvaluate(t *testing.T, explicit mpc.ExplicitController, locate func(x mat.Vector) int, name string) {
+	for x1 := -4.75; x1 <= 4.75; x1 += 0.5 {
+		for x2 := -1.9; x2 <= 1.9; x2 += 0.2 {
+			x := mat.NewVecDense(2, []float64{x1, x2})
+			expected := locate(x)
+			u, index, err := explicit.Evaluate(x)
+			if expected < 0 {
+				if err == nil {
+					t.Errorf("At x = (%v, %v), Evaluate returned region %v; %v locates no region.", x1, x2, index, name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("At x = (%v, %v), Evaluate returned the error %v; %v locates region %v.", x1, x2, err, name, expected)
+				continue
+			}
+			if index != expected {
+				t.Errorf("At x = (%v, %v), Evaluate located region %v; %v locates region %v.", x1, x2, index, name, expected)
+			}
+			region := explicit.Regions[expected]
+			law := mat.Dot(region.Gain.RowView(0), x) + region.Offset.AtVec(0)
+			if math.Abs(u.AtVec(0)-law) > 1e-12 {
+				t.Errorf("At x = (%v, %v), Evaluate applied %v; the law of region %v gives %v.", x1, x2, u.AtVec(0), expected, law)
+			}
+		}
+	}
+}
+
+/*
+TestExplicitMPC_Evaluate1
+Description:
+
+	Verifies that Evaluate agrees with LocateSequential before the search tree is built and with LocateTree after.
+	Region 0 is then enlarged to the whole domain, so that a sequential search would return it everywhere while the
+	search tree still locates the original regions.
+*/
+func TestExplicitMPC_Evaluate1(t *testing.T) {
+	// Constants
+	sys := getTestDoubleIntegrator()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 0.1})
+	R := mat.NewDense(1, 1, []float64{0.1})
+
+	controller, err := mpc.GetController(sys, Q, R, 4, getTestBox(5, 2), getTestBox(1))
+	if err != nil {
+		t.Errorf("There was an error creating the controller: %v", err)
+	}
+	explicit, err := controller.ToExplicit(nil)
+	if err != nil {
+		t.Fatalf("There was an error computing the explicit controller: %v", err)
+	}
+
+	// Algorithm
+	checkEvaluate(t, explicit, explicit.LocateSequential, "LocateSequential")
+
+	if err := explicit.BuildSearchTree(); err != nil {
+		t.Fatalf("There was an error building the search tree: %v", err)
+	}
+	explicit.Regions = append([]mpc.CriticalRegion{}, explicit.Regions...)
+	explicit.Regions[0].Region = *getTestBox(5, 2)
+	checkEvaluate(t, explicit, explicit.LocateTree, "LocateTree")
+
+	differs := false
+	for x1 := -4.75; x1 <= 4.75; x1 += 0.5 {
+		x := mat.NewVecDense(2, []float64{x1, 0})
+		differs = differs || (explicit.LocateTree(x) != explicit.LocateSequential(x))
+	}
+	if !differs {
+		t.Errorf("Expected the search tree and the sequential search to locate different regions somewhere.")
+	}
+}
+
+/*
+TestExplicitMPC_Export1
+Description:
+
+	Verifies that the exported partition is valid JSON with one entry per region.
+*/
+func TestExplicitMPC_Export1(t *testing.T) {
+	// Constants
+	sys := getTestDoubleIntegrator()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 0.1})
+	R := mat.NewDense(1, 1, []float64{1})
+
+	controller, _ := mpc.GetController(sys, Q, R, 2, nil, getTestBox(1))
+	explicit, err := controller.ToExplicit(getTestBox(3, 3))
+	if err != nil {
+		t.Fatalf("There was an error computing the explicit controller: %v", err)
+	}
+
+	// Algorithm
+	var buffer bytes.Buffer
+	if err := explicit.Export(&buffer); err != nil {
+		t.Errorf("There was an error exporting the partition: %v", err)
+	}
+
+	var partition struct {
+		StateDimension int `json:"stateDimension"`
+		Regions        []struct {
+			A [][]float64 `json:"A"`
+			F [][]float64 `json:"F"`
+		} `json:"regions"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &partition); err != nil {
+		t.Errorf("The exported partition is not valid JSON: %v", err)
+	}
+	if (partition.StateDimension != 2) || (len(partition.Regions) != explicit.NumRegions()) {
+		t.Errorf("The exported partition has dimension %v and %v regions; want 2 and %v", partition.StateDimension, len(partition.Regions), explicit.NumRegions())
+	}
+	for _, region := range partition.Regions {
+		if (len(region.F) != 1) || (len(region.F[0]) != 2) {
+			t.Errorf("The exported control law has the wrong dimensions: %v", region.F)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package mpc_test
+
+/*
+mpc_test.go
+Description:
+	Tests for the model predictive controller defined in mpc.go.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"github.com/kwesiRutledge/goControl/mpc"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestDoubleIntegrator
+Description:
+
+	Creates the double integrator discretized with sample time 0.1.
+*/
+func getTestDoubleIntegrator() goControl.StateSpace {
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(2, 2, []float64{1, 0.1, 0, 1}),
+		mat.NewDense(2, 1, []float64{0.005, 0.1}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, []float64{0}),
+		0.1,
+	)
+	return sys
+}
+
+/*
+getTestBox
+Description:
+
+	Creates the Polyhedron {x : |x_i| <= bounds[i]}.
+*/
+func getTestBox(bounds ...float64) *goControl.Polyhedron {
+	n := len(bounds)
+	A := mat.NewDense(2*n, n, nil)
+	b := mat.NewVecDense(2*n, nil)
+	for i, bound := range bounds {
+		A.Set(2*i, i, 1)
+		A.Set(2*i+1, i, -1)
+		b.SetVec(2*i, bound)
+		b.SetVec(2*i+1, bound)
+	}
+	box := goControl.GetPolyhedron(A, b)
+	return &box
+}
+
+/*
+TestMPC_Solve1
+Description:
+
+	Verifies that the unconstrained controller with the LQR terminal cost applies the LQR gain, in both formulations.
+*/
+func TestMPC_Solve1(t *testing.T) {
+	// Constants
+	sys := getTestDoubleIntegrator()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 0.1})
+	R := mat.NewDense(1, 1, []float64{0.5})
+	x0 := mat.NewVecDense(2, []float64{1, -0.5})
+
+	K, _, _, err := goControl.Dlqr(sys.A, sys.B, Q, R, nil)
+	if err != nil {
+		t.Errorf("There was an error computing the LQR gain: %v", err)
+	}
+	expected := -mat.Dot(K.RowView(0), x0)
+
+	// Algorithm
+	for _, formulation := range []mpc.Formulation{mpc.Condensed, mpc.Sparse} {
+		controller, err := mpc.GetControllerWithOptions(sys, Q, R, 5, nil, nil, mpc.Options{Formulation: formulation, LQRTerminalCost: true})
+		if err != nil {
+			t.Errorf("There was an error creating the controller: %v", err)
+		}
+
+		u, err := controller.Control(x0)
+		if err != nil {
+			t.Errorf("There was an error solving the MPC problem: %v", err)
+		}
+		if math.Abs(u.AtVec(0)-expected) > 1e-8 {
+			t.Errorf("The input of formulation %v is %v; want %v", formulation, u.AtVec(0), expected)
+		}
+	}
+}
+
+/*
+TestMPC_Solve2
+Description:
+
+	Verifies that the condensed and sparse formulations return the same constrained solution, that the predicted
+	trajectories satisfy the dynamics and the constraints, and that the cost matches the trajectories.
+*/
+func TestMPC_Solve2(t *testing.T) {
+	// Constants
+	sys := getTestDoubleIntegrator()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 0.1})
+	R := mat.NewDense(1, 1, []float64{0.1})
+	x0 := mat.NewVecDense(2, []float64{4, 0})
+	N := 10
+
+	// Algorithm
+	var solutions []mpc.Solution
+	for _, formulation := range []mpc.Formulation{mpc.Condensed, mpc.Sparse} {
+		controller, err := mpc.GetControllerWithOptions(sys, Q, R, N, getTestBox(5, 1), getTestBox(1), mpc.Options{Formulation: formulation})
+		if err != nil {
+			t.Errorf("There was an error creating the controller: %v", err)
+		}
+		solution, err := controller.Solve(x0)
+		if err != nil {
+			t.Errorf("There was an error solving the MPC problem: %v", err)
+		}
+		solutions = append(solutions, solution)
+	}
+
+	for _, solution := range solutions {
+		cost := 0.0
+		for k := 0; k < N; k++ {
+			x, u := solution.X.RowView(k), solution.U.RowView(k)
+			if math.Abs(u.AtVec(0)) > 1+1e-9 || math.Abs(x.AtVec(1)) > 1+1e-9 {
+				t.Errorf("The constraints are violated at step %v: x = %v, u = %v", k, mat.Formatted(x.T()), u.AtVec(0))
+			}
+
+			var next, Bu mat.VecDense
+			next.MulVec(sys.A, x)
+			Bu.MulVec(sys.B, u)
+			next.AddVec(&next, &Bu)
+			if !mat.EqualApprox(&next, solution.X.RowView(k+1), 1e-9) {
+				t.Errorf("The predicted states do not satisfy the dynamics at step %v.", k)
+			}
+
+			cost += mat.Inner(x, Q, x) + mat.Inner(u, R, u)
+		}
+		xN := solution.X.RowView(N)
+		cost += mat.Inner(xN, Q, xN)
+		if math.Abs(cost-solution.Cost) > 1e-8*cost {
+			t.Errorf("The cost is %v; want %v", solution.Cost, cost)
+		}
+	}
+
+	if !mat.EqualApprox(solutions[0].U, solutions[1].U, 1e-7) {
+		t.Errorf("The condensed and sparse formulations give different inputs: %v and %v", mat.Formatted(solutions[0].U.T()), mat.Formatted(solutions[1].U.T()))
+	}
+	if math.Abs(solutions[0].U.At(0, 0)+1) > 1e-9 {
+		t.Errorf("The first input is %v; want the constraint -1", solutions[0].U.At(0, 0))
+	}
+}
+
+/*
+TestMPC_Solve3
+Description:
+
+	Simulates the closed loop and verifies that the QPs after the first one are warm started, that the constraints
+	hold and that the state converges to the origin.
+*/
+func TestMPC_Solve3(t *testing.T) {
+	// Constants
+	sys := getTestDoubleIntegrator()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 0.1})
+	R := mat.NewDense(1, 1, []float64{0.1})
+	x := mat.NewVecDense(2, []float64{3, 0})
+
+	controller, err := mpc.GetControllerWithOptions(sys, Q, R, 15, getTestBox(5, 1), getTestBox(1), mpc.Options{LQRTerminalCost: true})
+	if err != nil {
+		t.Errorf("There was an error creating the controller: %v", err)
+	}
+
+	// Algorithm
+	for step := 0; step < 100; step++ {
+		solution, err := controller.Solve(x)
+		if err != nil {
+			t.Fatalf("There was an error solving the MPC problem at step %v: %v", step, err)
+		}
+		if (step > 0) && !solution.Diagnostics.WarmStarted {
+			t.Errorf("The QP at step %v was not warm started.", step)
+		}
+
+		u := solution.U.RowView(0)
+		if math.Abs(u.AtVec(0)) > 1+1e-9 {
+			t.Errorf("The input %v violates the constraint.", u.AtVec(0))
+		}
+
+		var next, Bu mat.VecDense
+		next.MulVec(sys.A, x)
+		Bu.MulVec(sys.B, u)
+		x.AddVec(&next, &Bu)
+	}
+
+	if mat.Norm(x, 2) > 1e-3 {
+		t.Errorf("The final state is %v; want the origin", mat.Formatted(x.T()))
+	}
+}
+
+/*
+TestMPC_Solve4
+Description:
+
+	Verifies that the terminal set constrains the last predicted state.
+*/
+func TestMPC_Solve4(t *testing.T) {
+	// Constants
+	sys := getTestDoubleIntegrator()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 0.1})
+	R := mat.NewDense(1, 1, []float64{10})
+	x0 := mat.NewVecDense(2, []float64{1, 0})
+	terminalSet := getTestBox(0.1, 0.1)
+
+	controller, err := mpc.GetControllerWithOptions(sys, Q, R, 20, nil, getTestBox(2), mpc.Options{TerminalSet: terminalSet, Formulation: mpc.Sparse})
+	if err != nil {
+		t.Errorf("There was an error creating the controller: %v", err)
+	}
+
+	// Algorithm
+	solution, err := controller.Solve(x0)
+	if err != nil {
+		t.Errorf("There was an error solving the MPC problem: %v", err)
+	}
+
+	xN := solution.X.RowView(20)
+	if (math.Abs(xN.AtVec(0)) > 0.1+1e-9) || (math.Abs(xN.AtVec(1)) > 0.1+1e-9) {
+		t.Errorf("The terminal state %v is not in the terminal set.", mat.Formatted(xN.T()))
+	}
+	if len(solution.Diagnostics.ActiveSet) == 0 {
+		t.Errorf("Expected the terminal constraint to be active.")
+	}
+}
+
+/*
+TestMPC_Solve5
+Description:
+
+	Verifies that an infeasible problem (the terminal set cannot be reached with the bounded input) returns an error,
+	and that invalid weights are rejected.
+*/
+func TestMPC_Solve5(t *testing.T) {
+	// Constants
+	sys := getTestDoubleIntegrator()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 0.1})
+	R := mat.NewDense(1, 1, []float64{1})
+
+	controller, err := mpc.GetControllerWithOptions(sys, Q, R, 3, nil, getTestBox(0.1), mpc.Options{TerminalSet: getTestBox(0.01, 0.01)})
+	if err != nil {
+		t.Errorf("There was an error creating the controller: %v", err)
+	}
+
+	// Algorithm
+	if _, err := controller.Solve(mat.NewVecDense(2, []float64{5, 0})); err == nil {
+		t.Errorf("Expected an error for an infeasible problem.")
+	}
+
+	if _, err := mpc.GetController(sys, Q, mat.NewDense(1, 1, []float64{0}), 3, nil, nil); err == nil {
+		t.Errorf("Expected an error for an input weight that is not positive definite.")
+	}
+}
@@ -0,0 +1,145 @@
+package mpc_test
+
+/*
+quadratic_program_test.go
+Description:
+	Tests for the active-set QP solver defined in quadratic_program.go.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/mpc"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestQuadraticProgram
+Description:
+
+	Creates Example 16.4 of Nocedal and Wright: minimize (z1 - 1)^2 + (z2 - 2.5)^2 subject to five linear
+	inequality constraints. The solution is (1.4, 1.7) and only the first constraint is active.
+*/
+func getTestQuadraticProgram() mpc.QuadraticProgram {
+	return mpc.QuadraticProgram{
+		H: mat.NewSymDense(2, []float64{2, 0, 0, 2}),
+		F: mat.NewVecDense(2, []float64{-2, -5}),
+		G: mat.NewDense(5, 2, []float64{
+			-1, 2,
+			1, 2,
+			1, -2,
+			-1, 0,
+			0, -1,
+		}),
+		W: mat.NewVecDense(5, []float64{2, 6, 2, 0, 0}),
+	}
+}
+
+/*
+TestQuadraticProgram_Solve1
+Description:
+
+	Solves Example 16.4 of Nocedal and Wright without a warm start.
+*/
+func TestQuadraticProgram_Solve1(t *testing.T) {
+	// Constants
+	qp := getTestQuadraticProgram()
+
+	// Algorithm
+	solution, err := qp.Solve(mpc.QPWarmStart{})
+	if err != nil {
+		t.Errorf("There was an error solving the QP: %v", err)
+	}
+
+	if (math.Abs(solution.Z.AtVec(0)-1.4) > 1e-9) || (math.Abs(solution.Z.AtVec(1)-1.7) > 1e-9) {
+		t.Errorf("The solution is %v; want [1.4 1.7]", mat.Formatted(solution.Z.T()))
+	}
+	if (len(solution.ActiveSet) != 1) || (solution.ActiveSet[0] != 0) {
+		t.Errorf("The active set is %v; want [0]", solution.ActiveSet)
+	}
+	if math.Abs(solution.Lambda.AtVec(0)-0.8) > 1e-9 {
+		t.Errorf("The multiplier of the active constraint is %v; want 0.8", solution.Lambda.AtVec(0))
+	}
+	if (solution.PrimalResidual > 1e-9) || (solution.DualResidual > 1e-9) {
+		t.Errorf("The residuals are %v and %v; want 0", solution.PrimalResidual, solution.DualResidual)
+	}
+	if solution.WarmStarted {
+		t.Errorf("The solver reports a warm start that was not given.")
+	}
+}
+
+/*
+TestQuadraticProgram_Solve2
+Description:
+
+	Verifies that warm starting with the optimal active set solves Example 16.4 in one iteration.
+*/
+func TestQuadraticProgram_Solve2(t *testing.T) {
+	// Constants
+	qp := getTestQuadraticProgram()
+
+	// Algorithm
+	solution, err := qp.Solve(mpc.QPWarmStart{ActiveSet: []int{0}})
+	if err != nil {
+		t.Errorf("There was an error solving the QP: %v", err)
+	}
+
+	if !solution.WarmStarted || (solution.Iterations != 1) {
+		t.Errorf("The solver took %v iterations (warm started: %v); want 1 warm started iteration", solution.Iterations, solution.WarmStarted)
+	}
+	if (math.Abs(solution.Z.AtVec(0)-1.4) > 1e-9) || (math.Abs(solution.Z.AtVec(1)-1.7) > 1e-9) {
+		t.Errorf("The solution is %v; want [1.4 1.7]", mat.Formatted(solution.Z.T()))
+	}
+}
+
+/*
+TestQuadraticProgram_Solve3
+Description:
+
+	Solves a QP with an equality constraint: minimize z1^2 + z2^2 subject to z1 + z2 = 2 and z1 <= 0.5.
+	The solution is (0.5, 1.5).
+*/
+func TestQuadraticProgram_Solve3(t *testing.T) {
+	// Constants
+	qp := mpc.QuadraticProgram{
+		H:   mat.NewSymDense(2, []float64{2, 0, 0, 2}),
+		F:   mat.NewVecDense(2, nil),
+		G:   mat.NewDense(1, 2, []float64{1, 0}),
+		W:   mat.NewVecDense(1, []float64{0.5}),
+		Aeq: mat.NewDense(1, 2, []float64{1, 1}),
+		Beq: mat.NewVecDense(1, []float64{2}),
+	}
+
+	// Algorithm
+	solution, err := qp.Solve(mpc.QPWarmStart{})
+	if err != nil {
+		t.Errorf("There was an error solving the QP: %v", err)
+	}
+
+	if (math.Abs(solution.Z.AtVec(0)-0.5) > 1e-9) || (math.Abs(solution.Z.AtVec(1)-1.5) > 1e-9) {
+		t.Errorf("The solution is %v; want [0.5 1.5]", mat.Formatted(solution.Z.T()))
+	}
+}
+
+/*
+TestQuadraticProgram_Solve4
+Description:
+
+	Verifies that an infeasible QP is reported as an error.
+*/
+func TestQuadraticProgram_Solve4(t *testing.T) {
+	// Constants
+	qp := mpc.QuadraticProgram{
+		H: mat.NewSymDense(1, []float64{1}),
+		F: mat.NewVecDense(1, nil),
+		G: mat.NewDense(2, 1, []float64{1, -1}),
+		W: mat.NewVecDense(2, []float64{-1, -1}),
+	}
+
+	// Algorithm
+	_, err := qp.Solve(mpc.QPWarmStart{})
+	if err == nil {
+		t.Errorf("Expected an error for an infeasible QP.")
+	}
+}
@@ -0,0 +1,178 @@
+package mpc_test
+
+/*
+tube_mpc_test.go
+Description:
+	Tests for the tube MPC controller defined in tube_mpc.go.
+*/
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"github.com/kwesiRutledge/goControl/mpc"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestTubeSystem
+Description:
+
+	Creates the double integrator x+ = [1 1; 0 1] x + [0.5; 1] u with sample time 1.
+*/
+func getTestTubeSystem() goControl.StateSpace {
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(2, 2, []float64{1, 1, 0, 1}),
+		mat.NewDense(2, 1, []float64{0.5, 1}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, []float64{0}),
+		1,
+	)
+	return sys
+}
+
+/*
+TestTubeMPC_Solve1
+Description:
+
+	Simulates the closed loop with random disturbances (including the vertices of W) and verifies that the state
+	and input constraints hold at every step, and that the error between the state and the nominal state stays in
+	the tube.
+*/
+func TestTubeMPC_Solve1(t *testing.T) {
+	// Constants
+	sys := getTestTubeSystem()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 1})
+	R := mat.NewDense(1, 1, []float64{0.01})
+	X := getTestBox(5, 2)
+	U := getTestBox(1)
+	W := getTestBox(0.1, 0.1)
+	generator := rand.New(rand.NewSource(1))
+
+	// Algorithm
+	controller, err := mpc.GetTubeControllerWithOptions(sys, Q, R, 8, X, U, *W, mpc.TubeOptions{
+		Controller: mpc.Options{LQRTerminalCost: true},
+	})
+	if err != nil {
+		t.Fatalf("There was an error creating the controller: %v", err)
+	}
+
+	if !controller.Tube.Contains(*W) {
+		t.Errorf("The tube does not contain the disturbance set.")
+	}
+	if !X.Contains(*controller.Nominal.StateConstraints) || !U.Contains(*controller.Nominal.InputConstraints) {
+		t.Errorf("The tightened constraints are not contained in the original constraints.")
+	}
+
+	x := mat.NewVecDense(2, []float64{-4.5, 1})
+	for k := 0; k < 25; k++ {
+		solution, err := controller.Solve(x)
+		if err != nil {
+			t.Fatalf("Step %v: there was an error solving the tube MPC problem: %v", k, err)
+		}
+
+		var e mat.VecDense
+		e.SubVec(x, solution.NominalState)
+		if !controller.Tube.Contains(&e) {
+			t.Errorf("Step %v: the error %v left the tube.", k, mat.Formatted(e.T()))
+		}
+		if !U.Contains(solution.U) {
+			t.Errorf("Step %v: the input %v violates the input constraints.", k, solution.U.AtVec(0))
+		}
+
+		w := mat.NewVecDense(2, []float64{0.1, -0.1})
+		if k%3 != 0 {
+			w.SetVec(0, 0.2*generator.Float64()-0.1)
+			w.SetVec(1, 0.2*generator.Float64()-0.1)
+		}
+		var next, Bu mat.VecDense
+		next.MulVec(sys.A, x)
+		Bu.MulVec(sys.B, solution.U)
+		next.AddVec(&next, &Bu)
+		next.AddVec(&next, w)
+		x = &next
+
+		if !X.Contains(x) {
+			t.Errorf("Step %v: the state %v violates the state constraints.", k, mat.Formatted(x.T()))
+		}
+	}
+
+	if mat.Norm(x, 2) > 0.5 {
+		t.Errorf("The state %v did not approach the origin.", mat.Formatted(x.T()))
+	}
+}
+
+/*
+TestTubeMPC_Solve2
+Description:
+
+	Verifies that the controller with a given gain and tube tightens the constraints by the Pontryagin difference,
+	and that Reset restarts the nominal state at the measured state.
+*/
+func TestTubeMPC_Solve2(t *testing.T) {
+	// Constants
+	sys := getTestTubeSystem()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 1})
+	R := mat.NewDense(1, 1, []float64{1})
+	K := mat.NewDense(1, 2, []float64{1, 1.5}) // A - B K is nilpotent
+	X := getTestBox(5, 2)
+	U := getTestBox(2)
+	W := getTestBox(0.1, 0.1)
+	Z := getTestBox(0.5, 0.5)
+
+	// Algorithm
+	controller, err := mpc.GetTubeControllerWithOptions(sys, Q, R, 5, X, U, *W, mpc.TubeOptions{Gain: K, Tube: Z})
+	if err != nil {
+		t.Fatalf("There was an error creating the controller: %v", err)
+	}
+
+	expectedState := getTestBox(4.5, 1.5)
+	expectedInput := getTestBox(0.75) // |K e| <= 0.5 + 0.75 on Z
+	if !expectedState.Contains(*controller.Nominal.StateConstraints) || !controller.Nominal.StateConstraints.Contains(*expectedState) {
+		t.Errorf("The tightened state constraints are not [-4.5, 4.5] x [-1.5, 1.5].")
+	}
+	if !expectedInput.Contains(*controller.Nominal.InputConstraints) || !controller.Nominal.InputConstraints.Contains(*expectedInput) {
+		t.Errorf("The tightened input constraints are not [-0.75, 0.75].")
+	}
+
+	x := mat.NewVecDense(2, []float64{1, 0})
+	if _, err := controller.Solve(x); err != nil {
+		t.Fatalf("There was an error solving the tube MPC problem: %v", err)
+	}
+	controller.Reset()
+	y := mat.NewVecDense(2, []float64{-2, 0.5})
+	solution, err := controller.Solve(y)
+	if err != nil {
+		t.Fatalf("There was an error solving the tube MPC problem: %v", err)
+	}
+	if !mat.Equal(solution.NominalState, y) {
+		t.Errorf("The nominal state after Reset is %v; want %v", mat.Formatted(solution.NominalState.T()), mat.Formatted(y.T()))
+	}
+}
+
+/*
+TestTubeMPC_GetTubeController1
+Description:
+
+	Verifies that a disturbance too large for the constraints and an ancillary gain with the wrong dimensions are
+	rejected.
+*/
+func TestTubeMPC_GetTubeController1(t *testing.T) {
+	// Constants
+	sys := getTestTubeSystem()
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 1})
+	R := mat.NewDense(1, 1, []float64{0.01})
+	X := getTestBox(5, 2)
+	U := getTestBox(1)
+
+	// Algorithm
+	if _, err := mpc.GetTubeController(sys, Q, R, 5, X, U, *getTestBox(3, 3)); err == nil {
+		t.Errorf("Expected an error for a disturbance larger than the constraints.")
+	}
+	if _, err := mpc.GetTubeControllerWithOptions(sys, Q, R, 5, X, U, *getTestBox(0.1, 0.1), mpc.TubeOptions{
+		Gain: mat.NewDense(2, 2, nil),
+	}); err == nil {
+		t.Errorf("Expected an error for an ancillary gain with the wrong dimensions.")
+	}
+}
@@ -0,0 +1,218 @@
+/*
+   nonlinear_system_test.go
+   Description:
+	   Tests for the nonlinear models defined in nonlinear_system.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"github.com/kwesiRutledge/goControl/symbolic"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestDuffingSystem
+Description:
+
+	Returns the forced Duffing oscillator
+		dx1/dt = x2,   dx2/dt = -x1 - x1^3 - 0.5 x2 + u,
+	together with its state and input variables.
+*/
+func getTestDuffingSystem(t *testing.T) (goControl.NonlinearSystem, []symbolic.Variable, symbolic.Variable) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	u := symbolic.Variable{Name: "u"}
+
+	f2 := symbolic.Polynomial{Monomials: []symbolic.Monomial{
+		{Coefficient: -1, Variables: []symbolic.Variable{x[0]}, Exponents: []int{1}},
+		{Coefficient: -1, Variables: []symbolic.Variable{x[0]}, Exponents: []int{3}},
+		{Coefficient: -0.5, Variables: []symbolic.Variable{x[1]}, Exponents: []int{1}},
+		{Coefficient: 1, Variables: []symbolic.Variable{u}, Exponents: []int{1}},
+	}}
+
+	// Algorithm
+	sys, err := goControl.GetNonlinearSystem(x, []symbolic.Variable{u}, []interface{}{x[1], f2}, 0)
+	if err != nil {
+		t.Fatalf("There was an error creating the system: %v", err)
+	}
+	return sys, x, u
+}
+
+/*
+TestNonlinearSystem_GetNonlinearSystem1
+Description:
+
+	Verifies that models with a mismatched number of dynamics, a repeated variable or dynamics depending on an
+	unknown variable are rejected.
+*/
+func TestNonlinearSystem_GetNonlinearSystem1(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	u := symbolic.Variable{Name: "u"}
+	w := symbolic.Variable{Name: "w"}
+
+	// Algorithm
+	if _, err := goControl.GetNonlinearSystem(x, []symbolic.Variable{u}, []interface{}{x[1]}, 0); err == nil {
+		t.Errorf("A system with one dynamics for two states was created.")
+	}
+	if _, err := goControl.GetNonlinearSystem(x, []symbolic.Variable{x[0]}, []interface{}{x[1], x[0]}, 0); err == nil {
+		t.Errorf("A system whose input is also a state was created.")
+	}
+	if _, err := goControl.GetNonlinearSystem(x, []symbolic.Variable{u}, []interface{}{x[1], w}, 0); err == nil {
+		t.Errorf("A system depending on an unknown variable was created.")
+	}
+	if _, err := goControl.GetNonlinearSystem(x, []symbolic.Variable{u}, []interface{}{x[1], "u"}, 0); err == nil {
+		t.Errorf("A system with a string as dynamics was created.")
+	}
+}
+
+/*
+TestNonlinearSystem_Linearize1
+Description:
+
+	Linearizes the Duffing oscillator around the equilibrium x = (1, 0), u = 2 and compares the result with the
+	Jacobians computed by hand, A = [0 1; -4 -0.5] and B = [0; 1].
+*/
+func TestNonlinearSystem_Linearize1(t *testing.T) {
+	// Constants
+	sys, _, _ := getTestDuffingSystem(t)
+	x0 := mat.NewVecDense(2, []float64{1, 0})
+	u0 := mat.NewVecDense(1, []float64{2})
+
+	// Algorithm
+	f, err := sys.Evaluate(x0, u0)
+	if err != nil {
+		t.Fatalf("There was an error evaluating the dynamics: %v", err)
+	}
+	if mat.Norm(f, 2) > 1e-12 {
+		t.Errorf("f(x0, u0) = %v; expected zero at the equilibrium", mat.Formatted(f.T()))
+	}
+
+	linear, err := sys.Linearize(x0, u0)
+	if err != nil {
+		t.Fatalf("There was an error linearizing the system: %v", err)
+	}
+	if linear.IsDiscrete() {
+		t.Errorf("The linearization of a continuous-time model is discrete.")
+	}
+	if !mat.EqualApprox(linear.A, mat.NewDense(2, 2, []float64{0, 1, -4, -0.5}), 1e-12) {
+		t.Errorf("A = %v; expected [0 1; -4 -0.5]", mat.Formatted(linear.A))
+	}
+	if !mat.EqualApprox(linear.B, mat.NewDense(2, 1, []float64{0, 1}), 1e-12) {
+		t.Errorf("B = %v; expected [0; 1]", mat.Formatted(linear.B))
+	}
+	if !mat.EqualApprox(linear.C, mat.NewDense(2, 2, []float64{1, 0, 0, 1}), 1e-12) {
+		t.Errorf("C = %v; expected the identity", mat.Formatted(linear.C))
+	}
+}
+
+/*
+TestNonlinearSystem_Linearize2
+Description:
+
+	Linearizes a discrete-time model with the output y = x1 x2 and verifies the sample time and the output
+	matrices C = [x2 x1] and D = 0.
+*/
+func TestNonlinearSystem_Linearize2(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	u := symbolic.Variable{Name: "u"}
+	y := symbolic.Monomial{Coefficient: 1, Variables: []symbolic.Variable{x[0], x[1]}, Exponents: []int{1, 1}}
+
+	sys, err := goControl.GetNonlinearSystemWithOutputs(x, []symbolic.Variable{u}, []interface{}{x[1], u}, []interface{}{y}, 0.1)
+	if err != nil {
+		t.Fatalf("There was an error creating the system: %v", err)
+	}
+
+	// Algorithm
+	linear, err := sys.Linearize(mat.NewVecDense(2, []float64{2, 3}), mat.NewVecDense(1, []float64{0}))
+	if err != nil {
+		t.Fatalf("There was an error linearizing the system: %v", err)
+	}
+	if linear.Ts != 0.1 {
+		t.Errorf("The sample time is %v; expected 0.1", linear.Ts)
+	}
+	if !mat.EqualApprox(linear.C, mat.NewDense(1, 2, []float64{3, 2}), 1e-12) {
+		t.Errorf("C = %v; expected [3 2]", mat.Formatted(linear.C))
+	}
+	if !mat.EqualApprox(linear.D, mat.NewDense(1, 1, nil), 1e-12) {
+		t.Errorf("D = %v; expected 0", mat.Formatted(linear.D))
+	}
+}
+
+/*
+TestNonlinearSystem_Simulate1
+Description:
+
+	Simulates dx/dt = -x + u with a unit input from x(0) = 0 and compares the result with the exact solution
+	1 - exp(-t).
+*/
+func TestNonlinearSystem_Simulate1(t *testing.T) {
+	// Constants
+	x := symbolic.Variable{Name: "x"}
+	u := symbolic.Variable{Name: "u"}
+	f := symbolic.Polynomial{Monomials: []symbolic.Monomial{
+		{Coefficient: -1, Variables: []symbolic.Variable{x}, Exponents: []int{1}},
+		{Coefficient: 1, Variables: []symbolic.Variable{u}, Exponents: []int{1}},
+	}}
+	sys, err := goControl.GetNonlinearSystem([]symbolic.Variable{x}, []symbolic.Variable{u}, []interface{}{f}, 0)
+	if err != nil {
+		t.Fatalf("There was an error creating the system: %v", err)
+	}
+
+	N := 21
+	times := make([]float64, N)
+	inputs := mat.NewDense(N, 1, nil)
+	for k := range times {
+		times[k] = 0.25 * float64(k)
+		inputs.Set(k, 0, 1)
+	}
+
+	// Algorithm
+	response, err := sys.Simulate(inputs, times, mat.NewVecDense(1, nil))
+	if err != nil {
+		t.Fatalf("There was an error simulating the system: %v", err)
+	}
+	for k, tk := range times {
+		if expected := 1 - math.Exp(-tk); math.Abs(response.State.At(k, 0)-expected) > 1e-7 {
+			t.Errorf("x(%v) = %v; expected %v", tk, response.State.At(k, 0), expected)
+		}
+	}
+}
+
+/*
+TestNonlinearSystem_Simulate2
+Description:
+
+	Simulates the discrete-time model x+ = x^2 without inputs from x(0) = 0.5 and verifies the squares, and verifies
+	that time samples that are not spaced by the sample time are rejected.
+*/
+func TestNonlinearSystem_Simulate2(t *testing.T) {
+	// Constants
+	x := symbolic.Variable{Name: "x"}
+	f := symbolic.Monomial{Coefficient: 1, Variables: []symbolic.Variable{x}, Exponents: []int{2}}
+	sys, err := goControl.GetNonlinearSystem([]symbolic.Variable{x}, nil, []interface{}{f}, 1)
+	if err != nil {
+		t.Fatalf("There was an error creating the system: %v", err)
+	}
+
+	// Algorithm
+	response, err := sys.Simulate(nil, []float64{0, 1, 2, 3}, mat.NewVecDense(1, []float64{0.5}))
+	if err != nil {
+		t.Fatalf("There was an error simulating the system: %v", err)
+	}
+	for k, expected := range []float64{0.5, 0.25, 0.0625, 0.00390625} {
+		if math.Abs(response.Output.At(k, 0)-expected) > 1e-12 {
+			t.Errorf("y[%v] = %v; expected %v", k, response.Output.At(k, 0), expected)
+		}
+	}
+
+	if _, err := sys.Simulate(nil, []float64{0, 0.5}, mat.NewVecDense(1, nil)); err == nil {
+		t.Errorf("A simulation with the wrong time spacing succeeded.")
+	}
+}
@@ -0,0 +1,174 @@
+/*
+   norms_test.go
+   Description:
+	   Tests for the system norms defined in norms.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestNorms_Norm1
+Description:
+
+	Computes the norms of 1 / (s + 1): the H2 norm is sqrt(1/2) and the H-infinity norm is 1 (at w = 0).
+*/
+func TestNorms_Norm1(t *testing.T) {
+	// Constants
+	sys := getTestFirstOrderSystem()
+
+	// Algorithm
+	h2, _, err := goControl.Norm(sys, "2")
+	if err != nil {
+		t.Errorf("There was an error computing the H2 norm: %v", err)
+	}
+	if math.Abs(h2-math.Sqrt(0.5)) > 1e-12 {
+		t.Errorf("The H2 norm is %v; want %v", h2, math.Sqrt(0.5))
+	}
+
+	hinf, w, err := goControl.Norm(sys, "inf")
+	if err != nil {
+		t.Errorf("There was an error computing the H-infinity norm: %v", err)
+	}
+	if (math.Abs(hinf-1) > 1e-9) || (w != 0) {
+		t.Errorf("The H-infinity norm is %v at %v; want 1 at 0", hinf, w)
+	}
+}
+
+/*
+TestNorms_Norm2
+Description:
+
+	Computes the H-infinity norm of the lightly damped model 1 / (s^2 + 2 zeta s + 1), whose peak gain
+	1 / (2 zeta sqrt(1 - zeta^2)) is reached at w = sqrt(1 - 2 zeta^2).
+*/
+func TestNorms_Norm2(t *testing.T) {
+	// Constants
+	zeta := 0.05
+	tf, _ := goControl.GetTransferFunction([]float64{1}, []float64{1, 2 * zeta, 1})
+	sys, _ := goControl.Tf2ss(tf)
+
+	// Algorithm
+	hinf, w, err := goControl.Norm(sys, "inf")
+	if err != nil {
+		t.Errorf("There was an error computing the H-infinity norm: %v", err)
+	}
+
+	expected := 1 / (2 * zeta * math.Sqrt(1-zeta*zeta))
+	if math.Abs(hinf-expected) > 1e-8*expected {
+		t.Errorf("The H-infinity norm is %v; want %v", hinf, expected)
+	}
+	if math.Abs(w-math.Sqrt(1-2*zeta*zeta)) > 1e-4 {
+		t.Errorf("The peak frequency is %v; want %v", w, math.Sqrt(1-2*zeta*zeta))
+	}
+}
+
+/*
+TestNorms_Norm3
+Description:
+
+	Computes the norms of the discrete-time model x+ = 0.5 x + u, y = x (the impulse response is 0.5^(k-1) for k >= 1):
+	the H2 norm is sqrt(4/3) and the H-infinity norm is 2 (at w = 0). Also verifies the H-infinity norm of a MIMO
+	discrete-time model against a dense frequency grid.
+*/
+func TestNorms_Norm3(t *testing.T) {
+	// Constants
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(1, 1, []float64{0.5}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+		0.1,
+	)
+	sysMIMO, _ := goControl.C2d(getTestSecondOrderSystem(), 0.2, goControl.ZeroOrderHold)
+
+	// Algorithm
+	h2, _, err := goControl.Norm(sys, "2")
+	if err != nil {
+		t.Errorf("There was an error computing the H2 norm: %v", err)
+	}
+	if math.Abs(h2-math.Sqrt(4.0/3.0)) > 1e-12 {
+		t.Errorf("The H2 norm is %v; want %v", h2, math.Sqrt(4.0/3.0))
+	}
+
+	hinf, w, err := goControl.Norm(sys, "inf")
+	if err != nil {
+		t.Errorf("There was an error computing the H-infinity norm: %v", err)
+	}
+	if (math.Abs(hinf-2) > 1e-9) || (math.Abs(w) > 1e-9) {
+		t.Errorf("The H-infinity norm is %v at %v; want 2 at 0", hinf, w)
+	}
+
+	hinf, w, err = goControl.Norm(sysMIMO, "inf")
+	if err != nil {
+		t.Errorf("There was an error computing the H-infinity norm: %v", err)
+	}
+	grid := make([]float64, 20001)
+	for k := range grid {
+		grid[k] = math.Pi / 0.2 * float64(k) / float64(len(grid)-1)
+	}
+	responses, _ := goControl.FreqResp(sysMIMO, grid)
+	peak := 0.0
+	for _, H := range responses {
+		var svd mat.SVD
+		embedding := mat.NewDense(2, 4, nil)
+		for j := 0; j < 2; j++ {
+			embedding.Set(0, j, real(H.At(0, j)))
+			embedding.Set(0, 2+j, -imag(H.At(0, j)))
+			embedding.Set(1, j, imag(H.At(0, j)))
+			embedding.Set(1, 2+j, real(H.At(0, j)))
+		}
+		svd.Factorize(embedding, mat.SVDNone)
+		peak = math.Max(peak, svd.Values(nil)[0])
+	}
+	if (hinf < peak-1e-9) || (hinf > peak*(1+1e-4)) {
+		t.Errorf("The H-infinity norm is %v; the peak on a dense grid is %v", hinf, peak)
+	}
+	if (w < 0) || (w > math.Pi/0.2) {
+		t.Errorf("The peak frequency %v is outside of [0, pi/Ts]", w)
+	}
+}
+
+/*
+TestNorms_Norm4
+Description:
+
+	Verifies that unstable models have infinite norms, that a continuous-time model with a nonzero D has an infinite
+	H2 norm and that unknown norm types are rejected.
+*/
+func TestNorms_Norm4(t *testing.T) {
+	// Constants
+	unstable, _ := goControl.GetStateSpace(
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+	feedthrough, _ := goControl.GetStateSpace(
+		mat.NewDense(1, 1, []float64{-1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{3}),
+	)
+
+	// Algorithm
+	if value, _, _ := goControl.Norm(unstable, "inf"); !math.IsInf(value, 1) {
+		t.Errorf("The H-infinity norm of an unstable model is %v; want +Inf", value)
+	}
+	if value, _, _ := goControl.Norm(feedthrough, "2"); !math.IsInf(value, 1) {
+		t.Errorf("The H2 norm of a model with feedthrough is %v; want +Inf", value)
+	}
+	if value, w, _ := goControl.Norm(feedthrough, "inf"); (math.Abs(value-4) > 1e-9) || (w != 0) {
+		t.Errorf("The H-infinity norm of 3 + 1/(s+1) is %v at %v; want 4 at 0", value, w)
+	}
+	if _, _, err := goControl.Norm(feedthrough, "1"); err == nil {
+		t.Errorf("Expected an error for an unknown norm type; received nil")
+	}
+}
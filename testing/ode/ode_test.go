@@ -0,0 +1,215 @@
+package ode_test
+
+/*
+ode_test.go
+Description:
+	Tests for the integration driver, the dense output and the events defined in ode.go.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/ode"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+decay
+Description:
+
+	The right-hand side of dx/dt = -x.
+*/
+func decay(t float64, x mat.Vector) (*mat.VecDense, error) {
+	dx := mat.VecDenseCopyOf(x)
+	dx.ScaleVec(-1, dx)
+	return dx, nil
+}
+
+/*
+freeFall
+Description:
+
+	The right-hand side of a falling mass, with x = (height, velocity).
+*/
+func freeFall(t float64, x mat.Vector) (*mat.VecDense, error) {
+	return mat.NewVecDense(2, []float64{x.AtVec(1), -9.81}), nil
+}
+
+/*
+TestOde_Solve1
+Description:
+
+	Integrates dx/dt = -x from x(0) = 1 with the default options and compares every step with exp(-t).
+*/
+func TestOde_Solve1(t *testing.T) {
+	// Algorithm
+	sol, err := ode.Solve(decay, []float64{0, 5}, mat.NewVecDense(1, []float64{1}))
+	if err != nil {
+		t.Fatalf("There was an error solving the equation: %v", err)
+	}
+
+	if last := sol.Time.AtVec(sol.Time.Len() - 1); last != 5 {
+		t.Errorf("The integration ended at %v; expected 5", last)
+	}
+	for k := 0; k < sol.Time.Len(); k++ {
+		tk := sol.Time.AtVec(k)
+		if math.Abs(sol.State.At(k, 0)-math.Exp(-tk)) > 1e-4 {
+			t.Errorf("x(%v) = %v; expected %v", tk, sol.State.At(k, 0), math.Exp(-tk))
+		}
+	}
+	if sol.Rejected+sol.Steps < 1 || sol.Evaluations < 7 {
+		t.Errorf("The statistics of the solution are not consistent: %v steps, %v evaluations", sol.Steps, sol.Evaluations)
+	}
+}
+
+/*
+TestOde_Solve2
+Description:
+
+	Requests the solution of dx/dt = -x at given times and verifies the dense output there and between the steps.
+*/
+func TestOde_Solve2(t *testing.T) {
+	// Constants
+	tspan := []float64{0, 0.3, 1.1, 2, 3.7}
+	options := ode.Options{RelTol: 1e-8, AbsTol: 1e-10}
+
+	// Algorithm
+	sol, err := ode.SolveWithOptions(decay, tspan, mat.NewVecDense(1, []float64{1}), options)
+	if err != nil {
+		t.Fatalf("There was an error solving the equation: %v", err)
+	}
+	if sol.Time.Len() != len(tspan) {
+		t.Fatalf("The solution has %v times; expected %v", sol.Time.Len(), len(tspan))
+	}
+	for k, tk := range tspan {
+		if math.Abs(sol.State.At(k, 0)-math.Exp(-tk)) > 1e-6 {
+			t.Errorf("x(%v) = %v; expected %v", tk, sol.State.At(k, 0), math.Exp(-tk))
+		}
+	}
+
+	x, err := sol.At(2.5)
+	if err != nil {
+		t.Fatalf("There was an error evaluating the dense output: %v", err)
+	}
+	if math.Abs(x.AtVec(0)-math.Exp(-2.5)) > 1e-6 {
+		t.Errorf("The dense output gives x(2.5) = %v; expected %v", x.AtVec(0), math.Exp(-2.5))
+	}
+	if _, err := sol.At(4); err == nil {
+		t.Errorf("The dense output was evaluated after the final time.")
+	}
+}
+
+/*
+TestOde_Solve3
+Description:
+
+	Verifies that invalid time spans, initial states and options are rejected.
+*/
+func TestOde_Solve3(t *testing.T) {
+	// Constants
+	x0 := mat.NewVecDense(1, []float64{1})
+
+	// Algorithm
+	if _, err := ode.Solve(decay, []float64{0}, x0); err == nil {
+		t.Errorf("A time span with one time was accepted.")
+	}
+	if _, err := ode.Solve(decay, []float64{1, 0}, x0); err == nil {
+		t.Errorf("A decreasing time span was accepted.")
+	}
+	if _, err := ode.Solve(decay, []float64{0, 1}, nil); err == nil {
+		t.Errorf("A nil initial state was accepted.")
+	}
+	if _, err := ode.SolveWithOptions(decay, []float64{0, 1}, x0, ode.Options{RelTol: -1}); err == nil {
+		t.Errorf("A negative tolerance was accepted.")
+	}
+	if _, err := ode.SolveWithOptions(decay, []float64{0, 1}, x0, ode.Options{Method: ode.Method(7)}); err == nil {
+		t.Errorf("An unknown method was accepted.")
+	}
+}
+
+/*
+TestOde_Events1
+Description:
+
+	Drops a mass from a height of 10 and stops the integration when it hits the ground, at sqrt(20 / 9.81).
+*/
+func TestOde_Events1(t *testing.T) {
+	// Constants
+	ground := ode.Event{
+		Function:  func(t float64, x mat.Vector) float64 { return x.AtVec(0) },
+		Direction: -1,
+		Terminal:  true,
+	}
+	expected := math.Sqrt(20 / 9.81)
+
+	// Algorithm
+	sol, err := ode.SolveWithOptions(freeFall, []float64{0, 5}, mat.NewVecDense(2, []float64{10, 0}), ode.Options{Events: []ode.Event{ground}})
+	if err != nil {
+		t.Fatalf("There was an error solving the equation: %v", err)
+	}
+	if !sol.Terminated {
+		t.Fatalf("The integration was not stopped by the terminal event.")
+	}
+	if len(sol.Events) != 1 {
+		t.Fatalf("There are %v events; expected 1", len(sol.Events))
+	}
+	if math.Abs(sol.Events[0].Time-expected) > 1e-8 {
+		t.Errorf("The mass hits the ground at %v; expected %v", sol.Events[0].Time, expected)
+	}
+	if last := sol.Time.AtVec(sol.Time.Len() - 1); last != sol.Events[0].Time {
+		t.Errorf("The integration ended at %v; expected the event time %v", last, sol.Events[0].Time)
+	}
+	if math.Abs(sol.Events[0].State.AtVec(1)+9.81*expected) > 1e-6 {
+		t.Errorf("The velocity at the ground is %v; expected %v", sol.Events[0].State.AtVec(1), -9.81*expected)
+	}
+}
+
+/*
+TestOde_Events2
+Description:
+
+	Records the zero-crossings of sin(t) along the solution of dx/dt = -x without stopping the integration, and
+	verifies that the direction filters the rising crossings at 2 pi and 4 pi.
+*/
+func TestOde_Events2(t *testing.T) {
+	// Constants
+	sine := func(t float64, x mat.Vector) float64 { return math.Sin(t) }
+	options := ode.Options{
+		Events: []ode.Event{
+			{Function: sine},
+			{Function: sine, Direction: 1},
+		},
+	}
+
+	// Algorithm
+	sol, err := ode.SolveWithOptions(decay, []float64{0.5, 13}, mat.NewVecDense(1, []float64{1}), options)
+	if err != nil {
+		t.Fatalf("There was an error solving the equation: %v", err)
+	}
+	if sol.Terminated {
+		t.Errorf("The integration was stopped by a non terminal event.")
+	}
+
+	var all, rising []float64
+	for _, event := range sol.Events {
+		if event.Index == 0 {
+			all = append(all, event.Time)
+		} else {
+			rising = append(rising, event.Time)
+		}
+	}
+	if len(all) != 4 || len(rising) != 2 {
+		t.Fatalf("Found %v crossings and %v rising crossings; expected 4 and 2", len(all), len(rising))
+	}
+	for k, tk := range all {
+		if math.Abs(tk-float64(k+1)*math.Pi) > 1e-8 {
+			t.Errorf("Crossing %v at %v; expected %v", k, tk, float64(k+1)*math.Pi)
+		}
+	}
+	for k, tk := range rising {
+		if math.Abs(tk-float64(2*(k+1))*math.Pi) > 1e-8 {
+			t.Errorf("Rising crossing %v at %v; expected %v", k, tk, float64(2*(k+1))*math.Pi)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package ode_test
+
+/*
+rosenbrock_test.go
+Description:
+	Tests for the Rosenbrock method defined in rosenbrock.go.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/ode"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+stiffTracking
+Description:
+
+	The right-hand side of the stiff equation dx/dt = -1000 (x - cos t), whose solution quickly reaches
+	(1000^2 cos t + 1000 sin t) / (1000^2 + 1).
+*/
+func stiffTracking(t float64, x mat.Vector) (*mat.VecDense, error) {
+	return mat.NewVecDense(1, []float64{-1000 * (x.AtVec(0) - math.Cos(t))}), nil
+}
+
+/*
+TestRosenbrock_Solve1
+Description:
+
+	Integrates a stiff equation with the Rosenbrock and Dormand-Prince methods, and verifies that both are accurate
+	and that the Rosenbrock method takes far fewer steps.
+*/
+func TestRosenbrock_Solve1(t *testing.T) {
+	// Constants
+	tFinal := 10.0
+	expected := (1e6*math.Cos(tFinal) + 1e3*math.Sin(tFinal)) / (1e6 + 1)
+	x0 := mat.NewVecDense(1, []float64{0})
+
+	// Algorithm
+	steps := map[ode.Method]int{}
+	for _, method := range []ode.Method{ode.Rosenbrock, ode.DormandPrince} {
+		sol, err := ode.SolveWithOptions(stiffTracking, []float64{0, tFinal}, x0, ode.Options{Method: method})
+		if err != nil {
+			t.Fatalf("There was an error solving the equation with method %v: %v", method, err)
+		}
+		last := sol.Time.Len() - 1
+		if math.Abs(sol.State.At(last, 0)-expected) > 1e-3 {
+			t.Errorf("Method %v gives x(%v) = %v; expected %v", method, tFinal, sol.State.At(last, 0), expected)
+		}
+		steps[method] = sol.Steps + sol.Rejected
+	}
+
+	if 5*steps[ode.Rosenbrock] > steps[ode.DormandPrince] {
+		t.Errorf("The Rosenbrock method took %v steps and the Dormand-Prince method %v; expected far fewer for Rosenbrock", steps[ode.Rosenbrock], steps[ode.DormandPrince])
+	}
+}
+
+/*
+TestRosenbrock_Solve2
+Description:
+
+	Integrates the Van der Pol oscillator with mu = 100 with an analytic Jacobian and with finite differences, and
+	verifies that both solutions agree.
+*/
+func TestRosenbrock_Solve2(t *testing.T) {
+	// Constants
+	mu := 100.0
+	f := func(t float64, x mat.Vector) (*mat.VecDense, error) {
+		x1, x2 := x.AtVec(0), x.AtVec(1)
+		return mat.NewVecDense(2, []float64{x2, mu*(1-x1*x1)*x2 - x1}), nil
+	}
+	jacobian := func(t float64, x mat.Vector) (*mat.Dense, error) {
+		x1, x2 := x.AtVec(0), x.AtVec(1)
+		return mat.NewDense(2, 2, []float64{0, 1, -2*mu*x1*x2 - 1, mu * (1 - x1*x1)}), nil
+	}
+	tspan := []float64{0, 50, 100}
+	x0 := mat.NewVecDense(2, []float64{2, 0})
+
+	// Algorithm
+	analytic, err := ode.SolveWithOptions(f, tspan, x0, ode.Options{Method: ode.Rosenbrock, Jacobian: jacobian, RelTol: 1e-6, AbsTol: 1e-8})
+	if err != nil {
+		t.Fatalf("There was an error solving the equation with the analytic Jacobian: %v", err)
+	}
+	numeric, err := ode.SolveWithOptions(f, tspan, x0, ode.Options{Method: ode.Rosenbrock, RelTol: 1e-6, AbsTol: 1e-8})
+	if err != nil {
+		t.Fatalf("There was an error solving the equation with finite differences: %v", err)
+	}
+
+	for k := range tspan {
+		if math.Abs(analytic.State.At(k, 0)-numeric.State.At(k, 0)) > 1e-3 {
+			t.Errorf("x1(%v) = %v with the analytic Jacobian and %v with finite differences", tspan[k], analytic.State.At(k, 0), numeric.State.At(k, 0))
+		}
+	}
+	if numeric.Evaluations <= analytic.Evaluations {
+		t.Errorf("The finite differences used %v evaluations; expected more than the %v of the analytic Jacobian", numeric.Evaluations, analytic.Evaluations)
+	}
+}
@@ -0,0 +1,117 @@
+package ode_test
+
+/*
+runge_kutta_test.go
+Description:
+	Tests for the explicit Runge-Kutta methods defined in runge_kutta.go.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/ode"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+oscillator
+Description:
+
+	The right-hand side of the harmonic oscillator dx1/dt = x2, dx2/dt = -x1.
+*/
+func oscillator(t float64, x mat.Vector) (*mat.VecDense, error) {
+	return mat.NewVecDense(2, []float64{x.AtVec(1), -x.AtVec(0)}), nil
+}
+
+/*
+TestRungeKutta_RK41
+Description:
+
+	Integrates the harmonic oscillator over one period with RK4 and a fixed step of 0.01, and verifies the fixed
+	number of steps and the fourth-order accuracy of the final state.
+*/
+func TestRungeKutta_RK41(t *testing.T) {
+	// Constants
+	period := 2 * math.Pi
+	options := ode.Options{Method: ode.RK4, Step: period / 628}
+
+	// Algorithm
+	sol, err := ode.SolveWithOptions(oscillator, []float64{0, period}, mat.NewVecDense(2, []float64{1, 0}), options)
+	if err != nil {
+		t.Fatalf("There was an error solving the equation: %v", err)
+	}
+	if sol.Steps != 628 || sol.Rejected != 0 {
+		t.Errorf("RK4 took %v steps and rejected %v; expected 628 and 0", sol.Steps, sol.Rejected)
+	}
+
+	last := sol.Time.Len() - 1
+	if e := math.Hypot(sol.State.At(last, 0)-1, sol.State.At(last, 1)); e > 1e-8 {
+		t.Errorf("The error after one period is %v; expected less than 1e-8", e)
+	}
+}
+
+/*
+TestRungeKutta_RK4Step1
+Description:
+
+	Verifies that one step of RK4 for dx/dt = x reproduces the Taylor polynomial of degree 4 of e^h, and that the
+	returned derivative is f at the new state.
+*/
+func TestRungeKutta_RK4Step1(t *testing.T) {
+	// Constants
+	h := 0.1
+	growth := func(t float64, x mat.Vector) (*mat.VecDense, error) {
+		return mat.VecDenseCopyOf(x), nil
+	}
+	x := mat.NewVecDense(1, []float64{1})
+
+	// Algorithm
+	xNew, fNew, err := ode.RK4Step(growth, 0, h, x, mat.VecDenseCopyOf(x))
+	if err != nil {
+		t.Fatalf("There was an error taking the step: %v", err)
+	}
+	expected := 1 + h + h*h/2 + h*h*h/6 + h*h*h*h/24
+	if math.Abs(xNew.AtVec(0)-expected) > 1e-15 {
+		t.Errorf("The step reached %v; expected %v", xNew.AtVec(0), expected)
+	}
+	if fNew.AtVec(0) != xNew.AtVec(0) {
+		t.Errorf("The returned derivative is %v; expected f(x) = %v", fNew.AtVec(0), xNew.AtVec(0))
+	}
+}
+
+/*
+TestRungeKutta_DormandPrince1
+Description:
+
+	Integrates the harmonic oscillator over ten periods with the Dormand-Prince method at two tolerances, and verifies
+	that the tighter tolerance gives a smaller error with more steps.
+*/
+func TestRungeKutta_DormandPrince1(t *testing.T) {
+	// Constants
+	tFinal := 20 * math.Pi
+	x0 := mat.NewVecDense(2, []float64{1, 0})
+
+	// Algorithm
+	var finalErrors [2]float64
+	var steps [2]int
+	for i, tolerance := range []float64{1e-4, 1e-9} {
+		sol, err := ode.SolveWithOptions(oscillator, []float64{0, tFinal}, x0, ode.Options{RelTol: tolerance, AbsTol: tolerance})
+		if err != nil {
+			t.Fatalf("There was an error solving the equation: %v", err)
+		}
+		last := sol.Time.Len() - 1
+		finalErrors[i] = math.Hypot(sol.State.At(last, 0)-1, sol.State.At(last, 1))
+		steps[i] = sol.Steps
+	}
+
+	if finalErrors[0] > 1e-2 {
+		t.Errorf("The error with tolerance 1e-4 is %v; expected less than 1e-2", finalErrors[0])
+	}
+	if finalErrors[1] > 1e-6 {
+		t.Errorf("The error with tolerance 1e-9 is %v; expected less than 1e-6", finalErrors[1])
+	}
+	if steps[1] <= steps[0] {
+		t.Errorf("The tighter tolerance took %v steps; expected more than %v", steps[1], steps[0])
+	}
+}
@@ -0,0 +1,110 @@
+package ode_test
+
+/*
+sampled_test.go
+Description:
+	Tests for the sampled-data closed loops defined in sampled.go.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"github.com/kwesiRutledge/goControl/ode"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+firstOrderPlant
+Description:
+
+	The right-hand side of the plant dx/dt = -x + u.
+*/
+func firstOrderPlant(t float64, x, u mat.Vector) (*mat.VecDense, error) {
+	return mat.NewVecDense(1, []float64{-x.AtVec(0) + u.AtVec(0)}), nil
+}
+
+/*
+TestSampled_SimulateSampled1
+Description:
+
+	Controls dx/dt = -x + u with a discrete PI controller sampled every 0.1 s and verifies that the state reaches the
+	setpoint, that the inputs are held between samples and that the controller is called once per sample.
+*/
+func TestSampled_SimulateSampled1(t *testing.T) {
+	// Constants
+	Ts := 0.1
+	pid, err := goControl.GetPID(2, 2, 0, 0, Ts)
+	if err != nil {
+		t.Fatalf("There was an error creating the controller: %v", err)
+	}
+	law := func(t float64, x mat.Vector) (*mat.VecDense, error) {
+		return mat.NewVecDense(1, []float64{pid.Update(1, x.AtVec(0))}), nil
+	}
+
+	// Algorithm
+	sol, err := ode.SimulateSampled(firstOrderPlant, law, Ts, 0, 10, mat.NewVecDense(1, nil), ode.Options{})
+	if err != nil {
+		t.Fatalf("There was an error simulating the loop: %v", err)
+	}
+
+	if sol.SampleTimes.Len() != 100 {
+		t.Errorf("The control law was called %v times; expected 100", sol.SampleTimes.Len())
+	}
+	if rows, _ := sol.Inputs.Dims(); rows != sol.SampleTimes.Len() {
+		t.Errorf("There are %v inputs for %v sample times", rows, sol.SampleTimes.Len())
+	}
+	if sol.Inputs.At(0, 0) != 2 {
+		t.Errorf("The first input is %v; expected 2", sol.Inputs.At(0, 0))
+	}
+
+	last := sol.Time.Len() - 1
+	if sol.Time.AtVec(last) != 10 {
+		t.Errorf("The simulation ended at %v; expected 10", sol.Time.AtVec(last))
+	}
+	if math.Abs(sol.State.At(last, 0)-1) > 1e-3 {
+		t.Errorf("The state at the final time is %v; expected the setpoint 1", sol.State.At(last, 0))
+	}
+
+	// Within the first interval the input is 2, so x(t) = 2 (1 - exp(-t))
+	x, err := sol.At(0.05)
+	if err != nil {
+		t.Fatalf("There was an error evaluating the dense output: %v", err)
+	}
+	if math.Abs(x.AtVec(0)-2*(1-math.Exp(-0.05))) > 1e-6 {
+		t.Errorf("x(0.05) = %v; expected %v", x.AtVec(0), 2*(1-math.Exp(-0.05)))
+	}
+}
+
+/*
+TestSampled_SimulateSampled2
+Description:
+
+	Verifies that a terminal event stops the whole closed loop simulation.
+*/
+func TestSampled_SimulateSampled2(t *testing.T) {
+	// Constants
+	law := func(t float64, x mat.Vector) (*mat.VecDense, error) {
+		return mat.NewVecDense(1, []float64{2}), nil
+	}
+	halfway := ode.Event{
+		Function: func(t float64, x mat.Vector) float64 { return x.AtVec(0) - 1 },
+		Terminal: true,
+	}
+
+	// Algorithm
+	sol, err := ode.SimulateSampled(firstOrderPlant, law, 0.1, 0, 10, mat.NewVecDense(1, nil), ode.Options{Events: []ode.Event{halfway}})
+	if err != nil {
+		t.Fatalf("There was an error simulating the loop: %v", err)
+	}
+	if !sol.Terminated || len(sol.Events) != 1 {
+		t.Fatalf("The simulation was not stopped by the terminal event.")
+	}
+	if math.Abs(sol.Events[0].Time-math.Ln2) > 1e-6 {
+		t.Errorf("The state reaches 1 at %v; expected ln 2 = %v", sol.Events[0].Time, math.Ln2)
+	}
+	if sol.SampleTimes.Len() != 7 {
+		t.Errorf("The control law was called %v times; expected 7", sol.SampleTimes.Len())
+	}
+}
@@ -0,0 +1,208 @@
+/*
+   pid_test.go
+   Description:
+	   Tests for the PID controller defined in pid.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+)
+
+/*
+TestPID_Update1
+Description:
+
+	Verifies the proportional, integral and filtered derivative terms of a parallel PID controller over a few
+	samples against values computed by hand.
+*/
+func TestPID_Update1(t *testing.T) {
+	// Constants
+	pid, err := goControl.GetPID(2, 1, 0.5, 0.1, 0.1)
+	if err != nil {
+		t.Fatalf("There was an error creating the controller: %v", err)
+	}
+
+	// Algorithm
+	// Sample 0: e = 1, no derivative kick, integral 0
+	if u := pid.Update(1, 0); math.Abs(u-2) > 1e-12 {
+		t.Errorf("u_0 = %v; want 2", u)
+	}
+	// Sample 1: e = 0.5, I = 0.1, D = 0.5 (-0.5) / 0.2 = -1.25
+	if u := pid.Update(1, 0.5); math.Abs(u-(1+0.1-1.25)) > 1e-12 {
+		t.Errorf("u_1 = %v; want %v", u, 1+0.1-1.25)
+	}
+	// Sample 2: e = 0.5, I = 0.15, D = 0.5 D_1 = -0.625
+	if u := pid.Update(1, 0.5); math.Abs(u-(1+0.15-0.625)) > 1e-12 {
+		t.Errorf("u_2 = %v; want %v", u, 1+0.15-0.625)
+	}
+}
+
+/*
+TestPID_Update2
+Description:
+
+	Verifies that the setpoint weights remove the proportional and derivative kicks of a setpoint step.
+*/
+func TestPID_Update2(t *testing.T) {
+	// Constants
+	pid, _ := goControl.GetPID(2, 0, 1, 0, 0.1)
+	pid.B, pid.C = 0, 0
+
+	// Algorithm
+	pid.Update(0, 0)
+	if u := pid.Update(1, 0); u != 0 {
+		t.Errorf("The setpoint step gives u = %v; want 0 with zero setpoint weights", u)
+	}
+	if u := pid.Update(1, 0.1); math.Abs(u-(-0.2-1)) > 1e-12 {
+		t.Errorf("u = %v; want %v", u, -0.2-1)
+	}
+}
+
+/*
+TestPID_AntiWindup1
+Description:
+
+	Saturates a PI controller for a long time and verifies that with anti-windup the output leaves the saturation
+	as soon as the error changes sign, while without anti-windup it stays saturated.
+*/
+func TestPID_AntiWindup1(t *testing.T) {
+	for _, method := range []goControl.AntiWindup{goControl.NoAntiWindup, goControl.ClampingAntiWindup, goControl.BackCalculationAntiWindup} {
+		// Constants
+		pid, _ := goControl.GetPID(1, 1, 0, 0, 0.1)
+		pid.UMin, pid.UMax = -1, 1
+		pid.AntiWindup = method
+
+		// Algorithm
+		for k := 0; k < 200; k++ {
+			if u := pid.Update(2, 0); u != 1 {
+				t.Errorf("Method %v: the output %v is not saturated at 1.", method, u)
+			}
+		}
+		u := pid.Update(0, 0.5)
+
+		if (method == goControl.NoAntiWindup) && (u != 1) {
+			t.Errorf("Without anti-windup the output should remain saturated; received %v.", u)
+		}
+		if (method != goControl.NoAntiWindup) && !(u < 1) {
+			t.Errorf("Method %v: the output should leave the saturation; received %v.", method, u)
+		}
+	}
+}
+
+/*
+TestPID_Manual1
+Description:
+
+	Verifies that the switch from manual to automatic mode is bumpless and that the output limits apply in manual
+	mode.
+*/
+func TestPID_Manual1(t *testing.T) {
+	// Constants
+	pid, _ := goControl.GetPID(1.5, 0.8, 0.2, 0.05, 0.1)
+	pid.UMin, pid.UMax = -5, 5
+
+	// Algorithm
+	pid.SetManual(3)
+	for k := 0; k < 10; k++ {
+		if u := pid.Update(1, 0.2); u != 3 {
+			t.Errorf("The manual output is %v; want 3", u)
+		}
+	}
+	if !pid.IsManual() {
+		t.Errorf("The controller should be in manual mode.")
+	}
+
+	pid.SetAutomatic()
+	if u := pid.Update(1, 0.2); math.Abs(u-3) > 0.8*0.1*0.8+1e-12 {
+		t.Errorf("The first automatic output is %v; want close to 3", u)
+	}
+
+	pid.SetManual(10)
+	if u := pid.Update(1, 0.2); u != 5 {
+		t.Errorf("The manual output is %v; want the limit 5", u)
+	}
+}
+
+/*
+TestPID_GetIdealPID1
+Description:
+
+	Verifies the conversion of the ideal form to the parallel form and back.
+*/
+func TestPID_GetIdealPID1(t *testing.T) {
+	// Constants
+	pid, err := goControl.GetIdealPID(2, 4, 0.5, 10, 0.01)
+	if err != nil {
+		t.Fatalf("There was an error creating the controller: %v", err)
+	}
+
+	// Algorithm
+	if (pid.Kp != 2) || (pid.Ki != 0.5) || (pid.Kd != 1) || (math.Abs(pid.Tf-0.05) > 1e-15) || (pid.Form != goControl.IdealForm) {
+		t.Errorf("Unexpected parallel gains Kp = %v, Ki = %v, Kd = %v, Tf = %v", pid.Kp, pid.Ki, pid.Kd, pid.Tf)
+	}
+
+	Kp, Ti, Td, N, err := pid.IdealGains()
+	if err != nil {
+		t.Errorf("There was an error computing the ideal gains: %v", err)
+	}
+	if (Kp != 2) || (math.Abs(Ti-4) > 1e-12) || (math.Abs(Td-0.5) > 1e-12) || (math.Abs(N-10) > 1e-9) {
+		t.Errorf("Unexpected ideal gains Kp = %v, Ti = %v, Td = %v, N = %v", Kp, Ti, Td, N)
+	}
+
+	if _, err := goControl.GetIdealPID(1, 0, 0, 0, 0.1); err == nil {
+		t.Errorf("Expected an error for a zero integral time.")
+	}
+	if _, err := goControl.GetPID(1, 0, 0, 0, 0); err == nil {
+		t.Errorf("Expected an error for a zero sample time.")
+	}
+}
+
+/*
+TestPID_TransferFunction1
+Description:
+
+	Verifies the transfer function of a PID controller with derivative filter at a point.
+*/
+func TestPID_TransferFunction1(t *testing.T) {
+	// Constants
+	pid, _ := goControl.GetPID(2, 3, 0.5, 0.1, 0.01)
+	s := complex(0.3, 1.7)
+
+	// Algorithm
+	C, err := pid.TransferFunction()
+	if err != nil {
+		t.Fatalf("There was an error computing the transfer function: %v", err)
+	}
+
+	expected := 2 + 3/s + 0.5*s/(0.1*s+1)
+	if got := C.Evaluate(s).At(0, 0); math.Abs(real(got-expected))+math.Abs(imag(got-expected)) > 1e-12 {
+		t.Errorf("C(s) = %v; want %v", got, expected)
+	}
+}
+
+/*
+TestPID_Update3
+Description:
+
+	Verifies that Update does not allocate.
+*/
+func TestPID_Update3(t *testing.T) {
+	// Constants
+	pid, _ := goControl.GetPID(2, 1, 0.5, 0.1, 0.1)
+	pid.UMin, pid.UMax = -1, 1
+	pid.AntiWindup = goControl.BackCalculationAntiWindup
+
+	// Algorithm
+	y := 0.0
+	allocations := testing.AllocsPerRun(100, func() {
+		y += 0.01 * pid.Update(1, y)
+	})
+	if allocations != 0 {
+		t.Errorf("Update allocated %v times per call.", allocations)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+   pid_tuning_test.go
+   Description:
+	   Tests for the PID tuning rules defined in pid_tuning.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+)
+
+/*
+getTestThirdOrderPlant
+Description:
+
+	Creates the plant 1 / (s + 1)^3, whose ultimate gain is 8 at the frequency sqrt(3).
+*/
+func getTestThirdOrderPlant() goControl.TransferFunction {
+	G, _ := goControl.GetTransferFunction([]float64{1}, []float64{1, 3, 3, 1})
+	return G
+}
+
+/*
+TestPIDTuning_ZieglerNichols1
+Description:
+
+	Verifies the ultimate gain and period of 1 / (s + 1)^3 and the Ziegler-Nichols PID gains.
+*/
+func TestPIDTuning_ZieglerNichols1(t *testing.T) {
+	// Constants
+	sys, _ := goControl.Tf2ss(getTestThirdOrderPlant())
+
+	// Algorithm
+	Ku, Pu, err := goControl.UltimateGain(sys)
+	if err != nil {
+		t.Fatalf("There was an error computing the ultimate gain: %v", err)
+	}
+	if (math.Abs(Ku-8) > 1e-6) || (math.Abs(Pu-2*math.Pi/math.Sqrt(3)) > 1e-6) {
+		t.Errorf("Ku = %v, Pu = %v; want 8, %v", Ku, Pu, 2*math.Pi/math.Sqrt(3))
+	}
+
+	pid, err := goControl.ZieglerNichols(Ku, Pu, goControl.PIDTypePID, 0.01)
+	if err != nil {
+		t.Fatalf("There was an error tuning the controller: %v", err)
+	}
+	Kp, Ti, Td, _, _ := pid.IdealGains()
+	if (math.Abs(Kp-4.8) > 1e-5) || (math.Abs(Ti-Pu/2) > 1e-9) || (math.Abs(Td-Pu/8) > 1e-9) {
+		t.Errorf("Kp = %v, Ti = %v, Td = %v; want 4.8, %v, %v", Kp, Ti, Td, Pu/2, Pu/8)
+	}
+
+	if _, _, err := goControl.UltimateGain(getTestFirstOrderSystem()); err == nil {
+		t.Errorf("Expected an error for a model without phase crossover.")
+	}
+}
+
+/*
+TestPIDTuning_SIMC1
+Description:
+
+	Verifies the SIMC PI and PID gains of first and second order models with delay.
+*/
+func TestPIDTuning_SIMC1(t *testing.T) {
+	// Constants
+	firstOrder := goControl.SIMCModel{Gain: 1, TimeConstant: 10, Delay: 1}
+	secondOrder := goControl.SIMCModel{Gain: 2, TimeConstant: 10, SecondTimeConstant: 2, Delay: 1}
+
+	// Algorithm
+	pi, err := goControl.SIMC(firstOrder, 0, goControl.PIDTypePI, 0.1)
+	if err != nil {
+		t.Fatalf("There was an error tuning the controller: %v", err)
+	}
+	if (math.Abs(pi.Kp-5) > 1e-12) || (math.Abs(pi.Ki-5.0/8) > 1e-12) || (pi.Kd != 0) {
+		t.Errorf("Kp = %v, Ki = %v, Kd = %v; want 5, 0.625, 0", pi.Kp, pi.Ki, pi.Kd)
+	}
+
+	pid, err := goControl.SIMC(secondOrder, 1, goControl.PIDTypePID, 0.1)
+	if err != nil {
+		t.Fatalf("There was an error tuning the controller: %v", err)
+	}
+	Kc, tauI, tauD := 10.0/(2*2), 8.0, 2.0
+	if (math.Abs(pid.Kp-Kc*(1+tauD/tauI)) > 1e-12) || (math.Abs(pid.Ki-Kc/tauI) > 1e-12) || (math.Abs(pid.Kd-Kc*tauD) > 1e-12) {
+		t.Errorf("Kp = %v, Ki = %v, Kd = %v; want %v, %v, %v", pid.Kp, pid.Ki, pid.Kd, Kc*(1+tauD/tauI), Kc/tauI, Kc*tauD)
+	}
+
+	if _, err := goControl.SIMC(goControl.SIMCModel{Gain: 1, TimeConstant: 1}, 0, goControl.PIDTypePI, 0.1); err == nil {
+		t.Errorf("Expected an error for a model without delay and no closed-loop time constant.")
+	}
+}
+
+/*
+TestPIDTuning_PIDTune1
+Description:
+
+	Tunes PI, PID and PIDF controllers for 1 / (s + 1)^3 and verifies that the loops are stable and reach the
+	target phase margin.
+*/
+func TestPIDTuning_PIDTune1(t *testing.T) {
+	// Constants
+	G := getTestThirdOrderPlant()
+
+	// Algorithm
+	for _, pidType := range []goControl.PIDType{goControl.PIDTypePI, goControl.PIDTypePID, goControl.PIDTypePIDF} {
+		pid, info, err := goControl.PIDTune(G, pidType, 0.01)
+		if err != nil {
+			t.Fatalf("Type %v: there was an error tuning the controller: %v", pidType, err)
+		}
+		if !info.Stable || (info.PhaseMargin < 59.5) || (info.PeakSensitivity > 2) {
+			t.Errorf("Type %v: unexpected loop %+v", pidType, info)
+		}
+		if !(pid.Kp > 0) || !(pid.Ki > 0) {
+			t.Errorf("Type %v: unexpected gains Kp = %v, Ki = %v", pidType, pid.Kp, pid.Ki)
+		}
+	}
+}
+
+/*
+TestPIDTuning_PIDTune2
+Description:
+
+	Tunes a PIDF controller for 1 / (s (s + 1)) at a given crossover frequency and verifies that the loop has unit
+	gain and a 45 degree phase margin at that frequency.
+*/
+func TestPIDTuning_PIDTune2(t *testing.T) {
+	// Constants
+	G, _ := goControl.GetTransferFunction([]float64{1}, []float64{1, 1, 0})
+	wc := 2.0
+
+	// Algorithm
+	pid, info, err := goControl.PIDTuneWithOptions(G, goControl.PIDTypePIDF, 0.01, goControl.PIDTuneOptions{
+		CrossoverFrequency: wc,
+		PhaseMargin:        45,
+	})
+	if err != nil {
+		t.Fatalf("There was an error tuning the controller: %v", err)
+	}
+
+	C, _ := pid.TransferFunction()
+	L := C.Evaluate(complex(0, wc)).At(0, 0) * G.Evaluate(complex(0, wc)).At(0, 0)
+	if math.Abs(cmplx.Abs(L)-1) > 1e-8 {
+		t.Errorf("|L(j wc)| = %v; want 1", cmplx.Abs(L))
+	}
+	if pm := cmplx.Phase(L)*180/math.Pi + 180; math.Abs(pm-45) > 1e-6 {
+		t.Errorf("The phase margin at wc is %v; want 45", pm)
+	}
+	if !info.Stable || math.Abs(info.CrossoverFrequency-wc) > 1e-6 {
+		t.Errorf("Unexpected loop %+v", info)
+	}
+
+	if _, _, err := goControl.PIDTuneWithOptions(G, goControl.PIDTypePI, 0.01, goControl.PIDTuneOptions{CrossoverFrequency: wc}); err == nil {
+		t.Errorf("Expected an error: a PI controller cannot add the phase needed at wc.")
+	}
+}
@@ -0,0 +1,164 @@
+/*
+   pole_placement_test.go
+   Description:
+	   Tests for the pole placement functions defined in pole_placement.go.
+*/
+
+package testing
+
+import (
+	"math/cmplx"
+	"sort"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestPolePlacement_Acker1
+Description:
+
+	Places the poles of a double integrator at -1 and -2. The closed-loop characteristic polynomial
+	s^2 + 3 s + 2 requires K = [2, 3].
+*/
+func TestPolePlacement_Acker1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{0, 1, 0, 0})
+	B := mat.NewDense(2, 1, []float64{0, 1})
+	poles := []complex128{-1, -2}
+
+	// Algorithm
+	K, err := goControl.Acker(A, B, poles)
+	if err != nil {
+		t.Errorf("There was an error placing the poles: %v", err)
+	}
+	if !mat.EqualApprox(K, mat.NewDense(1, 2, []float64{2, 3}), 1e-10) {
+		t.Errorf("K = %v; want [2, 3]", mat.Formatted(K))
+	}
+
+	K2, err := goControl.Place(A, B, poles)
+	if err != nil {
+		t.Errorf("There was an error placing the poles: %v", err)
+	}
+	if !mat.EqualApprox(K2, mat.NewDense(1, 2, []float64{2, 3}), 1e-10) {
+		t.Errorf("K = %v; want [2, 3]", mat.Formatted(K2))
+	}
+}
+
+/*
+TestPolePlacement_Place1
+Description:
+
+	Places complex and repeated poles for a model with two inputs, and verifies the eigenvalues of A - B K.
+*/
+func TestPolePlacement_Place1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(4, 4, []float64{
+		0, 1, 0, 0,
+		2, -1, 0.5, 0,
+		0, 0, 0, 1,
+		0.3, 0, -4, 0.2,
+	})
+	B := mat.NewDense(4, 2, []float64{
+		0, 0,
+		1, 0,
+		0, 0,
+		0.2, 1,
+	})
+	poles := []complex128{complex(-1, 2), complex(-1, -2), -3, -3}
+
+	// Algorithm
+	K, err := goControl.Place(A, B, poles)
+	if err != nil {
+		t.Errorf("There was an error placing the poles: %v", err)
+	}
+
+	checkClosedLoopEigenvalues(t, A, B, K, poles, 1e-6)
+}
+
+/*
+TestPolePlacement_Place2
+Description:
+
+	Designs an observer gain L by duality (L^T = Place(A^T, C^T, poles)) and verifies the eigenvalues of A - L C.
+*/
+func TestPolePlacement_Place2(t *testing.T) {
+	// Constants
+	sys := getTestSecondOrderSystem()
+	poles := []complex128{complex(-5, 1), complex(-5, -1)}
+
+	// Algorithm
+	Lt, err := goControl.Place(sys.A.T(), sys.C.T(), poles)
+	if err != nil {
+		t.Errorf("There was an error placing the observer poles: %v", err)
+	}
+
+	checkClosedLoopEigenvalues(t, sys.A.T(), sys.C.T(), Lt, poles, 1e-8)
+}
+
+/*
+TestPolePlacement_Place3
+Description:
+
+	Verifies that Place rejects uncontrollable models, poles that are repeated more than rank(B) times and
+	complex poles without their conjugates.
+*/
+func TestPolePlacement_Place3(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{1, 0, 0, 2})
+	BUncontrollable := mat.NewDense(2, 1, []float64{1, 0})
+	B := mat.NewDense(2, 1, []float64{1, 1})
+
+	// Algorithm
+	if _, err := goControl.Place(A, BUncontrollable, []complex128{-1, -2}); err == nil {
+		t.Errorf("Expected an error for an uncontrollable model; received nil")
+	}
+	if _, err := goControl.Place(A, B, []complex128{-1, -1}); err == nil {
+		t.Errorf("Expected an error for a pole repeated more than rank(B) times; received nil")
+	}
+	if _, err := goControl.Place(A, B, []complex128{complex(-1, 1), complex(-2, 1)}); err == nil {
+		t.Errorf("Expected an error for poles that are not conjugate symmetric; received nil")
+	}
+	if _, err := goControl.Acker(A, BUncontrollable, []complex128{-1, -2}); err == nil {
+		t.Errorf("Expected an error from Acker for an uncontrollable model; received nil")
+	}
+}
+
+/*
+checkClosedLoopEigenvalues
+Description:
+
+	Verifies that the eigenvalues of A - B K match the given poles.
+*/
+func checkClosedLoopEigenvalues(t *testing.T, A, B, K mat.Matrix, poles []complex128, tol float64) {
+	// Constants
+	var BK, Acl mat.Dense
+	BK.Mul(B, K)
+	Acl.Sub(A, &BK)
+
+	// Algorithm
+	var eig mat.Eigen
+	if ok := eig.Factorize(&Acl, mat.EigenNone); !ok {
+		t.Errorf("The eigenvalue decomposition did not converge.")
+		return
+	}
+	achieved := eig.Values(nil)
+
+	expected := append([]complex128(nil), poles...)
+	for _, values := range [][]complex128{achieved, expected} {
+		sort.Slice(values, func(i, j int) bool {
+			if real(values[i]) != real(values[j]) {
+				return real(values[i]) < real(values[j])
+			}
+			return imag(values[i]) < imag(values[j])
+		})
+	}
+
+	for i := range expected {
+		if cmplx.Abs(achieved[i]-expected[i]) > tol {
+			t.Errorf("The closed-loop eigenvalues are %v; want %v", achieved, expected)
+			return
+		}
+	}
+}
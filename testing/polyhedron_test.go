@@ -8,6 +8,7 @@ package testing
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/kwesiRutledge/goControl"
@@ -176,3 +177,253 @@ func TestPolyhedronConstructor1(t *testing.T) {
 // 	}
 
 // }
+
+/*
+getTestSquare
+Description:
+
+	Creates the square [-1, 1]^2 with a redundant inequality x1 + x2 <= 5 and a duplicate of x1 <= 1.
+*/
+func getTestSquare() goControl.Polyhedron {
+	return goControl.GetPolyhedron(
+		mat.NewDense(6, 2, []float64{
+			1, 0,
+			-1, 0,
+			0, 1,
+			0, -1,
+			1, 1,
+			2, 0,
+		}),
+		mat.NewVecDense(6, []float64{1, 1, 1, 1, 5, 2}),
+	)
+}
+
+/*
+TestPolyhedron_Contains1
+Description:
+
+	Verifies the containment of points and of Polyhedra in the square [-1, 1]^2.
+*/
+func TestPolyhedron_Contains1(t *testing.T) {
+	// Constants
+	square := getTestSquare()
+	smallSquare := goControl.GetPolyhedron(
+		mat.NewDense(4, 2, []float64{1, 0, -1, 0, 0, 1, 0, -1}),
+		mat.NewVecDense(4, []float64{0.5, 0.5, 0.5, 0.5}),
+	)
+
+	// Algorithm
+	if !square.Contains(mat.NewVecDense(2, []float64{1, -0.5})) {
+		t.Errorf("The square does not contain the point (1, -0.5) on its boundary.")
+	}
+	if square.Contains(mat.NewVecDense(2, []float64{1.1, 0})) {
+		t.Errorf("The square contains the point (1.1, 0).")
+	}
+	if !square.Contains(smallSquare) {
+		t.Errorf("The square does not contain the smaller square.")
+	}
+	if smallSquare.Contains(square) {
+		t.Errorf("The smaller square contains the square.")
+	}
+}
+
+/*
+TestPolyhedron_ChebyshevBall1
+Description:
+
+	Verifies the Chebyshev ball of the square [-1, 1]^2 (center 0, radius 1), and that of an empty Polyhedron.
+*/
+func TestPolyhedron_ChebyshevBall1(t *testing.T) {
+	// Constants
+	square := getTestSquare()
+	empty := goControl.GetPolyhedron(mat.NewDense(2, 1, []float64{1, -1}), mat.NewVecDense(2, []float64{-1, 0}))
+
+	// Algorithm
+	center, radius, err := square.ChebyshevBall()
+	if err != nil {
+		t.Errorf("There was an error computing the Chebyshev ball: %v", err)
+	}
+	if (math.Abs(radius-1) > 1e-9) || (mat.Norm(center, 2) > 1e-9) {
+		t.Errorf("The Chebyshev ball has center %v and radius %v; want 0 and 1", mat.Formatted(center.T()), radius)
+	}
+
+	isEmpty, err := empty.IsEmptySet()
+	if err != nil || !isEmpty {
+		t.Errorf("The set {x : x <= -1, x >= 0} is not reported as empty (error %v).", err)
+	}
+}
+
+/*
+TestPolyhedron_MinHRep1
+Description:
+
+	Verifies that the redundant and duplicate inequalities of the square are removed, and that the support function
+	in the direction (1, 1) is 2.
+*/
+func TestPolyhedron_MinHRep1(t *testing.T) {
+	// Constants
+	square := getTestSquare()
+
+	// Algorithm
+	minimal, err := square.MinHRep()
+	if err != nil {
+		t.Errorf("There was an error computing the minimal representation: %v", err)
+	}
+	if rows, _ := minimal.A.Dims(); rows != 4 {
+		t.Errorf("The minimal representation has %v inequalities; want 4", rows)
+	}
+	if !minimal.Contains(square) || !square.Contains(minimal) {
+		t.Errorf("The minimal representation is not the same set.")
+	}
+
+	support, err := square.Support(mat.NewVecDense(2, []float64{1, 1}))
+	if (err != nil) || (math.Abs(support-2) > 1e-9) {
+		t.Errorf("The support function is %v (error %v); want 2", support, err)
+	}
+}
+
+/*
+TestPolyhedron_Projection1
+Description:
+
+	Projects {(x, u) : |u| <= 1, |x - u| <= 1} onto x, which gives [-2, 2].
+*/
+func TestPolyhedron_Projection1(t *testing.T) {
+	// Constants
+	P := goControl.GetPolyhedron(
+		mat.NewDense(4, 2, []float64{
+			0, 1,
+			0, -1,
+			1, -1,
+			-1, 1,
+		}),
+		mat.NewVecDense(4, []float64{1, 1, 1, 1}),
+	)
+
+	// Algorithm
+	projection, err := P.Projection([]int{0})
+	if err != nil {
+		t.Errorf("There was an error computing the projection: %v", err)
+	}
+
+	upper, _ := projection.Support(mat.NewVecDense(1, []float64{1}))
+	lower, _ := projection.Support(mat.NewVecDense(1, []float64{-1}))
+	if (math.Abs(upper-2) > 1e-9) || (math.Abs(lower-2) > 1e-9) {
+		t.Errorf("The projection is [%v, %v]; want [-2, 2]", -lower, upper)
+	}
+}
+
+/*
+TestPolyhedron_AffineMap1
+Description:
+
+	Maps the unit square with a rotation by 45 degrees (invertible) and with a projection onto the first
+	coordinate (singular), and compares the support functions of the images.
+*/
+func TestPolyhedron_AffineMap1(t *testing.T) {
+	// Constants
+	P := getTestSquare()
+	c := math.Sqrt(0.5)
+	rotation := mat.NewDense(2, 2, []float64{c, -c, c, c})
+	projection := mat.NewDense(2, 2, []float64{1, 0, 0, 0})
+
+	// Algorithm
+	rotated, err := P.AffineMap(rotation)
+	if err != nil {
+		t.Errorf("There was an error mapping the square: %v", err)
+	}
+	projected, err := P.AffineMap(projection)
+	if err != nil {
+		t.Errorf("There was an error mapping the square: %v", err)
+	}
+
+	for _, d := range [][]float64{{1, 0}, {0, 1}, {1, 1}, {-1, 2}} {
+		direction := mat.NewVecDense(2, d)
+		var mapped mat.VecDense
+
+		mapped.MulVec(rotation.T(), direction)
+		want, _ := P.Support(&mapped)
+		if got, _ := rotated.Support(direction); math.Abs(got-want) > 1e-8 {
+			t.Errorf("The support of the rotated square in direction %v is %v; want %v", d, got, want)
+		}
+
+		mapped.MulVec(projection.T(), direction)
+		want, _ = P.Support(&mapped)
+		if got, _ := projected.Support(direction); math.Abs(got-want) > 1e-8 {
+			t.Errorf("The support of the projected square in direction %v is %v; want %v", d, got, want)
+		}
+	}
+}
+
+/*
+TestPolyhedron_Plus1
+Description:
+
+	Verifies that the Minkowski sum of the unit square and the diamond {|x| + |y| <= 1} is the octagon whose support
+	function is the sum of the two support functions.
+*/
+func TestPolyhedron_Plus1(t *testing.T) {
+	// Constants
+	P := getTestSquare()
+	Q := goControl.GetPolyhedron(
+		mat.NewDense(4, 2, []float64{
+			1, 1,
+			1, -1,
+			-1, 1,
+			-1, -1,
+		}),
+		mat.NewVecDense(4, []float64{1, 1, 1, 1}),
+	)
+
+	// Algorithm
+	sum, err := P.Plus(Q)
+	if err != nil {
+		t.Errorf("There was an error computing the Minkowski sum: %v", err)
+	}
+
+	if rows, _ := sum.A.Dims(); rows != 8 {
+		t.Errorf("The Minkowski sum has %v inequalities; want 8", rows)
+	}
+	for _, d := range [][]float64{{1, 0}, {0, -1}, {1, 1}, {-2, 1}, {0.3, 0.7}} {
+		direction := mat.NewVecDense(2, d)
+		hP, _ := P.Support(direction)
+		hQ, _ := Q.Support(direction)
+		if got, _ := sum.Support(direction); math.Abs(got-hP-hQ) > 1e-8 {
+			t.Errorf("The support of the sum in direction %v is %v; want %v", d, got, hP+hQ)
+		}
+	}
+}
+
+/*
+TestPolyhedron_Minus1
+Description:
+
+	Verifies that the Pontryagin difference of the square [-1, 1]^2 and the box [-0.5, 0.5] x [-0.25, 0.25] is the
+	box [-0.5, 0.5] x [-0.75, 0.75].
+*/
+func TestPolyhedron_Minus1(t *testing.T) {
+	// Constants
+	P := getTestSquare()
+	Q := goControl.GetPolyhedron(
+		mat.NewDense(4, 2, []float64{
+			1, 0,
+			-1, 0,
+			0, 1,
+			0, -1,
+		}),
+		mat.NewVecDense(4, []float64{0.5, 0.5, 0.25, 0.25}),
+	)
+
+	// Algorithm
+	difference, err := P.Minus(Q)
+	if err != nil {
+		t.Errorf("There was an error computing the Pontryagin difference: %v", err)
+	}
+
+	expected := []float64{0.5, 0.5, 0.75, 0.75}
+	for i, d := range [][]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		if got, _ := difference.Support(mat.NewVecDense(2, d)); math.Abs(got-expected[i]) > 1e-9 {
+			t.Errorf("The support of the difference in direction %v is %v; want %v", d, got, expected[i])
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+   pwa_system_test.go
+   Description:
+	   Tests for the PWA system model defined in pwa_system.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestSaturatedSystem
+Description:
+
+	Creates the PWA model x+ = 0.8 x + sat(u) of a scalar system with a saturated input, whose modes are
+	u <= -1, -1 <= u <= upper and u >= 1 in the (x, u) space.
+*/
+func getTestSaturatedSystem(upper float64) (goControl.PWASystem, error) {
+	A := mat.NewDense(1, 1, []float64{0.8})
+	return goControl.GetPWASystem([]goControl.PWAMode{
+		{
+			A:      A,
+			B:      mat.NewDense(1, 1, []float64{0}),
+			F:      mat.NewVecDense(1, []float64{-1}),
+			Region: goControl.GetPolyhedron(mat.NewDense(1, 2, []float64{0, 1}), mat.NewVecDense(1, []float64{-1})),
+		},
+		{
+			A:      A,
+			B:      mat.NewDense(1, 1, []float64{1}),
+			Region: goControl.GetPolyhedron(mat.NewDense(2, 2, []float64{0, 1, 0, -1}), mat.NewVecDense(2, []float64{upper, 1})),
+		},
+		{
+			A:      A,
+			B:      mat.NewDense(1, 1, []float64{0}),
+			F:      mat.NewVecDense(1, []float64{1}),
+			Region: goControl.GetPolyhedron(mat.NewDense(1, 2, []float64{0, -1}), mat.NewVecDense(1, []float64{-1})),
+		},
+	}, 0.1)
+}
+
+/*
+TestPWASystem_Simulate1
+Description:
+
+	Simulates the saturated system and compares the states and modes with the values computed by hand.
+*/
+func TestPWASystem_Simulate1(t *testing.T) {
+	// Constants
+	sys, err := getTestSaturatedSystem(1)
+	if err != nil {
+		t.Fatalf("There was an error creating the PWA system: %v", err)
+	}
+	u := mat.NewDense(3, 1, []float64{2, 0.5, -3})
+
+	// Algorithm
+	response, err := sys.Simulate(u, mat.NewVecDense(1, []float64{1}))
+	if err != nil {
+		t.Fatalf("There was an error simulating the PWA system: %v", err)
+	}
+
+	expectedStates := []float64{1, 1.8, 1.94, 0.552}
+	for k, expected := range expectedStates {
+		if math.Abs(response.State.At(k, 0)-expected) > 1e-12 {
+			t.Errorf("x_%v = %v; want %v", k, response.State.At(k, 0), expected)
+		}
+	}
+	expectedModes := []int{2, 1, 0}
+	for k, expected := range expectedModes {
+		if response.Mode[k] != expected {
+			t.Errorf("The mode at step %v is %v; want %v", k, response.Mode[k], expected)
+		}
+	}
+}
+
+/*
+TestPWASystem_CheckWellPosedness1
+Description:
+
+	Verifies that the saturated system is well-posed on |x| <= 10, |u| <= 2, and that overlapping regions or regions
+	with a gap are detected.
+*/
+func TestPWASystem_CheckWellPosedness1(t *testing.T) {
+	// Constants
+	domain := getTestBoxPolyhedron(10, 2)
+	sys, _ := getTestSaturatedSystem(1)
+	overlapping, _ := getTestSaturatedSystem(1.5)
+	gap, _ := getTestSaturatedSystem(0.5)
+
+	// Algorithm
+	if err := sys.CheckWellPosedness(domain); err != nil {
+		t.Errorf("The saturated system should be well-posed: %v", err)
+	}
+
+	if disjoint, _ := overlapping.IsDisjoint(); disjoint {
+		t.Errorf("The overlapping regions were reported as disjoint.")
+	}
+	if err := overlapping.CheckWellPosedness(domain); err == nil {
+		t.Errorf("Expected an error for overlapping regions.")
+	}
+
+	if covers, _ := gap.Covers(domain); covers {
+		t.Errorf("The regions with a gap were reported as covering the domain.")
+	}
+	if disjoint, _ := gap.IsDisjoint(); !disjoint {
+		t.Errorf("The regions with a gap were reported as overlapping.")
+	}
+}
+
+/*
+TestPWASystem_ToMLD1
+Description:
+
+	Verifies that the MLD form of the saturated system accepts the binary and auxiliary variables of the active mode
+	(and gives the same successor state as the PWA system), and rejects those of the other modes.
+*/
+func TestPWASystem_ToMLD1(t *testing.T) {
+	// Constants
+	sys, _ := getTestSaturatedSystem(1)
+	domain := getTestBoxPolyhedron(10, 2)
+	points := [][]float64{{3, -1.7}, {-4, 0.3}, {9, 1.9}, {-0.5, -0.2}, {0, 1.5}}
+
+	// Algorithm
+	mld, err := sys.ToMLD(domain)
+	if err != nil {
+		t.Fatalf("There was an error converting the PWA system: %v", err)
+	}
+
+	for _, point := range points {
+		x := mat.NewVecDense(1, point[:1])
+		u := mat.NewVecDense(1, point[1:])
+		expected, active, err := sys.Step(x, u)
+		if err != nil {
+			t.Fatalf("There was an error computing the step: %v", err)
+		}
+
+		for mode := 0; mode < 3; mode++ {
+			delta := mat.NewVecDense(3, nil)
+			delta.SetVec(mode, 1)
+			z := mat.NewVecDense(3, nil)
+			affine := sys.Modes[mode].A.At(0, 0)*x.AtVec(0) + sys.Modes[mode].B.At(0, 0)*u.AtVec(0) + sys.Modes[mode].F.AtVec(0)
+			z.SetVec(mode, affine)
+
+			feasible := mld.Satisfies(x, u, delta, z)
+			if (mode == active) && !feasible {
+				t.Errorf("The active mode %v at %v does not satisfy the MLD constraints.", mode, point)
+			}
+			if (mode != active) && feasible {
+				t.Errorf("The inactive mode %v at %v satisfies the MLD constraints.", mode, point)
+			}
+			if mode == active {
+				if next := mld.Next(x, u, delta, z); math.Abs(next.AtVec(0)-expected.AtVec(0)) > 1e-12 {
+					t.Errorf("The MLD successor at %v is %v; want %v", point, next.AtVec(0), expected.AtVec(0))
+				}
+			}
+		}
+	}
+}
+
+/*
+TestPWASystem_ToMLD2
+Description:
+
+	Verifies that unbounded domains and inconsistent modes are rejected, and that points outside every region
+	give an error.
+*/
+func TestPWASystem_ToMLD2(t *testing.T) {
+	// Constants
+	sys, _ := getTestSaturatedSystem(1)
+	halfPlane := goControl.GetPolyhedron(mat.NewDense(1, 2, []float64{1, 0}), mat.NewVecDense(1, []float64{1}))
+
+	// Algorithm
+	if _, err := sys.ToMLD(halfPlane); err == nil {
+		t.Errorf("Expected an error for an unbounded domain.")
+	}
+
+	if _, err := goControl.GetPWASystem([]goControl.PWAMode{
+		{
+			A:      mat.NewDense(2, 2, nil),
+			B:      mat.NewDense(1, 1, nil),
+			Region: getTestBoxPolyhedron(1, 1),
+		},
+	}, 0.1); err == nil {
+		t.Errorf("Expected an error for inconsistent dimensions.")
+	}
+
+	gap, _ := getTestSaturatedSystem(0.5)
+	if _, _, err := gap.Step(mat.NewVecDense(1, []float64{0}), mat.NewVecDense(1, []float64{0.7})); err == nil {
+		t.Errorf("Expected an error for a point outside every region.")
+	}
+}
@@ -0,0 +1,308 @@
+/*
+   reachable_sets_test.go
+   Description:
+	   Tests for the reachable set computations defined in reachable_sets.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestReachSystem
+Description:
+
+	Creates the discrete-time double integrator x+ = [1 1; 0 1] x + [0.5; 1] u.
+*/
+func getTestReachSystem() goControl.StateSpace {
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(2, 2, []float64{1, 1, 0, 1}),
+		mat.NewDense(2, 1, []float64{0.5, 1}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, nil),
+		1,
+	)
+	return sys
+}
+
+/*
+TestReachableSets_ForwardReach1
+Description:
+
+	Verifies that the exact forward reachable sets of the double integrator have the support function
+		h_{X_k}(d) = h_{X_0}((A^k)^T d) + sum_{i<k} h_U(B^T (A^i)^T d).
+*/
+func TestReachableSets_ForwardReach1(t *testing.T) {
+	// Constants
+	sys := getTestReachSystem()
+	X0 := getTestBoxPolyhedron(0.1, 0.1)
+	U := getTestBoxPolyhedron(1)
+	N := 3
+
+	// Algorithm
+	sets, err := goControl.ForwardReach(sys, X0, U, nil, N)
+	if err != nil {
+		t.Fatalf("There was an error computing the reachable sets: %v", err)
+	}
+	if len(sets) != N+1 {
+		t.Fatalf("Expected %v sets; received %v", N+1, len(sets))
+	}
+
+	for _, values := range [][]float64{{1, 0}, {0, 1}, {1, -1}, {-2, 1}} {
+		d := mat.NewVecDense(2, values)
+		inputTerms := 0.0
+		power := mat.NewDense(2, 2, []float64{1, 0, 0, 1}) // A^{k-1}
+		for k := 1; k <= N; k++ {
+			var ATd, BTd mat.VecDense
+			ATd.MulVec(power.T(), d)
+			BTd.MulVec(sys.B.T(), &ATd)
+			hU, _ := U.Support(&BTd)
+			inputTerms += hU
+
+			var next mat.Dense
+			next.Mul(power, sys.A)
+			power = &next
+
+			ATd.MulVec(power.T(), d)
+			hX0, _ := X0.Support(&ATd)
+
+			if got, _ := sets[k].Support(d); math.Abs(got-hX0-inputTerms) > 1e-8 {
+				t.Errorf("The support of X_%v in direction %v is %v; want %v", k, values, got, hX0+inputTerms)
+			}
+		}
+	}
+}
+
+/*
+TestReachableSets_ForwardReach2
+Description:
+
+	Verifies that the box and template over-approximations contain the exact forward reachable sets (with a
+	disturbance), and that the box of the first step is the bounding box of the exact set.
+*/
+func TestReachableSets_ForwardReach2(t *testing.T) {
+	// Constants
+	sys := getTestReachSystem()
+	X0 := getTestBoxPolyhedron(0.1, 0.1)
+	U := getTestBoxPolyhedron(1)
+	W := getTestBoxPolyhedron(0.05, 0.05)
+	N := 3
+	directions := mat.NewDense(8, 2, []float64{
+		1, 0,
+		-1, 0,
+		0, 1,
+		0, -1,
+		1, 1,
+		1, -1,
+		-1, 1,
+		-1, -1,
+	})
+
+	// Algorithm
+	exact, err := goControl.ForwardReach(sys, X0, U, &W, N)
+	if err != nil {
+		t.Fatalf("There was an error computing the exact reachable sets: %v", err)
+	}
+	boxes, err := goControl.ForwardReachWithOptions(sys, X0, U, &W, N, goControl.ReachOptions{Method: goControl.ReachBox})
+	if err != nil {
+		t.Fatalf("There was an error computing the box reachable sets: %v", err)
+	}
+	templates, err := goControl.ForwardReachWithOptions(sys, X0, U, &W, N, goControl.ReachOptions{
+		Method:     goControl.ReachTemplate,
+		Directions: directions,
+	})
+	if err != nil {
+		t.Fatalf("There was an error computing the template reachable sets: %v", err)
+	}
+
+	for k := 0; k <= N; k++ {
+		if !boxes[k].Contains(exact[k]) {
+			t.Errorf("The box at step %v does not contain the exact reachable set.", k)
+		}
+		if !templates[k].Contains(exact[k]) {
+			t.Errorf("The template polyhedron at step %v does not contain the exact reachable set.", k)
+		}
+		if rows, _ := templates[k].A.Dims(); rows != 8 {
+			t.Errorf("The template polyhedron at step %v has %v inequalities; want 8", k, rows)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		d := mat.NewVecDense(2, mat.Row(nil, i, directions))
+		want, _ := exact[1].Support(d)
+		if got, _ := boxes[1].Support(d); math.Abs(got-want) > 1e-8 {
+			t.Errorf("The box of X_1 has support %v in direction %v; want %v", got, i, want)
+		}
+	}
+}
+
+/*
+TestReachableSets_BackwardReach1
+Description:
+
+	Verifies that for x+ = x + u + w with |u| <= 1, |w| <= 0.5 and the target [-1, 1] the backward reachable sets are
+	[-(1 + k/2), 1 + k/2], with the exact and box methods.
+*/
+func TestReachableSets_BackwardReach1(t *testing.T) {
+	// Constants
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, nil),
+		0.1,
+	)
+	target := getTestBoxPolyhedron(1)
+	U := getTestBoxPolyhedron(1)
+	W := getTestBoxPolyhedron(0.5)
+	N := 4
+
+	// Algorithm
+	for _, method := range []goControl.ReachMethod{goControl.ReachExact, goControl.ReachBox} {
+		sets, err := goControl.BackwardReachWithOptions(sys, target, U, &W, N, goControl.ReachOptions{Method: method})
+		if err != nil {
+			t.Fatalf("There was an error computing the backward reachable sets: %v", err)
+		}
+
+		for k, set := range sets {
+			expected := 1 + 0.5*float64(k)
+			upper, _ := set.Support(mat.NewVecDense(1, []float64{1}))
+			lower, _ := set.Support(mat.NewVecDense(1, []float64{-1}))
+			if (math.Abs(upper-expected) > 1e-8) || (math.Abs(lower-expected) > 1e-8) {
+				t.Errorf("Method %v: T_%v is [%v, %v]; want [%v, %v]", method, k, -lower, upper, -expected, expected)
+			}
+		}
+	}
+}
+
+/*
+TestReachableSets_BackwardReach2
+Description:
+
+	Verifies that the backward reachable set of the double integrator into a box contains the states steered into
+	the box by some admissible input, and that a disturbance larger than the target gives an error.
+*/
+func TestReachableSets_BackwardReach2(t *testing.T) {
+	// Constants
+	sys := getTestReachSystem()
+	target := getTestBoxPolyhedron(0.5, 0.5)
+	U := getTestBoxPolyhedron(1)
+	W := getTestBoxPolyhedron(1, 1)
+
+	// Algorithm
+	sets, err := goControl.BackwardReach(sys, target, U, nil, 1)
+	if err != nil {
+		t.Fatalf("There was an error computing the backward reachable sets: %v", err)
+	}
+
+	x := mat.NewVecDense(2, []float64{0.9, -0.9}) // u = 0.4 gives x+ = (0.2, -0.5)
+	if !sets[1].Contains(x) {
+		t.Errorf("The state %v can be steered into the target but is not in T_1.", x)
+	}
+	if y := mat.NewVecDense(2, []float64{2, 2}); sets[1].Contains(y) {
+		t.Errorf("The state %v cannot be steered into the target but is in T_1.", y)
+	}
+
+	if _, err := goControl.BackwardReach(sys, target, U, &W, 1); err == nil {
+		t.Errorf("Expected an error for a disturbance larger than the target.")
+	}
+}
+
+/*
+boxCorners
+Description:
+
+	Returns the corners of the bounding box of the two-dimensional polyhedron P.
+*/
+func boxCorners(P goControl.Polyhedron) []*mat.VecDense {
+	var bounds [4]float64
+	for i, values := range [][]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		bounds[i], _ = P.Support(mat.NewVecDense(2, values))
+	}
+	return []*mat.VecDense{
+		mat.NewVecDense(2, []float64{bounds[0], bounds[2]}),
+		mat.NewVecDense(2, []float64{bounds[0], -bounds[3]}),
+		mat.NewVecDense(2, []float64{-bounds[1], bounds[2]}),
+		mat.NewVecDense(2, []float64{-bounds[1], -bounds[3]}),
+	}
+}
+
+/*
+TestReachableSets_BackwardReach3
+Description:
+
+	Verifies that the box approximations of the backward reachable sets of the double integrator are inner
+	approximations: every corner of each box lies in the exact set, while the bounding box of the exact set (its
+	outer approximation) contains states that cannot be steered into the target.
+*/
+func TestReachableSets_BackwardReach3(t *testing.T) {
+	// Constants
+	sys := getTestReachSystem()
+	target := getTestBoxPolyhedron(0.5, 0.5)
+	U := getTestBoxPolyhedron(1)
+	N := 3
+
+	// Algorithm
+	exact, err := goControl.BackwardReach(sys, target, U, nil, N)
+	if err != nil {
+		t.Fatalf("There was an error computing the exact backward reachable sets: %v", err)
+	}
+	boxes, err := goControl.BackwardReachWithOptions(sys, target, U, nil, N, goControl.ReachOptions{Method: goControl.ReachBox})
+	if err != nil {
+		t.Fatalf("There was an error computing the box backward reachable sets: %v", err)
+	}
+
+	outside := false
+	for k := 1; k <= N; k++ {
+		if empty, _ := boxes[k].IsEmptySet(); empty {
+			t.Errorf("Expected a nonempty box approximation of T_%v.", k)
+		}
+		for _, corner := range boxCorners(boxes[k]) {
+			shrunk := mat.NewVecDense(2, nil)
+			shrunk.ScaleVec(1-1e-9, corner)
+			if !exact[k].Contains(shrunk) {
+				t.Errorf("The corner %v of the box approximation of T_%v is not in the exact set.", corner, k)
+			}
+		}
+		for _, corner := range boxCorners(exact[k]) {
+			outside = outside || !exact[k].Contains(corner)
+		}
+	}
+	if !outside {
+		t.Errorf("Expected the bounding box of some exact set to contain states outside of it.")
+	}
+}
+
+/*
+TestReachableSets_ForwardReach3
+Description:
+
+	Verifies that continuous-time models, missing template directions and unknown methods are rejected.
+*/
+func TestReachableSets_ForwardReach3(t *testing.T) {
+	// Constants
+	sys := getTestReachSystem()
+	continuous := sys
+	continuous.Ts = 0
+	X0 := getTestBoxPolyhedron(0.1, 0.1)
+	U := getTestBoxPolyhedron(1)
+
+	// Algorithm
+	if _, err := goControl.ForwardReach(continuous, X0, U, nil, 2); err == nil {
+		t.Errorf("Expected an error for a continuous-time model.")
+	}
+	if _, err := goControl.ForwardReachWithOptions(sys, X0, U, nil, 2, goControl.ReachOptions{Method: goControl.ReachTemplate}); err == nil {
+		t.Errorf("Expected an error for missing template directions.")
+	}
+	if _, err := goControl.ForwardReachWithOptions(sys, X0, U, nil, 2, goControl.ReachOptions{Method: 7}); err == nil {
+		t.Errorf("Expected an error for an unknown method.")
+	}
+	if _, err := goControl.ForwardReach(sys, X0, getTestBoxPolyhedron(1, 1), nil, 2); err == nil {
+		t.Errorf("Expected an error for an input set of the wrong dimension.")
+	}
+}
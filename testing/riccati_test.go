@@ -0,0 +1,206 @@
+/*
+   riccati_test.go
+   Description:
+	   Tests for the algebraic Riccati equation solvers defined in riccati.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestRiccati_Care1
+Description:
+
+	Solves the scalar continuous-time Riccati equation 2 X - X^2 + 1 = 0 (A = B = Q = R = 1), whose stabilizing
+	solution is X = 1 + sqrt(2), with gain K = 1 + sqrt(2) and closed-loop eigenvalue -sqrt(2).
+*/
+func TestRiccati_Care1(t *testing.T) {
+	// Constants
+	one := mat.NewDense(1, 1, []float64{1})
+
+	// Algorithm
+	solution, err := goControl.Care(one, one, one, one, nil)
+	if err != nil {
+		t.Errorf("There was an error solving the Riccati equation: %v", err)
+	}
+
+	if math.Abs(solution.X.At(0, 0)-(1+math.Sqrt2)) > 1e-10 {
+		t.Errorf("X = %v; want %v", solution.X.At(0, 0), 1+math.Sqrt2)
+	}
+	if math.Abs(solution.K.At(0, 0)-(1+math.Sqrt2)) > 1e-10 {
+		t.Errorf("K = %v; want %v", solution.K.At(0, 0), 1+math.Sqrt2)
+	}
+	if math.Abs(real(solution.ClosedLoopEigenvalues[0])+math.Sqrt2) > 1e-10 {
+		t.Errorf("The closed-loop eigenvalue is %v; want %v", solution.ClosedLoopEigenvalues[0], -math.Sqrt2)
+	}
+}
+
+/*
+TestRiccati_Care2
+Description:
+
+	Solves the Riccati equation of a double integrator with a cross weight and verifies the residual,
+	the symmetry of X and the stability of the closed loop. The Newton-Kleinman refinement must give the
+	same solution.
+*/
+func TestRiccati_Care2(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{0, 1, 0, 0})
+	B := mat.NewDense(2, 1, []float64{0, 1})
+	Q := mat.NewDense(2, 2, []float64{2, 0, 0, 1})
+	R := mat.NewDense(1, 1, []float64{1})
+	N := mat.NewDense(2, 1, []float64{0.5, 0})
+
+	// Algorithm
+	solution, err := goControl.Care(A, B, Q, R, N)
+	if err != nil {
+		t.Errorf("There was an error solving the Riccati equation: %v", err)
+	}
+
+	// A^T X + X A - (X B + N) R^{-1} (B^T X + N^T) + Q = 0
+	X := solution.X
+	var AtX, XA, XBN, cross, residual mat.Dense
+	AtX.Mul(A.T(), X)
+	XA.Mul(X, A)
+	XBN.Mul(X, B)
+	XBN.Add(&XBN, N)
+	cross.Mul(&XBN, XBN.T())
+	residual.Add(&AtX, &XA)
+	residual.Sub(&residual, &cross)
+	residual.Add(&residual, Q)
+	if mat.Norm(&residual, 1) > 1e-10 {
+		t.Errorf("The residual is %v; want 0", mat.Formatted(&residual))
+	}
+
+	for _, lambda := range solution.ClosedLoopEigenvalues {
+		if real(lambda) >= 0 {
+			t.Errorf("The closed-loop eigenvalue %v is not stable", lambda)
+		}
+	}
+
+	refined, err := goControl.CareWithOptions(A, B, Q, R, N, goControl.RiccatiOptions{NewtonRefinement: true})
+	if err != nil {
+		t.Errorf("There was an error solving the Riccati equation with refinement: %v", err)
+	}
+	if !mat.EqualApprox(refined.X, X, 1e-10) {
+		t.Errorf("The refined solution %v differs from %v", mat.Formatted(refined.X), mat.Formatted(X))
+	}
+}
+
+/*
+TestRiccati_Care3
+Description:
+
+	Verifies that an unstable mode that cannot be controlled is rejected, since no stabilizing solution exists.
+*/
+func TestRiccati_Care3(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{1, 0, 0, -1})
+	B := mat.NewDense(2, 1, []float64{0, 1})
+	Q := mat.NewDense(2, 2, []float64{1, 0, 0, 1})
+	R := mat.NewDense(1, 1, []float64{1})
+
+	// Algorithm
+	_, err := goControl.Care(A, B, Q, R, nil)
+	if err == nil {
+		t.Errorf("Expected an error for a model that is not stabilizable; received nil")
+	}
+}
+
+/*
+TestRiccati_Dare1
+Description:
+
+	Solves the scalar discrete-time Riccati equation with A = 2 and B = Q = R = 1, whose stabilizing solution is
+	X = 2 + sqrt(5).
+*/
+func TestRiccati_Dare1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(1, 1, []float64{2})
+	one := mat.NewDense(1, 1, []float64{1})
+	XExpected := 2 + math.Sqrt(5)
+
+	// Algorithm
+	solution, err := goControl.Dare(A, one, one, one, nil)
+	if err != nil {
+		t.Errorf("There was an error solving the Riccati equation: %v", err)
+	}
+
+	if math.Abs(solution.X.At(0, 0)-XExpected) > 1e-10 {
+		t.Errorf("X = %v; want %v", solution.X.At(0, 0), XExpected)
+	}
+	KExpected := 2 * XExpected / (1 + XExpected)
+	if math.Abs(solution.K.At(0, 0)-KExpected) > 1e-10 {
+		t.Errorf("K = %v; want %v", solution.K.At(0, 0), KExpected)
+	}
+}
+
+/*
+TestRiccati_Dare2
+Description:
+
+	Solves the discrete-time Riccati equation for a model with a singular A matrix (a shift register) and
+	a cross weight, and verifies the residual and the stability of the closed loop, with and without the
+	Newton-Kleinman refinement.
+*/
+func TestRiccati_Dare2(t *testing.T) {
+	// Constants
+	A := mat.NewDense(3, 3, []float64{
+		0, 1, 0,
+		0, 0, 1,
+		0, 0, 0,
+	})
+	B := mat.NewDense(3, 2, []float64{
+		0, 1,
+		0, 0,
+		1, 0,
+	})
+	Q := mat.NewDense(3, 3, []float64{
+		1, 0, 0,
+		0, 2, 0,
+		0, 0, 1,
+	})
+	R := mat.NewDense(2, 2, []float64{2, 0.5, 0.5, 1})
+	N := mat.NewDense(3, 2, []float64{
+		0.1, 0,
+		0, 0.2,
+		0, 0,
+	})
+
+	for _, options := range []goControl.RiccatiOptions{{}, {NewtonRefinement: true}} {
+		// Algorithm
+		solution, err := goControl.DareWithOptions(A, B, Q, R, N, options)
+		if err != nil {
+			t.Errorf("There was an error solving the Riccati equation: %v", err)
+			continue
+		}
+
+		// A^T X A - X - (A^T X B + N) K + Q = 0
+		X, K := solution.X, solution.K
+		var AtX, AtXA, AtXB, cross, residual mat.Dense
+		AtX.Mul(A.T(), X)
+		AtXA.Mul(&AtX, A)
+		AtXB.Mul(&AtX, B)
+		AtXB.Add(&AtXB, N)
+		cross.Mul(&AtXB, K)
+		residual.Sub(&AtXA, X)
+		residual.Sub(&residual, &cross)
+		residual.Add(&residual, Q)
+		if mat.Norm(&residual, 1) > 1e-10 {
+			t.Errorf("The residual is %v; want 0", mat.Formatted(&residual))
+		}
+
+		for _, lambda := range solution.ClosedLoopEigenvalues {
+			if real(lambda)*real(lambda)+imag(lambda)*imag(lambda) >= 1 {
+				t.Errorf("The closed-loop eigenvalue %v is not stable", lambda)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+/*
+   robust_invariant_sets_test.go
+   Description:
+	   Tests for the minimal robust positively invariant set approximation defined in robust_invariant_sets.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+checkRobustInvariance
+Description:
+
+	Verifies that A S + W is contained in S for S = {x : H x <= h}, i.e. that h_S(A^T h_i) + h_W(h_i) <= h_i for each
+	row h_i of H.
+*/
+func checkRobustInvariance(t *testing.T, A mat.Matrix, S, W goControl.Polyhedron) {
+	rows, n := S.A.Dims()
+	for i := 0; i < rows; i++ {
+		normal := mat.NewVecDense(n, mat.Row(nil, i, S.A))
+		var direction mat.VecDense
+		direction.MulVec(A.T(), normal)
+		hS, err := S.Support(&direction)
+		if err != nil {
+			t.Errorf("There was an error computing the support function: %v", err)
+		}
+		hW, err := W.Support(normal)
+		if err != nil {
+			t.Errorf("There was an error computing the support function: %v", err)
+		}
+		if bound := S.Get_b().AtVec(i); hS+hW > bound+1e-8 {
+			t.Errorf("The set is not robustly invariant: the support in direction %v is %v > %v.", i, hS+hW, bound)
+		}
+	}
+}
+
+/*
+TestRobustInvariantSets_MinimalRPI1
+Description:
+
+	Verifies that for x+ = 0.5 x + w with |w| <= 1 (whose minimal RPI set is [-2, 2]) the approximation contains
+	[-2, 2] and is within the error bound of it.
+*/
+func TestRobustInvariantSets_MinimalRPI1(t *testing.T) {
+	// Constants
+	A := mat.NewDense(1, 1, []float64{0.5})
+	W := getTestBoxPolyhedron(1)
+	epsilon := 1e-3
+
+	// Algorithm
+	approximation, err := goControl.MinimalRPI(A, W, epsilon)
+	if err != nil {
+		t.Errorf("There was an error computing the minimal RPI set: %v", err)
+	}
+
+	if (approximation.Bound > epsilon) || (approximation.Alpha >= 1) || (approximation.S < 1) {
+		t.Errorf("Unexpected parameters s = %v, alpha = %v, bound = %v", approximation.S, approximation.Alpha, approximation.Bound)
+	}
+	upper, _ := approximation.Set.Support(mat.NewVecDense(1, []float64{1}))
+	lower, _ := approximation.Set.Support(mat.NewVecDense(1, []float64{-1}))
+	for _, value := range []float64{upper, lower} {
+		if (value < 2-1e-9) || (value > 2+approximation.Bound+1e-9) {
+			t.Errorf("The approximation is [%v, %v]; want [-2, 2] up to %v", -lower, upper, approximation.Bound)
+		}
+	}
+}
+
+/*
+TestRobustInvariantSets_MinimalRPI2
+Description:
+
+	Computes the minimal RPI approximation of a double integrator in closed loop with a stabilizing gain and checks
+	that it is robustly invariant, contains W and satisfies the error bound.
+*/
+func TestRobustInvariantSets_MinimalRPI2(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{
+		1, 1,
+		-0.3, 0.2,
+	}) // A + B K for A = [1 1; 0 1], B = [0; 1], K = [-0.3 -0.8]
+	W := getTestBoxPolyhedron(0.1, 0.1)
+	epsilon := 1e-2
+
+	// Algorithm
+	approximation, err := goControl.MinimalRPI(A, W, epsilon)
+	if err != nil {
+		t.Fatalf("There was an error computing the minimal RPI set: %v", err)
+	}
+
+	if approximation.Bound > epsilon {
+		t.Errorf("The error bound is %v > %v", approximation.Bound, epsilon)
+	}
+	if !approximation.Set.Contains(W) {
+		t.Errorf("The approximation does not contain W.")
+	}
+	checkRobustInvariance(t, A, approximation.Set, W)
+}
+
+/*
+TestRobustInvariantSets_MinimalRPI3
+Description:
+
+	Verifies that a nilpotent closed loop matrix gives the exact minimal RPI set W + A W.
+*/
+func TestRobustInvariantSets_MinimalRPI3(t *testing.T) {
+	// Constants
+	A := mat.NewDense(2, 2, []float64{0, 1, 0, 0})
+	W := getTestBoxPolyhedron(1, 1)
+
+	// Algorithm
+	approximation, err := goControl.MinimalRPI(A, W, 1e-6)
+	if err != nil {
+		t.Fatalf("There was an error computing the minimal RPI set: %v", err)
+	}
+
+	if (approximation.S != 2) || (approximation.Alpha != 0) || (approximation.Bound != 0) {
+		t.Errorf("Unexpected parameters s = %v, alpha = %v, bound = %v", approximation.S, approximation.Alpha, approximation.Bound)
+	}
+	exact := getTestBoxPolyhedron(2, 1)
+	if !approximation.Set.Contains(exact) || !exact.Contains(approximation.Set) {
+		t.Errorf("The approximation is not the box [-2, 2] x [-1, 1].")
+	}
+}
+
+/*
+TestRobustInvariantSets_MinimalRPI4
+Description:
+
+	Verifies that unstable systems, disturbance sets without the origin in their interior and non-positive error
+	bounds are rejected.
+*/
+func TestRobustInvariantSets_MinimalRPI4(t *testing.T) {
+	// Constants
+	stable := mat.NewDense(1, 1, []float64{0.5})
+	unstable := mat.NewDense(1, 1, []float64{1.5})
+	W := getTestBoxPolyhedron(1)
+	shifted := goControl.GetPolyhedron(
+		mat.NewDense(2, 1, []float64{1, -1}),
+		mat.NewVecDense(2, []float64{2, -1}),
+	)
+
+	// Algorithm
+	if _, err := goControl.MinimalRPIWithOptions(unstable, W, 1e-2, goControl.MinimalRPIOptions{MaxIterations: 50}); err == nil {
+		t.Errorf("Expected an error for an unstable system.")
+	}
+	if _, err := goControl.MinimalRPI(stable, shifted, 1e-2); err == nil {
+		t.Errorf("Expected an error for a disturbance set without the origin.")
+	}
+	if _, err := goControl.MinimalRPI(stable, W, 0); err == nil {
+		t.Errorf("Expected an error for a zero error bound.")
+	}
+	if _, err := goControl.MinimalRPI(stable, getTestBoxPolyhedron(1, 1), math.Inf(1)); err == nil {
+		t.Errorf("Expected an error for mismatched dimensions.")
+	}
+}
@@ -0,0 +1,299 @@
+package sos_test
+
+/*
+barrier_test.go
+Description:
+	Tests for the barrier certificate synthesis defined in barrier.go.
+*/
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/sos"
+	"github.com/kwesiRutledge/goControl/symbolic"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+disk
+Description:
+
+	Returns the polynomial r^2 - (x1 - c1)^2 - (x2 - c2)^2, which is nonnegative on the disk of radius r centered at
+	(c1, c2).
+*/
+func disk(x []symbolic.Variable, c1, c2, r float64) symbolic.Polynomial {
+	return symbolic.Polynomial{Monomials: []symbolic.Monomial{
+		term(r*r-c1*c1-c2*c2, x, 0, 0),
+		term(2*c1, x, 1, 0), term(-1, x, 2, 0),
+		term(2*c2, x, 0, 1), term(-1, x, 0, 2),
+	}}
+}
+
+/*
+checkBarrier
+Description:
+
+	Verifies the certificate and checks that B is nonpositive at the given initial points and at least the margin at
+	the given unsafe points.
+*/
+func checkBarrier(t *testing.T, x []symbolic.Variable, certificate sos.BarrierCertificate, initial, unsafe [][2]float64) {
+	// Algorithm
+	if err := certificate.Verify(1e-6); err != nil {
+		t.Errorf("Expected the certificate to verify; received %v", err)
+	}
+	for _, point := range initial {
+		B, err := certificate.B.Evaluate(map[symbolic.Variable]float64{x[0]: point[0], x[1]: point[1]})
+		if err != nil {
+			t.Fatalf("There was an error evaluating B: %v", err)
+		}
+		if B > 1e-6 {
+			t.Errorf("Expected B(%v) <= 0 on the initial set; received %v.", point, B)
+		}
+	}
+	for _, point := range unsafe {
+		B, err := certificate.B.Evaluate(map[symbolic.Variable]float64{x[0]: point[0], x[1]: point[1]})
+		if err != nil {
+			t.Fatalf("There was an error evaluating B: %v", err)
+		}
+		if B < certificate.Margin-1e-6 {
+			t.Errorf("Expected B(%v) >= %v on the unsafe set; received %v.", point, certificate.Margin, B)
+		}
+	}
+}
+
+/*
+TestBarrier_FindBarrier1
+Description:
+
+	For dx/dt = -x, trajectories starting in the disk of radius 0.5 around (1, 0) never reach the half plane
+	x1 >= 3; B = |x|^2 - 3 is a quadratic barrier certificate.
+*/
+func TestBarrier_FindBarrier1(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	problem := sos.BarrierProblem{
+		Variables: x,
+		Dynamics: []symbolic.Polynomial{
+			{Monomials: []symbolic.Monomial{term(-1, x, 1, 0)}},
+			{Monomials: []symbolic.Monomial{term(-1, x, 0, 1)}},
+		},
+		Initial: []symbolic.Polynomial{disk(x, 1, 0, 0.5)},
+		Unsafe:  []symbolic.Polynomial{{Monomials: []symbolic.Monomial{term(1, x, 1, 0), term(-3, x, 0, 0)}}},
+	}
+
+	// Algorithm
+	certificate, err := sos.FindBarrier(problem, 2)
+	if err != nil {
+		t.Fatalf("There was an error finding a barrier certificate: %v", err)
+	}
+	if len(certificate.InitialMultipliers) != 1 || len(certificate.UnsafeMultipliers) != 1 || len(certificate.Multipliers) != 2 {
+		t.Errorf("Expected one multiplier per inequality; received %v multipliers.", len(certificate.Multipliers))
+	}
+	checkBarrier(t, x, certificate,
+		[][2]float64{{1, 0}, {1.5, 0}, {0.5, 0}, {1, 0.5}, {1, -0.5}},
+		[][2]float64{{3, 0}, {3, 2}, {4, -1}, {10, 5}},
+	)
+}
+
+/*
+TestBarrier_FindBarrier2
+Description:
+
+	Safety of the nonlinear system dx1/dt = x2, dx2/dt = -x1 + x1^3 / 3 - x2 from the disk of radius 0.5 around
+	(1.5, 0) to the disk of radius 0.4 around (-1, -1), for which a quartic barrier certificate exists.
+*/
+func TestBarrier_FindBarrier2(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	problem := sos.BarrierProblem{
+		Variables: x,
+		Dynamics: []symbolic.Polynomial{
+			{Monomials: []symbolic.Monomial{term(1, x, 0, 1)}},
+			{Monomials: []symbolic.Monomial{term(-1, x, 1, 0), term(1.0/3.0, x, 3, 0), term(-1, x, 0, 1)}},
+		},
+		Initial: []symbolic.Polynomial{disk(x, 1.5, 0, 0.5)},
+		Unsafe:  []symbolic.Polynomial{disk(x, -1, -1, 0.4)},
+	}
+
+	// Algorithm
+	certificate, err := sos.FindBarrier(problem, 4)
+	if err != nil {
+		t.Fatalf("There was an error finding a barrier certificate: %v", err)
+	}
+	checkBarrier(t, x, certificate,
+		[][2]float64{{1.5, 0}, {2, 0}, {1, 0}, {1.5, 0.5}, {1.5, -0.5}},
+		[][2]float64{{-1, -1}, {-1.4, -1}, {-0.6, -1}, {-1, -0.6}, {-1, -1.4}},
+	)
+}
+
+/*
+TestBarrier_FindBarrier3
+Description:
+
+	Verifies that overlapping initial and unsafe sets admit no barrier certificate and that malformed problems are
+	rejected.
+*/
+func TestBarrier_FindBarrier3(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	y, _ := symbolic.GetVariableVector("y", 1)
+	dynamics := []symbolic.Polynomial{
+		{Monomials: []symbolic.Monomial{term(-1, x, 1, 0)}},
+		{Monomials: []symbolic.Monomial{term(-1, x, 0, 1)}},
+	}
+	overlapping := sos.BarrierProblem{
+		Variables: x,
+		Dynamics:  dynamics,
+		Initial:   []symbolic.Polynomial{disk(x, 1, 0, 0.5)},
+		Unsafe:    []symbolic.Polynomial{disk(x, 1, 0, 0.1)},
+	}
+
+	// Algorithm
+	if _, err := sos.FindBarrier(overlapping, 2); err == nil {
+		t.Errorf("Expected no barrier certificate for overlapping initial and unsafe sets.")
+	}
+
+	malformed := overlapping
+	malformed.Dynamics = dynamics[:1]
+	if _, err := sos.FindBarrier(malformed, 2); err == nil {
+		t.Errorf("Expected an error for dynamics with the wrong number of components.")
+	}
+
+	malformed = overlapping
+	malformed.Unsafe = nil
+	if _, err := sos.FindBarrier(malformed, 2); err == nil {
+		t.Errorf("Expected an error for an empty description of the unsafe set.")
+	}
+
+	malformed = overlapping
+	malformed.Initial = []symbolic.Polynomial{{Monomials: []symbolic.Monomial{term(1, y, 1)}}}
+	if _, err := sos.FindBarrier(malformed, 2); err == nil {
+		t.Errorf("Expected an error for an initial set in a foreign variable.")
+	}
+
+	if _, err := sos.FindBarrier(overlapping, 0); err == nil {
+		t.Errorf("Expected an error for a barrier certificate of degree 0.")
+	}
+}
+
+/*
+TestBarrier_FindBarrier4
+Description:
+
+	With the loose solver tolerance 0.1, the semidefinite program of the problem of TestBarrier_FindBarrier2 is
+	reported as solved although its solution misses the SOS conditions by about 1e-5; the certificate built from it
+	must be rejected instead of returned.
+*/
+func TestBarrier_FindBarrier4(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	problem := sos.BarrierProblem{
+		Variables: x,
+		Dynamics: []symbolic.Polynomial{
+			{Monomials: []symbolic.Monomial{term(1, x, 0, 1)}},
+			{Monomials: []symbolic.Monomial{term(-1, x, 1, 0), term(1.0/3.0, x, 3, 0), term(-1, x, 0, 1)}},
+		},
+		Initial: []symbolic.Polynomial{disk(x, 1.5, 0, 0.5)},
+		Unsafe:  []symbolic.Polynomial{disk(x, -1, -1, 0.4)},
+	}
+	options := sos.BarrierOptions{SDP: sos.SDPOptions{Tolerance: 0.1}}
+
+	// Algorithm
+	certificate, err := sos.FindBarrierWithOptions(problem, 4, options)
+	if err == nil {
+		t.Errorf("Expected the inexact solution to be rejected; received a certificate with status %v.", certificate.Solution.Status)
+	} else if !strings.Contains(err.Error(), "not certified") {
+		t.Errorf("Expected the certificate to fail verification; received %v", err)
+	}
+}
+
+/*
+TestBarrier_Check1
+Description:
+
+	Verifies that a problem with foreign variables in several of its polynomials always reports the first of them,
+	in the order dynamics, initial set, unsafe set and domain.
+*/
+func TestBarrier_Check1(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	y, _ := symbolic.GetVariableVector("y", 1)
+	foreign := symbolic.Polynomial{Monomials: []symbolic.Monomial{term(1, y, 1)}}
+	problem := sos.BarrierProblem{
+		Variables: x,
+		Dynamics:  []symbolic.Polynomial{{Monomials: []symbolic.Monomial{term(-1, x, 1, 0)}}, foreign},
+		Initial:   []symbolic.Polynomial{disk(x, 1, 0, 0.5)},
+		Unsafe:    []symbolic.Polynomial{foreign},
+		Domain:    []symbolic.Polynomial{foreign},
+	}
+
+	// Algorithm
+	for trial := 0; trial < 20; trial++ {
+		err := problem.Check()
+		if err == nil {
+			t.Fatalf("Expected an error for polynomials in a foreign variable.")
+		}
+		if !strings.Contains(err.Error(), "Polynomial 2 of the dynamics") {
+			t.Fatalf("Expected the error to report the dynamics; received %v", err)
+		}
+	}
+}
+
+/*
+scaled
+Description:
+
+	Returns the polynomial alpha p.
+*/
+func scaled(p symbolic.Polynomial, alpha float64) symbolic.Polynomial {
+	result := symbolic.Polynomial{Monomials: make([]symbolic.Monomial, len(p.Monomials))}
+	for i, monomial := range p.Monomials {
+		result.Monomials[i] = monomial
+		result.Monomials[i].Coefficient *= alpha
+	}
+	return result
+}
+
+/*
+TestBarrier_Verify1
+Description:
+
+	Verifies that the multipliers of the set conditions are checked: a multiplier of the unsafe set that no longer
+	matches its Gram matrix, and a multiplier of the initial set that is still a sum of squares (its Gram matrix is
+	scaled with it) but no longer gives the initial condition, are both reported for their set.
+*/
+func TestBarrier_Verify1(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	problem := sos.BarrierProblem{
+		Variables: x,
+		Dynamics: []symbolic.Polynomial{
+			{Monomials: []symbolic.Monomial{term(-1, x, 1, 0)}},
+			{Monomials: []symbolic.Monomial{term(-1, x, 0, 1)}},
+		},
+		Initial: []symbolic.Polynomial{disk(x, 1, 0, 0.5)},
+		Unsafe:  []symbolic.Polynomial{{Monomials: []symbolic.Monomial{term(1, x, 1, 0), term(-3, x, 0, 0)}}},
+	}
+	certificate, err := sos.FindBarrier(problem, 2)
+	if err != nil {
+		t.Fatalf("There was an error finding a barrier certificate: %v", err)
+	}
+
+	// Algorithm
+	tampered := certificate
+	tampered.UnsafeMultipliers = []symbolic.Polynomial{scaled(certificate.UnsafeMultipliers[0], 2)}
+	if err := tampered.Verify(1e-6); (err == nil) || !strings.Contains(err.Error(), "Multiplier 1 of the unsafe set") {
+		t.Errorf("Expected the unsafe multiplier to fail its Gram matrix; received %v", err)
+	}
+
+	tampered = certificate
+	tampered.InitialMultipliers = []symbolic.Polynomial{scaled(certificate.InitialMultipliers[0], 2)}
+	tampered.Multipliers = append([]sos.GramCertificate{}, certificate.Multipliers...)
+	gram := mat.NewSymDense(certificate.Multipliers[0].Gram.SymmetricDim(), nil)
+	gram.ScaleSym(2, certificate.Multipliers[0].Gram)
+	tampered.Multipliers[0].Gram = gram
+	if err := tampered.Verify(1e-6); (err == nil) || !strings.Contains(err.Error(), "condition on the initial set") {
+		t.Errorf("Expected the initial condition to fail with a rescaled multiplier; received %v", err)
+	}
+}
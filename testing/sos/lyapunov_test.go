@@ -0,0 +1,187 @@
+package sos_test
+
+/*
+lyapunov_test.go
+Description:
+	Tests for the Lyapunov function search defined in lyapunov.go.
+*/
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/sos"
+	"github.com/kwesiRutledge/goControl/symbolic"
+)
+
+/*
+term
+Description:
+
+	Returns the monomial coefficient v1^e1 v2^e2 ... for the variables and exponents given in pairs.
+*/
+func term(coefficient float64, variables []symbolic.Variable, exponents ...int) symbolic.Monomial {
+	monomial := symbolic.Monomial{Coefficient: coefficient, Variables: []symbolic.Variable{}, Exponents: []int{}}
+	for i, exponent := range exponents {
+		if exponent > 0 {
+			monomial.Variables = append(monomial.Variables, variables[i])
+			monomial.Exponents = append(monomial.Exponents, exponent)
+		}
+	}
+	return monomial
+}
+
+/*
+checkLyapunov
+Description:
+
+	Verifies the certificate (positive semidefinite Gram matrices whose polynomials match V - eps1 |x|^2 and
+	-dV/dt - eps2 |x|^2) and checks on a grid of points that V is positive and dV/dt is nonpositive away from the
+	origin.
+*/
+func checkLyapunov(t *testing.T, x []symbolic.Variable, certificate sos.LyapunovCertificate) {
+	// Algorithm
+	if err := certificate.Verify(1e-6); err != nil {
+		t.Errorf("Expected the certificate to verify; received %v", err)
+	}
+	for _, a := range []float64{-1.5, -0.5, 0, 0.5, 1.5} {
+		for _, b := range []float64{-1.5, -0.5, 0, 0.5, 1.5} {
+			if (a == 0) && (b == 0) {
+				continue
+			}
+			point := map[symbolic.Variable]float64{x[0]: a, x[1]: b}
+			V, err := certificate.V.Evaluate(point)
+			if err != nil {
+				t.Fatalf("There was an error evaluating V: %v", err)
+			}
+			Vdot, err := certificate.Derivative.Evaluate(point)
+			if err != nil {
+				t.Fatalf("There was an error evaluating dV/dt: %v", err)
+			}
+			if V <= 0 {
+				t.Errorf("Expected V(%v, %v) > 0; received %v.", a, b, V)
+			}
+			if Vdot > 1e-6 {
+				t.Errorf("Expected dV/dt(%v, %v) <= 0; received %v.", a, b, Vdot)
+			}
+		}
+	}
+}
+
+/*
+TestLyapunov_FindLyapunov1
+Description:
+
+	Finds a quadratic Lyapunov function of the nonlinear system
+		dx1/dt = -x1 - 2 x2^2,   dx2/dt = -x2 + x1 x2,
+	for which V = x1^2 + 2 x2^2 gives dV/dt = -2 x1^2 - 4 x2^2.
+*/
+func TestLyapunov_FindLyapunov1(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	f := []symbolic.Polynomial{
+		{Monomials: []symbolic.Monomial{term(-1, x, 1, 0), term(-2, x, 0, 2)}},
+		{Monomials: []symbolic.Monomial{term(-1, x, 0, 1), term(1, x, 1, 1)}},
+	}
+
+	// Algorithm
+	certificate, err := sos.FindLyapunovWithOptions(x, f, 2, sos.LyapunovOptions{DecreaseMargin: 0.1})
+	if err != nil {
+		t.Fatalf("There was an error finding a Lyapunov function: %v", err)
+	}
+	if certificate.V.Degree() != 2 {
+		t.Errorf("Expected a quadratic Lyapunov function; received degree %v.", certificate.V.Degree())
+	}
+	checkLyapunov(t, x, certificate)
+}
+
+/*
+TestLyapunov_FindLyapunov2
+Description:
+
+	The damped Duffing oscillator dx1/dt = x2, dx2/dt = -x1 - x1^3 - x2 has no quadratic SOS Lyapunov function (the
+	x1^3 x2 term of dV/dt cannot be cancelled), but has the quartic energy V = x1^2 + x1^4 / 2 + x2^2.
+*/
+func TestLyapunov_FindLyapunov2(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	f := []symbolic.Polynomial{
+		{Monomials: []symbolic.Monomial{term(1, x, 0, 1)}},
+		{Monomials: []symbolic.Monomial{term(-1, x, 1, 0), term(-1, x, 3, 0), term(-1, x, 0, 1)}},
+	}
+
+	// Algorithm
+	if _, err := sos.FindLyapunov(x, f, 2); err == nil {
+		t.Errorf("Expected no quadratic Lyapunov function to be found.")
+	}
+
+	certificate, err := sos.FindLyapunov(x, f, 4)
+	if err != nil {
+		t.Fatalf("There was an error finding a quartic Lyapunov function: %v", err)
+	}
+	checkLyapunov(t, x, certificate)
+}
+
+/*
+TestLyapunov_FindLyapunov3
+Description:
+
+	Verifies that odd degrees, dynamics whose equilibrium is not the origin and unstable dynamics are rejected.
+*/
+func TestLyapunov_FindLyapunov3(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	unstable := []symbolic.Polynomial{
+		{Monomials: []symbolic.Monomial{term(1, x, 1, 0)}},
+		{Monomials: []symbolic.Monomial{term(-1, x, 0, 1)}},
+	}
+	shifted := []symbolic.Polynomial{
+		{Monomials: []symbolic.Monomial{term(-1, x, 1, 0), term(1, x, 0, 0)}},
+		{Monomials: []symbolic.Monomial{term(-1, x, 0, 1)}},
+	}
+
+	// Algorithm
+	if _, err := sos.FindLyapunov(x, unstable, 3); err == nil {
+		t.Errorf("Expected an error for an odd degree.")
+	}
+	if _, err := sos.FindLyapunov(x, shifted, 2); err == nil {
+		t.Errorf("Expected an error for dynamics whose equilibrium is not the origin.")
+	}
+	if _, err := sos.FindLyapunov(x, unstable, 2); err == nil {
+		t.Errorf("Expected no Lyapunov function for unstable dynamics.")
+	}
+}
+
+/*
+TestLyapunov_Verify1
+Description:
+
+	Verifies that the stored Derivative must be grad V . f: an edited Derivative, which still looks like a decrease
+	(twice the true one), and a certificate moved onto the dynamics 2 f, whose Derivative is that of f, are both
+	rejected as derivative errors.
+*/
+func TestLyapunov_Verify1(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	f := []symbolic.Polynomial{
+		{Monomials: []symbolic.Monomial{term(-1, x, 1, 0), term(-2, x, 0, 2)}},
+		{Monomials: []symbolic.Monomial{term(-1, x, 0, 1), term(1, x, 1, 1)}},
+	}
+	certificate, err := sos.FindLyapunov(x, f, 2)
+	if err != nil {
+		t.Fatalf("There was an error finding a Lyapunov function: %v", err)
+	}
+
+	// Algorithm
+	tampered := certificate
+	tampered.Derivative = scaled(certificate.Derivative, 2)
+	if err := tampered.Verify(1e-6); (err == nil) || !strings.Contains(err.Error(), "derivative") {
+		t.Errorf("Expected an edited derivative to be rejected; received %v", err)
+	}
+
+	tampered = certificate
+	tampered.Dynamics = []symbolic.Polynomial{scaled(f[0], 2), scaled(f[1], 2)}
+	if err := tampered.Verify(1e-6); (err == nil) || !strings.Contains(err.Error(), "derivative") {
+		t.Errorf("Expected the derivative of other dynamics to be rejected; received %v", err)
+	}
+}
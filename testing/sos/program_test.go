@@ -0,0 +1,219 @@
+package sos_test
+
+/*
+program_test.go
+Description:
+	Tests for the sum of squares programs defined in program.go.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/sos"
+	"github.com/kwesiRutledge/goControl/symbolic"
+)
+
+/*
+TestProgram_GetProgram1
+Description:
+
+	Verifies that programs without variables or with a repeated variable are rejected.
+*/
+func TestProgram_GetProgram1(t *testing.T) {
+	// Constants
+	x := symbolic.Variable{Name: "x"}
+
+	// Algorithm
+	if _, err := sos.GetProgram([]symbolic.Variable{}); err == nil {
+		t.Errorf("Expected an error for a program without variables.")
+	}
+	if _, err := sos.GetProgram([]symbolic.Variable{x, x}); err == nil {
+		t.Errorf("Expected an error for a repeated variable.")
+	}
+}
+
+/*
+TestProgram_Solve1
+Description:
+
+	Maximizes gamma such that x^4 - 2 x^2 - gamma is a sum of squares. Since x^4 - 2 x^2 + 1 = (x^2 - 1)^2 and the
+	minimum of x^4 - 2 x^2 is -1, the optimum is gamma = -1, and the certificate must reproduce the polynomial.
+*/
+func TestProgram_Solve1(t *testing.T) {
+	// Constants
+	x := symbolic.Variable{Name: "x"}
+	p := symbolic.Polynomial{Monomials: []symbolic.Monomial{
+		{Coefficient: 1, Variables: []symbolic.Variable{x}, Exponents: []int{4}},
+		{Coefficient: -2, Variables: []symbolic.Variable{x}, Exponents: []int{2}},
+	}}
+
+	prog, err := sos.GetProgram([]symbolic.Variable{x})
+	if err != nil {
+		t.Fatalf("There was an error creating the program: %v", err)
+	}
+	fixed, err := prog.Constant(p)
+	if err != nil {
+		t.Fatalf("There was an error converting the polynomial: %v", err)
+	}
+	gamma := prog.NewVariable()
+
+	// Algorithm
+	index, err := prog.AddSOSConstraint(fixed.Minus(gamma))
+	if err != nil {
+		t.Fatalf("There was an error adding the constraint: %v", err)
+	}
+	if err := prog.Minimize(gamma.Scale(-1)); err != nil {
+		t.Fatalf("There was an error setting the objective: %v", err)
+	}
+	solution, err := prog.Solve()
+	if err != nil {
+		t.Fatalf("There was an error solving the program: %v", err)
+	}
+
+	if solution.SDP.Status != sos.SDPOptimal {
+		t.Fatalf("Expected the program to be solved; received status %v.", solution.SDP.Status)
+	}
+	if value := solution.Scalar(gamma); math.Abs(value+1) > 1e-5 {
+		t.Errorf("Expected gamma = -1; received %v.", value)
+	}
+
+	certificate, err := solution.Certificate(index)
+	if err != nil {
+		t.Fatalf("There was an error retrieving the certificate: %v", err)
+	}
+	if len(certificate.Basis) != 3 {
+		t.Errorf("Expected the basis (1, x, x^2); received %v monomials.", len(certificate.Basis))
+	}
+	if certificate.MinEigenvalue() < -1e-7 {
+		t.Errorf("Expected a positive semidefinite Gram matrix; its smallest eigenvalue is %v.", certificate.MinEigenvalue())
+	}
+	for _, value := range []float64{-2, -0.5, 0, 1.5} {
+		point := map[symbolic.Variable]float64{x: value}
+		expected, _ := p.Evaluate(point)
+		received, err := certificate.Polynomial().Evaluate(point)
+		if err != nil {
+			t.Fatalf("There was an error evaluating the certificate: %v", err)
+		}
+		if math.Abs(received-(expected+1)) > 1e-5 {
+			t.Errorf("Expected the certificate to equal %v at x = %v; received %v.", expected+1, value, received)
+		}
+	}
+}
+
+/*
+TestProgram_Solve2
+Description:
+
+	Finds a polynomial q of degree 2 with q(x) - (x^2 + 2 x + 1) = 0 through an equality constraint, and verifies
+	that the recovered polynomial has the expected coefficients.
+*/
+func TestProgram_Solve2(t *testing.T) {
+	// Constants
+	x := symbolic.Variable{Name: "x"}
+	target := symbolic.Polynomial{Monomials: []symbolic.Monomial{
+		{Coefficient: 1, Variables: []symbolic.Variable{x}, Exponents: []int{2}},
+		{Coefficient: 2, Variables: []symbolic.Variable{x}, Exponents: []int{1}},
+		{Coefficient: 1, Variables: []symbolic.Variable{}, Exponents: []int{}},
+	}}
+
+	prog, _ := sos.GetProgram([]symbolic.Variable{x})
+	q, err := prog.NewPolynomial(0, 2)
+	if err != nil {
+		t.Fatalf("There was an error creating the polynomial: %v", err)
+	}
+	fixed, _ := prog.Constant(target)
+
+	// Algorithm
+	if err := prog.AddEqualityConstraint(q.Minus(fixed)); err != nil {
+		t.Fatalf("There was an error adding the constraint: %v", err)
+	}
+	if _, err := prog.AddSOSConstraint(q); err != nil {
+		t.Fatalf("There was an error adding the constraint: %v", err)
+	}
+	solution, err := prog.Solve()
+	if err != nil {
+		t.Fatalf("There was an error solving the program: %v", err)
+	}
+
+	value := solution.Value(q)
+	for _, point := range []float64{-1, 0, 2} {
+		expected, _ := target.Evaluate(map[symbolic.Variable]float64{x: point})
+		received, _ := value.Evaluate(map[symbolic.Variable]float64{x: point})
+		if math.Abs(received-expected) > 1e-6 {
+			t.Errorf("Expected q(%v) = %v; received %v.", point, expected, received)
+		}
+	}
+}
+
+/*
+TestProgram_Minimize1
+Description:
+
+	Maximizes gamma such that x^4 - 2 x^2 - gamma is a sum of squares, as in TestProgram_Solve1, with an objective
+	that also carries the cancelled monomials of p - p. Their coefficients are zero, so the objective is still
+	-gamma and the optimum is gamma = -1 every time the objective is set.
+*/
+func TestProgram_Minimize1(t *testing.T) {
+	// Constants
+	x := symbolic.Variable{Name: "x"}
+	p := symbolic.Polynomial{Monomials: []symbolic.Monomial{
+		{Coefficient: 1, Variables: []symbolic.Variable{x}, Exponents: []int{4}},
+		{Coefficient: -2, Variables: []symbolic.Variable{x}, Exponents: []int{2}},
+	}}
+
+	prog, _ := sos.GetProgram([]symbolic.Variable{x})
+	fixed, _ := prog.Constant(p)
+	gamma := prog.NewVariable()
+	if _, err := prog.AddSOSConstraint(fixed.Minus(gamma)); err != nil {
+		t.Fatalf("There was an error adding the constraint: %v", err)
+	}
+	objective := gamma.Scale(-1).Plus(fixed.Minus(fixed))
+
+	// Algorithm
+	for trial := 0; trial < 10; trial++ {
+		if err := prog.Minimize(objective); err != nil {
+			t.Fatalf("There was an error setting the objective: %v", err)
+		}
+		solution, err := prog.Solve()
+		if err != nil {
+			t.Fatalf("There was an error solving the program: %v", err)
+		}
+		if value := solution.Scalar(gamma); math.Abs(value+1) > 1e-5 {
+			t.Fatalf("Expected gamma = -1 in trial %v; received %v.", trial, value)
+		}
+	}
+}
+
+/*
+TestProgram_AddSOSConstraint1
+Description:
+
+	Verifies that odd polynomials cannot be constrained to be sums of squares, that two polynomials depending on
+	decision variables cannot be multiplied, and that a violated constant constraint is reported.
+*/
+func TestProgram_AddSOSConstraint1(t *testing.T) {
+	// Constants
+	x := symbolic.Variable{Name: "x"}
+	prog, _ := sos.GetProgram([]symbolic.Variable{x})
+	cubic, _ := prog.Constant(symbolic.Monomial{Coefficient: 1, Variables: []symbolic.Variable{x}, Exponents: []int{3}})
+
+	// Algorithm
+	if _, err := prog.AddSOSConstraint(cubic); err == nil {
+		t.Errorf("Expected an error for the odd polynomial x^3.")
+	}
+
+	p, _ := prog.NewPolynomial(0, 1)
+	q, _ := prog.NewPolynomial(0, 1)
+	if _, err := p.Multiply(q); err == nil {
+		t.Errorf("Expected an error for a product of two polynomials with decision variables.")
+	}
+
+	linear, _ := prog.Constant(x)
+	if err := prog.AddEqualityConstraint(linear); err != nil {
+		t.Fatalf("There was an error adding the constraint: %v", err)
+	}
+	if _, err := prog.Solve(); err == nil {
+		t.Errorf("Expected an error for the violated constraint x = 0.")
+	}
+}
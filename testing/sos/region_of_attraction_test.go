@@ -0,0 +1,246 @@
+package sos_test
+
+/*
+region_of_attraction_test.go
+Description:
+	Tests for the region of attraction estimation defined in region_of_attraction.go.
+*/
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/sos"
+	"github.com/kwesiRutledge/goControl/symbolic"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+vanDerPol
+Description:
+
+	Returns the Van der Pol oscillator in reverse time, dx1/dt = -x2, dx2/dt = x1 + (x1^2 - 1) x2, whose origin is
+	stable with a region of attraction bounded by the limit cycle, and the quadratic Lyapunov function
+	V = 1.5 x1^2 - x1 x2 + x2^2 of its linearization.
+*/
+func vanDerPol(x []symbolic.Variable) ([]symbolic.Polynomial, symbolic.Polynomial) {
+	f := []symbolic.Polynomial{
+		{Monomials: []symbolic.Monomial{term(-1, x, 0, 1)}},
+		{Monomials: []symbolic.Monomial{term(1, x, 1, 0), term(1, x, 2, 1), term(-1, x, 0, 1)}},
+	}
+	V := symbolic.Polynomial{Monomials: []symbolic.Monomial{term(1.5, x, 2, 0), term(-1, x, 1, 1), term(1, x, 0, 2)}}
+	return f, V
+}
+
+/*
+checkRegion
+Description:
+
+	Verifies the estimate (positive semidefinite Gram matrices whose polynomials match the conditions) and checks on
+	a grid of points that dV/dt is negative on the certified sublevel set away from the origin and that the sublevel
+	set of the shape function is contained in it.
+*/
+func checkRegion(t *testing.T, x []symbolic.Variable, region sos.RegionOfAttraction) {
+	// Algorithm
+	if err := region.Verify(1e-6); err != nil {
+		t.Errorf("Expected the estimate to verify; received %v", err)
+	}
+	for a := -3.0; a <= 3.0; a += 0.25 {
+		for b := -3.0; b <= 3.0; b += 0.25 {
+			if (a == 0) && (b == 0) {
+				continue
+			}
+			point := map[symbolic.Variable]float64{x[0]: a, x[1]: b}
+			V, _ := region.V.Evaluate(point)
+			Vdot, _ := region.Derivative.Evaluate(point)
+			p, _ := region.Shape.Evaluate(point)
+			if (V <= region.Level) && (Vdot >= 0) {
+				t.Errorf("Expected dV/dt(%v, %v) < 0 inside the certified region; received %v.", a, b, Vdot)
+			}
+			if (p <= region.ShapeLevel) && (V > region.Level*(1+1e-6)) {
+				t.Errorf("Expected (%v, %v) with p = %v to lie in {V <= %v}; received V = %v.", a, b, p, region.Level, V)
+			}
+		}
+	}
+}
+
+/*
+TestRegionOfAttraction_EstimateRegionOfAttraction1
+Description:
+
+	Certifies a sublevel set of the quadratic Lyapunov function of the linearized Van der Pol oscillator, and checks
+	that it excludes the point (2.5, 0) outside the limit cycle.
+*/
+func TestRegionOfAttraction_EstimateRegionOfAttraction1(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	f, V := vanDerPol(x)
+
+	// Algorithm
+	region, err := sos.EstimateRegionOfAttractionWithOptions(x, f, V, sos.RegionOfAttractionOptions{Iterations: 1})
+	if err != nil {
+		t.Fatalf("There was an error estimating the region of attraction: %v", err)
+	}
+	if !(region.Level > 0) || !(region.ShapeLevel > 0) {
+		t.Errorf("Expected positive levels; received %v and %v.", region.Level, region.ShapeLevel)
+	}
+	if region.Iterations != 1 {
+		t.Errorf("Expected a single iteration; received %v.", region.Iterations)
+	}
+	outside, _ := region.V.Evaluate(map[symbolic.Variable]float64{x[0]: 2.5, x[1]: 0})
+	if outside <= region.Level {
+		t.Errorf("Expected (2.5, 0) to lie outside the certified region; V = %v <= %v.", outside, region.Level)
+	}
+	checkRegion(t, x, region)
+}
+
+/*
+TestRegionOfAttraction_EstimateRegionOfAttraction2
+Description:
+
+	Verifies that the V-s iteration with a quartic Lyapunov function certifies a larger disk {|x|^2 <= beta} in the
+	region of attraction of the Van der Pol oscillator than the quadratic candidate alone, and that the disk stays
+	inside the limit cycle (whose closest point to the origin has |x|^2 of about 2.35).
+*/
+func TestRegionOfAttraction_EstimateRegionOfAttraction2(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	f, V := vanDerPol(x)
+
+	// Algorithm
+	initial, err := sos.EstimateRegionOfAttractionWithOptions(x, f, V, sos.RegionOfAttractionOptions{Iterations: 1})
+	if err != nil {
+		t.Fatalf("There was an error certifying the candidate: %v", err)
+	}
+	region, err := sos.EstimateRegionOfAttractionWithOptions(x, f, V, sos.RegionOfAttractionOptions{Degree: 4, Iterations: 5})
+	if err != nil {
+		t.Fatalf("There was an error estimating the region of attraction: %v", err)
+	}
+	if region.ShapeLevel <= 1.1*initial.ShapeLevel {
+		t.Errorf("Expected the iteration to enlarge the certified disk beyond %v; received %v.", initial.ShapeLevel, region.ShapeLevel)
+	}
+	if region.ShapeLevel >= 2.35 {
+		t.Errorf("Expected the certified disk to stay inside the limit cycle; received |x|^2 <= %v.", region.ShapeLevel)
+	}
+	checkRegion(t, x, region)
+
+	// The certified disk must be attracted to the origin.
+	r := math.Sqrt(region.ShapeLevel) * 0.99
+	for k := 0; k < 8; k++ {
+		angle := float64(k) * math.Pi / 4
+		x1, x2 := r*math.Cos(angle), r*math.Sin(angle)
+		for step := 0; step < 20000; step++ {
+			dx1, dx2 := -x2, x1+(x1*x1-1)*x2
+			x1, x2 = x1+1e-3*dx1, x2+1e-3*dx2
+		}
+		if math.Hypot(x1, x2) > 1e-2 {
+			t.Errorf("Expected the trajectory from angle %v to approach the origin; it ended at (%v, %v).", angle, x1, x2)
+		}
+	}
+}
+
+/*
+TestRegionOfAttraction_EstimateRegionOfAttraction3
+Description:
+
+	Verifies that unstable dynamics, candidates that are not positive definite and dynamics whose equilibrium is not
+	the origin are rejected.
+*/
+func TestRegionOfAttraction_EstimateRegionOfAttraction3(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	f, V := vanDerPol(x)
+	unstable := []symbolic.Polynomial{
+		{Monomials: []symbolic.Monomial{term(1, x, 1, 0)}},
+		{Monomials: []symbolic.Monomial{term(-1, x, 0, 1)}},
+	}
+	shifted := []symbolic.Polynomial{f[0], {Monomials: append(append([]symbolic.Monomial{}, f[1].Monomials...), term(1, x, 0, 0))}}
+	indefinite := symbolic.Polynomial{Monomials: []symbolic.Monomial{term(1, x, 2, 0), term(-1, x, 0, 2)}}
+
+	// Algorithm
+	if _, err := sos.EstimateRegionOfAttraction(x, unstable, squaredNormOf(x)); err == nil {
+		t.Errorf("Expected no region of attraction for unstable dynamics.")
+	}
+	if _, err := sos.EstimateRegionOfAttraction(x, f, indefinite); err == nil {
+		t.Errorf("Expected an error for an indefinite Lyapunov candidate.")
+	}
+	if _, err := sos.EstimateRegionOfAttraction(x, shifted, V); err == nil {
+		t.Errorf("Expected an error for dynamics whose equilibrium is not the origin.")
+	}
+	if _, err := sos.EstimateRegionOfAttraction(x, f[:1], V); err == nil {
+		t.Errorf("Expected an error for dynamics with the wrong number of components.")
+	}
+}
+
+/*
+TestRegionOfAttraction_EstimateRegionOfAttraction4
+Description:
+
+	Verifies that the levels certified with a loose tolerance of the semidefinite programming solver, whose
+	solutions do not match the SOS conditions exactly, are only accepted when they pass the independent check.
+*/
+func TestRegionOfAttraction_EstimateRegionOfAttraction4(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	f, V := vanDerPol(x)
+	options := sos.RegionOfAttractionOptions{Iterations: 1, SDP: sos.SDPOptions{Tolerance: 1e-3}}
+
+	// Algorithm
+	region, err := sos.EstimateRegionOfAttractionWithOptions(x, f, V, options)
+	if err != nil {
+		t.Fatalf("There was an error estimating the region of attraction: %v", err)
+	}
+	checkRegion(t, x, region)
+}
+
+/*
+TestRegionOfAttraction_Verify1
+Description:
+
+	Verifies that each level is tied to its own condition: a larger Level breaks the decrease condition, and a
+	larger ShapeLevel, or a containment multiplier s2 rescaled together with its Gram matrix (so that it is still a
+	sum of squares), breaks the containment condition.
+*/
+func TestRegionOfAttraction_Verify1(t *testing.T) {
+	// Constants
+	x, _ := symbolic.GetVariableVector("x", 2)
+	f, V := vanDerPol(x)
+	region, err := sos.EstimateRegionOfAttractionWithOptions(x, f, V, sos.RegionOfAttractionOptions{Iterations: 1})
+	if err != nil {
+		t.Fatalf("There was an error estimating the region of attraction: %v", err)
+	}
+
+	// Algorithm
+	tampered := region
+	tampered.Level = 1.5 * region.Level
+	if err := tampered.Verify(1e-6); (err == nil) || !strings.Contains(err.Error(), "decrease of V") {
+		t.Errorf("Expected a larger level of V to fail the decrease condition; received %v", err)
+	}
+
+	tampered = region
+	tampered.ShapeLevel = 1.5 * region.ShapeLevel
+	if err := tampered.Verify(1e-6); (err == nil) || !strings.Contains(err.Error(), "containment") {
+		t.Errorf("Expected a larger level of the shape function to fail the containment condition; received %v", err)
+	}
+
+	tampered = region
+	tampered.ShapeMultiplier = scaled(region.ShapeMultiplier, 2)
+	tampered.Multipliers = append([]sos.GramCertificate{}, region.Multipliers...)
+	gram := mat.NewSymDense(region.Multipliers[1].Gram.SymmetricDim(), nil)
+	gram.ScaleSym(2, region.Multipliers[1].Gram)
+	tampered.Multipliers[1].Gram = gram
+	if err := tampered.Verify(1e-6); (err == nil) || !strings.Contains(err.Error(), "containment of the shape") {
+		t.Errorf("Expected a rescaled containment multiplier to fail the containment condition; received %v", err)
+	}
+}
+
+/*
+squaredNormOf
+Description:
+
+	Returns x1^2 + x2^2.
+*/
+func squaredNormOf(x []symbolic.Variable) symbolic.Polynomial {
+	return symbolic.Polynomial{Monomials: []symbolic.Monomial{term(1, x, 2, 0), term(1, x, 0, 2)}}
+}
@@ -0,0 +1,127 @@
+package sos_test
+
+/*
+semidefinite_program_test.go
+Description:
+	Tests for the semidefinite programming solver defined in semidefinite_program.go.
+*/
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl/sos"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestSemidefiniteProgram_Solve1
+Description:
+
+	Minimizes <C, X> with C = [2 1; 1 2] over the positive semidefinite matrices of unit trace. The minimum is the
+	smallest eigenvalue 1 of C, attained at X = v v^T with v = (1, -1) / sqrt(2).
+*/
+func TestSemidefiniteProgram_Solve1(t *testing.T) {
+	// Constants
+	sdp := sos.SemidefiniteProgram{
+		BlockSizes: []int{2},
+		C:          []*mat.SymDense{mat.NewSymDense(2, []float64{2, 1, 1, 2})},
+		Constraints: []sos.SDPConstraint{
+			{Entries: []sos.SDPEntry{{Block: 0, Row: 0, Column: 0, Value: 1}, {Block: 0, Row: 1, Column: 1, Value: 1}}, B: 1},
+		},
+	}
+
+	// Algorithm
+	solution, err := sdp.Solve()
+	if err != nil {
+		t.Fatalf("There was an error solving the SDP: %v", err)
+	}
+	if solution.Status != sos.SDPOptimal {
+		t.Fatalf("The status is %v; expected SDPOptimal", solution.Status)
+	}
+	if math.Abs(solution.PrimalObjective-1) > 1e-6 || math.Abs(solution.DualObjective-1) > 1e-6 {
+		t.Errorf("The objectives are %v and %v; expected 1", solution.PrimalObjective, solution.DualObjective)
+	}
+	X := solution.X[0]
+	if math.Abs(X.At(0, 1)+0.5) > 1e-6 || math.Abs(X.At(0, 0)-0.5) > 1e-6 {
+		t.Errorf("X = %v; expected [0.5 -0.5; -0.5 0.5]", mat.Formatted(X))
+	}
+}
+
+/*
+TestSemidefiniteProgram_Solve2
+Description:
+
+	Minimizes the free variable t subject to [t 1; 1 t] positive semidefinite, whose minimum is t = 1. The
+	matrix entries are tied to t with equality constraints.
+*/
+func TestSemidefiniteProgram_Solve2(t *testing.T) {
+	// Constants
+	sdp := sos.SemidefiniteProgram{
+		BlockSizes: []int{2},
+		NumFree:    1,
+		CFree:      mat.NewVecDense(1, []float64{1}),
+		Constraints: []sos.SDPConstraint{
+			{Entries: []sos.SDPEntry{{Block: 0, Row: 0, Column: 0, Value: 1}}, FreeEntries: []sos.SDPFreeEntry{{Index: 0, Value: -1}}},
+			{Entries: []sos.SDPEntry{{Block: 0, Row: 1, Column: 1, Value: 1}}, FreeEntries: []sos.SDPFreeEntry{{Index: 0, Value: -1}}},
+			{Entries: []sos.SDPEntry{{Block: 0, Row: 0, Column: 1, Value: 1}}, B: 1},
+		},
+	}
+
+	// Algorithm
+	solution, err := sdp.Solve()
+	if err != nil {
+		t.Fatalf("There was an error solving the SDP: %v", err)
+	}
+	if solution.Status != sos.SDPOptimal {
+		t.Fatalf("The status is %v; expected SDPOptimal", solution.Status)
+	}
+	if math.Abs(solution.Free.AtVec(0)-1) > 1e-6 {
+		t.Errorf("t = %v; expected 1", solution.Free.AtVec(0))
+	}
+}
+
+/*
+TestSemidefiniteProgram_Solve3
+Description:
+
+	Verifies that the SDP with X(0, 0) = -1 and X positive semidefinite is detected as infeasible.
+*/
+func TestSemidefiniteProgram_Solve3(t *testing.T) {
+	// Constants
+	sdp := sos.SemidefiniteProgram{
+		BlockSizes: []int{2},
+		Constraints: []sos.SDPConstraint{
+			{Entries: []sos.SDPEntry{{Block: 0, Row: 0, Column: 0, Value: 1}}, B: -1},
+		},
+	}
+
+	// Algorithm
+	solution, err := sdp.Solve()
+	if err != nil {
+		t.Fatalf("There was an error solving the SDP: %v", err)
+	}
+	if solution.Status != sos.SDPPrimalInfeasible {
+		t.Errorf("The status is %v after %v iterations; expected SDPPrimalInfeasible", solution.Status, solution.Iterations)
+	}
+}
+
+/*
+TestSemidefiniteProgram_Check1
+Description:
+
+	Verifies that constraints referring to blocks, entries or free variables that do not exist are rejected.
+*/
+func TestSemidefiniteProgram_Check1(t *testing.T) {
+	// Constants
+	for _, constraint := range []sos.SDPConstraint{
+		{Entries: []sos.SDPEntry{{Block: 1, Row: 0, Column: 0, Value: 1}}},
+		{Entries: []sos.SDPEntry{{Block: 0, Row: 2, Column: 0, Value: 1}}},
+		{FreeEntries: []sos.SDPFreeEntry{{Index: 0, Value: 1}}},
+	} {
+		sdp := sos.SemidefiniteProgram{BlockSizes: []int{2}, Constraints: []sos.SDPConstraint{constraint}}
+		if err := sdp.Check(); err == nil {
+			t.Errorf("The constraint %v was accepted.", constraint)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+   state_space_test.go
+   Description:
+	   Tests for the StateSpace type defined in state_space.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestStateSpace_GetStateSpace1
+Description:
+
+	Verifies that a state space model with incompatible B and C matrices is rejected.
+*/
+func TestStateSpace_GetStateSpace1(t *testing.T) {
+	// Algorithm
+	_, err := goControl.GetStateSpace(
+		mat.NewDense(2, 2, []float64{0, 1, -2, -3}),
+		mat.NewDense(3, 1, []float64{0, 1, 0}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+	if err == nil {
+		t.Errorf("Expected an error when B has the wrong number of rows; received nil")
+	}
+}
+
+/*
+TestStateSpace_Poles1
+Description:
+
+	Computes the poles of a second order model with poles at -1 and -2.
+*/
+func TestStateSpace_Poles1(t *testing.T) {
+	// Constants
+	sys, err := goControl.GetStateSpace(
+		mat.NewDense(2, 2, []float64{0, 1, -2, -3}),
+		mat.NewDense(2, 1, []float64{0, 1}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+	if err != nil {
+		t.Errorf("There was an error creating the model: %v", err)
+	}
+
+	// Algorithm
+	poles, err := sys.Poles()
+	if err != nil {
+		t.Errorf("There was an error computing the poles: %v", err)
+	}
+
+	expected := []complex128{-2, -1}
+	for i := range expected {
+		if cmplx.Abs(poles[i]-expected[i]) > 1e-9 {
+			t.Errorf("poles[%v] = %v; want %v", i, poles[i], expected[i])
+		}
+	}
+}
+
+/*
+TestStateSpace_DCGain1
+Description:
+
+	Verifies the DC gain of a continuous-time model and of a discrete-time model.
+*/
+func TestStateSpace_DCGain1(t *testing.T) {
+	// Constants
+	sysC, _ := goControl.GetStateSpace(
+		mat.NewDense(2, 2, []float64{0, 1, -2, -3}),
+		mat.NewDense(2, 1, []float64{0, 1}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+	sysD, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(1, 1, []float64{0.5}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+		0.1,
+	)
+
+	// Algorithm
+	gainC, err := sysC.DCGain()
+	if err != nil {
+		t.Errorf("There was an error computing the DC gain: %v", err)
+	}
+	if math.Abs(gainC.At(0, 0)-0.5) > 1e-12 {
+		t.Errorf("Continuous DC gain = %v; want 0.5", gainC.At(0, 0))
+	}
+
+	gainD, err := sysD.DCGain()
+	if err != nil {
+		t.Errorf("There was an error computing the DC gain: %v", err)
+	}
+	if math.Abs(gainD.At(0, 0)-2) > 1e-12 {
+		t.Errorf("Discrete DC gain = %v; want 2", gainD.At(0, 0))
+	}
+}
@@ -2,6 +2,7 @@ package symbolic_test
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 
@@ -329,3 +330,63 @@ func TestMonomial_String2(t *testing.T) {
 	}
 
 }
+
+/*
+TestMonomial_Evaluate1
+Description:
+
+	Evaluates the monomial 3 x^2 y at (x, y) = (2, -1) and verifies that a missing value is an error.
+*/
+func TestMonomial_Evaluate1(t *testing.T) {
+	// Constants
+	x, y := symbolic.Variable{Name: "x"}, symbolic.Variable{Name: "y"}
+	m := symbolic.Monomial{Coefficient: 3, Variables: []symbolic.Variable{x, y}, Exponents: []int{2, 1}}
+
+	// Algorithm
+	value, err := m.Evaluate(map[symbolic.Variable]float64{x: 2, y: -1})
+	if err != nil {
+		t.Fatalf("There was an error evaluating the monomial: %v", err)
+	}
+	if math.Abs(value+12) > 1e-12 {
+		t.Errorf("The monomial evaluates to %v; expected -12.", value)
+	}
+
+	if _, err := m.Evaluate(map[symbolic.Variable]float64{x: 2}); err == nil {
+		t.Errorf("The monomial was evaluated without a value for y.")
+	}
+}
+
+/*
+TestMonomial_Derivative1
+Description:
+
+	Verifies the partial derivatives of 3 x^2 y with respect to x, y and a variable that does not appear.
+*/
+func TestMonomial_Derivative1(t *testing.T) {
+	// Constants
+	x, y, z := symbolic.Variable{Name: "x"}, symbolic.Variable{Name: "y"}, symbolic.Variable{Name: "z"}
+	m := symbolic.Monomial{Coefficient: 3, Variables: []symbolic.Variable{x, y}, Exponents: []int{2, 1}}
+	point := map[symbolic.Variable]float64{x: 2, y: -1}
+
+	// Algorithm
+	for _, test := range []struct {
+		v        symbolic.Variable
+		expected float64
+	}{
+		{x, -12}, // 6 x y
+		{y, 12},  // 3 x^2
+		{z, 0},
+	} {
+		value, err := m.Derivative(test.v).Evaluate(point)
+		if err != nil {
+			t.Fatalf("There was an error evaluating the derivative: %v", err)
+		}
+		if math.Abs(value-test.expected) > 1e-12 {
+			t.Errorf("The derivative with respect to %v evaluates to %v; expected %v.", test.v, value, test.expected)
+		}
+	}
+
+	if m.Exponents[0] != 2 {
+		t.Errorf("Derivative modified the exponents of the monomial.")
+	}
+}
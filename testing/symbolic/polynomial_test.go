@@ -1,6 +1,7 @@
 package symbolic_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/kwesiRutledge/goControl/symbolic"
@@ -124,3 +125,81 @@ func TestPolynomial_FindPolynomialLikeObject3(t *testing.T) {
 		t.Errorf("The monomial was found at index %v; expected 2.", symbolic.FindPolynomialLikeObject(crazySlice))
 	}
 }
+
+/*
+TestPolynomial_Evaluate1
+Description:
+
+	Evaluates the polynomial x^2 - 2 x y + 1 and its partial derivatives at (x, y) = (3, 2).
+*/
+func TestPolynomial_Evaluate1(t *testing.T) {
+	// Constants
+	x, y := symbolic.Variable{Name: "x"}, symbolic.Variable{Name: "y"}
+	p := symbolic.Polynomial{Monomials: []symbolic.Monomial{
+		{Coefficient: 1, Variables: []symbolic.Variable{x}, Exponents: []int{2}},
+		{Coefficient: -2, Variables: []symbolic.Variable{x, y}, Exponents: []int{1, 1}},
+		{Coefficient: 1, Variables: []symbolic.Variable{}, Exponents: []int{}},
+	}}
+	point := map[symbolic.Variable]float64{x: 3, y: 2}
+
+	// Algorithm
+	for _, test := range []struct {
+		p        symbolic.Polynomial
+		expected float64
+	}{
+		{p, -2},
+		{p.Derivative(x), 2},  // 2 x - 2 y
+		{p.Derivative(y), -6}, // -2 x
+		{p.Derivative(y).Derivative(y), 0},
+	} {
+		value, err := test.p.Evaluate(point)
+		if err != nil {
+			t.Fatalf("There was an error evaluating the polynomial: %v", err)
+		}
+		if math.Abs(value-test.expected) > 1e-12 {
+			t.Errorf("The polynomial evaluates to %v; expected %v.", value, test.expected)
+		}
+	}
+
+	if p.Degree() != 2 {
+		t.Errorf("The degree is %v; expected 2.", p.Degree())
+	}
+	if variables := p.Variables(); len(variables) != 2 {
+		t.Errorf("The polynomial has %v variables; expected 2.", len(variables))
+	}
+}
+
+/*
+TestPolynomial_ToPolynomial1
+Description:
+
+	Converts a constant, a Variable and a Monomial into polynomials, and verifies that other types are rejected.
+*/
+func TestPolynomial_ToPolynomial1(t *testing.T) {
+	// Constants
+	x := symbolic.Variable{Name: "x"}
+	point := map[symbolic.Variable]float64{x: 1.5}
+
+	// Algorithm
+	for _, test := range []struct {
+		e        interface{}
+		expected float64
+	}{
+		{2.0, 2},
+		{x, 1.5},
+		{&x, 1.5},
+		{symbolic.Monomial{Coefficient: 2, Variables: []symbolic.Variable{x}, Exponents: []int{2}}, 4.5},
+	} {
+		p, err := symbolic.ToPolynomial(test.e)
+		if err != nil {
+			t.Fatalf("There was an error converting %v: %v", test.e, err)
+		}
+		if value, _ := p.Evaluate(point); math.Abs(value-test.expected) > 1e-12 {
+			t.Errorf("The conversion of %v evaluates to %v; expected %v.", test.e, value, test.expected)
+		}
+	}
+
+	if _, err := symbolic.ToPolynomial("x"); err == nil {
+		t.Errorf("A string was converted into a Polynomial.")
+	}
+}
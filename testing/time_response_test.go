@@ -0,0 +1,184 @@
+/*
+   time_response_test.go
+   Description:
+	   Tests for the Lsim, Step and Impulse functions defined in time_response.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+getTestFirstOrderSystem
+Description:
+
+	Creates the continuous-time model 1/(s + 1).
+*/
+func getTestFirstOrderSystem() goControl.StateSpace {
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(1, 1, []float64{-1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+	)
+	return sys
+}
+
+/*
+TestTimeResponse_Step1
+Description:
+
+	Compares the step response of 1/(s + 1) with 1 - e^{-t} and verifies that the automatically
+	chosen final time lets the response settle.
+*/
+func TestTimeResponse_Step1(t *testing.T) {
+	// Constants
+	sys := getTestFirstOrderSystem()
+
+	// Algorithm
+	responses, err := goControl.Step(sys, 0)
+	if err != nil {
+		t.Errorf("There was an error computing the step response: %v", err)
+	}
+
+	response := responses[0]
+	N := response.Time.Len()
+	for k := 0; k < N; k++ {
+		tk := response.Time.AtVec(k)
+		if math.Abs(response.Output.At(k, 0)-(1-math.Exp(-tk))) > 1e-10 {
+			t.Errorf("y(%v) = %v; want %v", tk, response.Output.At(k, 0), 1-math.Exp(-tk))
+		}
+	}
+
+	if math.Abs(response.Output.At(N-1, 0)-1) > 1e-2 {
+		t.Errorf("The final value of the step response is %v; want approximately 1", response.Output.At(N-1, 0))
+	}
+}
+
+/*
+TestTimeResponse_Impulse1
+Description:
+
+	Compares the impulse response of 1/(s + 1) with e^{-t}.
+*/
+func TestTimeResponse_Impulse1(t *testing.T) {
+	// Constants
+	sys := getTestFirstOrderSystem()
+
+	// Algorithm
+	responses, err := goControl.Impulse(sys, 5)
+	if err != nil {
+		t.Errorf("There was an error computing the impulse response: %v", err)
+	}
+
+	response := responses[0]
+	if math.Abs(response.Time.AtVec(response.Time.Len()-1)-5) > 1e-9 {
+		t.Errorf("The final time is %v; want 5", response.Time.AtVec(response.Time.Len()-1))
+	}
+	for k := 0; k < response.Time.Len(); k++ {
+		tk := response.Time.AtVec(k)
+		if math.Abs(response.Output.At(k, 0)-math.Exp(-tk)) > 1e-10 {
+			t.Errorf("y(%v) = %v; want %v", tk, response.Output.At(k, 0), math.Exp(-tk))
+		}
+	}
+}
+
+/*
+TestTimeResponse_Lsim1
+Description:
+
+	Simulates 1/(s + 1) on an unevenly spaced time grid with a piecewise-constant input and an
+	initial state, and compares with the exact solution.
+*/
+func TestTimeResponse_Lsim1(t *testing.T) {
+	// Constants
+	sys := getTestFirstOrderSystem()
+	times := []float64{0, 0.1, 0.35, 0.5, 1.2, 2.0}
+	u := mat.NewDense(len(times), 1, []float64{1, 1, -2, -2, 0.5, 0.5})
+	x0 := mat.NewVecDense(1, []float64{3})
+
+	// Algorithm
+	response, err := goControl.Lsim(sys, u, times, x0)
+	if err != nil {
+		t.Errorf("There was an error simulating the model: %v", err)
+	}
+
+	x := 3.0
+	for k := range times {
+		if math.Abs(response.State.At(k, 0)-x) > 1e-12 {
+			t.Errorf("x(%v) = %v; want %v", times[k], response.State.At(k, 0), x)
+		}
+		if k < len(times)-1 {
+			dt := times[k+1] - times[k]
+			x = math.Exp(-dt)*x + (1-math.Exp(-dt))*u.At(k, 0)
+		}
+	}
+}
+
+/*
+TestTimeResponse_Lsim2
+Description:
+
+	Verifies that Lsim rejects a time vector that does not match the sample time of a discrete-time model.
+*/
+func TestTimeResponse_Lsim2(t *testing.T) {
+	// Constants
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(1, 1, []float64{0.5}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+		0.1,
+	)
+	u := mat.NewDense(3, 1, []float64{1, 1, 1})
+
+	// Algorithm
+	_, err := goControl.Lsim(sys, u, []float64{0, 0.2, 0.4}, nil)
+	if err == nil {
+		t.Errorf("Expected an error when the time vector does not match the sample time; received nil")
+	}
+}
+
+/*
+TestTimeResponse_Step2
+Description:
+
+	Computes the step response of the discrete-time model x+ = 0.5 x + u, y = x with an input delay of
+	two samples, which should be 0, 0, 0, 1, 1.5, 1.75, ...
+*/
+func TestTimeResponse_Step2(t *testing.T) {
+	// Constants
+	Ts := 0.1
+	sys, _ := goControl.GetDiscreteStateSpace(
+		mat.NewDense(1, 1, []float64{0.5}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{1}),
+		mat.NewDense(1, 1, []float64{0}),
+		Ts,
+	)
+	sys.InputDelay = []float64{2 * Ts}
+
+	// Algorithm
+	responses, err := goControl.Step(sys, 10*Ts)
+	if err != nil {
+		t.Errorf("There was an error computing the step response: %v", err)
+	}
+
+	response := responses[0]
+	if response.Time.Len() != 11 {
+		t.Errorf("The step response has %v samples; want 11", response.Time.Len())
+	}
+
+	expected := []float64{0, 0, 0, 1, 1.5, 1.75}
+	for k := range expected {
+		if math.Abs(response.Output.At(k, 0)-expected[k]) > 1e-12 {
+			t.Errorf("y[%v] = %v; want %v", k, response.Output.At(k, 0), expected[k])
+		}
+	}
+}
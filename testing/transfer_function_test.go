@@ -0,0 +1,248 @@
+/*
+   transfer_function_test.go
+   Description:
+	   Tests for the TransferFunction type defined in transfer_function.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+	"gonum.org/v1/gonum/mat"
+)
+
+/*
+TestTransferFunction_GetTransferFunction1
+Description:
+
+	Verifies that a transfer function with a zero denominator is rejected.
+*/
+func TestTransferFunction_GetTransferFunction1(t *testing.T) {
+	// Algorithm
+	_, err := goControl.GetTransferFunction([]float64{1}, []float64{0, 0})
+	if err == nil {
+		t.Errorf("Expected an error when the denominator is zero; received nil")
+	}
+}
+
+/*
+TestTransferFunction_Poles1
+Description:
+
+	Computes the poles of 1/(s^2 + 3s + 2), which should be -2 and -1.
+*/
+func TestTransferFunction_Poles1(t *testing.T) {
+	// Constants
+	tf, err := goControl.GetTransferFunction([]float64{1}, []float64{1, 3, 2})
+	if err != nil {
+		t.Errorf("There was an error creating the transfer function: %v", err)
+	}
+
+	// Algorithm
+	poles, err := tf.Poles()
+	if err != nil {
+		t.Errorf("There was an error computing the poles: %v", err)
+	}
+
+	expected := []complex128{-2, -1}
+	if len(poles) != len(expected) {
+		t.Fatalf("Expected %v poles; received %v", len(expected), len(poles))
+	}
+	for i := range expected {
+		if cmplx.Abs(poles[i]-expected[i]) > 1e-9 {
+			t.Errorf("poles[%v] = %v; want %v", i, poles[i], expected[i])
+		}
+	}
+}
+
+/*
+TestTransferFunction_Zeros1
+Description:
+
+	Computes the zeros of (s^2 + 1)/(s^3 + s + 1), which should be -i and +i.
+*/
+func TestTransferFunction_Zeros1(t *testing.T) {
+	// Constants
+	tf, _ := goControl.GetTransferFunction([]float64{1, 0, 1}, []float64{1, 0, 1, 1})
+
+	// Algorithm
+	zeros, err := tf.Zeros()
+	if err != nil {
+		t.Errorf("There was an error computing the zeros: %v", err)
+	}
+
+	expected := []complex128{-1i, 1i}
+	for i := range expected {
+		if cmplx.Abs(zeros[i]-expected[i]) > 1e-9 {
+			t.Errorf("zeros[%v] = %v; want %v", i, zeros[i], expected[i])
+		}
+	}
+}
+
+/*
+TestTransferFunction_DCGain1
+Description:
+
+	Verifies the DC gain of (2s + 4)/(s^2 + 3s + 2) which is 2 and that an integrator has infinite DC gain.
+*/
+func TestTransferFunction_DCGain1(t *testing.T) {
+	// Constants
+	tf, _ := goControl.GetTransferFunction([]float64{2, 4}, []float64{1, 3, 2})
+	integrator, _ := goControl.GetTransferFunction([]float64{1}, []float64{1, 0})
+
+	// Algorithm
+	gain, err := tf.DCGain()
+	if err != nil {
+		t.Errorf("There was an error computing the DC gain: %v", err)
+	}
+	if math.Abs(gain.At(0, 0)-2) > 1e-12 {
+		t.Errorf("DC gain = %v; want 2", gain.At(0, 0))
+	}
+
+	gain, _ = integrator.DCGain()
+	if !math.IsInf(gain.At(0, 0), 1) {
+		t.Errorf("DC gain of integrator = %v; want +Inf", gain.At(0, 0))
+	}
+}
+
+/*
+TestTransferFunction_Minreal1
+Description:
+
+	Verifies that the pole-zero pair at -1 is cancelled from 2(s+1)/((s+1)(s+2)).
+*/
+func TestTransferFunction_Minreal1(t *testing.T) {
+	// Constants
+	tf, _ := goControl.GetTransferFunction([]float64{2, 2}, []float64{1, 3, 2})
+
+	// Algorithm
+	tfMin, err := tf.Minreal(1e-6)
+	if err != nil {
+		t.Errorf("There was an error computing the minimal realization: %v", err)
+	}
+
+	num, den := tfMin.Numerator[0][0], tfMin.Denominator[0][0]
+	if len(num) != 1 || math.Abs(num[0]-2) > 1e-9 {
+		t.Errorf("Numerator = %v; want [2]", num)
+	}
+	if len(den) != 2 || math.Abs(den[0]-1) > 1e-9 || math.Abs(den[1]-2) > 1e-9 {
+		t.Errorf("Denominator = %v; want [1 2]", den)
+	}
+}
+
+/*
+TestTransferFunction_Tf2ss1
+Description:
+
+	Converts (s + 3)/(s^2 + 3s + 2) into a state space model and checks the controllable canonical form.
+*/
+func TestTransferFunction_Tf2ss1(t *testing.T) {
+	// Constants
+	tf, _ := goControl.GetTransferFunction([]float64{1, 3}, []float64{1, 3, 2})
+
+	// Algorithm
+	sys, err := goControl.Tf2ss(tf)
+	if err != nil {
+		t.Errorf("There was an error converting the transfer function: %v", err)
+	}
+
+	expectedA := mat.NewDense(2, 2, []float64{-3, -2, 1, 0})
+	expectedB := mat.NewDense(2, 1, []float64{1, 0})
+	expectedC := mat.NewDense(1, 2, []float64{1, 3})
+	if !mat.EqualApprox(sys.A, expectedA, 1e-12) {
+		t.Errorf("A = %v; want %v", mat.Formatted(sys.A), mat.Formatted(expectedA))
+	}
+	if !mat.EqualApprox(sys.B, expectedB, 1e-12) {
+		t.Errorf("B = %v; want %v", mat.Formatted(sys.B), mat.Formatted(expectedB))
+	}
+	if !mat.EqualApprox(sys.C, expectedC, 1e-12) {
+		t.Errorf("C = %v; want %v", mat.Formatted(sys.C), mat.Formatted(expectedC))
+	}
+	if sys.D.At(0, 0) != 0 {
+		t.Errorf("D = %v; want 0", sys.D.At(0, 0))
+	}
+}
+
+/*
+TestTransferFunction_Tf2ss2
+Description:
+
+	Converts a 2 x 2 transfer function matrix into a state space model and verifies that the frequency
+	response of the realization matches the original transfer function.
+*/
+func TestTransferFunction_Tf2ss2(t *testing.T) {
+	// Constants
+	tf, err := goControl.GetMIMOTransferFunction(
+		[][][]float64{
+			{{1}, {2, 1}},
+			{{1, 0}, {3}},
+		},
+		[][][]float64{
+			{{1, 1}, {1, 3, 2}},
+			{{1, 2}, {1, 3, 2}},
+		},
+	)
+	if err != nil {
+		t.Errorf("There was an error creating the transfer function: %v", err)
+	}
+
+	// Algorithm
+	sys, err := goControl.Tf2ss(tf)
+	if err != nil {
+		t.Errorf("There was an error converting the transfer function: %v", err)
+	}
+
+	tfBack, err := goControl.Ss2tf(sys)
+	if err != nil {
+		t.Errorf("There was an error converting the state space model: %v", err)
+	}
+
+	for _, s := range []complex128{0.5i, 1 + 2i, 3} {
+		G1, G2 := tf.Evaluate(s), tfBack.Evaluate(s)
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				if cmplx.Abs(G1.At(i, j)-G2.At(i, j)) > 1e-8 {
+					t.Errorf("G(%v)[%v,%v] = %v after the round trip; want %v", s, i, j, G2.At(i, j), G1.At(i, j))
+				}
+			}
+		}
+	}
+}
+
+/*
+TestTransferFunction_Ss2tf1
+Description:
+
+	Converts a second order state space model with a feedthrough term into a transfer function.
+*/
+func TestTransferFunction_Ss2tf1(t *testing.T) {
+	// Constants
+	sys, _ := goControl.GetStateSpace(
+		mat.NewDense(2, 2, []float64{0, 1, -2, -3}),
+		mat.NewDense(2, 1, []float64{0, 1}),
+		mat.NewDense(1, 2, []float64{1, 0}),
+		mat.NewDense(1, 1, []float64{1}),
+	)
+
+	// Algorithm
+	tf, err := goControl.Ss2tf(sys)
+	if err != nil {
+		t.Errorf("There was an error converting the state space model: %v", err)
+	}
+
+	// G(s) = 1/(s^2 + 3s + 2) + 1 = (s^2 + 3s + 3)/(s^2 + 3s + 2)
+	expectedNum := []float64{1, 3, 3}
+	expectedDen := []float64{1, 3, 2}
+	for i := range expectedNum {
+		if math.Abs(tf.Numerator[0][0][i]-expectedNum[i]) > 1e-9 {
+			t.Errorf("Numerator = %v; want %v", tf.Numerator[0][0], expectedNum)
+		}
+		if math.Abs(tf.Denominator[0][0][i]-expectedDen[i]) > 1e-9 {
+			t.Errorf("Denominator = %v; want %v", tf.Denominator[0][0], expectedDen)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+   zpk_test.go
+   Description:
+	   Tests for the ZPK type defined in zpk.go.
+*/
+
+package testing
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/kwesiRutledge/goControl"
+)
+
+/*
+TestZPK_GetZPK1
+Description:
+
+	Verifies that a complex pole without its conjugate is rejected.
+*/
+func TestZPK_GetZPK1(t *testing.T) {
+	// Algorithm
+	_, err := goControl.GetZPK([]complex128{}, []complex128{-1 + 1i}, 1.0)
+	if err == nil {
+		t.Errorf("Expected an error when a pole does not have its conjugate; received nil")
+	}
+}
+
+/*
+TestZPK_Zpk2tf1
+Description:
+
+	Converts 3(s + 1)/((s + 1 - i)(s + 1 + i)) into the transfer function (3s + 3)/(s^2 + 2s + 2).
+*/
+func TestZPK_Zpk2tf1(t *testing.T) {
+	// Constants
+	zpk, err := goControl.GetZPK([]complex128{-1}, []complex128{-1 + 1i, -1 - 1i}, 3.0)
+	if err != nil {
+		t.Errorf("There was an error creating the ZPK model: %v", err)
+	}
+
+	// Algorithm
+	tf, err := goControl.Zpk2tf(zpk)
+	if err != nil {
+		t.Errorf("There was an error converting the ZPK model: %v", err)
+	}
+
+	expectedNum := []float64{3, 3}
+	expectedDen := []float64{1, 2, 2}
+	for i := range expectedNum {
+		if math.Abs(tf.Numerator[0][0][i]-expectedNum[i]) > 1e-12 {
+			t.Errorf("Numerator = %v; want %v", tf.Numerator[0][0], expectedNum)
+		}
+	}
+	for i := range expectedDen {
+		if math.Abs(tf.Denominator[0][0][i]-expectedDen[i]) > 1e-12 {
+			t.Errorf("Denominator = %v; want %v", tf.Denominator[0][0], expectedDen)
+		}
+	}
+}
+
+/*
+TestZPK_Tf2zpk1
+Description:
+
+	Verifies that converting a transfer function into a ZPK model and back recovers the same zeros, poles and gain.
+*/
+func TestZPK_Tf2zpk1(t *testing.T) {
+	// Constants
+	tf, _ := goControl.GetTransferFunction([]float64{2, 6}, []float64{1, 4, 5})
+
+	// Algorithm
+	zpk, err := goControl.Tf2zpk(tf)
+	if err != nil {
+		t.Errorf("There was an error converting the transfer function: %v", err)
+	}
+
+	if math.Abs(zpk.Gain[0][0]-2) > 1e-12 {
+		t.Errorf("Gain = %v; want 2", zpk.Gain[0][0])
+	}
+	if len(zpk.Zeros[0][0]) != 1 || cmplx.Abs(zpk.Zeros[0][0][0]+3) > 1e-9 {
+		t.Errorf("Zeros = %v; want [-3]", zpk.Zeros[0][0])
+	}
+
+	expectedPoles := []complex128{-2 - 1i, -2 + 1i}
+	for i := range expectedPoles {
+		if cmplx.Abs(zpk.Poles[0][0][i]-expectedPoles[i]) > 1e-9 {
+			t.Errorf("Poles = %v; want %v", zpk.Poles[0][0], expectedPoles)
+		}
+	}
+
+	gain, err := zpk.DCGain()
+	if err != nil {
+		t.Errorf("There was an error computing the DC gain: %v", err)
+	}
+	if math.Abs(gain.At(0, 0)-6.0/5.0) > 1e-9 {
+		t.Errorf("DC gain = %v; want 1.2", gain.At(0, 0))
+	}
+}
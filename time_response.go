@@ -0,0 +1,455 @@
+/*
+   time_response.go
+   Description:
+       Time-domain simulation of linear time-invariant models "like" MATLAB's lsim, step and impulse functions.
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type TimeResponse struct {
+	Time   *mat.VecDense // The N sample times.
+	Output *mat.Dense    // N x p matrix; row k is the output at Time[k].
+	State  *mat.Dense    // N x n matrix; row k is the state at Time[k].
+}
+
+/*
+Lsim
+Description:
+
+	Simulates the response of sys to the input u (an N x m matrix whose row k is applied at time t[k])
+	starting from the initial state x0 (nil means the zero state).
+	The input is held constant between samples, so the simulation of continuous-time models is exact:
+	each interval uses the zero-order hold discretization of the model. The time samples of discrete-time
+	models must be spaced by the model's sample time.
+*/
+func Lsim(sys StateSpace, u mat.Matrix, t []float64, x0 mat.Vector) (TimeResponse, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return TimeResponse{}, err
+	}
+
+	n, m, _ := sys.Dims()
+	N := len(t)
+	if N == 0 {
+		return TimeResponse{}, errors.New("The time vector given to Lsim is empty.")
+	}
+
+	if uRows, uCols := u.Dims(); (uRows != N) || (uCols != m) {
+		return TimeResponse{}, fmt.Errorf("The input has dimensions %v x %v; expected %v x %v.", uRows, uCols, N, m)
+	}
+
+	for k := 1; k < N; k++ {
+		if !(t[k] > t[k-1]) {
+			return TimeResponse{}, fmt.Errorf("The time vector must be increasing; t[%v] = %v and t[%v] = %v.", k-1, t[k-1], k, t[k])
+		}
+	}
+
+	initialState := mat.NewVecDense(n, nil)
+	if x0 != nil {
+		if x0.Len() != n {
+			return TimeResponse{}, fmt.Errorf("The initial state has length %v; expected %v.", x0.Len(), n)
+		}
+		initialState.CopyVec(x0)
+	}
+
+	// Algorithm
+	if sys.IsDiscrete() {
+		return lsimDiscrete(sys, u, t, initialState)
+	}
+	return lsimContinuous(sys, u, t, initialState)
+}
+
+/*
+lsimDiscrete
+Description:
+
+	Simulates the discrete-time model sys. Input delays must be integer multiples of the sample time.
+*/
+func lsimDiscrete(sys StateSpace, u mat.Matrix, t []float64, x0 *mat.VecDense) (TimeResponse, error) {
+	// Constants
+	n, _, _ := sys.Dims()
+
+	// Check the spacing of the time vector
+	for k := 1; k < len(t); k++ {
+		if math.Abs((t[k]-t[k-1])-sys.Ts) > 1e-9*sys.Ts {
+			return TimeResponse{}, fmt.Errorf("The time samples of a discrete-time model must be spaced by the sample time %v.", sys.Ts)
+		}
+	}
+
+	// Absorb input delays into the state
+	integerDelays, fractionalDelays := splitInputDelays(sys, sys.Ts)
+	for j, fraction := range fractionalDelays {
+		if fraction > 0 {
+			return TimeResponse{}, fmt.Errorf("Input delay %v of the discrete-time model is not an integer multiple of the sample time.", j)
+		}
+	}
+
+	delayFree := sys.Copy()
+	delayFree.InputDelay = nil
+	augmented, err := appendInputDelayStates(delayFree, integerDelays)
+	if err != nil {
+		return TimeResponse{}, err
+	}
+
+	// Simulate
+	nAug, _, _ := augmented.Dims()
+	xAug := mat.NewVecDense(nAug, nil)
+	xAug.SliceVec(0, n).(*mat.VecDense).CopyVec(x0)
+
+	steps := make([]discreteStep, len(t)-1)
+	for k := range steps {
+		steps[k] = discreteStep{A: augmented.A, B: augmented.B}
+	}
+
+	return simulateSteps(augmented, steps, u, t, xAug, n), nil
+}
+
+/*
+lsimContinuous
+Description:
+
+	Simulates the continuous-time model sys by discretizing it exactly over each interval between samples.
+	Models with input delays require evenly spaced time samples.
+*/
+func lsimContinuous(sys StateSpace, u mat.Matrix, t []float64, x0 *mat.VecDense) (TimeResponse, error) {
+	// Constants
+	n, _, _ := sys.Dims()
+	N := len(t)
+
+	// Simulate a model that was observed at a single instant
+	if N == 1 {
+		return simulateSteps(sys, []discreteStep{}, u, t, x0, n), nil
+	}
+
+	// Evenly spaced samples use a single discretization (which can absorb input delays)
+	if isEvenlySpaced(t) {
+		sysD, err := C2d(sys, (t[N-1]-t[0])/float64(N-1), ZeroOrderHold)
+		if err != nil {
+			return TimeResponse{}, err
+		}
+
+		nAug, _, _ := sysD.Dims()
+		xAug := mat.NewVecDense(nAug, nil)
+		xAug.SliceVec(0, n).(*mat.VecDense).CopyVec(x0)
+
+		steps := make([]discreteStep, N-1)
+		for k := range steps {
+			steps[k] = discreteStep{A: sysD.A, B: sysD.B}
+		}
+		return simulateSteps(sysD, steps, u, t, xAug, n), nil
+	}
+
+	if sys.HasInputDelay() {
+		return TimeResponse{}, errors.New("Lsim requires evenly spaced time samples for models with input delays.")
+	}
+
+	// Otherwise, discretize each interval (reusing the matrices of intervals with the same length)
+	cache := map[float64]discreteStep{}
+	steps := make([]discreteStep, N-1)
+	for k := range steps {
+		dt := t[k+1] - t[k]
+		if _, ok := cache[dt]; !ok {
+			Phi, Gamma := zohMatrices(sys.A, sys.B, dt)
+			cache[dt] = discreteStep{A: Phi, B: Gamma}
+		}
+		steps[k] = cache[dt]
+	}
+
+	return simulateSteps(sys, steps, u, t, x0, n), nil
+}
+
+type discreteStep struct {
+	A *mat.Dense
+	B *mat.Dense
+}
+
+/*
+simulateSteps
+Description:
+
+	Propagates x+ = A_k x + B_k u_k through the given steps and records y_k = C x_k + D u_k.
+	Only the first nOut states are recorded.
+*/
+func simulateSteps(sys StateSpace, steps []discreteStep, u mat.Matrix, t []float64, x0 *mat.VecDense, nOut int) TimeResponse {
+	// Constants
+	nAug, m, p := sys.Dims()
+	N := len(t)
+
+	// Algorithm
+	response := TimeResponse{
+		Time:   mat.NewVecDense(N, append([]float64{}, t...)),
+		Output: mat.NewDense(N, p, nil),
+		State:  mat.NewDense(N, nOut, nil),
+	}
+
+	x := mat.VecDenseCopyOf(x0)
+	uk := mat.NewVecDense(m, nil)
+	yk := mat.NewVecDense(p, nil)
+	Du := mat.NewVecDense(p, nil)
+	Bu := mat.NewVecDense(nAug, nil)
+	xNext := mat.NewVecDense(nAug, nil)
+	for k := 0; k < N; k++ {
+		for j := 0; j < m; j++ {
+			uk.SetVec(j, u.At(k, j))
+		}
+
+		// Record the output and state
+		yk.MulVec(sys.C, x)
+		Du.MulVec(sys.D, uk)
+		yk.AddVec(yk, Du)
+		for i := 0; i < p; i++ {
+			response.Output.Set(k, i, yk.AtVec(i))
+		}
+		for i := 0; i < nOut; i++ {
+			response.State.Set(k, i, x.AtVec(i))
+		}
+
+		// Propagate
+		if k < N-1 {
+			xNext.MulVec(steps[k].A, x)
+			Bu.MulVec(steps[k].B, uk)
+			xNext.AddVec(xNext, Bu)
+			x.CopyVec(xNext)
+		}
+	}
+
+	return response
+}
+
+/*
+Step
+Description:
+
+	Computes the step response of sys from each input (with zero initial state). Element j of the output
+	is the response to a unit step applied at t = 0 on input j. If tFinal is not positive, then the final time
+	is chosen automatically from the poles of the model.
+*/
+func Step(sys StateSpace, tFinal float64) ([]TimeResponse, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return nil, err
+	}
+
+	// Constants
+	_, m, _ := sys.Dims()
+	t, err := responseTimeVector(sys, tFinal)
+	if err != nil {
+		return nil, err
+	}
+	N := len(t)
+
+	// Algorithm
+	responses := make([]TimeResponse, m)
+	for j := 0; j < m; j++ {
+		u := mat.NewDense(N, m, nil)
+		for k := 0; k < N; k++ {
+			u.Set(k, j, 1)
+		}
+
+		responses[j], err = Lsim(sys, u, t, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return responses, nil
+}
+
+/*
+Impulse
+Description:
+
+	Computes the impulse response of sys from each input (with zero initial state). Element j of the output
+	is the response to a unit impulse applied at t = 0 on input j. As in MATLAB, the direct feedthrough D is
+	not included for continuous-time models. For discrete-time models, the impulse is the unit pulse
+	u[0] = 1. If tFinal is not positive, then the final time is chosen automatically from the poles of the model.
+*/
+func Impulse(sys StateSpace, tFinal float64) ([]TimeResponse, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return nil, err
+	}
+
+	// Constants
+	n, m, p := sys.Dims()
+	t, err := responseTimeVector(sys, tFinal)
+	if err != nil {
+		return nil, err
+	}
+	N := len(t)
+
+	// Algorithm
+	PhiStep := eye(n)
+	if N > 1 {
+		PhiStep = expmBlock(sys.A, t[1]-t[0])
+	}
+
+	responses := make([]TimeResponse, m)
+	for j := 0; j < m; j++ {
+		if sys.IsDiscrete() {
+			u := mat.NewDense(N, m, nil)
+			u.Set(0, j, 1)
+
+			responses[j], err = Lsim(sys, u, t, nil)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// The impulse on input j at time tau_j places the state at B_j at time tau_j,
+		// after which the model evolves freely.
+		delay := 0.0
+		if sys.InputDelay != nil {
+			delay = sys.InputDelay[j]
+		}
+
+		response := TimeResponse{
+			Time:   mat.NewVecDense(N, append([]float64{}, t...)),
+			Output: mat.NewDense(N, p, nil),
+			State:  mat.NewDense(N, n, nil),
+		}
+
+		x := mat.NewVecDense(n, nil)
+		xNext := mat.NewVecDense(n, nil)
+		y := mat.NewVecDense(p, nil)
+		started := false
+		for k := 0; k < N; k++ {
+			if t[k] < delay {
+				continue
+			}
+
+			if started {
+				xNext.MulVec(PhiStep, x)
+				x.CopyVec(xNext)
+			} else {
+				x.MulVec(expmBlock(sys.A, t[k]-delay), sys.B.ColView(j))
+				started = true
+			}
+			y.MulVec(sys.C, x)
+
+			response.State.SetRow(k, x.RawVector().Data)
+			response.Output.SetRow(k, y.RawVector().Data)
+		}
+
+		responses[j] = response
+	}
+
+	return responses, nil
+}
+
+/*
+responseTimeVector
+Description:
+
+	Creates the time vector used by Step and Impulse. If tFinal is not positive, then it is chosen
+	automatically using the slowest (or fastest unstable) dynamics of the model, and the time step is chosen
+	from the fastest dynamics. Discrete-time models always use their sample time as the time step.
+*/
+func responseTimeVector(sys StateSpace, tFinal float64) ([]float64, error) {
+	// Constants
+	minSamples, maxSamples := 200, 10000
+
+	// Collect the decay rates (continuous-time equivalents for discrete-time models)
+	poles, err := sys.Poles()
+	if err != nil {
+		return nil, err
+	}
+
+	slowestDecay, fastestGrowth, fastestMode := math.Inf(1), 0.0, 0.0
+	for _, pole := range poles {
+		s := pole
+		if sys.IsDiscrete() {
+			if cmplx.Abs(pole) < 1e-12 {
+				continue // Poles at z = 0 settle in one sample
+			}
+			s = cmplx.Log(pole) / complex(sys.Ts, 0)
+		}
+
+		fastestMode = math.Max(fastestMode, cmplx.Abs(s))
+		switch {
+		case real(s) < -1e-9*math.Max(1, cmplx.Abs(s)):
+			slowestDecay = math.Min(slowestDecay, -real(s))
+		case real(s) > 1e-9*math.Max(1, cmplx.Abs(s)):
+			fastestGrowth = math.Max(fastestGrowth, real(s))
+		}
+	}
+
+	// Choose the final time
+	maxDelay := 0.0
+	for _, delay := range sys.InputDelay {
+		maxDelay = math.Max(maxDelay, delay)
+	}
+
+	if !(tFinal > 0) {
+		switch {
+		case fastestGrowth > 0:
+			tFinal = 5 / fastestGrowth // Show a growth of about e^5
+		case !math.IsInf(slowestDecay, 1):
+			tFinal = 7 / slowestDecay // Decay to about 0.1% of the initial value
+		default:
+			tFinal = 10
+		}
+
+		if sys.IsDiscrete() {
+			tFinal = math.Max(tFinal, float64(minSamples/10)*sys.Ts)
+		}
+		tFinal += maxDelay
+	}
+
+	// Choose the time step
+	var dt float64
+	if sys.IsDiscrete() {
+		dt = sys.Ts
+		if tFinal/dt > float64(maxSamples) {
+			return nil, fmt.Errorf("The final time %v requires more than %v samples.", tFinal, maxSamples)
+		}
+	} else {
+		dt = tFinal / float64(minSamples)
+		if fastestMode > 0 {
+			dt = math.Min(dt, 1/(10*fastestMode))
+		}
+		dt = math.Max(dt, tFinal/float64(maxSamples))
+	}
+
+	// Create the time vector
+	N := int(math.Floor(tFinal/dt+1e-9)) + 1
+	t := make([]float64, N)
+	for k := range t {
+		t[k] = float64(k) * dt
+	}
+
+	return t, nil
+}
+
+/*
+isEvenlySpaced
+Description:
+
+	Returns true if the samples of t are evenly spaced (up to round off).
+*/
+func isEvenlySpaced(t []float64) bool {
+	// Constants
+	N := len(t)
+	if N < 3 {
+		return true
+	}
+	dt := (t[N-1] - t[0]) / float64(N-1)
+
+	// Algorithm
+	for k := 1; k < N; k++ {
+		if math.Abs((t[k]-t[k-1])-dt) > 1e-9*dt {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,578 @@
+/*
+   transfer_function.go
+   Description:
+       An implementation "like" MATLAB's tf object. Each entry (i,j) of a TransferFunction is the ratio of
+       two polynomials whose coefficients are given in DESCENDING powers of s (or z for discrete-time models).
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type TransferFunction struct {
+	Numerator   [][][]float64 // Numerator[i][j] contains the coefficients of the numerator from input j to output i.
+	Denominator [][][]float64 // Denominator[i][j] contains the coefficients of the denominator from input j to output i.
+	Ts          float64       // Sample time. Ts = 0 means that the model is continuous-time.
+}
+
+/*
+GetTransferFunction
+Description:
+
+	Creates a continuous-time single-input, single-output transfer function num(s)/den(s).
+*/
+func GetTransferFunction(numIn, denIn []float64) (TransferFunction, error) {
+	return GetMIMOTransferFunction([][][]float64{{numIn}}, [][][]float64{{denIn}})
+}
+
+/*
+GetMIMOTransferFunction
+Description:
+
+	Creates a continuous-time transfer function matrix where the entry from input j to output i is
+	numIn[i][j](s) / denIn[i][j](s).
+*/
+func GetMIMOTransferFunction(numIn, denIn [][][]float64) (TransferFunction, error) {
+	// Copy the coefficients
+	tf := TransferFunction{
+		Numerator:   make([][][]float64, len(numIn)),
+		Denominator: make([][][]float64, len(denIn)),
+	}
+	for i := range numIn {
+		tf.Numerator[i] = make([][]float64, len(numIn[i]))
+		for j := range numIn[i] {
+			tf.Numerator[i][j] = polyTrim(numIn[i][j])
+		}
+	}
+	for i := range denIn {
+		tf.Denominator[i] = make([][]float64, len(denIn[i]))
+		for j := range denIn[i] {
+			tf.Denominator[i][j] = polyTrim(denIn[i][j])
+		}
+	}
+
+	// Check it
+	if err := tf.Check(); err != nil {
+		return TransferFunction{}, err
+	}
+
+	return tf, nil
+}
+
+/*
+Check
+Description:
+
+	Returns an error if the numerator and denominator arrays are not compatible or if a
+	denominator is identically zero.
+*/
+func (tf TransferFunction) Check() error {
+	// Check that the model is defined
+	if len(tf.Numerator) == 0 || len(tf.Numerator[0]) == 0 {
+		return errors.New("The numerator of the transfer function is not defined.")
+	}
+
+	// Constants
+	p, m := len(tf.Numerator), len(tf.Numerator[0])
+
+	// Check dimensions
+	if len(tf.Denominator) != p {
+		return fmt.Errorf("The denominator has %v rows; expected %v.", len(tf.Denominator), p)
+	}
+
+	for i := 0; i < p; i++ {
+		if len(tf.Numerator[i]) != m {
+			return fmt.Errorf("Row %v of the numerator has %v entries; expected %v.", i, len(tf.Numerator[i]), m)
+		}
+		if len(tf.Denominator[i]) != m {
+			return fmt.Errorf("Row %v of the denominator has %v entries; expected %v.", i, len(tf.Denominator[i]), m)
+		}
+
+		for j := 0; j < m; j++ {
+			if polyIsZero(tf.Denominator[i][j]) {
+				return fmt.Errorf("The denominator of entry (%v,%v) is zero.", i, j)
+			}
+		}
+	}
+
+	// Check sample time
+	if tf.Ts < 0 || math.IsNaN(tf.Ts) || math.IsInf(tf.Ts, 0) {
+		return fmt.Errorf("The sample time must be a nonnegative, finite number; received %v.", tf.Ts)
+	}
+
+	// If nothing is wrong, return nil
+	return nil
+}
+
+/*
+Dims
+Description:
+
+	Returns the number of outputs and inputs of the transfer function, in that order.
+*/
+func (tf TransferFunction) Dims() (p, m int) {
+	if len(tf.Numerator) == 0 {
+		return 0, 0
+	}
+	return len(tf.Numerator), len(tf.Numerator[0])
+}
+
+/*
+IsSISO
+Description:
+
+	Returns true if the transfer function has exactly one input and one output.
+*/
+func (tf TransferFunction) IsSISO() bool {
+	p, m := tf.Dims()
+	return (p == 1) && (m == 1)
+}
+
+/*
+IsDiscrete
+Description:
+
+	Returns true if the transfer function is a discrete-time model (i.e. it has a positive sample time).
+*/
+func (tf TransferFunction) IsDiscrete() bool {
+	return tf.Ts > 0
+}
+
+/*
+IsProper
+Description:
+
+	Returns true if the degree of each numerator is no larger than the degree of the corresponding denominator.
+*/
+func (tf TransferFunction) IsProper() bool {
+	p, m := tf.Dims()
+	for i := 0; i < p; i++ {
+		for j := 0; j < m; j++ {
+			if polyIsZero(tf.Numerator[i][j]) {
+				continue
+			}
+			if polyDegree(tf.Numerator[i][j]) > polyDegree(tf.Denominator[i][j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+/*
+Evaluate
+Description:
+
+	Evaluates the transfer function matrix at the complex point s.
+*/
+func (tf TransferFunction) Evaluate(s complex128) *mat.CDense {
+	// Constants
+	p, m := tf.Dims()
+
+	// Algorithm
+	G := mat.NewCDense(p, m, nil)
+	for i := 0; i < p; i++ {
+		for j := 0; j < m; j++ {
+			G.Set(i, j, polyEval(tf.Numerator[i][j], s)/polyEval(tf.Denominator[i][j], s))
+		}
+	}
+	return G
+}
+
+/*
+Poles
+Description:
+
+	Computes the poles of each entry of the transfer function using the eigenvalues of the
+	companion matrix of each denominator. For MIMO models the poles of all entries are returned
+	together, with the repeated denominators counted once.
+*/
+func (tf TransferFunction) Poles() ([]complex128, error) {
+	// Input Processing
+	if err := tf.Check(); err != nil {
+		return nil, err
+	}
+
+	// Constants
+	p, m := tf.Dims()
+
+	// Algorithm
+	poles := []complex128{}
+	seenDenominators := [][]float64{}
+	for i := 0; i < p; i++ {
+		for j := 0; j < m; j++ {
+			den := tf.Denominator[i][j]
+
+			alreadySeen := false
+			for _, seen := range seenDenominators {
+				if polyEqual(seen, den) {
+					alreadySeen = true
+				}
+			}
+			if alreadySeen {
+				continue
+			}
+			seenDenominators = append(seenDenominators, den)
+
+			denRoots, err := polyRoots(den)
+			if err != nil {
+				return nil, err
+			}
+			poles = append(poles, denRoots...)
+		}
+	}
+
+	sortComplex(poles)
+	return poles, nil
+}
+
+/*
+Zeros
+Description:
+
+	Computes the zeros of a single-input, single-output transfer function using the eigenvalues of the
+	companion matrix of its numerator.
+*/
+func (tf TransferFunction) Zeros() ([]complex128, error) {
+	// Input Processing
+	if err := tf.Check(); err != nil {
+		return nil, err
+	}
+
+	if !tf.IsSISO() {
+		return nil, errors.New("Zeros is only defined for single-input, single-output transfer functions.")
+	}
+
+	// Algorithm
+	if polyIsZero(tf.Numerator[0][0]) {
+		return []complex128{}, nil
+	}
+	return polyRoots(tf.Numerator[0][0])
+}
+
+/*
+DCGain
+Description:
+
+	Computes the steady-state gain of the transfer function, i.e. G(0) for continuous-time models and
+	G(1) for discrete-time models. Entries with a pole at the DC point are set to +Inf or -Inf.
+*/
+func (tf TransferFunction) DCGain() (*mat.Dense, error) {
+	// Input Processing
+	if err := tf.Check(); err != nil {
+		return nil, err
+	}
+
+	// Constants
+	p, m := tf.Dims()
+	dcPoint := complex(0, 0)
+	if tf.IsDiscrete() {
+		dcPoint = complex(1, 0)
+	}
+
+	// Algorithm
+	gain := mat.NewDense(p, m, nil)
+	for i := 0; i < p; i++ {
+		for j := 0; j < m; j++ {
+			numValue := real(polyEval(tf.Numerator[i][j], dcPoint))
+			denValue := real(polyEval(tf.Denominator[i][j], dcPoint))
+
+			switch {
+			case denValue != 0:
+				gain.Set(i, j, numValue/denValue)
+			case numValue == 0:
+				gain.Set(i, j, math.NaN())
+			default:
+				gain.Set(i, j, math.Inf(int(math.Copysign(1, numValue))))
+			}
+		}
+	}
+
+	return gain, nil
+}
+
+/*
+Minreal
+Description:
+
+	Cancels the pole-zero pairs of each entry of the transfer function that are closer than the relative
+	tolerance tol. The denominators of the result are monic.
+*/
+func (tf TransferFunction) Minreal(tol float64) (TransferFunction, error) {
+	// Input Processing
+	if err := tf.Check(); err != nil {
+		return TransferFunction{}, err
+	}
+
+	// Constants
+	p, m := tf.Dims()
+
+	// Algorithm
+	tfOut := TransferFunction{
+		Numerator:   make([][][]float64, p),
+		Denominator: make([][][]float64, p),
+		Ts:          tf.Ts,
+	}
+	for i := 0; i < p; i++ {
+		tfOut.Numerator[i] = make([][]float64, m)
+		tfOut.Denominator[i] = make([][]float64, m)
+		for j := 0; j < m; j++ {
+			num, den := polyTrim(tf.Numerator[i][j]), polyTrim(tf.Denominator[i][j])
+
+			if polyIsZero(num) {
+				tfOut.Numerator[i][j] = []float64{0}
+				tfOut.Denominator[i][j] = []float64{1}
+				continue
+			}
+
+			zeros, err := polyRoots(num)
+			if err != nil {
+				return TransferFunction{}, err
+			}
+			poles, err := polyRoots(den)
+			if err != nil {
+				return TransferFunction{}, err
+			}
+
+			zeros, poles = cancelCommonRoots(zeros, poles, tol)
+			gain := num[0] / den[0]
+
+			tfOut.Numerator[i][j] = polyScale(gain, polyFromRoots(zeros))
+			tfOut.Denominator[i][j] = polyFromRoots(poles)
+		}
+	}
+
+	return tfOut, nil
+}
+
+/*
+Tf2ss
+Description:
+
+	Converts a proper transfer function into a state space model.
+	Each column of the transfer function matrix (i.e. each input) is realized in controllable
+	canonical form using the common denominator of that column, and the realizations
+	are stacked in a block diagonal form (as MATLAB does).
+*/
+func Tf2ss(tf TransferFunction) (StateSpace, error) {
+	// Input Processing
+	if err := tf.Check(); err != nil {
+		return StateSpace{}, err
+	}
+
+	if !tf.IsProper() {
+		return StateSpace{}, errors.New("The transfer function is not proper, so it does not have a state space realization.")
+	}
+
+	// Constants
+	p, m := tf.Dims()
+
+	// Find the realization of each column
+	columnRealizations := make([]StateSpace, 0, m)
+	numStates := 0
+	for j := 0; j < m; j++ {
+		// Find the common denominator of the column
+		commonDen := []float64{1}
+		distinctDens := [][]float64{}
+		for i := 0; i < p; i++ {
+			den := polyScale(1/tf.Denominator[i][j][0], tf.Denominator[i][j])
+
+			alreadySeen := false
+			for _, seen := range distinctDens {
+				if polyEqual(seen, den) {
+					alreadySeen = true
+				}
+			}
+			if !alreadySeen {
+				distinctDens = append(distinctDens, den)
+				commonDen = polyMul(commonDen, den)
+			}
+		}
+
+		// Express each numerator over the common denominator
+		nums := make([][]float64, p)
+		for i := 0; i < p; i++ {
+			den := tf.Denominator[i][j]
+			scaledNum := polyScale(1/den[0], tf.Numerator[i][j])
+
+			// Multiply by the denominators that this entry is missing
+			skipped := false
+			for _, otherDen := range distinctDens {
+				if !skipped && polyEqual(otherDen, polyScale(1/den[0], den)) {
+					skipped = true
+					continue
+				}
+				scaledNum = polyMul(scaledNum, otherDen)
+			}
+			nums[i] = scaledNum
+		}
+
+		columnSS, err := controllableCanonicalForm(nums, commonDen)
+		if err != nil {
+			return StateSpace{}, err
+		}
+		numStates += columnSS.A.RawMatrix().Rows
+		columnRealizations = append(columnRealizations, columnSS)
+	}
+
+	if numStates == 0 {
+		return StateSpace{}, errors.New("The transfer function is a static gain; state space models must have at least one state.")
+	}
+
+	// Stack the realizations of each column
+	A := mat.NewDense(numStates, numStates, nil)
+	B := mat.NewDense(numStates, m, nil)
+	C := mat.NewDense(p, numStates, nil)
+	D := mat.NewDense(p, m, nil)
+
+	offset := 0
+	for j, columnSS := range columnRealizations {
+		nj := columnSS.A.RawMatrix().Rows
+		for r := 0; r < nj; r++ {
+			for c := 0; c < nj; c++ {
+				A.Set(offset+r, offset+c, columnSS.A.At(r, c))
+			}
+			B.Set(offset+r, j, columnSS.B.At(r, 0))
+			for i := 0; i < p; i++ {
+				C.Set(i, offset+r, columnSS.C.At(i, r))
+			}
+		}
+		for i := 0; i < p; i++ {
+			D.Set(i, j, columnSS.D.At(i, 0))
+		}
+		offset += nj
+	}
+
+	return GetDiscreteStateSpace(A, B, C, D, tf.Ts)
+}
+
+/*
+controllableCanonicalForm
+Description:
+
+	Realizes the single-input, multiple-output transfer function nums[i](s) / den(s) in
+	controllable canonical form. The state space model that is returned may have zero states,
+	in which case only the D matrix is meaningful.
+*/
+func controllableCanonicalForm(nums [][]float64, den []float64) (StateSpace, error) {
+	// Constants
+	den = polyTrim(den)
+	den = polyScale(1/den[0], den)
+	n := len(den) - 1
+	p := len(nums)
+
+	// Algorithm
+	DData := make([]float64, p)
+	CData := make([]float64, p*n)
+	for i, num := range nums {
+		num = polyPad(polyTrim(num), n+1)
+		if len(num) > n+1 {
+			return StateSpace{}, errors.New("The transfer function is not proper, so it does not have a state space realization.")
+		}
+
+		DData[i] = num[0]
+		for k := 0; k < n; k++ {
+			CData[i*n+k] = num[k+1] - num[0]*den[k+1]
+		}
+	}
+
+	if n == 0 {
+		return StateSpace{A: &mat.Dense{}, B: &mat.Dense{}, C: &mat.Dense{}, D: mat.NewDense(p, 1, DData)}, nil
+	}
+
+	A := mat.NewDense(n, n, nil)
+	for k := 0; k < n; k++ {
+		A.Set(0, k, -den[k+1])
+	}
+	for k := 1; k < n; k++ {
+		A.Set(k, k-1, 1)
+	}
+
+	B := mat.NewDense(n, 1, nil)
+	B.Set(0, 0, 1)
+
+	return StateSpace{A: A, B: B, C: mat.NewDense(p, n, CData), D: mat.NewDense(p, 1, DData)}, nil
+}
+
+/*
+Ss2tf
+Description:
+
+	Converts a state space model into a transfer function. Every entry of the resulting transfer function
+	has the characteristic polynomial of A as its denominator, and the numerator of entry (i,j) is
+	computed as det(sI - A + B_j C_i) + (D_ij - 1) det(sI - A).
+*/
+func Ss2tf(sys StateSpace) (TransferFunction, error) {
+	// Input Processing
+	if err := sys.Check(); err != nil {
+		return TransferFunction{}, err
+	}
+
+	// Constants
+	n, m, p := sys.Dims()
+
+	// Algorithm
+	poles, err := eigenvalues(sys.A)
+	if err != nil {
+		return TransferFunction{}, err
+	}
+	den := polyFromRoots(poles)
+
+	tf := TransferFunction{
+		Numerator:   make([][][]float64, p),
+		Denominator: make([][][]float64, p),
+		Ts:          sys.Ts,
+	}
+	for i := 0; i < p; i++ {
+		tf.Numerator[i] = make([][]float64, m)
+		tf.Denominator[i] = make([][]float64, m)
+		for j := 0; j < m; j++ {
+			// Compute A - B_j C_i
+			BjCi := mat.NewDense(n, n, nil)
+			BjCi.Outer(1, sys.B.ColView(j), sys.C.RowView(i))
+			ABC := mat.NewDense(n, n, nil)
+			ABC.Sub(sys.A, BjCi)
+
+			zerosOfABC, err := eigenvalues(ABC)
+			if err != nil {
+				return TransferFunction{}, err
+			}
+
+			num := polyAdd(polyFromRoots(zerosOfABC), polyScale(sys.D.At(i, j)-1, den))
+			tf.Numerator[i][j] = polyTrim(cleanRoundoff(num))
+			tf.Denominator[i][j] = polyTrim(den)
+		}
+	}
+
+	return tf, nil
+}
+
+/*
+cleanRoundoff
+Description:
+
+	Sets the coefficients of p that are negligible compared to its largest coefficient to zero.
+	This removes the round off left behind when two polynomials of the same degree are subtracted.
+*/
+func cleanRoundoff(p []float64) []float64 {
+	// Constants
+	maxCoeff := 0.0
+	for _, coeff := range p {
+		maxCoeff = math.Max(maxCoeff, math.Abs(coeff))
+	}
+
+	// Algorithm
+	pOut := make([]float64, len(p))
+	for i, coeff := range p {
+		if math.Abs(coeff) > 1e-10*maxCoeff {
+			pOut[i] = coeff
+		}
+	}
+	return pOut
+}
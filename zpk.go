@@ -0,0 +1,254 @@
+/*
+   zpk.go
+   Description:
+       An implementation "like" MATLAB's zpk object. Each entry (i,j) of a ZPK model is
+           Gain[i][j] * prod_k (s - Zeros[i][j][k]) / prod_k (s - Poles[i][j][k]).
+*/
+
+package goControl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type ZPK struct {
+	Zeros [][][]complex128 // Zeros[i][j] contains the zeros of the entry from input j to output i.
+	Poles [][][]complex128 // Poles[i][j] contains the poles of the entry from input j to output i.
+	Gain  [][]float64      // Gain[i][j] contains the gain of the entry from input j to output i.
+	Ts    float64          // Sample time. Ts = 0 means that the model is continuous-time.
+}
+
+/*
+GetZPK
+Description:
+
+	Creates a continuous-time single-input, single-output model from its zeros, poles and gain.
+*/
+func GetZPK(zerosIn, polesIn []complex128, gainIn float64) (ZPK, error) {
+	// Create Model
+	zpk := ZPK{
+		Zeros: [][][]complex128{{append([]complex128{}, zerosIn...)}},
+		Poles: [][][]complex128{{append([]complex128{}, polesIn...)}},
+		Gain:  [][]float64{{gainIn}},
+	}
+
+	// Check it
+	if err := zpk.Check(); err != nil {
+		return ZPK{}, err
+	}
+
+	return zpk, nil
+}
+
+/*
+Check
+Description:
+
+	Returns an error if the zeros, poles and gains of the model do not have compatible dimensions or if the
+	complex zeros and poles do not come in conjugate pairs (which would make the model's coefficients complex).
+*/
+func (zpk ZPK) Check() error {
+	// Check that the model is defined
+	if len(zpk.Gain) == 0 || len(zpk.Gain[0]) == 0 {
+		return errors.New("The gain of the ZPK model is not defined.")
+	}
+
+	// Constants
+	p, m := len(zpk.Gain), len(zpk.Gain[0])
+
+	// Check dimensions
+	if len(zpk.Zeros) != p || len(zpk.Poles) != p {
+		return fmt.Errorf("The zeros and poles of the ZPK model must have %v rows.", p)
+	}
+
+	for i := 0; i < p; i++ {
+		if len(zpk.Gain[i]) != m || len(zpk.Zeros[i]) != m || len(zpk.Poles[i]) != m {
+			return fmt.Errorf("Row %v of the ZPK model does not have %v entries.", i, m)
+		}
+
+		for j := 0; j < m; j++ {
+			if !hasConjugateSymmetry(zpk.Zeros[i][j]) {
+				return fmt.Errorf("The zeros of entry (%v,%v) do not come in complex conjugate pairs.", i, j)
+			}
+			if !hasConjugateSymmetry(zpk.Poles[i][j]) {
+				return fmt.Errorf("The poles of entry (%v,%v) do not come in complex conjugate pairs.", i, j)
+			}
+		}
+	}
+
+	// Check sample time
+	if zpk.Ts < 0 || math.IsNaN(zpk.Ts) || math.IsInf(zpk.Ts, 0) {
+		return fmt.Errorf("The sample time must be a nonnegative, finite number; received %v.", zpk.Ts)
+	}
+
+	// If nothing is wrong, return nil
+	return nil
+}
+
+/*
+Dims
+Description:
+
+	Returns the number of outputs and inputs of the model, in that order.
+*/
+func (zpk ZPK) Dims() (p, m int) {
+	if len(zpk.Gain) == 0 {
+		return 0, 0
+	}
+	return len(zpk.Gain), len(zpk.Gain[0])
+}
+
+/*
+DCGain
+Description:
+
+	Computes the steady-state gain of the model by converting it to a transfer function.
+*/
+func (zpk ZPK) DCGain() (*mat.Dense, error) {
+	tf, err := Zpk2tf(zpk)
+	if err != nil {
+		return nil, err
+	}
+	return tf.DCGain()
+}
+
+/*
+Zpk2tf
+Description:
+
+	Converts a ZPK model into a transfer function by expanding the products of its zeros and poles.
+*/
+func Zpk2tf(zpk ZPK) (TransferFunction, error) {
+	// Input Processing
+	if err := zpk.Check(); err != nil {
+		return TransferFunction{}, err
+	}
+
+	// Constants
+	p, m := zpk.Dims()
+
+	// Algorithm
+	tf := TransferFunction{
+		Numerator:   make([][][]float64, p),
+		Denominator: make([][][]float64, p),
+		Ts:          zpk.Ts,
+	}
+	for i := 0; i < p; i++ {
+		tf.Numerator[i] = make([][]float64, m)
+		tf.Denominator[i] = make([][]float64, m)
+		for j := 0; j < m; j++ {
+			tf.Numerator[i][j] = polyTrim(polyScale(zpk.Gain[i][j], polyFromRoots(zpk.Zeros[i][j])))
+			tf.Denominator[i][j] = polyFromRoots(zpk.Poles[i][j])
+		}
+	}
+
+	return tf, nil
+}
+
+/*
+Tf2zpk
+Description:
+
+	Converts a transfer function into a ZPK model by computing the roots of each numerator and denominator.
+*/
+func Tf2zpk(tf TransferFunction) (ZPK, error) {
+	// Input Processing
+	if err := tf.Check(); err != nil {
+		return ZPK{}, err
+	}
+
+	// Constants
+	p, m := tf.Dims()
+
+	// Algorithm
+	zpk := ZPK{
+		Zeros: make([][][]complex128, p),
+		Poles: make([][][]complex128, p),
+		Gain:  make([][]float64, p),
+		Ts:    tf.Ts,
+	}
+	for i := 0; i < p; i++ {
+		zpk.Zeros[i] = make([][]complex128, m)
+		zpk.Poles[i] = make([][]complex128, m)
+		zpk.Gain[i] = make([]float64, m)
+		for j := 0; j < m; j++ {
+			num, den := polyTrim(tf.Numerator[i][j]), polyTrim(tf.Denominator[i][j])
+
+			poles, err := polyRoots(den)
+			if err != nil {
+				return ZPK{}, err
+			}
+			zpk.Poles[i][j] = poles
+
+			if polyIsZero(num) {
+				zpk.Zeros[i][j] = []complex128{}
+				continue
+			}
+
+			zeros, err := polyRoots(num)
+			if err != nil {
+				return ZPK{}, err
+			}
+			zpk.Zeros[i][j] = zeros
+			zpk.Gain[i][j] = num[0] / den[0]
+		}
+	}
+
+	return zpk, nil
+}
+
+/*
+Zpk2ss
+Description:
+
+	Converts a ZPK model into a state space model (through its transfer function).
+*/
+func Zpk2ss(zpk ZPK) (StateSpace, error) {
+	tf, err := Zpk2tf(zpk)
+	if err != nil {
+		return StateSpace{}, err
+	}
+	return Tf2ss(tf)
+}
+
+/*
+hasConjugateSymmetry
+Description:
+
+	Returns true if every complex value in the slice has its conjugate (up to round off) in the slice too.
+*/
+func hasConjugateSymmetry(values []complex128) bool {
+	// Constants
+	matched := make([]bool, len(values))
+
+	// Algorithm
+	for i, v := range values {
+		if matched[i] {
+			continue
+		}
+
+		tol := 1e-9 * math.Max(1, cmplx.Abs(v))
+		if math.Abs(imag(v)) <= tol {
+			matched[i] = true
+			continue
+		}
+
+		for k := i + 1; k < len(values); k++ {
+			if !matched[k] && cmplx.Abs(values[k]-cmplx.Conj(v)) <= tol {
+				matched[i], matched[k] = true, true
+				break
+			}
+		}
+
+		if !matched[i] {
+			return false
+		}
+	}
+
+	return true
+}